@@ -0,0 +1,114 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/haiyon/fitobj/fitter"
+	"github.com/haiyon/fitobj/utils"
+)
+
+// BatchRequest defines the structure for /process/batch requests. Items is an array
+// of documents or an object mapping names to documents, all processed with the
+// options of a /process request.
+type BatchRequest struct {
+	Items       any      `json:"items"`
+	Reverse     bool     `json:"reverse"`
+	Separator   string   `json:"separator,omitempty"`
+	ArrayFormat string   `json:"arrayFormat,omitempty"`
+	Escape      string   `json:"escape,omitempty"`
+	EscapeStyle string   `json:"escapeStyle,omitempty"`
+	Include     []string `json:"include,omitempty"`
+	Exclude     []string `json:"exclude,omitempty"`
+}
+
+// BatchResult is the outcome of processing one item of a batch
+type BatchResult struct {
+	Data    map[string]any `json:"data"`
+	Success bool           `json:"success"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// BatchResponse defines the structure for /process/batch responses. Results has the
+// shape of the request items: an array in item order, or an object keyed by name.
+// Success is set when every item succeeded.
+type BatchResponse struct {
+	Results any    `json:"results"`
+	Success bool   `json:"success"`
+	Failed  int    `json:"failed"`
+	Message string `json:"message,omitempty"`
+}
+
+// BatchHandler processes several documents in one request, reporting the result of
+// each item separately. Failed items do not fail the request.
+func (s *server) BatchHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.sendError(w, r, "Only POST method is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		s.sendBodyError(w, r, err)
+		return
+	}
+
+	params := validateTransformParams(request.Separator, "", request.Include, request.Exclude)
+	switch request.Items.(type) {
+	case []any, map[string]any:
+	case nil:
+		params = append([]InvalidParam{{Name: "items", Reason: "no items provided in request"}}, params...)
+	default:
+		params = append([]InvalidParam{{Name: "items", Reason: "must be an array of documents or an object of named documents"}}, params...)
+	}
+	flattenOpts, unflattenOpts, message := s.processOptions(request.Separator, request.ArrayFormat, request.Include, request.Exclude)
+	applyEscape(&flattenOpts, &unflattenOpts, request.Escape, request.EscapeStyle)
+	params = append(params, validateEscapeParams(flattenOpts.Escape, flattenOpts.EscapeStyle, flattenOpts.Separator)...)
+	if len(params) > 0 {
+		s.sendInvalidParams(w, r, params)
+		return
+	}
+	process := func(item any) BatchResult {
+		data, ok := item.(map[string]any)
+		if !ok {
+			return BatchResult{Error: "item is not a JSON object"}
+		}
+
+		var result map[string]any
+		if request.Reverse {
+			result = fitter.UnflattenMapWithOptions(data, unflattenOpts)
+		} else {
+			result = fitter.FlattenMapWithOptions(data, "", flattenOpts)
+		}
+		return BatchResult{Data: utils.FormatNumbers(result, s.options.NumberFormat), Success: true}
+	}
+
+	response := BatchResponse{Message: message}
+	switch items := request.Items.(type) {
+	case []any:
+		results := make([]BatchResult, len(items))
+		for i, item := range items {
+			results[i] = process(item)
+			if !results[i].Success {
+				response.Failed++
+			}
+		}
+		response.Results = results
+	case map[string]any:
+		results := make(map[string]BatchResult, len(items))
+		for name, item := range items {
+			result := process(item)
+			if !result.Success {
+				response.Failed++
+			}
+			results[name] = result
+		}
+		response.Results = results
+	}
+	response.Success = response.Failed == 0
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		s.sendError(w, r, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// checkTimeout bounds each readiness check
+const checkTimeout = 2 * time.Second
+
+// CheckFunc checks a dependency the server needs to serve requests
+type CheckFunc func(ctx context.Context) error
+
+// CheckResult is the outcome of a single readiness check
+type CheckResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// HealthResponse defines the structure for liveness and readiness responses
+type HealthResponse struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// HealthzHandler reports liveness: the process is up and serving HTTP
+func (s *server) HealthzHandler(w http.ResponseWriter, r *http.Request) {
+	sendHealth(w, HealthResponse{Status: "ok"}, http.StatusOK)
+}
+
+// ReadyzHandler reports readiness: the server is started, its configuration is valid,
+// served locales are loaded and every registered dependency check passes. It answers
+// 503 while any check fails so the instance is taken out of load balancing.
+func (s *server) ReadyzHandler(w http.ResponseWriter, r *http.Request) {
+	checks := map[string]CheckFunc{
+		"server": func(ctx context.Context) error {
+			if !s.ready.Load() {
+				return fmt.Errorf("server is not accepting requests")
+			}
+			return nil
+		},
+		"config": func(ctx context.Context) error {
+			return validateOptions(s.options)
+		},
+	}
+	if s.store != nil {
+		checks["locales"] = func(ctx context.Context) error {
+			info, err := os.Stat(s.options.LocalesDir)
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() {
+				return fmt.Errorf("'%s' is not a directory", s.options.LocalesDir)
+			}
+			return nil
+		}
+	}
+	for name, check := range s.options.Checks {
+		checks[name] = check
+	}
+
+	response := HealthResponse{Status: "ok", Checks: runChecks(r.Context(), checks)}
+	status := http.StatusOK
+	for _, result := range response.Checks {
+		if result.Status != "ok" {
+			response.Status = "unavailable"
+			status = http.StatusServiceUnavailable
+		}
+	}
+
+	sendHealth(w, response, status)
+}
+
+// runChecks runs checks concurrently, each bounded by checkTimeout
+func runChecks(ctx context.Context, checks map[string]CheckFunc) map[string]CheckResult {
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results := make([]CheckResult, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, check CheckFunc) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+			defer cancel()
+
+			done := make(chan error, 1)
+			go func() { done <- check(checkCtx) }()
+
+			var err error
+			select {
+			case err = <-done:
+			case <-checkCtx.Done():
+				err = fmt.Errorf("check timed out")
+			}
+
+			if err != nil {
+				results[i] = CheckResult{Status: "failed", Error: err.Error()}
+			} else {
+				results[i] = CheckResult{Status: "ok"}
+			}
+		}(i, checks[name])
+	}
+	wg.Wait()
+
+	byName := make(map[string]CheckResult, len(names))
+	for i, name := range names {
+		byName[name] = results[i]
+	}
+	return byName
+}
+
+func sendHealth(w http.ResponseWriter, response HealthResponse, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,151 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/haiyon/fitobj/i18n"
+)
+
+// SyncRequest defines the structure for locale sync requests
+type SyncRequest struct {
+	Base        map[string]any            `json:"base"`
+	Targets     map[string]map[string]any `json:"targets"`
+	AddMissing  bool                      `json:"addMissing"`
+	RemoveExtra bool                      `json:"removeExtra"`
+	Placeholder string                    `json:"placeholder,omitempty"`
+	Separator   string                    `json:"separator,omitempty"`
+}
+
+// SyncResponse defines the structure for locale sync responses
+type SyncResponse struct {
+	Data    map[string]map[string]any   `json:"data"`
+	Changes map[string]i18n.SyncChanges `json:"changes"`
+	Success bool                        `json:"success"`
+}
+
+// SyncHandler synchronizes target bundles with a base bundle, adding missing
+// and/or removing extra keys, and returns the updated bundles with a change report
+func (s *server) SyncHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.sendError(w, r, "Only POST method is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request SyncRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		s.sendBodyError(w, r, err)
+		return
+	}
+
+	var params []InvalidParam
+	if request.Base == nil {
+		params = append(params, InvalidParam{Name: "base", Reason: "no base bundle provided in request"})
+	}
+	if len(request.Targets) == 0 {
+		params = append(params, InvalidParam{Name: "targets", Reason: "no target bundles provided in request"})
+	}
+	if !request.AddMissing && !request.RemoveExtra {
+		params = append(params, InvalidParam{Name: "addMissing", Reason: "at least one of addMissing or removeExtra must be set"})
+	}
+	if reason := validateSeparator(request.Separator); reason != "" {
+		params = append(params, InvalidParam{Name: "separator", Reason: reason})
+	}
+	if len(params) > 0 {
+		s.sendInvalidParams(w, r, params)
+		return
+	}
+
+	separator := request.Separator
+	if separator == "" {
+		separator = s.options.FlattenOpts.Separator
+	}
+
+	updated, changes, err := i18n.SyncBundles(request.Base, request.Targets, i18n.SyncOptions{
+		AddMissing:  request.AddMissing,
+		RemoveExtra: request.RemoveExtra,
+		Placeholder: request.Placeholder,
+		Separator:   separator,
+	})
+	if err != nil {
+		s.sendError(w, r, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response := SyncResponse{
+		Data:    updated,
+		Changes: changes,
+		Success: true,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		s.sendError(w, r, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// KeyResponse defines the structure for single key lookups
+type KeyResponse struct {
+	Locale  string `json:"locale"`
+	Key     string `json:"key"`
+	Value   any    `json:"value"`
+	Success bool   `json:"success"`
+}
+
+// KeyHandler serves a single flattened key from the in-memory bundle store
+func (s *server) KeyHandler(w http.ResponseWriter, r *http.Request) {
+	locale, key := r.PathValue("locale"), r.PathValue("key")
+
+	value, etag, ok := s.store.Lookup(locale, key)
+	if etag == "" {
+		s.sendError(w, r, "Unknown locale: "+locale, http.StatusNotFound)
+		return
+	}
+	if !ok {
+		s.sendError(w, r, "Unknown key: "+key, http.StatusNotFound)
+		return
+	}
+
+	if notModified(w, r, etag) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(KeyResponse{Locale: locale, Key: key, Value: value, Success: true})
+}
+
+// BundleHandler serves the full flattened bundle of a locale
+func (s *server) BundleHandler(w http.ResponseWriter, r *http.Request) {
+	locale := r.PathValue("locale")
+
+	flat, etag, ok := s.store.Bundle(locale)
+	if !ok {
+		s.sendError(w, r, "Unknown locale: "+locale, http.StatusNotFound)
+		return
+	}
+
+	if notModified(w, r, etag) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Response{Data: flat, Success: true})
+}
+
+// LocalesHandler lists the loaded locales with their bundle ETags
+func (s *server) LocalesHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"locales": s.store.Locales(), "success": true})
+}
+
+// notModified sets the ETag header and answers 304 when the client copy is current
+func notModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/haiyon/fitobj/processor"
+)
+
+// JobsResponse lists the status of the jobs scheduled by 'fitobj serve-batch'
+type JobsResponse struct {
+	Jobs    []processor.JobStatus `json:"jobs"`
+	Success bool                  `json:"success"`
+}
+
+// JobsHandler serves the status of every scheduled job
+func (s *server) JobsHandler(w http.ResponseWriter, r *http.Request) {
+	jobs, ok := s.readJobs(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(JobsResponse{Jobs: jobs, Success: true})
+}
+
+// JobHandler serves the status of one scheduled job
+func (s *server) JobHandler(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.findJob(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+// JobLogHandler serves the log of the last run of a scheduled job as plain text
+func (s *server) JobLogHandler(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.findJob(w, r)
+	if !ok {
+		return
+	}
+	if job.Log == "" {
+		s.sendError(w, r, "Job has not run yet: "+job.Name, http.StatusNotFound)
+		return
+	}
+
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(s.options.JobsStatus), filepath.Base(job.Log)))
+	if err != nil {
+		s.sendError(w, r, "Failed to read job log: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write(data)
+}
+
+// readJobs reads the job status file, answering 503 when it is not available yet
+func (s *server) readJobs(w http.ResponseWriter, r *http.Request) ([]processor.JobStatus, bool) {
+	jobs, err := processor.ReadJobStatus(s.options.JobsStatus)
+	if err != nil {
+		s.sendError(w, r, err.Error(), http.StatusServiceUnavailable)
+		return nil, false
+	}
+	return jobs, true
+}
+
+// findJob returns the status of the job named in the request path
+func (s *server) findJob(w http.ResponseWriter, r *http.Request) (processor.JobStatus, bool) {
+	jobs, ok := s.readJobs(w, r)
+	if !ok {
+		return processor.JobStatus{}, false
+	}
+
+	name := r.PathValue("name")
+	for _, job := range jobs {
+		if job.Name == name {
+			return job, true
+		}
+	}
+	s.sendError(w, r, "Unknown job: "+name, http.StatusNotFound)
+	return processor.JobStatus{}, false
+}
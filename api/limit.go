@@ -0,0 +1,164 @@
+package api
+
+import (
+	"context"
+	"expvar"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// LimitOptions bounds the concurrency of each limited endpoint
+type LimitOptions struct {
+	MaxInFlight int // requests processed at once per endpoint (0 = unlimited)
+	MaxQueue    int // requests waiting for a slot per endpoint; more are rejected with 503
+	RetryAfter  int // Retry-After seconds sent with 503 responses (default 1)
+}
+
+// limitMetricsName is the expvar name of the limiter counters
+const limitMetricsName = "fitobj_limits"
+
+var (
+	limitMetricsOnce sync.Once
+	limitMetrics     *expvar.Map
+)
+
+// limitMetricsMap returns the map publishing per-endpoint limiter counters at
+// /debug/vars, with Options.DebugVars. It is registered once per process, so
+// several servers can be started; the counters of an endpoint are those of the
+// last server started.
+func limitMetricsMap() *expvar.Map {
+	limitMetricsOnce.Do(func() {
+		if published, ok := expvar.Get(limitMetricsName).(*expvar.Map); ok {
+			limitMetrics = published
+			return
+		}
+		limitMetrics = expvar.NewMap(limitMetricsName)
+	})
+	return limitMetrics
+}
+
+// limiter enforces the in-flight and queue limits of one endpoint
+type limiter struct {
+	mu      sync.Mutex
+	active  int             // slots taken
+	waiters []chan struct{} // queued requests, in arrival order
+	options LimitOptions
+
+	inFlight *expvar.Int
+	queued   *expvar.Int
+	rejected *expvar.Int
+}
+
+func newLimiter(endpoint string, options LimitOptions) *limiter {
+	metrics := new(expvar.Map).Init()
+	l := &limiter{
+		options:  options,
+		inFlight: new(expvar.Int),
+		queued:   new(expvar.Int),
+		rejected: new(expvar.Int),
+	}
+	metrics.Set("inFlight", l.inFlight)
+	metrics.Set("queued", l.queued)
+	metrics.Set("rejected", l.rejected)
+	limitMetricsMap().Set(endpoint, metrics)
+	return l
+}
+
+// wrap limits a handler. Requests take a free slot, or wait in the queue for one
+// while the queue has room; otherwise they are rejected with 503 and Retry-After.
+// Freed slots are handed to queued requests in arrival order, before new ones.
+func (l *limiter) wrap(s *server, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		acquired, queued := l.acquire(r.Context())
+		if !acquired {
+			if !queued {
+				l.reject(s, w, r)
+			}
+			return
+		}
+
+		l.inFlight.Add(1)
+		defer func() {
+			l.inFlight.Add(-1)
+			l.release()
+		}()
+
+		next(w, r)
+	}
+}
+
+// acquire takes a slot, waiting in the queue while the queue has room. It
+// reports whether a slot was taken and, when not, whether the request was
+// queued before it was canceled.
+func (l *limiter) acquire(ctx context.Context) (acquired, queued bool) {
+	l.mu.Lock()
+	if l.active < l.options.MaxInFlight && len(l.waiters) == 0 {
+		l.active++
+		l.mu.Unlock()
+		return true, false
+	}
+	if len(l.waiters) >= l.options.MaxQueue {
+		l.mu.Unlock()
+		return false, false
+	}
+	ready := make(chan struct{})
+	l.waiters = append(l.waiters, ready)
+	l.queued.Add(1)
+	l.mu.Unlock()
+
+	select {
+	case <-ready:
+		return true, true
+	case <-ctx.Done():
+	}
+
+	l.mu.Lock()
+	for i, waiter := range l.waiters {
+		if waiter == ready {
+			l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+			l.queued.Add(-1)
+			l.mu.Unlock()
+			return false, true
+		}
+	}
+	l.mu.Unlock()
+
+	// The slot was handed over while the request was canceled: pass it on
+	l.release()
+	return false, true
+}
+
+// release frees a slot, handing it to the first queued request if any
+func (l *limiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.waiters) == 0 {
+		l.active--
+		return
+	}
+	ready := l.waiters[0]
+	l.waiters = l.waiters[1:]
+	l.queued.Add(-1)
+	close(ready)
+}
+
+func (l *limiter) reject(s *server, w http.ResponseWriter, r *http.Request) {
+	l.rejected.Add(1)
+
+	retryAfter := l.options.RetryAfter
+	if retryAfter <= 0 {
+		retryAfter = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	s.sendError(w, r, "Too many concurrent requests, retry later", http.StatusServiceUnavailable)
+}
+
+// handle registers a handler, limited per endpoint when MaxInFlight is set. Health
+// endpoints are registered directly so saturation cannot starve them.
+func (s *server) handle(pattern string, handler http.HandlerFunc) {
+	if s.options.Limits.MaxInFlight > 0 {
+		handler = newLimiter(pattern, s.options.Limits).wrap(s, handler)
+	}
+	s.mux.HandleFunc(pattern, handler)
+}
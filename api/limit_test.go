@@ -0,0 +1,151 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// waitFor polls a condition until it holds, failing the test after a second
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// limiterCounter reads a limiter counter of an endpoint from the expvar map
+func limiterCounter(endpoint, name string) int64 {
+	metrics, ok := limitMetricsMap().Get(endpoint).(*expvar.Map)
+	if !ok {
+		return -1
+	}
+	counter, ok := metrics.Get(name).(*expvar.Int)
+	if !ok {
+		return -1
+	}
+	return counter.Value()
+}
+
+func TestLimiterSaturation(t *testing.T) {
+	options := DefaultOptions()
+	options.Limits = LimitOptions{MaxInFlight: 1, MaxQueue: 1, RetryAfter: 7}
+	s := newServer(options)
+
+	entered, unblock := make(chan struct{}, 2), make(chan struct{})
+	s.handle("/test/saturation", func(w http.ResponseWriter, r *http.Request) {
+		entered <- struct{}{}
+		<-unblock
+		w.WriteHeader(http.StatusNoContent)
+	})
+	ts := httptest.NewServer(s.mux)
+	defer ts.Close()
+
+	var wg sync.WaitGroup
+	statuses := make(chan int, 2)
+	get := func() {
+		defer wg.Done()
+		resp, err := http.Get(ts.URL + "/test/saturation")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		resp.Body.Close()
+		statuses <- resp.StatusCode
+	}
+
+	// The first request takes the slot, the second waits in the queue
+	wg.Add(2)
+	go get()
+	<-entered
+	go get()
+	waitFor(t, "the queued request", func() bool { return limiterCounter("/test/saturation", "queued") == 1 })
+
+	resp, err := http.Get(ts.URL + "/test/saturation")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var problem ProblemDetails
+	if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable || problem.Status != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503, got %d (problem status %d)", resp.StatusCode, problem.Status)
+	}
+	if got := resp.Header.Get("Retry-After"); got != "7" {
+		t.Errorf("Expected Retry-After 7, got %q", got)
+	}
+	if got := resp.Header.Get("Content-Type"); got != ProblemContentType {
+		t.Errorf("Expected %s, got %q", ProblemContentType, got)
+	}
+	if got := limiterCounter("/test/saturation", "rejected"); got != 1 {
+		t.Errorf("Expected 1 rejected request, got %d", got)
+	}
+
+	close(unblock)
+	wg.Wait()
+	close(statuses)
+	for status := range statuses {
+		if status != http.StatusNoContent {
+			t.Errorf("Expected the admitted requests to complete, got %d", status)
+		}
+	}
+	if inFlight, queued := limiterCounter("/test/saturation", "inFlight"), limiterCounter("/test/saturation", "queued"); inFlight != 0 || queued != 0 {
+		t.Errorf("Expected the counters back to 0, got %d in flight and %d queued", inFlight, queued)
+	}
+}
+
+func TestLimiterHandsSlotsOverInOrder(t *testing.T) {
+	l := newLimiter("/test/order", LimitOptions{MaxInFlight: 1, MaxQueue: 3})
+	if acquired, _ := l.acquire(context.Background()); !acquired {
+		t.Fatal("Expected a free slot")
+	}
+
+	order := make(chan int, 3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			if acquired, _ := l.acquire(context.Background()); acquired {
+				order <- i
+			}
+		}()
+		waitFor(t, "the queued request", func() bool { return l.queued.Value() == int64(i+1) })
+	}
+
+	for expected := 0; expected < 3; expected++ {
+		l.release()
+		if got := <-order; got != expected {
+			t.Fatalf("Expected waiter %d to get the slot, got %d", expected, got)
+		}
+
+		// A new arrival does not take the slot handed over, nor jump the queue
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if acquired, queued := l.acquire(ctx); acquired || !queued {
+			t.Fatalf("Expected a new arrival to be queued behind the waiters, got acquired %v, queued %v", acquired, queued)
+		}
+	}
+	l.release()
+	if l.active != 0 || len(l.waiters) != 0 || l.queued.Value() != 0 {
+		t.Errorf("Expected the limiter to be idle, got %d active and %d waiters", l.active, len(l.waiters))
+	}
+}
+
+func TestLimitMetricsRegisteredOnce(t *testing.T) {
+	// Starting a second server registers its endpoints in the same map
+	first := limitMetricsMap()
+	newLimiter("/test/twice", LimitOptions{MaxInFlight: 1})
+	newLimiter("/test/twice", LimitOptions{MaxInFlight: 1})
+	if limitMetricsMap() != first || expvar.Get(limitMetricsName) != first {
+		t.Error("Expected the limiter counters published once")
+	}
+}
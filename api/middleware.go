@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// RequestIDHeader carries the request ID in requests and responses
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID matches request IDs accepted from clients
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+type contextKey string
+
+const requestIDKey contextKey = "requestID"
+
+// RequestIDFromContext returns the request ID of a request context
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// withRequestID assigns every request an ID, taken from the X-Request-ID header when
+// the client sent a valid one and generated otherwise. The ID is stored in the
+// request context, echoed in the response header and written to the access log
+// of the logger.
+func withRequestID(next http.Handler, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+
+		next.ServeHTTP(recorder, r.WithContext(context.WithValue(r.Context(), requestIDKey, id)))
+
+		elapsed := time.Since(start).Round(time.Microsecond)
+		logger.Info(fmt.Sprintf("[%s] %s %s %d %s", id, r.Method, r.URL.Path, recorder.status, elapsed),
+			"request_id", id, "method", r.Method, "path", r.URL.Path, "status", recorder.status, "duration", elapsed)
+	})
+}
+
+// newRequestID generates a random 128-bit request ID
+func newRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// statusRecorder records the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// withBodyLimit caps the size of request bodies; reading past the limit fails
+// with an *http.MaxBytesError. A limit of 0 disables it.
+func withBodyLimit(next http.Handler, limit int64) http.Handler {
+	if limit <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+		next.ServeHTTP(w, r)
+	})
+}
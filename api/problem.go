@@ -0,0 +1,146 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/haiyon/fitobj/fitter"
+)
+
+// ProblemContentType is the media type of RFC 7807 error responses
+const ProblemContentType = "application/problem+json"
+
+// Problem types. Errors identified by their status code alone use about:blank.
+const (
+	ProblemInvalidBody   = "urn:fitobj:problem:invalid-body"
+	ProblemInvalidParams = "urn:fitobj:problem:invalid-params"
+)
+
+// InvalidParam describes a request field that failed validation
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// ProblemDetails is an RFC 7807 error response. Validation failures list the
+// offending request fields in invalid-params.
+type ProblemDetails struct {
+	Type          string         `json:"type"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail,omitempty"`
+	Instance      string         `json:"instance,omitempty"`
+	RequestID     string         `json:"requestId,omitempty"`
+	InvalidParams []InvalidParam `json:"invalid-params,omitempty"`
+}
+
+// sendError writes an about:blank problem with a detail message
+func (s *server) sendError(w http.ResponseWriter, r *http.Request, detail string, statusCode int) {
+	s.sendProblem(w, r, ProblemDetails{Status: statusCode, Detail: detail})
+}
+
+// sendBodyError writes a problem for a request body that could not be decoded, or
+// 413 when it exceeds the size limit
+func (s *server) sendBodyError(w http.ResponseWriter, r *http.Request, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		s.sendError(w, r, fmt.Sprintf("Request body exceeds %d bytes", tooLarge.Limit), http.StatusRequestEntityTooLarge)
+		return
+	}
+	s.sendProblem(w, r, ProblemDetails{
+		Type:   ProblemInvalidBody,
+		Title:  "Invalid request body",
+		Status: http.StatusBadRequest,
+		Detail: "Failed to parse request body: " + err.Error(),
+	})
+}
+
+// sendInvalidParams writes a validation problem listing the invalid request fields
+func (s *server) sendInvalidParams(w http.ResponseWriter, r *http.Request, params []InvalidParam) {
+	names := make([]string, len(params))
+	for i, param := range params {
+		names[i] = param.Name
+	}
+	s.sendProblem(w, r, ProblemDetails{
+		Type:          ProblemInvalidParams,
+		Title:         "Invalid request parameters",
+		Status:        http.StatusBadRequest,
+		Detail:        "Invalid fields: " + strings.Join(names, ", "),
+		InvalidParams: params,
+	})
+}
+
+// sendProblem fills in the defaults of a problem (type, title, instance and the
+// request ID set by the middleware), logs it and writes it as problem+json
+func (s *server) sendProblem(w http.ResponseWriter, r *http.Request, problem ProblemDetails) {
+	if problem.Type == "" {
+		problem.Type = "about:blank"
+	}
+	if problem.Title == "" {
+		problem.Title = http.StatusText(problem.Status)
+	}
+	problem.Instance = r.URL.Path
+	problem.RequestID = w.Header().Get(RequestIDHeader)
+
+	if problem.RequestID != "" {
+		s.logger().Warn(fmt.Sprintf("[%s] error %d: %s", problem.RequestID, problem.Status, problem.Detail),
+			"request_id", problem.RequestID, "status", problem.Status, "detail", problem.Detail)
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+// validateSeparator checks a separator requested by a client
+func validateSeparator(separator string) string {
+	switch {
+	case strings.TrimSpace(separator) == "" && separator != "":
+		return "separator must not be whitespace only"
+	case strings.ContainsAny(separator, "[]"):
+		return "separator must not contain brackets, which are reserved for array notation"
+	case len(separator) > 16:
+		return "separator must be at most 16 bytes"
+	}
+	return ""
+}
+
+// validateEscapeParams checks the escape and escape style a request applies to
+// its separator, both possibly server defaults
+func validateEscapeParams(escape, style, separator string) []InvalidParam {
+	switch style {
+	case "", fitter.EscapePrefix, fitter.EscapeQuote:
+	default:
+		return []InvalidParam{{Name: "escapeStyle", Reason: "must be 'prefix' or 'quote'"}}
+	}
+	if err := fitter.ValidateEscape(escape, style, separator); err != nil {
+		return []InvalidParam{{Name: "escape", Reason: err.Error()}}
+	}
+	return nil
+}
+
+// validateTransformParams checks the options shared by the transformation requests
+func validateTransformParams(separator, arrayFormat string, include, exclude []string) []InvalidParam {
+	var params []InvalidParam
+	if reason := validateSeparator(separator); reason != "" {
+		params = append(params, InvalidParam{Name: "separator", Reason: reason})
+	}
+	switch arrayFormat {
+	case "", "index", "bracket":
+	default:
+		params = append(params, InvalidParam{Name: "arrayFormat", Reason: "must be 'index' or 'bracket'"})
+	}
+	checkPatterns := func(name string, patterns []string) {
+		for i, pattern := range patterns {
+			if err := fitter.ValidateKeyPatterns([]string{pattern}, nil); err != nil {
+				params = append(params, InvalidParam{Name: fmt.Sprintf("%s[%d]", name, i), Reason: err.Error()})
+			}
+		}
+	}
+	checkPatterns("include", include)
+	checkPatterns("exclude", exclude)
+	return params
+}
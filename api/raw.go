@@ -0,0 +1,43 @@
+package api
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// RawHeader requests raw output like the raw=true query parameter
+const RawHeader = "X-Raw-Output"
+
+// wantsRaw reports whether a request asks for the transformed document as the entire
+// response body, through the raw query parameter or the X-Raw-Output header
+func wantsRaw(r *http.Request) bool {
+	value := r.URL.Query().Get("raw")
+	if value == "" {
+		value = r.Header.Get(RawHeader)
+	}
+	raw, _ := strconv.ParseBool(value)
+	return raw
+}
+
+// rawContentType returns the JSON media type of the request body, so vendor types
+// such as application/vnd.acme+json are passed through to the response
+func rawContentType(r *http.Request) string {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || !(mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")) {
+		return "application/json"
+	}
+	return mediaType
+}
+
+// sendRaw writes a document as the entire response body. A warning message is
+// carried in the Warning header since there is no envelope to hold it.
+func sendRaw(w http.ResponseWriter, r *http.Request, data map[string]any, message string) {
+	if message != "" {
+		w.Header().Set("Warning", `199 fitobj "`+strings.ReplaceAll(message, `"`, `'`)+`"`)
+	}
+	w.Header().Set("Content-Type", rawContentType(r))
+	json.NewEncoder(w).Encode(data)
+}
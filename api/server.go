@@ -1,11 +1,22 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"expvar"
 	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	"github.com/haiyon/fitobj/fitter"
+	"github.com/haiyon/fitobj/i18n"
+	"github.com/haiyon/fitobj/utils"
 )
 
 // Options configures the API server behavior
@@ -13,6 +24,21 @@ type Options struct {
 	Port          string
 	FlattenOpts   fitter.FlattenOptions
 	UnflattenOpts fitter.UnflattenOptions
+	NumberFormat  utils.NumberFormat
+	LocalesDir    string               // directory of locale files served from memory (optional)
+	WatchLocales  bool                 // reload locale files when they change
+	Checks        map[string]CheckFunc // additional dependency checks reported by /readyz (optional)
+	Limits        LimitOptions         // per-endpoint concurrency limits (health endpoints are exempt)
+	JobsStatus    string               // jobs.json written by 'fitobj serve-batch' for scheduled jobs, served at /v1/jobs (optional)
+	DebugVars     bool                 // serve the expvar counters, limiter counters included, at /debug/vars (off by default, as they expose the process command line)
+
+	ReadTimeout     time.Duration // time to read a request, body included (0 = no limit)
+	WriteTimeout    time.Duration // time to process a request and write its response (0 = no limit)
+	IdleTimeout     time.Duration // time a keep-alive connection waits for the next request (0 = no limit)
+	ShutdownTimeout time.Duration // time in-flight requests get to complete on shutdown (0 = no limit)
+	MaxBodySize     int64         // request body size limit in bytes; larger bodies get 413 (0 = no limit)
+
+	Logger *slog.Logger // receives startup lines, the access log and request errors (default: slog.Default())
 }
 
 // DefaultOptions returns the default options for the API server
@@ -21,6 +47,13 @@ func DefaultOptions() Options {
 		Port:          "8080",
 		FlattenOpts:   fitter.DefaultFlattenOptions(),
 		UnflattenOpts: fitter.DefaultUnflattenOptions(),
+		NumberFormat:  utils.DefaultNumberFormat(),
+
+		ReadTimeout:     30 * time.Second,
+		WriteTimeout:    60 * time.Second,
+		IdleTimeout:     120 * time.Second,
+		ShutdownTimeout: 15 * time.Second,
+		MaxBodySize:     10 << 20,
 	}
 }
 
@@ -30,6 +63,10 @@ type Request struct {
 	Reverse     bool           `json:"reverse"`
 	Separator   string         `json:"separator,omitempty"`
 	ArrayFormat string         `json:"arrayFormat,omitempty"`
+	Escape      string         `json:"escape,omitempty"`      // escape of separators inside keys (default: the server's)
+	EscapeStyle string         `json:"escapeStyle,omitempty"` // "prefix" or "quote" (default: the server's)
+	Include     []string       `json:"include,omitempty"`
+	Exclude     []string       `json:"exclude,omitempty"`
 }
 
 // Response defines the structure for API responses
@@ -39,68 +76,117 @@ type Response struct {
 	Message string         `json:"message,omitempty"`
 }
 
-// ErrorResponse defines the structure for error responses
-type ErrorResponse struct {
-	Success bool   `json:"success"`
-	Error   string `json:"error"`
-}
-
 type server struct {
 	options Options
+	mux     *http.ServeMux
+	store   *i18n.BundleStore
+	ready   atomic.Bool // set while the server accepts requests
 }
 
 func newServer(options Options) *server {
-	return &server{options: options}
+	return &server{options: options, mux: http.NewServeMux()}
 }
 
-// ProcessHandler handles API requests to process JSON data
+// logger returns the logger of the server options, the slog default by default
+func (s *server) logger() *slog.Logger {
+	if s.options.Logger != nil {
+		return s.options.Logger
+	}
+	return slog.Default()
+}
+
+// ProcessHandler handles API requests to process JSON data. With raw=true the
+// document is the entire response body.
 func (s *server) ProcessHandler(w http.ResponseWriter, r *http.Request) {
+	raw := wantsRaw(r)
 	if r.Method != http.MethodPost {
-		s.sendError(w, "Only POST method is supported", http.StatusMethodNotAllowed)
+		s.sendError(w, r, "Only POST method is supported", http.StatusMethodNotAllowed)
 		return
 	}
 
 	var request Request
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		s.sendError(w, "Failed to parse request body", http.StatusBadRequest)
+		s.sendBodyError(w, r, err)
 		return
 	}
 
 	if request.Data == nil {
-		s.sendError(w, "No data provided in request", http.StatusBadRequest)
+		s.sendInvalidParams(w, r, []InvalidParam{{Name: "data", Reason: "no data provided in request"}})
 		return
 	}
 
-	// Create options copies
+	// An unknown array format only produces a warning here, for compatibility
+	if params := validateTransformParams(request.Separator, "", request.Include, request.Exclude); len(params) > 0 {
+		s.sendInvalidParams(w, r, params)
+		return
+	}
+
+	flattenOpts, unflattenOpts, message := s.processOptions(request.Separator, request.ArrayFormat, request.Include, request.Exclude)
+	applyEscape(&flattenOpts, &unflattenOpts, request.Escape, request.EscapeStyle)
+	if params := validateEscapeParams(flattenOpts.Escape, flattenOpts.EscapeStyle, flattenOpts.Separator); len(params) > 0 {
+		s.sendInvalidParams(w, r, params)
+		return
+	}
+
+	// Process the data
+	var result map[string]any
+	if request.Reverse {
+		result = fitter.UnflattenMapWithOptions(request.Data, unflattenOpts)
+	} else {
+		result = fitter.FlattenMapWithOptions(request.Data, "", flattenOpts)
+	}
+
+	s.sendResult(w, r, raw, result, message)
+}
+
+// processOptions applies the options of a /process request to copies of the server
+// defaults. An unknown array format is ignored with a warning message.
+func (s *server) processOptions(separator, arrayFormat string, include, exclude []string) (fitter.FlattenOptions, fitter.UnflattenOptions, string) {
 	flattenOpts := s.options.FlattenOpts
 	unflattenOpts := s.options.UnflattenOpts
 
 	var message string
 
 	// Apply custom options if provided
-	if request.Separator != "" {
-		flattenOpts.Separator = request.Separator
-		unflattenOpts.Separator = request.Separator
+	if separator != "" {
+		flattenOpts.Separator = separator
+		unflattenOpts.Separator = separator
 	}
 
-	if request.ArrayFormat != "" {
-		if request.ArrayFormat == "index" || request.ArrayFormat == "bracket" {
-			flattenOpts.ArrayFormatting = request.ArrayFormat
-			unflattenOpts.SupportBracketNotation = request.ArrayFormat == "bracket"
+	if arrayFormat != "" {
+		if arrayFormat == "index" || arrayFormat == "bracket" {
+			flattenOpts.ArrayFormatting = arrayFormat
+			unflattenOpts.SupportBracketNotation = arrayFormat == "bracket"
 		} else {
 			message = "Warning: Invalid array format specified, using default ('index')."
 		}
 	}
 
-	// Process the data
-	var result map[string]any
-	if request.Reverse {
-		result = fitter.UnflattenMapWithOptions(request.Data, unflattenOpts)
-	} else {
-		result = fitter.FlattenMapWithOptions(request.Data, "", flattenOpts)
+	flattenOpts.IncludeKeys = include
+	flattenOpts.ExcludeKeys = exclude
+
+	return flattenOpts, unflattenOpts, message
+}
+
+// applyEscape applies the escape options of a request to copies of the server
+// defaults, leaving them unchanged when unset
+func applyEscape(flattenOpts *fitter.FlattenOptions, unflattenOpts *fitter.UnflattenOptions, escape, style string) {
+	if escape != "" {
+		flattenOpts.Escape, unflattenOpts.Escape = escape, escape
+	}
+	if style != "" {
+		flattenOpts.EscapeStyle, unflattenOpts.EscapeStyle = style, style
+	}
+}
+
+// sendResult writes a transformed document, wrapped in a Response or raw
+func (s *server) sendResult(w http.ResponseWriter, r *http.Request, raw bool, result map[string]any, message string) {
+	result = utils.FormatNumbers(result, s.options.NumberFormat)
+	if raw {
+		sendRaw(w, r, result, message)
+		return
 	}
 
-	// Send response
 	response := Response{
 		Data:    result,
 		Success: true,
@@ -109,19 +195,10 @@ func (s *server) ProcessHandler(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		s.sendError(w, "Failed to encode response", http.StatusInternalServerError)
+		s.sendError(w, r, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
 
-func (s *server) sendError(w http.ResponseWriter, message string, statusCode int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(ErrorResponse{
-		Success: false,
-		Error:   message,
-	})
-}
-
 // StartServer starts the API server on the specified port
 func StartServer(port string) error {
 	options := DefaultOptions()
@@ -129,7 +206,9 @@ func StartServer(port string) error {
 	return StartServerWithOptions(options)
 }
 
-// StartServerWithOptions starts the API server with custom options
+// StartServerWithOptions starts the API server with custom options. It serves until
+// SIGINT or SIGTERM, then stops accepting connections, reports not ready and waits
+// up to ShutdownTimeout for in-flight requests to complete.
 func StartServerWithOptions(options Options) error {
 	// Ensure proper defaults
 	if options.FlattenOpts.MaxDepth == 0 {
@@ -138,21 +217,133 @@ func StartServerWithOptions(options Options) error {
 	if !options.FlattenOpts.IncludeArrayIndices {
 		options.FlattenOpts.IncludeArrayIndices = true
 	}
+	if options.UnflattenOpts.MaxDepth == 0 {
+		options.UnflattenOpts.MaxDepth = -1
+	}
+
+	if err := validateOptions(options); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	s := newServer(options)
 
 	// Register handlers
-	http.HandleFunc("/process", s.ProcessHandler)
-	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
-	})
-
-	fmt.Printf("API server running at http://localhost:%s/process\n", options.Port)
-	fmt.Printf("Health check available at http://localhost:%s/health\n", options.Port)
-	fmt.Printf("Using separator: '%s', array format: '%s'\n",
-		options.FlattenOpts.Separator,
-		options.FlattenOpts.ArrayFormatting)
-
-	return http.ListenAndServe(":"+options.Port, nil)
+	s.handle("/process", s.ProcessHandler)
+	s.handle("/process/batch", s.BatchHandler)
+	s.handle("/process/file", s.FileHandler)
+	s.handle("/flatten", s.FlattenHandler)
+	s.handle("/unflatten", s.UnflattenHandler)
+	s.handle("GET /options", s.OptionsHandler)
+	s.handle("/v1/i18n/sync", s.SyncHandler)
+	s.handle("GET /ui", s.UIHandler)
+	s.mux.HandleFunc("/health", s.HealthzHandler)
+	s.mux.HandleFunc("GET /healthz", s.HealthzHandler)
+	s.mux.HandleFunc("GET /readyz", s.ReadyzHandler)
+	if options.DebugVars {
+		s.mux.Handle("GET /debug/vars", expvar.Handler())
+	}
+
+	logger := s.logger()
+	logger.Info(fmt.Sprintf("API server running at http://localhost:%s/process", options.Port), "port", options.Port)
+	logger.Info(fmt.Sprintf("Health checks available at http://localhost:%s/healthz and /readyz", options.Port))
+	logger.Info(fmt.Sprintf("Locale sync available at http://localhost:%s/v1/i18n/sync", options.Port))
+	logger.Info(fmt.Sprintf("Paste-and-convert page at http://localhost:%s/ui", options.Port))
+	if options.DebugVars {
+		logger.Info(fmt.Sprintf("Debug variables available at http://localhost:%s/debug/vars", options.Port))
+	}
+
+	if options.LocalesDir != "" {
+		store, err := i18n.NewBundleStore(options.LocalesDir, options.FlattenOpts)
+		if err != nil {
+			return fmt.Errorf("failed to load locales: %v", err)
+		}
+		s.store = store
+
+		if options.WatchLocales {
+			err := store.Watch(ctx.Done(), func(err error) {
+				logger.Error(fmt.Sprintf("Locale reload failed: %v", err), "error", err)
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		s.handle("GET /v1/i18n/locales", s.LocalesHandler)
+		s.handle("GET /v1/i18n/bundle/{locale}", s.BundleHandler)
+		s.handle("GET /v1/i18n/key/{locale}/{key}", s.KeyHandler)
+
+		logger.Info(fmt.Sprintf("Serving %d locales from %s at http://localhost:%s/v1/i18n/bundle/{locale}",
+			len(store.Locales()), options.LocalesDir, options.Port),
+			"locales", len(store.Locales()), "dir", options.LocalesDir)
+	}
+
+	if options.JobsStatus != "" {
+		s.handle("GET /v1/jobs", s.JobsHandler)
+		s.handle("GET /v1/jobs/{name}", s.JobHandler)
+		s.handle("GET /v1/jobs/{name}/log", s.JobLogHandler)
+
+		logger.Info(fmt.Sprintf("Scheduled job status available at http://localhost:%s/v1/jobs", options.Port))
+	}
+
+	logger.Info(fmt.Sprintf("Using separator: '%s', array format: '%s'",
+		options.FlattenOpts.Separator, options.FlattenOpts.ArrayFormatting),
+		"separator", options.FlattenOpts.Separator, "array_format", options.FlattenOpts.ArrayFormatting)
+
+	httpServer := &http.Server{
+		Handler:      withRequestID(withBodyLimit(s.mux, options.MaxBodySize), logger),
+		ReadTimeout:  options.ReadTimeout,
+		WriteTimeout: options.WriteTimeout,
+		IdleTimeout:  options.IdleTimeout,
+	}
+
+	listener, err := net.Listen("tcp", ":"+options.Port)
+	if err != nil {
+		return err
+	}
+	s.ready.Store(true)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	logger.Info("Shutting down API server...")
+	s.ready.Store(false)
+
+	shutdownCtx := context.Background()
+	if options.ShutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		shutdownCtx, cancel = context.WithTimeout(shutdownCtx, options.ShutdownTimeout)
+		defer cancel()
+	}
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down gracefully: %v", err)
+	}
+	return nil
+}
+
+// validateOptions checks that the server options are usable
+func validateOptions(options Options) error {
+	if options.Limits.MaxInFlight < 0 || options.Limits.MaxQueue < 0 {
+		return fmt.Errorf("concurrency limits must not be negative")
+	}
+	if options.ReadTimeout < 0 || options.WriteTimeout < 0 || options.IdleTimeout < 0 || options.ShutdownTimeout < 0 {
+		return fmt.Errorf("timeouts must not be negative")
+	}
+	if options.MaxBodySize < 0 {
+		return fmt.Errorf("max body size must not be negative")
+	}
+	if err := fitter.ValidateEscape(options.FlattenOpts.Escape, options.FlattenOpts.EscapeStyle, options.FlattenOpts.Separator); err != nil {
+		return err
+	}
+	return utils.ValidateNumberFormat(options.NumberFormat)
 }
@@ -0,0 +1,216 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/haiyon/fitobj/fitter"
+)
+
+// FlattenRequest defines the structure for /flatten requests. Unset options fall
+// back to the server defaults reported by /options.
+type FlattenRequest struct {
+	Data                map[string]any `json:"data"`
+	Separator           string         `json:"separator,omitempty"`
+	ArrayFormat         string         `json:"arrayFormat,omitempty"`
+	Escape              string         `json:"escape,omitempty"`
+	EscapeStyle         string         `json:"escapeStyle,omitempty"`
+	MaxDepth            *int           `json:"maxDepth,omitempty"`
+	IncludeArrayIndices *bool          `json:"includeArrayIndices,omitempty"`
+	Include             []string       `json:"include,omitempty"`
+	Exclude             []string       `json:"exclude,omitempty"`
+}
+
+// UnflattenRequest defines the structure for /unflatten requests. Unset options fall
+// back to the server defaults reported by /options.
+type UnflattenRequest struct {
+	Data                   map[string]any `json:"data"`
+	Separator              string         `json:"separator,omitempty"`
+	Escape                 string         `json:"escape,omitempty"`
+	EscapeStyle            string         `json:"escapeStyle,omitempty"`
+	DetectArrays           *bool          `json:"detectArrays,omitempty"`
+	SupportBracketNotation *bool          `json:"supportBracketNotation,omitempty"`
+	CoerceTypes            *bool          `json:"coerceTypes,omitempty"`
+	MaxDepth               *int           `json:"maxDepth,omitempty"`
+}
+
+// FlattenDefaults reports the server defaults for flattening
+type FlattenDefaults struct {
+	Separator           string `json:"separator"`
+	ArrayFormat         string `json:"arrayFormat"`
+	Escape              string `json:"escape"`
+	EscapeStyle         string `json:"escapeStyle"`
+	MaxDepth            int    `json:"maxDepth"`
+	IncludeArrayIndices bool   `json:"includeArrayIndices"`
+}
+
+// UnflattenDefaults reports the server defaults for unflattening
+type UnflattenDefaults struct {
+	Separator              string `json:"separator"`
+	Escape                 string `json:"escape"`
+	EscapeStyle            string `json:"escapeStyle"`
+	DetectArrays           bool   `json:"detectArrays"`
+	SupportBracketNotation bool   `json:"supportBracketNotation"`
+	CoerceTypes            bool   `json:"coerceTypes"`
+	MaxDepth               int    `json:"maxDepth"`
+}
+
+// NumberFormatDefaults reports how numbers are rendered in responses
+type NumberFormatDefaults struct {
+	Precision     int    `json:"precision"`
+	IntegralAsInt bool   `json:"integralAsInt"`
+	Notation      string `json:"notation"`
+}
+
+// OptionsResponse defines the structure for /options responses
+type OptionsResponse struct {
+	Flatten      FlattenDefaults      `json:"flatten"`
+	Unflatten    UnflattenDefaults    `json:"unflatten"`
+	NumberFormat NumberFormatDefaults `json:"numberFormat"`
+}
+
+// FlattenHandler flattens the request document
+func (s *server) FlattenHandler(w http.ResponseWriter, r *http.Request) {
+	raw := wantsRaw(r)
+	if r.Method != http.MethodPost {
+		s.sendError(w, r, "Only POST method is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request FlattenRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		s.sendBodyError(w, r, err)
+		return
+	}
+
+	params := validateTransformParams(request.Separator, request.ArrayFormat, request.Include, request.Exclude)
+	if request.Data == nil {
+		params = append([]InvalidParam{{Name: "data", Reason: "no data provided in request"}}, params...)
+	}
+	if request.MaxDepth != nil && *request.MaxDepth < -1 {
+		params = append(params, InvalidParam{Name: "maxDepth", Reason: "must be 0 or -1 (no limit) or greater"})
+	}
+
+	opts := s.options.FlattenOpts
+	if request.Separator != "" {
+		opts.Separator = request.Separator
+	}
+	if request.ArrayFormat != "" {
+		opts.ArrayFormatting = request.ArrayFormat
+	}
+	if request.Escape != "" {
+		opts.Escape = request.Escape
+	}
+	if request.EscapeStyle != "" {
+		opts.EscapeStyle = request.EscapeStyle
+	}
+	params = append(params, validateEscapeParams(opts.Escape, opts.EscapeStyle, opts.Separator)...)
+	if len(params) > 0 {
+		s.sendInvalidParams(w, r, params)
+		return
+	}
+	if request.MaxDepth != nil {
+		opts.MaxDepth = *request.MaxDepth
+	}
+	if request.IncludeArrayIndices != nil {
+		opts.IncludeArrayIndices = *request.IncludeArrayIndices
+	}
+	opts.IncludeKeys = request.Include
+	opts.ExcludeKeys = request.Exclude
+
+	s.sendResult(w, r, raw, fitter.FlattenMapWithOptions(request.Data, "", opts), "")
+}
+
+// UnflattenHandler unflattens the request document
+func (s *server) UnflattenHandler(w http.ResponseWriter, r *http.Request) {
+	raw := wantsRaw(r)
+	if r.Method != http.MethodPost {
+		s.sendError(w, r, "Only POST method is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request UnflattenRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		s.sendBodyError(w, r, err)
+		return
+	}
+
+	params := validateTransformParams(request.Separator, "", nil, nil)
+	if request.Data == nil {
+		params = append([]InvalidParam{{Name: "data", Reason: "no data provided in request"}}, params...)
+	}
+	if request.MaxDepth != nil && *request.MaxDepth < -1 {
+		params = append(params, InvalidParam{Name: "maxDepth", Reason: "must be 0 or -1 (no limit) or greater"})
+	}
+
+	opts := s.options.UnflattenOpts
+	if request.Separator != "" {
+		opts.Separator = request.Separator
+	}
+	if request.Escape != "" {
+		opts.Escape = request.Escape
+	}
+	if request.EscapeStyle != "" {
+		opts.EscapeStyle = request.EscapeStyle
+	}
+	params = append(params, validateEscapeParams(opts.Escape, opts.EscapeStyle, opts.Separator)...)
+	if len(params) > 0 {
+		s.sendInvalidParams(w, r, params)
+		return
+	}
+	if request.DetectArrays != nil {
+		opts.DetectArrays = *request.DetectArrays
+	}
+	if request.SupportBracketNotation != nil {
+		opts.SupportBracketNotation = *request.SupportBracketNotation
+	}
+	if request.CoerceTypes != nil {
+		opts.CoerceTypes = *request.CoerceTypes
+	}
+	if request.MaxDepth != nil {
+		opts.MaxDepth = *request.MaxDepth
+	}
+
+	s.sendResult(w, r, raw, fitter.UnflattenMapWithOptions(request.Data, opts), "")
+}
+
+// OptionsHandler reports the server defaults applied to unset request options
+func (s *server) OptionsHandler(w http.ResponseWriter, r *http.Request) {
+	flattenOpts := s.options.FlattenOpts
+	unflattenOpts := s.options.UnflattenOpts
+	numberFormat := s.options.NumberFormat
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(OptionsResponse{
+		Flatten: FlattenDefaults{
+			Separator:           flattenOpts.Separator,
+			ArrayFormat:         flattenOpts.ArrayFormatting,
+			Escape:              flattenOpts.Escape,
+			EscapeStyle:         escapeStyle(flattenOpts.EscapeStyle),
+			MaxDepth:            flattenOpts.MaxDepth,
+			IncludeArrayIndices: flattenOpts.IncludeArrayIndices,
+		},
+		Unflatten: UnflattenDefaults{
+			Separator:              unflattenOpts.Separator,
+			Escape:                 unflattenOpts.Escape,
+			EscapeStyle:            escapeStyle(unflattenOpts.EscapeStyle),
+			DetectArrays:           unflattenOpts.DetectArrays,
+			SupportBracketNotation: unflattenOpts.SupportBracketNotation,
+			CoerceTypes:            unflattenOpts.CoerceTypes,
+			MaxDepth:               unflattenOpts.MaxDepth,
+		},
+		NumberFormat: NumberFormatDefaults{
+			Precision:     numberFormat.Precision,
+			IntegralAsInt: numberFormat.IntegralAsInt,
+			Notation:      numberFormat.Notation,
+		},
+	})
+}
+
+// escapeStyle returns the escape style applied for a style option
+func escapeStyle(style string) string {
+	if style == "" {
+		return fitter.EscapePrefix
+	}
+	return style
+}
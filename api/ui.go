@@ -0,0 +1,102 @@
+package api
+
+import "net/http"
+
+// uiPage is a paste-and-convert page calling /flatten and /unflatten. Documents
+// without nested objects whose keys hold the separator are unflattened, the others
+// flattened, unless a direction is chosen.
+const uiPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>fitobj</title>
+<style>
+  body { font-family: system-ui, sans-serif; margin: 1.5rem; }
+  main { display: flex; gap: 1rem; }
+  textarea { width: 100%; height: 70vh; font-family: ui-monospace, monospace; font-size: 13px; }
+  section { flex: 1; }
+  #error { color: #b00020; white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<h1>fitobj</h1>
+<p>
+  <label>Direction
+    <select id="direction">
+      <option value="auto">auto</option>
+      <option value="flatten">flatten</option>
+      <option value="unflatten">unflatten</option>
+    </select>
+  </label>
+  <label>Separator <input id="separator" size="4" placeholder="."></label>
+  <button id="convert">Convert</button>
+  <span id="status"></span>
+</p>
+<p id="error"></p>
+<main>
+  <section><textarea id="input" placeholder="Paste a JSON object"></textarea></section>
+  <section><textarea id="output" readonly></textarea></section>
+</main>
+<script>
+const $ = (id) => document.getElementById(id);
+
+function isFlat(data, separator) {
+  let joined = false;
+  for (const [key, value] of Object.entries(data)) {
+    if (value && typeof value === "object" && !Array.isArray(value) && Object.keys(value).length > 0) {
+      return false;
+    }
+    if (key.includes(separator) || key.endsWith("]")) {
+      joined = true;
+    }
+  }
+  return joined;
+}
+
+async function convert() {
+  $("error").textContent = "";
+  $("status").textContent = "";
+  let data;
+  try {
+    data = JSON.parse($("input").value);
+  } catch (e) {
+    $("error").textContent = "Invalid JSON: " + e.message;
+    return;
+  }
+
+  const separator = $("separator").value;
+  let direction = $("direction").value;
+  if (direction === "auto") {
+    direction = isFlat(data, separator || ".") ? "unflatten" : "flatten";
+    $("status").textContent = direction === "unflatten" ? "Detected flat input" : "Detected nested input";
+  }
+
+  const body = { data };
+  if (separator) {
+    body.separator = separator;
+  }
+  const response = await fetch("/" + direction + "?raw=true", {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify(body),
+  });
+  const result = await response.json();
+  if (!response.ok) {
+    $("error").textContent = result.detail || result.title;
+    return;
+  }
+  $("output").value = JSON.stringify(result, null, 2);
+}
+
+$("convert").addEventListener("click", convert);
+$("input").addEventListener("paste", () => setTimeout(convert, 0));
+</script>
+</body>
+</html>
+`
+
+// UIHandler serves the paste-and-convert page
+func (s *server) UIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(uiPage))
+}
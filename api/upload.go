@@ -0,0 +1,281 @@
+package api
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/haiyon/fitobj/processor"
+	"github.com/haiyon/fitobj/utils"
+)
+
+// Result bundles of /process/file responses
+const (
+	BundleJSON = "json" // a FileResponse with every result parsed as JSON
+	BundleZip  = "zip"  // a zip archive of the processed files, in their own formats
+)
+
+// maxUploadMemory is the part of a multipart upload held in memory; the rest is
+// buffered in temporary files
+const maxUploadMemory = 32 << 20
+
+// FailedFilesHeader carries the number of files that could not be processed in zip
+// bundles
+const FailedFilesHeader = "X-Failed-Files"
+
+// FileResult is the outcome of processing one uploaded file
+type FileResult struct {
+	Name    string         `json:"name"`
+	Data    map[string]any `json:"data"`
+	Success bool           `json:"success"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// FileResponse defines the structure for /process/file responses in the json
+// bundle, with results in file name order. Success is set when every file was
+// processed.
+type FileResponse struct {
+	Files   []FileResult `json:"files"`
+	Success bool         `json:"success"`
+	Failed  int          `json:"failed"`
+}
+
+// uploadParams are the form fields of a /process/file request
+type uploadParams struct {
+	reverse     bool
+	separator   string
+	arrayFormat string
+	escape      string
+	escapeStyle string
+	include     []string
+	exclude     []string
+	bundle      string
+}
+
+// FileHandler processes multipart uploads of one or more documents with the
+// processor package, as the flatten and unflatten commands do. Every file part is
+// processed, whatever its field name; the format of each file is detected from its
+// extension. Options are passed as form fields (reverse, separator, arrayFormat,
+// escape, escapeStyle, include, exclude) and bundle selects the response: json (default) or zip, which
+// an Accept: application/zip header selects as well.
+func (s *server) FileHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.sendError(w, r, "Only POST method is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := r.ParseMultipartForm(maxUploadMemory); err != nil {
+		s.sendBodyError(w, r, err)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	params, invalid := parseUploadParams(r)
+	var files []*multipart.FileHeader
+	for _, headers := range r.MultipartForm.File {
+		files = append(files, headers...)
+	}
+	if len(files) == 0 {
+		invalid = append([]InvalidParam{{Name: "files", Reason: "no files uploaded"}}, invalid...)
+	}
+	options := processor.Options{NumberFormat: s.options.NumberFormat, Logger: s.logger()}
+	options.FlattenOpts, options.UnflattenOpts, _ = s.processOptions(params.separator, params.arrayFormat, params.include, params.exclude)
+	applyEscape(&options.FlattenOpts, &options.UnflattenOpts, params.escape, params.escapeStyle)
+	invalid = append(invalid, validateEscapeParams(options.FlattenOpts.Escape, options.FlattenOpts.EscapeStyle, options.FlattenOpts.Separator)...)
+	if len(invalid) > 0 {
+		s.sendInvalidParams(w, r, invalid)
+		return
+	}
+	sort.SliceStable(files, func(i, j int) bool { return files[i].Filename < files[j].Filename })
+
+	tmpDir, err := os.MkdirTemp("", "fitobj-upload-")
+	if err != nil {
+		s.sendError(w, r, "Failed to create temporary directory", http.StatusInternalServerError)
+		return
+	}
+	defer os.RemoveAll(tmpDir)
+
+	inputDir, outputDir := filepath.Join(tmpDir, "in"), filepath.Join(tmpDir, "out")
+	if err := os.Mkdir(inputDir, 0755); err != nil {
+		s.sendError(w, r, "Failed to create temporary directory", http.StatusInternalServerError)
+		return
+	}
+
+	// The json bundle converts every output to JSON; the zip bundle keeps the
+	// format of each input
+	results := make([]FileResult, len(files))
+	outputs := make([]string, len(files))
+	seen := make(map[string]bool, len(files))
+	for i, file := range files {
+		name := filepath.Base(file.Filename)
+		results[i].Name = name
+		switch {
+		case name != file.Filename || name == "." || name == ".." || name == "":
+			results[i].Error = "invalid file name"
+			continue
+		case seen[name]:
+			results[i].Error = "duplicate file name"
+			continue
+		}
+		seen[name] = true
+
+		outputs[i] = name
+		if params.bundle == BundleJSON {
+			outputs[i] = processor.OutputPath(name, processor.FormatJSON)
+		}
+		inputPath, outputPath := filepath.Join(inputDir, name), filepath.Join(outputDir, outputs[i])
+		if err := processUpload(file, inputPath, outputPath, params.reverse, options); err != nil {
+			// Report errors without the paths of the server
+			results[i].Error = strings.NewReplacer(inputPath, name, outputPath, outputs[i]).Replace(err.Error())
+			continue
+		}
+		results[i].Success = true
+	}
+
+	if params.bundle == BundleZip {
+		s.sendZipBundle(w, r, outputDir, results)
+		return
+	}
+
+	response := FileResponse{Files: results}
+	for i := range results {
+		if !results[i].Success {
+			response.Failed++
+			continue
+		}
+		data, err := utils.ReadJSONFile(filepath.Join(outputDir, outputs[i]))
+		if err != nil {
+			results[i].Success = false
+			results[i].Error = err.Error()
+			response.Failed++
+			continue
+		}
+		results[i].Data = data
+	}
+	response.Success = response.Failed == 0
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		s.sendError(w, r, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// parseUploadParams reads and validates the form fields of a /process/file request
+func parseUploadParams(r *http.Request) (uploadParams, []InvalidParam) {
+	form := r.MultipartForm.Value
+	value := func(name string) string {
+		if values := form[name]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	params := uploadParams{
+		separator:   value("separator"),
+		arrayFormat: value("arrayFormat"),
+		escape:      value("escape"),
+		escapeStyle: value("escapeStyle"),
+		include:     form["include"],
+		exclude:     form["exclude"],
+		bundle:      value("bundle"),
+	}
+	invalid := validateTransformParams(params.separator, params.arrayFormat, params.include, params.exclude)
+
+	if reverse := value("reverse"); reverse != "" {
+		var err error
+		if params.reverse, err = strconv.ParseBool(reverse); err != nil {
+			invalid = append(invalid, InvalidParam{Name: "reverse", Reason: "must be true or false"})
+		}
+	}
+
+	switch params.bundle {
+	case "":
+		params.bundle = BundleJSON
+		if r.Header.Get("Accept") == "application/zip" {
+			params.bundle = BundleZip
+		}
+	case BundleJSON, BundleZip:
+	default:
+		invalid = append(invalid, InvalidParam{Name: "bundle", Reason: "must be 'json' or 'zip'"})
+	}
+	return params, invalid
+}
+
+// processUpload saves an uploaded file and processes it into the output path
+func processUpload(file *multipart.FileHeader, inputPath, outputPath string, reverse bool, options processor.Options) error {
+	in, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("failed to read upload: %v", err)
+	}
+	defer in.Close()
+
+	out, err := os.Create(inputPath)
+	if err != nil {
+		return fmt.Errorf("failed to save upload: %v", err)
+	}
+	_, err = io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save upload: %v", err)
+	}
+
+	return processor.ProcessFileWithOptions(inputPath, outputPath, reverse, options)
+}
+
+// sendZipBundle writes the processed files, with their sidecars, as a zip archive.
+// Each file that failed is replaced by a <name>.error.txt entry holding the error,
+// and their count is reported in the X-Failed-Files header.
+func (s *server) sendZipBundle(w http.ResponseWriter, r *http.Request, outputDir string, results []FileResult) {
+	entries, err := os.ReadDir(outputDir)
+	if err != nil && !os.IsNotExist(err) {
+		s.sendError(w, r, "Failed to read processed files", http.StatusInternalServerError)
+		return
+	}
+
+	failed := 0
+	for _, result := range results {
+		if !result.Success {
+			failed++
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="fitobj-results.zip"`)
+	w.Header().Set(FailedFilesHeader, strconv.Itoa(failed))
+
+	zw := zip.NewWriter(w)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(outputDir, entry.Name()))
+		if err != nil {
+			id := w.Header().Get(RequestIDHeader)
+			s.logger().Error(fmt.Sprintf("[%s] failed to read %s: %v", id, entry.Name(), err),
+				"request_id", id, "file", entry.Name(), "error", err)
+			continue
+		}
+		if fw, err := zw.Create(entry.Name()); err == nil {
+			fw.Write(data)
+		}
+	}
+	for _, result := range results {
+		if result.Success {
+			continue
+		}
+		if fw, err := zw.Create(result.Name + ".error.txt"); err == nil {
+			fmt.Fprintln(fw, result.Error)
+		}
+	}
+	zw.Close()
+}
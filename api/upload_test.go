@@ -0,0 +1,224 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// uploadPart is a file part of a multipart upload
+type uploadPart struct {
+	name    string
+	content string
+}
+
+// postUpload sends files and form fields to the file handler
+func postUpload(t *testing.T, files []uploadPart, fields map[string]string, accept string) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for name, value := range fields {
+		if err := mw.WriteField(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, file := range files {
+		fw, err := mw.CreateFormFile("files", file.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fw.Write([]byte(file.content))
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/process/file", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	if accept != "" {
+		r.Header.Set("Accept", accept)
+	}
+	w := httptest.NewRecorder()
+	newServer(DefaultOptions()).FileHandler(w, r)
+	return w
+}
+
+// decodeFileResponse decodes a json bundle
+func decodeFileResponse(t *testing.T, w *httptest.ResponseRecorder) FileResponse {
+	t.Helper()
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var response FileResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	return response
+}
+
+func TestFileHandlerJSONBundle(t *testing.T) {
+	w := postUpload(t, []uploadPart{
+		{"b.yaml", "server:\n  port: 80\n"},
+		{"a.json", `{"a":{"b":[1,2]}}`},
+	}, map[string]string{"separator": "/"}, "")
+
+	response := decodeFileResponse(t, w)
+	expected := FileResponse{
+		Files: []FileResult{
+			{Name: "a.json", Data: map[string]any{"a/b/0": float64(1), "a/b/1": float64(2)}, Success: true},
+			{Name: "b.yaml", Data: map[string]any{"server/port": float64(80)}, Success: true},
+		},
+		Success: true,
+	}
+	if !reflect.DeepEqual(response, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, response)
+	}
+
+	w = postUpload(t, []uploadPart{{"flat.json", `{"a.b":1}`}}, map[string]string{"reverse": "true"}, "")
+	response = decodeFileResponse(t, w)
+	if got := response.Files[0].Data; !reflect.DeepEqual(got, map[string]any{"a": map[string]any{"b": float64(1)}}) {
+		t.Errorf("Expected the document unflattened, got %v", got)
+	}
+}
+
+func TestFileHandlerFileNames(t *testing.T) {
+	w := postUpload(t, []uploadPart{
+		{"a.json", `{"x":1}`},
+		{"a.json", `{"x":2}`},
+		{"../../etc/b.json", `{"y":{"z":1}}`},
+		{"..", `{}`},
+		{"broken.json", `{`},
+	}, nil, "")
+
+	response := decodeFileResponse(t, w)
+	if response.Success || response.Failed != 3 {
+		t.Errorf("Expected 3 failed files, got %d (success %v)", response.Failed, response.Success)
+	}
+
+	// Multipart parsing keeps the base name of a path, so it cannot leave the
+	// upload directory
+	failures := make(map[string][]string)
+	for _, file := range response.Files {
+		failures[file.Name] = append(failures[file.Name], file.Error)
+	}
+	expected := map[string][]string{
+		"..":          {"invalid file name"},
+		"a.json":      {"", "duplicate file name"},
+		"b.json":      {""},
+		"broken.json": {failures["broken.json"][0]},
+	}
+	if !reflect.DeepEqual(failures, expected) {
+		t.Errorf("Expected errors %v, got %v", expected, failures)
+	}
+	if msg := failures["broken.json"][0]; msg == "" || strings.Contains(msg, "fitobj-upload-") {
+		t.Errorf("Expected the parse error without server paths, got %q", msg)
+	}
+	for _, file := range response.Files {
+		if file.Name == "b.json" && !reflect.DeepEqual(file.Data, map[string]any{"y.z": float64(1)}) {
+			t.Errorf("Expected b.json flattened, got %v", file.Data)
+		}
+	}
+}
+
+func TestFileHandlerZipBundle(t *testing.T) {
+	for name, request := range map[string]struct {
+		fields map[string]string
+		accept string
+	}{
+		"field":  {fields: map[string]string{"bundle": "zip"}},
+		"accept": {accept: "application/zip"},
+	} {
+		w := postUpload(t, []uploadPart{
+			{"a.yaml", "a:\n  b: 1\n"},
+			{"c.json", `{"c":{"d":true}}`},
+			{"bad.json", `[`},
+		}, request.fields, request.accept)
+
+		if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/zip" {
+			t.Fatalf("%s: expected a zip bundle, got %d %s: %s", name, w.Code, w.Header().Get("Content-Type"), w.Body.String())
+		}
+		if got := w.Header().Get(FailedFilesHeader); got != "1" {
+			t.Errorf("%s: expected 1 failed file, got %q", name, got)
+		}
+
+		zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries := make(map[string]string)
+		var names []string
+		for _, f := range zr.File {
+			rc, err := f.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, _ := io.ReadAll(rc)
+			rc.Close()
+			entries[f.Name] = string(data)
+			names = append(names, f.Name)
+		}
+		sort.Strings(names)
+
+		// Outputs keep the format of their input
+		if expected := []string{"a.yaml", "bad.json.error.txt", "c.json"}; !reflect.DeepEqual(names, expected) {
+			t.Fatalf("%s: expected entries %v, got %v", name, expected, names)
+		}
+		if !strings.Contains(entries["a.yaml"], "a.b: 1") {
+			t.Errorf("%s: expected a.yaml flattened as YAML, got %q", name, entries["a.yaml"])
+		}
+		if !strings.Contains(entries["c.json"], `"c.d": true`) {
+			t.Errorf("%s: expected c.json flattened, got %q", name, entries["c.json"])
+		}
+		if entries["bad.json.error.txt"] == "" {
+			t.Errorf("%s: expected the error of bad.json", name)
+		}
+	}
+}
+
+func TestFileHandlerInvalidRequests(t *testing.T) {
+	tests := map[string]struct {
+		files  []uploadPart
+		fields map[string]string
+		params []string
+	}{
+		"no files":       {fields: map[string]string{"separator": "."}, params: []string{"files"}},
+		"bundle":         {files: []uploadPart{{"a.json", "{}"}}, fields: map[string]string{"bundle": "tar"}, params: []string{"bundle"}},
+		"reverse":        {files: []uploadPart{{"a.json", "{}"}}, fields: map[string]string{"reverse": "maybe"}, params: []string{"reverse"}},
+		"no files, both": {fields: map[string]string{"bundle": "tar"}, params: []string{"files", "bundle"}},
+	}
+	for name, test := range tests {
+		w := postUpload(t, test.files, test.fields, "")
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", name, w.Code)
+			continue
+		}
+		var problem ProblemDetails
+		if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+			t.Fatal(err)
+		}
+		var params []string
+		for _, param := range problem.InvalidParams {
+			params = append(params, param.Name)
+		}
+		if problem.Type != ProblemInvalidParams || !reflect.DeepEqual(params, test.params) {
+			t.Errorf("%s: expected invalid params %v, got %s %v", name, test.params, problem.Type, params)
+		}
+	}
+
+	// A body that is not multipart cannot be parsed
+	r := httptest.NewRequest(http.MethodPost, "/process/file", strings.NewReader(`{"data":{}}`))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	newServer(DefaultOptions()).FileHandler(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a JSON body, got %d", w.Code)
+	}
+}
@@ -1,7 +1,7 @@
 package cmd
 
 import (
-	"fmt"
+	"log/slog"
 
 	"github.com/haiyon/fitobj/api"
 	"github.com/spf13/cobra"
@@ -14,19 +14,54 @@ var apiCmd = &cobra.Command{
 	Long: `Start a RESTful API server for JSON processing.
 
 The server provides endpoints for flattening and unflattening JSON data.
+With --locales, locale bundles are kept in memory and served per key or per
+locale with ETags; --watch reloads them when the files change.
+
+--max-inflight and --max-queue bound each endpoint separately; when both are
+exhausted, requests get 503 with Retry-After. Health endpoints are never limited.
+--debug-vars publishes the limiter counters and other expvar variables at
+/debug/vars; keep it off on servers reachable by untrusted clients.
+
+Connections are bounded by --read-timeout, --write-timeout and --idle-timeout,
+and request bodies larger than --max-body-size get 413. On SIGINT or SIGTERM
+the server stops accepting connections, reports not ready on /readyz and waits
+up to --shutdown-timeout for in-flight requests before exiting.
+
+--jobs-status serves the status of the jobs scheduled by 'fitobj serve-batch'
+from its <log-dir>/jobs.json: /v1/jobs lists them, /v1/jobs/{name} reports one
+and /v1/jobs/{name}/log returns the log of its last run.
 
 Example:
   fitobj api --port=8080
-  fitobj api --port=3000 --separator="__"`,
+  fitobj api --port=3000 --separator="__"
+  fitobj api --locales ./locales --watch
+  fitobj api --max-inflight 8 --max-queue 32
+  fitobj api --write-timeout 2m --max-body-size 52428800
+  fitobj api --jobs-status ./fitobj-logs/jobs.json`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		port := viper.GetString("api.port")
 
-		fmt.Println("Starting fitobj in API mode...")
+		slog.Info("Starting fitobj in API mode...")
 
 		options := api.Options{
 			Port:          port,
 			FlattenOpts:   buildFlattenOptions(),
 			UnflattenOpts: buildUnflattenOptions(),
+			NumberFormat:  buildNumberFormat(),
+			LocalesDir:    viper.GetString("api.locales"),
+			WatchLocales:  viper.GetBool("api.watch"),
+			JobsStatus:    viper.GetString("api.jobs-status"),
+			DebugVars:     viper.GetBool("api.debug-vars"),
+			Limits: api.LimitOptions{
+				MaxInFlight: viper.GetInt("api.max-inflight"),
+				MaxQueue:    viper.GetInt("api.max-queue"),
+				RetryAfter:  viper.GetInt("api.retry-after"),
+			},
+			ReadTimeout:     viper.GetDuration("api.read-timeout"),
+			WriteTimeout:    viper.GetDuration("api.write-timeout"),
+			IdleTimeout:     viper.GetDuration("api.idle-timeout"),
+			ShutdownTimeout: viper.GetDuration("api.shutdown-timeout"),
+			MaxBodySize:     viper.GetInt64("api.max-body-size"),
 		}
 
 		return api.StartServerWithOptions(options)
@@ -36,6 +71,32 @@ Example:
 func init() {
 	apiCmd.Flags().String("port", "8080", "port for API server")
 	viper.BindPFlag("api.port", apiCmd.Flags().Lookup("port"))
+	apiCmd.Flags().String("locales", "", "directory of locale files to serve from memory")
+	viper.BindPFlag("api.locales", apiCmd.Flags().Lookup("locales"))
+	apiCmd.Flags().Bool("watch", false, "reload locale files when they change")
+	viper.BindPFlag("api.watch", apiCmd.Flags().Lookup("watch"))
+	apiCmd.Flags().String("jobs-status", "", "status file of scheduled jobs (<log-dir>/jobs.json of 'fitobj serve-batch') served at /v1/jobs")
+	viper.BindPFlag("api.jobs-status", apiCmd.Flags().Lookup("jobs-status"))
+	apiCmd.Flags().Bool("debug-vars", false, "serve expvar counters, limiter counters included, at /debug/vars")
+	viper.BindPFlag("api.debug-vars", apiCmd.Flags().Lookup("debug-vars"))
+	apiCmd.Flags().Int("max-inflight", 0, "requests processed at once per endpoint (0 = unlimited)")
+	viper.BindPFlag("api.max-inflight", apiCmd.Flags().Lookup("max-inflight"))
+	apiCmd.Flags().Int("max-queue", 0, "requests waiting per endpoint before 503 responses")
+	viper.BindPFlag("api.max-queue", apiCmd.Flags().Lookup("max-queue"))
+	apiCmd.Flags().Int("retry-after", 1, "Retry-After seconds sent when an endpoint is saturated")
+	viper.BindPFlag("api.retry-after", apiCmd.Flags().Lookup("retry-after"))
+
+	defaults := api.DefaultOptions()
+	apiCmd.Flags().Duration("read-timeout", defaults.ReadTimeout, "time to read a request, body included (0 = no limit)")
+	viper.BindPFlag("api.read-timeout", apiCmd.Flags().Lookup("read-timeout"))
+	apiCmd.Flags().Duration("write-timeout", defaults.WriteTimeout, "time to process a request and write its response (0 = no limit)")
+	viper.BindPFlag("api.write-timeout", apiCmd.Flags().Lookup("write-timeout"))
+	apiCmd.Flags().Duration("idle-timeout", defaults.IdleTimeout, "time a keep-alive connection waits for the next request (0 = no limit)")
+	viper.BindPFlag("api.idle-timeout", apiCmd.Flags().Lookup("idle-timeout"))
+	apiCmd.Flags().Duration("shutdown-timeout", defaults.ShutdownTimeout, "time in-flight requests get to complete on shutdown (0 = no limit)")
+	viper.BindPFlag("api.shutdown-timeout", apiCmd.Flags().Lookup("shutdown-timeout"))
+	apiCmd.Flags().Int64("max-body-size", defaults.MaxBodySize, "request body size limit in bytes (0 = no limit)")
+	viper.BindPFlag("api.max-body-size", apiCmd.Flags().Lookup("max-body-size"))
 
 	rootCmd.AddCommand(apiCmd)
 }
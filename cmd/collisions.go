@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/haiyon/fitobj/processor"
+	"github.com/spf13/cobra"
+)
+
+var collisionsCmd = &cobra.Command{
+	Use:   "collisions [dir]",
+	Short: "Report keys that documents set to conflicting values",
+	Long: `Flatten every document below a directory and report the keys that several
+documents set to different values or types, grouped by key, with the value each
+document sets. Run it before merging or bundling config or locale files into one
+namespace, where a colliding key would silently keep only one of its values.
+
+Keys set to equal values by every document are not collisions; numbers are equal
+whatever their encoding (1 in YAML and 1.0 in JSON). A key set to values of
+different types ("8080" and 8080) is a type collision, reported even with
+--types-only. --key-glob restricts the comparison to keys matching a pattern,
+with the syntax of 'fitobj flatten --include'.
+
+--output=json prints the collisions as [{"key", "kind", "entries": [{"file",
+"type", "value"}]}]. The command exits with status 3 when keys collide.
+
+Example:
+  fitobj collisions ./config
+  fitobj collisions ./locales/fragments --types-only
+  fitobj collisions ./config --key-glob 'database.**' --output=json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		if output != "text" && output != "json" {
+			return usageErrorf("invalid --output value '%s' (expected text or json)", output)
+		}
+
+		options := processor.CollisionOptions{Options: buildProcessorOptions()}
+		options.Format, _ = cmd.Flags().GetString("format")
+		options.Env = buildEnvOptions(cmd)
+		options.Properties = buildPropertiesOptions(cmd)
+		options.KeyGlobs, _ = cmd.Flags().GetStringSlice("key-glob")
+		options.TypesOnly, _ = cmd.Flags().GetBool("types-only")
+
+		collisions, files, err := processor.FindCollisions(args[0], options)
+		if err != nil {
+			return err
+		}
+
+		if output == "json" {
+			if collisions == nil {
+				collisions = []processor.KeyCollision{}
+			}
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(collisions); err != nil {
+				return err
+			}
+		} else {
+			for _, collision := range collisions {
+				fmt.Printf("%s: conflicting %ss\n", collision.Key, collision.Kind)
+				for _, entry := range collision.Entries {
+					value, _ := json.Marshal(entry.Value)
+					fmt.Printf("  %s: %s (%s)\n", entry.File, value, entry.Type)
+				}
+			}
+			if len(collisions) == 0 {
+				fmt.Printf("No colliding keys in %d files\n", files)
+			} else {
+				fmt.Printf("\n%d colliding keys in %d files\n", len(collisions), files)
+			}
+		}
+
+		if len(collisions) > 0 {
+			return exitSilently(cmd, ExitCheck)
+		}
+		return nil
+	},
+}
+
+func init() {
+	collisionsCmd.Flags().StringSlice("key-glob", nil, "only compare keys matching these patterns (globs like 'database.**', or 're:<regexp>')")
+	collisionsCmd.Flags().Bool("types-only", false, "only report keys set to values of different types")
+	collisionsCmd.Flags().String("output", "text", "output format: 'text' or 'json'")
+	addFormatFlags(collisionsCmd)
+
+	rootCmd.AddCommand(collisionsCmd)
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/haiyon/fitobj/fitter"
+	"github.com/haiyon/fitobj/processor"
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [old] [new]",
+	Short: "Compare two documents or directories key by key",
+	Long: `Flatten two JSON, JSONC or YAML documents and report the keys added to,
+removed from and changed in the second one.
+
+When both arguments are directories, their documents are matched by relative
+path (subdirectories included): files present on one side only are reported as
+added or removed, and the key differences of every other file are listed in one
+report.
+
+--output=json prints the differences as {"added": [...], "removed": [...],
+"changed": [...]}. --keys-only compares the structure only: keys added or
+removed and keys whose value type changed (string, number, boolean, null, array,
+object), with type names shown instead of values.
+
+--array-key identifies the elements of arrays of objects by a field instead of
+their index, so reordering them is not a change: with --array-key=id, the port of
+{"id": "web", ...} is compared as servers.[id=web].port. Arrays whose elements
+do not all hold distinct values of the field are compared by index.
+
+--ignore leaves out keys matching a pattern, so volatile fields such as
+timestamps do not show up as changes: globs split on the separator ("*" matches
+one segment, "**" any number, and a match also covers the keys below it), or
+regular expressions prefixed with "re:". Repeat it for several patterns.
+
+With --exit-code the command exits with status 3 when the
+documents differ, and --silent prints nothing and only sets the exit status.
+
+Example:
+  fitobj diff ./locales/en.json ./locales/de.json
+  fitobj diff config.prod.yaml config.staging.yaml --output=json
+  fitobj diff old.json new.json --silent && echo unchanged
+  fitobj diff --keys-only config.prod.yaml config.staging.yaml --exit-code
+  fitobj diff ./release-1.4/locales ./release-1.5/locales
+  fitobj diff old.yaml new.yaml --array-key=name
+  fitobj diff ./out-1 ./out-2 --ignore metadata.updatedAt --ignore '**.timestamp'`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		exitCode, _ := cmd.Flags().GetBool("exit-code")
+		silent, _ := cmd.Flags().GetBool("silent")
+
+		if output != "text" && output != "json" {
+			return usageErrorf("invalid --output value '%s' (expected text or json)", output)
+		}
+
+		options := processor.DiffOptions{Options: buildProcessorOptions()}
+		options.Format, _ = cmd.Flags().GetString("format")
+		options.Env = buildEnvOptions(cmd)
+		options.Properties = buildPropertiesOptions(cmd)
+		options.KeysOnly, _ = cmd.Flags().GetBool("keys-only")
+		options.ArrayKey, _ = cmd.Flags().GetString("array-key")
+		options.Ignore, _ = cmd.Flags().GetStringSlice("ignore")
+
+		oldIsDir, newIsDir := isDir(args[0]), isDir(args[1])
+		if oldIsDir != newIsDir {
+			return fmt.Errorf("cannot compare a file with a directory")
+		}
+
+		var result interface{ Empty() bool }
+		if oldIsDir {
+			diff, err := processor.DiffDirectories(args[0], args[1], options)
+			if err != nil {
+				return err
+			}
+			result = diff
+		} else {
+			diff, err := processor.DiffFiles(args[0], args[1], options)
+			if err != nil {
+				return err
+			}
+			result = diff
+		}
+
+		switch {
+		case silent:
+		case output == "json":
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(result); err != nil {
+				return err
+			}
+		default:
+			switch diff := result.(type) {
+			case processor.DirDiff:
+				printDirDiff(args[0], args[1], diff, options.KeysOnly)
+			case fitter.FlatDiff:
+				printDiff(args[0], args[1], diff, options.KeysOnly)
+			}
+		}
+
+		if (exitCode || silent) && !result.Empty() {
+			return exitSilently(cmd, ExitCheck)
+		}
+		return nil
+	},
+}
+
+// isDir reports whether a path is an existing directory
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// printDiff prints the differences between two documents
+func printDiff(oldPath, newPath string, diff fitter.FlatDiff, keysOnly bool) {
+	fmt.Printf("--- %s\n+++ %s\n", oldPath, newPath)
+	printKeyDiff(diff, keysOnly)
+	fmt.Printf("\n%d added, %d removed, %d changed\n", len(diff.Added), len(diff.Removed), len(diff.Changed))
+}
+
+// printDirDiff prints the files added to and removed from a directory, followed
+// by the key differences of each changed file
+func printDirDiff(oldDir, newDir string, diff processor.DirDiff, keysOnly bool) {
+	fmt.Printf("--- %s\n+++ %s\n", oldDir, newDir)
+	for _, file := range diff.RemovedFiles {
+		fmt.Printf("- %s (file removed)\n", file)
+	}
+	for _, file := range diff.AddedFiles {
+		fmt.Printf("+ %s (file added)\n", file)
+	}
+
+	added, removed, changed := 0, 0, 0
+	for _, file := range diff.ChangedFiles {
+		fmt.Printf("\n=== %s\n", file.File)
+		printKeyDiff(file.FlatDiff, keysOnly)
+		added += len(file.Added)
+		removed += len(file.Removed)
+		changed += len(file.Changed)
+	}
+
+	fmt.Printf("\n%d files added, %d removed, %d changed (%d keys added, %d removed, %d changed)\n",
+		len(diff.AddedFiles), len(diff.RemovedFiles), len(diff.ChangedFiles), added, removed, changed)
+}
+
+// printKeyDiff prints the removed, added and changed keys of a diff, one per line.
+// With keysOnly the values are type names and are printed unquoted.
+func printKeyDiff(diff fitter.FlatDiff, keysOnly bool) {
+	value := func(v any) string {
+		if keysOnly {
+			return fmt.Sprint(v)
+		}
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+
+	for _, entry := range diff.Removed {
+		fmt.Printf("- %s: %s\n", entry.Key, value(entry.Value))
+	}
+	for _, entry := range diff.Added {
+		fmt.Printf("+ %s: %s\n", entry.Key, value(entry.Value))
+	}
+	for _, change := range diff.Changed {
+		fmt.Printf("~ %s: %s -> %s\n", change.Key, value(change.Old), value(change.New))
+	}
+}
+
+func init() {
+	diffCmd.Flags().String("output", "text", "output format: 'text' or 'json'")
+	diffCmd.Flags().Bool("keys-only", false, "compare key presence and value types only, ignoring values")
+	diffCmd.Flags().String("array-key", "", "identify elements of arrays of objects by this field instead of their index")
+	diffCmd.Flags().StringSlice("ignore", nil, "leave out keys matching these patterns (globs like '**.timestamp', or 're:<regexp>')")
+	diffCmd.Flags().Bool("exit-code", false, "exit with status 3 when the documents differ")
+	diffCmd.Flags().BoolP("silent", "s", false, "print nothing; only set the exit status (unlike the global --quiet, which only lowers the log level)")
+	addFormatFlags(diffCmd)
+
+	rootCmd.AddCommand(diffCmd)
+}
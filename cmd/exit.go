@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/haiyon/fitobj/processor"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Exit codes, so scripts and CI pipelines can branch on the outcome of a command
+const (
+	ExitOK      = 0 // success
+	ExitUsage   = 1 // invalid arguments, flags or options
+	ExitFailure = 2 // processing failed
+	ExitCheck   = 3 // a check failed: missing keys, differences, lint, budget or markup issues
+	ExitPartial = 4 // some files were processed and others failed
+)
+
+// started is set once the arguments and flags of the command have been accepted,
+// so errors returned afterwards are processing failures rather than usage errors
+var started bool
+
+// markStarted records that a command passed argument and flag validation
+func markStarted(cmd *cobra.Command, args []string) {
+	started = true
+}
+
+// exitError is an error with the exit code it stands for. Silent errors are not
+// printed.
+type exitError struct {
+	code   int
+	err    error
+	silent bool
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+// usageErrorf returns an error exiting with ExitUsage, for invalid flag values and
+// combinations detected by a command
+func usageErrorf(format string, args ...any) error {
+	return &exitError{code: ExitUsage, err: fmt.Errorf(format, args...)}
+}
+
+// checkFailedf returns an error exiting with ExitCheck, for checks that found issues
+func checkFailedf(format string, args ...any) error {
+	return &exitError{code: ExitCheck, err: fmt.Errorf(format, args...)}
+}
+
+// exitSilently returns an error exiting with code without printing the error or
+// the usage, for commands whose output already reports the outcome, or whose exit
+// status is their only output
+func exitSilently(cmd *cobra.Command, code int) error {
+	cmd.SilenceErrors, cmd.SilenceUsage = true, true
+	return &exitError{code: code, err: fmt.Errorf("exit status %d", code), silent: true}
+}
+
+// isSilent reports whether an error ends the command without being printed
+func isSilent(err error) bool {
+	var exitErr *exitError
+	return errors.As(err, &exitErr) && exitErr.silent
+}
+
+// exitCode returns the exit code of a command error: usage errors before the
+// command started, partial success when only some files failed, and processing
+// failures otherwise
+func exitCode(err error) int {
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	if !started {
+		return ExitUsage
+	}
+	var failed *processor.FilesFailedError
+	if errors.As(err, &failed) && failed.Failed < failed.Total {
+		return ExitPartial
+	}
+	return ExitFailure
+}
+
+// isStrict reports whether warnings are promoted to failures (--strict)
+func isStrict() bool {
+	return viper.GetBool("strict")
+}
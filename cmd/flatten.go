@@ -2,7 +2,10 @@ package cmd
 
 import (
 	"fmt"
+	"log/slog"
+	"os"
 
+	"github.com/haiyon/fitobj/fitter"
 	"github.com/haiyon/fitobj/processor"
 	"github.com/spf13/cobra"
 )
@@ -12,23 +15,246 @@ var flattenCmd = &cobra.Command{
 	Short: "Flatten nested JSON objects",
 	Long: `Flatten converts nested JSON objects into flat key-value pairs.
 
+YAML files (.yaml, .yml) and JSONC files are processed as well, detected by
+extension. --format forces the parser for every input file and the encoding of
+the output, renaming outputs to match (--format=yaml writes config.json as
+config.yaml).
+
+--format=env writes dotenv files instead: keys are mangled into variable names
+(db.host becomes DB_HOST), configurable with --env-separator, --env-prefix and
+--env-keep-case. Dotenv inputs (.env, *.env, .env.*) are always read as dotenv.
+
+--format=properties writes Java .properties files (a.b.c=value), escaping keys
+and values as java.util.Properties does; --properties-ascii also escapes
+non-ASCII characters as \uXXXX for readers expecting ISO-8859-1.
+
+With --yaml-anchors=record, anchors, aliases and merge keys are recorded in a
+<output>.anchors.json sidecar and restored by unflatten, instead of being
+expanded into independent copies.
+
+--include and --exclude filter the flattened keys: a glob pattern selects a key
+and everything below it ("*" matches one segment, "**" any number), and patterns
+prefixed with "re:" are regular expressions matched against the whole key.
+
+--level N only flattens the first N levels: keys join at most N segments (array
+indices included) and deeper objects and arrays are kept as values, for a
+partially denormalized document. 'fitobj unflatten --level N' is the reverse.
+
+--nulls, --empty-objects and --empty-arrays control how null values, {} and []
+are emitted: 'keep' them as-is (default), 'drop' the key, or emit the
+--empty-placeholder string instead.
+
+With --target=mongodb or --target=firestore, field names are validated against the
+database rules (no leading '$', no embedded dots, length and depth limits) and the
+output is an update document ({"$set": {"a.b": v}} for MongoDB) that can be applied
+directly.
+
+With --to=parquet, the documents are exported as a single table instead: one row
+per input file, one column per distinct flattened key, and column types inferred
+from the values (boolean, int64, double, otherwise string). The second argument is
+then the output file, ready for DuckDB or Spark. With --to=bigquery, the output file
+holds newline-delimited JSON rows with column names sanitized for BigQuery, and a
+<name>.schema.json with the table schema is written next to it.
+
+With --to=csv or --to=tsv, the output file holds one key,value row per flattened
+key instead, for editing in a spreadsheet; --source-column adds a file column
+naming the document of each key. 'fitobj unflatten --from=csv' imports it back.
+Exported documents are read by --workers in parallel and combined in file name
+order, so repeated exports produce identical files.
+
+With --to=bundle, the documents are combined into one flattened document (in the
+format of the output file) for shipping a single runtime bundle: the keys of
+each document are prefixed with its file name without the extension, so en.json
+holds en.title. A source map (<name>.map.json) records the file, format and keys
+of every document; 'fitobj unflatten --from=bundle' splits the bundle back.
+
+--output=json prints a summary for scripts instead of progress lines: every file
+with its key counts (leaf values read and written) and, when it failed, its error
+and an error code (read_error, transform_error or write_error; run_error when
+the run could not start; schema_error for schema warnings under --strict).
+Files also report their worker and timing (seconds spent decoding, transforming
+and encoding), and the run reports the load of every worker, for tuning --workers.
+--metrics-file writes the same timings in the Prometheus text format.
+--units parses values with a unit suffix (durations such as 10s or 1h30m, byte
+sizes such as 512Mi or 2GB, percentages) into comparable numbers: 'base' writes
+the number in seconds, bytes or a fraction, 'split' writes <key>.value and
+<key>.unit. A bare m is minutes; use --unit-keys to leave CPU quantities alone.
+--progress draws a bar with the file count, rate and ETA on stderr, and leaves
+the line per processed file to --verbose.
+--max-memory holds back file starts while the estimated decode memory of the
+files in progress would exceed it; those files are marked throttled.
+
+Runs keep a journal of completed files in the output directory (.fitobj-journal),
+removed once every file succeeded. --resume continues an interrupted or partly
+failed run: files the journal lists are skipped when their input is unchanged
+and their output exists, and reported as resumed.
+
+Entries of the input directory that are not documents (other extensions,
+sidecar files, subdirectories) are skipped and counted by reason; --verbose
+lists each of them.
+
+Documents already flat (no nested objects or arrays) are written as read,
+without being flattened, and listed as skipped (flat), so flattening a
+directory twice, or the wrong one, transforms nothing twice. --force flattens
+them anyway; runs with --format conversions, --target, --es-index, --include,
+--exclude, --nulls, --empty-objects, --empty-arrays, --units or number
+formatting always do, as these change their values.
+
+An input holding glob characters, such as './locales/**/en*.json' (quoted, so
+the shell does not expand it), walks the directory before the first pattern
+segment and processes the documents matching the rest; --match adds patterns
+relative to the input directory. ** matches across subdirectories, outputs keep
+the relative paths of their inputs, and the other documents are skipped as
+unmatched.
+
+The command exits with status 1 on usage errors, 2 when processing failed, and 4
+when some files were processed and others failed.
+
+An input or output of - (or --stdin and --stdout) reads or writes a single
+document on the standard streams instead, for use in shell pipelines. Both sides
+use --format, JSON by default, or the format of the file on the other side; no
+status is printed and no sidecar files are read or written.
+
 Example:
   fitobj flatten ./nested ./flattened
-  fitobj flatten ./data ./output --separator="__" --array-format=bracket`,
-	Args: cobra.ExactArgs(2),
+  fitobj flatten ./nested ./flattened --output=json
+  fitobj flatten ./exports ./flat --resume
+  fitobj flatten ./mixed ./flat --force
+  fitobj flatten './locales/**/en*.json' ./out
+  fitobj flatten ./exports ./flat --workers=16 --progress
+  fitobj flatten ./nested ./flattened --workers=8 --metrics-file=/var/lib/node_exporter/fitobj.prom
+  cat config.json | fitobj flatten - -
+  fitobj flatten --stdin --stdout --format=yaml < values.yaml
+  fitobj flatten ./data ./output --separator="__" --array-format=bracket
+  fitobj flatten ./charts ./flat --yaml-anchors=record
+  fitobj flatten ./json ./flat-yaml --format=yaml
+  fitobj flatten ./config ./env --format=env --env-prefix=APP_
+  fitobj flatten ./locales ./bundles --format=properties --properties-ascii
+  fitobj flatten ./config ./flat --include 'server.**' --exclude 're:(?i)password'
+  fitobj flatten ./config ./flat --level=2
+  fitobj flatten ./locales ./flat --nulls=drop --empty-objects=drop --empty-arrays=drop
+  fitobj flatten ./docs ./updates --target=mongodb
+  fitobj flatten ./k8s ./flat --units=base --unit-keys='**.memory,**.timeout'
+  fitobj flatten ./docs ./bulk --es-index=products --es-id-path=sku
+  fitobj flatten ./events events.parquet --to=parquet
+  fitobj flatten ./payloads rows.ndjson --to=bigquery
+  fitobj flatten ./locales translations.csv --to=csv --source-column
+  fitobj flatten ./locales dist/i18n.json --to=bundle
+  FITOBJ_SIGNING_KEY=secret fitobj flatten ./in ./out --artifact out.tar.gz`,
+	Args: cobra.RangeArgs(0, 2),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		inputDir := args[0]
-		outputDir := args[1]
-
-		fmt.Printf("Flattening JSON files from %s to %s\n", inputDir, outputDir)
-		fmt.Printf("Using separator: '%s', array format: '%s', workers: %d\n",
-			getSeparator(), getArrayFormat(), getWorkers())
+		inputDir, outputDir, pipe, err := pipeArgs(cmd, args)
+		if err != nil {
+			return err
+		}
+		output, err := getOutputFormat(cmd)
+		if err != nil {
+			return err
+		}
+		if output == "json" && pipe {
+			return usageErrorf("--output=json cannot be used with standard streams")
+		}
 
 		options := buildProcessorOptions()
-		return processor.ProcessDirectoryWithOptions(inputDir, outputDir, false, options)
+		options.Format, _ = cmd.Flags().GetString("format")
+		options.Env = buildEnvOptions(cmd)
+		if inputDir, options.Match, err = inputMatch(cmd, inputDir); err != nil {
+			return err
+		}
+		options.Properties = buildPropertiesOptions(cmd)
+		options.FlattenOpts.IncludeKeys, _ = cmd.Flags().GetStringSlice("include")
+		options.FlattenOpts.ExcludeKeys, _ = cmd.Flags().GetStringSlice("exclude")
+		options.FlattenOpts.Nulls, _ = cmd.Flags().GetString("nulls")
+		options.FlattenOpts.EmptyObjects, _ = cmd.Flags().GetString("empty-objects")
+		options.FlattenOpts.EmptyArrays, _ = cmd.Flags().GetString("empty-arrays")
+		options.FlattenOpts.Placeholder, _ = cmd.Flags().GetString("empty-placeholder")
+		if options.FlattenOpts.MaxDepth, err = getMaxDepth(cmd); err != nil {
+			return err
+		}
+		if units, _ := cmd.Flags().GetString("units"); units != "" {
+			options.Units = &fitter.UnitOptions{Mode: units}
+			options.Units.KeyGlobs, _ = cmd.Flags().GetStringSlice("unit-keys")
+		}
+
+		if pipe {
+			options.Target, _ = cmd.Flags().GetString("target")
+			if err := validateOptions(options); err != nil {
+				return err
+			}
+			return runPipe(cmd, inputDir, outputDir, false, options)
+		}
+
+		if to, _ := cmd.Flags().GetString("to"); to != "" {
+			if output == "json" {
+				return usageErrorf("--output=json cannot be used with --to")
+			}
+			options.SourceColumn, _ = cmd.Flags().GetBool("source-column")
+			if err := processor.ValidateExportFormat(to); err != nil {
+				return usageErrorf("%v", err)
+			}
+			if err := validateOptions(options); err != nil {
+				return err
+			}
+			slog.Info(fmt.Sprintf("Exporting JSON files from %s to %s (%s)", inputDir, outputDir, to))
+			return processor.ExportDirectory(inputDir, outputDir, to, options)
+		}
+
+		options.YAMLAnchors, _ = cmd.Flags().GetString("yaml-anchors")
+		options.Bulk = buildBulkOptions(cmd)
+		options.MetricsFile, _ = cmd.Flags().GetString("metrics-file")
+		options.Resume, _ = cmd.Flags().GetBool("resume")
+		if force, _ := cmd.Flags().GetBool("force"); !force {
+			options.SkipConverted = true
+		}
+		options.Progress = buildProgress(cmd)
+		if options.MaxMemory, err = getMaxMemory(); err != nil {
+			return err
+		}
+		options.Target, _ = cmd.Flags().GetString("target")
+		if err := validateOptions(options); err != nil {
+			return err
+		}
+		if output == "json" {
+			return printSummary(cmd, inputDir, outputDir, false, options, "flatten")
+		}
+
+		slog.Info(fmt.Sprintf("Flattening JSON files from %s to %s", inputDir, outputDir))
+		slog.Info(fmt.Sprintf("Using separator: '%s', array format: '%s', workers: %s",
+			getSeparator(), getArrayFormat(), describeWorkers()))
+
+		if err := processor.ProcessDirectoryWithOptions(inputDir, outputDir, false, options); err != nil {
+			return err
+		}
+
+		return writeArtifact(cmd, outputDir, "flatten", os.Stdout)
 	},
 }
 
 func init() {
+	flattenCmd.Flags().StringSlice("include", nil, "keep only keys matching these patterns (globs like 'user.**', or 're:<regexp>')")
+	flattenCmd.Flags().StringSlice("exclude", nil, "drop keys matching these patterns (globs or 're:<regexp>')")
+	flattenCmd.Flags().String("nulls", fitter.EmptyKeep, "null values: 'keep', 'drop' or 'placeholder'")
+	flattenCmd.Flags().String("empty-objects", fitter.EmptyKeep, "empty objects: 'keep', 'drop' or 'placeholder'")
+	flattenCmd.Flags().String("empty-arrays", fitter.EmptyKeep, "empty arrays: 'keep', 'drop' or 'placeholder'")
+	flattenCmd.Flags().String("empty-placeholder", "", "value emitted for nulls and empty values in placeholder mode")
+	flattenCmd.Flags().String("units", "", "parse unit-suffixed values such as 10s, 512Mi and 80%: 'base' (number in seconds, bytes or a fraction) or 'split' (value and unit keys) (default: off)")
+	flattenCmd.Flags().StringSlice("unit-keys", nil, "only parse units of keys matching these patterns (globs or 're:<regexp>')")
+	flattenCmd.Flags().String("to", "", "export all documents as one table file: 'parquet', 'bigquery', 'csv' or 'tsv', or as one flattened document: 'bundle'")
+	flattenCmd.Flags().Bool("source-column", false, "csv/tsv export: add a file column naming the document of each key")
+	flattenCmd.Flags().String("target", "", "emit database update documents: 'mongodb' or 'firestore'")
+	addFormatFlags(flattenCmd)
+	addYAMLFlags(flattenCmd)
+	addBulkFlags(flattenCmd)
+	addArtifactFlags(flattenCmd)
+	addResumeFlags(flattenCmd)
+	addForceFlags(flattenCmd)
+	addProgressFlags(flattenCmd)
+	addMatchFlags(flattenCmd)
+	addMetricsFlags(flattenCmd)
+	addWorkerFlags(flattenCmd)
+	addValueFlags(flattenCmd)
+	addLevelFlags(flattenCmd)
+	addPipeFlags(flattenCmd)
+	flattenCmd.Flags().String("output", "text", "result summary format: 'text' or 'json' (files, key counts and error codes)")
 	rootCmd.AddCommand(flattenCmd)
 }
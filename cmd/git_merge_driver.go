@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/haiyon/fitobj/processor"
+	"github.com/spf13/cobra"
+)
+
+var gitMergeDriverCmd = &cobra.Command{
+	Use:   "git-merge-driver [base] [current] [other] [path]",
+	Short: "Merge JSON files key by key during git merges",
+	Long: `Run as a git merge driver: three-way merge the %O (base), %A (current) and
+%B (other) versions of a JSON document key by key and write the result to the
+current file, as git expects. Git stores the versions in temporary files without
+extensions; the optional %P (path) argument gives the real file name, used to
+detect the format and in messages.
+
+Keys changed on one side only, or the same way on both sides, merge
+automatically. Keys changed differently on both sides are written between
+conflict markers holding the current and the other entry, and the driver exits
+with status 3 so git reports the conflict. Files in other formats get the
+current value for conflicting keys instead of markers.
+
+Register the driver once, then assign it to files in .gitattributes:
+
+  git config merge.fitobj.name "fitobj key-level JSON merge"
+  git config merge.fitobj.driver "fitobj git-merge-driver %O %A %B %P --marker-size %L"
+  echo 'locales/**/*.json merge=fitobj' >> .gitattributes`,
+	Args: cobra.RangeArgs(3, 4),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		markerSize, _ := cmd.Flags().GetInt("marker-size")
+		if markerSize <= 0 {
+			markerSize = 7
+		}
+
+		options := buildProcessorOptions()
+		options.Format, _ = cmd.Flags().GetString("format")
+		options.Env = buildEnvOptions(cmd)
+		options.Properties = buildPropertiesOptions(cmd)
+
+		basePath, currentPath, otherPath := args[0], args[1], args[2]
+		name := currentPath
+		if len(args) == 4 {
+			name = args[3]
+			if options.Format == processor.FormatAuto {
+				options.Format = processor.DetectFormat(name)
+			}
+		}
+		merge, err := processor.Merge3Files(basePath, currentPath, otherPath, options)
+		if err != nil {
+			return err
+		}
+
+		if len(merge.Conflicts) > 0 && processor.DetectFormat(name) == processor.FormatJSON {
+			err = processor.WriteMerge3Markers(currentPath, merge, [2]string{"current", "other"}, markerSize, options)
+		} else {
+			err = processor.WriteMerge3(currentPath, merge, options)
+		}
+		if err != nil {
+			return err
+		}
+
+		if len(merge.Conflicts) > 0 {
+			fmt.Fprintf(os.Stderr, "fitobj: %d conflicting keys in %s:\n", len(merge.Conflicts), name)
+			for _, conflict := range merge.Conflicts {
+				fmt.Fprintf(os.Stderr, "  %s\n", conflict.Key)
+			}
+			return exitSilently(cmd, ExitCheck)
+		}
+		return nil
+	},
+}
+
+func init() {
+	gitMergeDriverCmd.Flags().Int("marker-size", 7, "length of the conflict markers (git passes it as %L)")
+	addFormatFlags(gitMergeDriverCmd)
+
+	rootCmd.AddCommand(gitMergeDriverCmd)
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/haiyon/fitobj/processor"
+	"github.com/spf13/cobra"
+)
+
+var grepCmd = &cobra.Command{
+	Use:   "grep [path]",
+	Short: "Search the values and keys of documents",
+	Long: `Flatten a document, or every document below a directory, and print the keys
+whose value matches --value-regex, as file:key: value.
+
+Values are matched as strings, and other values as JSON (true, 42, null, [1,2]).
+--key-regex matches the flattened keys instead or as well: when both are set,
+a key is printed only when both match. --key-glob restricts the search to keys
+matching a pattern, with the syntax of 'fitobj flatten --include'.
+
+--output=json prints the matches as [{"file", "key", "value"}]. The command exits
+with status 3 when nothing matches.
+
+Example:
+  fitobj grep ./config --value-regex 'https?://internal'
+  fitobj grep ./config --value-regex 'https?://internal' --key-glob '**.url'
+  fitobj grep ./locales --key-regex '(?i)legacy' --output=json
+  fitobj grep values.yaml --value-regex '^\d+Mi$'`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		if output != "text" && output != "json" {
+			return usageErrorf("invalid --output value '%s' (expected text or json)", output)
+		}
+
+		options := processor.GrepOptions{Options: buildProcessorOptions()}
+		options.Format, _ = cmd.Flags().GetString("format")
+		options.Env = buildEnvOptions(cmd)
+		options.Properties = buildPropertiesOptions(cmd)
+		options.ValueRegex, _ = cmd.Flags().GetString("value-regex")
+		options.KeyRegex, _ = cmd.Flags().GetString("key-regex")
+		options.KeyGlobs, _ = cmd.Flags().GetStringSlice("key-glob")
+
+		matches, err := processor.GrepPath(args[0], options)
+		if err != nil {
+			return err
+		}
+
+		if output == "json" {
+			if matches == nil {
+				matches = []processor.GrepMatch{}
+			}
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(matches); err != nil {
+				return err
+			}
+		} else {
+			for _, match := range matches {
+				value, _ := json.Marshal(match.Value)
+				fmt.Printf("%s:%s: %s\n", match.File, match.Key, value)
+			}
+		}
+
+		if len(matches) == 0 {
+			return exitSilently(cmd, ExitCheck)
+		}
+		return nil
+	},
+}
+
+func init() {
+	grepCmd.Flags().String("value-regex", "", "print keys whose value matches this regular expression")
+	grepCmd.Flags().String("key-regex", "", "print keys matching this regular expression")
+	grepCmd.Flags().StringSlice("key-glob", nil, "only search keys matching these patterns (globs like '**.url', or 're:<regexp>')")
+	grepCmd.Flags().String("output", "text", "output format: 'text' or 'json'")
+	addFormatFlags(grepCmd)
+
+	rootCmd.AddCommand(grepCmd)
+}
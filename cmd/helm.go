@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/haiyon/fitobj/fitter"
+	"github.com/haiyon/fitobj/utils"
+	"github.com/spf13/cobra"
+)
+
+var helmCmd = &cobra.Command{
+	Use:   "helm",
+	Short: "Helm values helpers",
+	Long:  `Convert between Helm values files and --set style command line flags.`,
+}
+
+var helmSetCmd = &cobra.Command{
+	Use:   "set [values-file]",
+	Short: "Flatten a values file into Helm --set flags",
+	Long: `Flatten a values.yaml (or JSON) file into --set compatible flags. Keys and
+values are escaped per Helm rules; strings that --set would convert to another
+type use --set-string, and floats and empty collections use --set-json.
+
+Example:
+  fitobj helm set values.yaml
+  fitobj helm set values.yaml --raw
+  helm upgrade app ./chart $(fitobj helm set overrides.yaml --one-line)`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		raw, _ := cmd.Flags().GetBool("raw")
+		oneLine, _ := cmd.Flags().GetBool("one-line")
+
+		data, err := readValuesFile(args[0])
+		if err != nil {
+			return err
+		}
+
+		values := fitter.FlattenHelmValues(data)
+		lines := make([]string, 0, len(values))
+		for _, value := range values {
+			if raw {
+				lines = append(lines, value.String())
+			} else {
+				lines = append(lines, value.Flag+" "+shellQuote(value.String()))
+			}
+		}
+
+		if oneLine {
+			fmt.Println(strings.Join(lines, " "))
+		} else {
+			for _, line := range lines {
+				fmt.Println(line)
+			}
+		}
+		return nil
+	},
+}
+
+var helmValuesCmd = &cobra.Command{
+	Use:   "values",
+	Short: "Parse Helm --set strings into a nested values document",
+	Long: `Parse --set, --set-string and --set-json expressions, the way Helm does, into
+nested values, optionally layered over a base values file. The result is written
+as YAML, or as JSON when --out ends with .json.
+
+Example:
+  fitobj helm values --set image.tag=1.2.3,replicas=3 --set-string version=010
+  fitobj helm values -f values.yaml --set 'ingress.hosts[0]=example.com' --out merged.yaml`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		base, _ := cmd.Flags().GetString("values")
+		sets, _ := cmd.Flags().GetStringArray("set")
+		setStrings, _ := cmd.Flags().GetStringArray("set-string")
+		setJSON, _ := cmd.Flags().GetStringArray("set-json")
+		outPath, _ := cmd.Flags().GetString("out")
+
+		data := make(map[string]any)
+		if base != "" {
+			var err error
+			if data, err = readValuesFile(base); err != nil {
+				return err
+			}
+		}
+
+		for _, expr := range setJSON {
+			if err := fitter.ParseHelmSetJSON(data, expr); err != nil {
+				return err
+			}
+		}
+		for _, expr := range sets {
+			if err := fitter.ParseHelmSet(data, expr, false); err != nil {
+				return err
+			}
+		}
+		for _, expr := range setStrings {
+			if err := fitter.ParseHelmSet(data, expr, true); err != nil {
+				return err
+			}
+		}
+
+		if strings.HasSuffix(outPath, ".json") {
+			return utils.WriteJSONFile(outPath, data)
+		}
+
+		out, err := utils.MarshalYAML(data, nil, nil, nil, getSeparator(), nil)
+		if err != nil {
+			return err
+		}
+		if outPath == "" {
+			fmt.Print(string(out))
+			return nil
+		}
+		if err := utils.EnsureDirectoryExists(filepath.Dir(outPath)); err != nil {
+			return err
+		}
+		return os.WriteFile(outPath, out, 0644)
+	},
+}
+
+// readValuesFile reads a YAML or JSON values file
+func readValuesFile(path string) (map[string]any, error) {
+	if utils.IsYAMLFile(path) {
+		data, _, _, _, err := utils.ReadYAMLFile(path, getSeparator())
+		return data, err
+	}
+	return utils.ReadJSONFile(path)
+}
+
+// shellQuote single-quotes a string when it contains characters special to the shell
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\n'\"\\$`!*?[]{}()<>|&;#~") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func init() {
+	helmSetCmd.Flags().Bool("raw", false, "print bare key=value assignments without flags or quoting")
+	helmSetCmd.Flags().Bool("one-line", false, "print all flags on a single line")
+
+	helmValuesCmd.Flags().StringP("values", "f", "", "base values file (YAML or JSON)")
+	helmValuesCmd.Flags().StringArray("set", nil, "set values (typed), e.g. a.b=1,c[0]=x")
+	helmValuesCmd.Flags().StringArray("set-string", nil, "set string values")
+	helmValuesCmd.Flags().StringArray("set-json", nil, "set JSON values, e.g. a.b=[1,2]")
+	helmValuesCmd.Flags().String("out", "", "output file (default: stdout)")
+
+	helmCmd.AddCommand(helmSetCmd)
+	helmCmd.AddCommand(helmValuesCmd)
+	rootCmd.AddCommand(helmCmd)
+}
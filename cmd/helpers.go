@@ -1,8 +1,17 @@
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
 	"github.com/haiyon/fitobj/fitter"
 	"github.com/haiyon/fitobj/processor"
+	"github.com/haiyon/fitobj/utils"
+	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
@@ -11,6 +20,41 @@ func buildProcessorOptions() processor.Options {
 		Workers:       getWorkers(),
 		FlattenOpts:   buildFlattenOptions(),
 		UnflattenOpts: buildUnflattenOptions(),
+		NumberFormat:  buildNumberFormat(),
+		Strict:        isStrict(),
+		Order:         viper.GetString("key-order"),
+		Adaptive:      viper.GetBool("adaptive-workers"),
+		NonJSON:       viper.GetString("non-json"),
+		Dates:         buildDateOptions(),
+	}
+}
+
+// addValueFlags registers the flags handling values as documents are read on a
+// processing command
+func addValueFlags(cmd *cobra.Command) {
+	cmd.Flags().String("non-json", fitter.NonJSONKeep, "values JSON cannot encode, such as YAML .nan and .inf: 'keep', 'error' (fail early with their key), 'null' or 'string'")
+	addDateFlags(cmd)
+}
+
+// addDateFlags registers the timestamp normalization flags on a processing command
+func addDateFlags(cmd *cobra.Command) {
+	cmd.Flags().String("dates", "", "normalize timestamp values as documents are read: 'rfc3339', 'epoch' or 'epoch-ms' (default: off)")
+	cmd.Flags().StringSlice("date-layouts", nil, "Go time layouts of timestamp text, and 'epoch' or 'epoch-ms' for numbers (default: RFC 3339, RFC 1123, 2006-01-02 and variants)")
+	cmd.Flags().StringSlice("date-keys", nil, "only normalize timestamps of keys matching these patterns (globs or 're:<regexp>')")
+	cmd.Flags().Bool("dates-utc", false, "write RFC 3339 timestamps in UTC instead of their own offset")
+}
+
+// buildDateOptions returns the timestamp normalization of --dates, nil when it is off
+func buildDateOptions() *fitter.DateOptions {
+	to := viper.GetString("dates")
+	if to == "" {
+		return nil
+	}
+	return &fitter.DateOptions{
+		To:       to,
+		Layouts:  viper.GetStringSlice("date-layouts"),
+		KeyGlobs: viper.GetStringSlice("date-keys"),
+		UTC:      viper.GetBool("dates-utc"),
 	}
 }
 
@@ -19,6 +63,8 @@ func buildFlattenOptions() fitter.FlattenOptions {
 	opts.Separator = getSeparator()
 	opts.ArrayFormatting = getArrayFormat()
 	opts.BufferSize = getBufferSize()
+	opts.Escape = viper.GetString("key-escape")
+	opts.EscapeStyle = viper.GetString("key-escape-style")
 	return opts
 }
 
@@ -27,9 +73,37 @@ func buildUnflattenOptions() fitter.UnflattenOptions {
 	opts.Separator = getSeparator()
 	opts.SupportBracketNotation = getArrayFormat() == "bracket"
 	opts.BufferSize = getBufferSize()
+	opts.Escape = viper.GetString("key-escape")
+	opts.EscapeStyle = viper.GetString("key-escape-style")
 	return opts
 }
 
+func buildNumberFormat() utils.NumberFormat {
+	return utils.NumberFormat{
+		Precision:     viper.GetInt("float-precision"),
+		IntegralAsInt: viper.GetBool("integral-as-int"),
+		Notation:      viper.GetString("number-notation"),
+	}
+}
+
+// validateOptions checks the processing options built from the flags before the
+// run starts, so invalid values exit with ExitUsage instead of ExitFailure
+func validateOptions(options processor.Options) error {
+	if err := options.Validate(); err != nil {
+		return usageErrorf("%v", err)
+	}
+	return nil
+}
+
+// validateKeyEscape checks --key-escape and --key-escape-style against the
+// separator and bracket array notation, for every command
+func validateKeyEscape() error {
+	if err := fitter.ValidateEscape(viper.GetString("key-escape"), viper.GetString("key-escape-style"), getSeparator()); err != nil {
+		return usageErrorf("%v", err)
+	}
+	return nil
+}
+
 func getSeparator() string {
 	return viper.GetString("separator")
 }
@@ -50,6 +124,49 @@ func getWorkers() int {
 	return workers
 }
 
+// commandConfigFlags are flags processing commands register locally, bound to
+// the top-level config key of the same name when the command runs
+var commandConfigFlags = []string{"adaptive-workers", "max-memory", "non-json", "dates", "date-layouts", "date-keys", "dates-utc"}
+
+// bindCommandFlags binds the local flags of the running command to their config
+// keys, so the configuration file sets them for every command and a flag given
+// to the command overrides it
+func bindCommandFlags(cmd *cobra.Command) {
+	for _, name := range commandConfigFlags {
+		if flag := cmd.Flags().Lookup(name); flag != nil {
+			viper.BindPFlag(name, flag)
+		}
+	}
+}
+
+// addWorkerFlags registers the worker pool and memory budget flags of directory
+// runs on a processing command
+func addWorkerFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("adaptive-workers", false, "size the worker pool and batch files by file size, --workers being the maximum")
+	cmd.Flags().String("max-memory", "", "estimated decode memory of directory runs, e.g. 512MiB; workers wait before starting files beyond it (default: unlimited)")
+}
+
+// describeWorkers describes the worker pool for progress lines
+func describeWorkers() string {
+	if viper.GetBool("adaptive-workers") {
+		return fmt.Sprintf("adaptive, up to %d", getWorkers())
+	}
+	return fmt.Sprint(getWorkers())
+}
+
+// getMaxMemory parses --max-memory into the memory budget of directory runs
+func getMaxMemory() (int64, error) {
+	value := viper.GetString("max-memory")
+	if value == "" {
+		return 0, nil
+	}
+	size, err := utils.ParseByteSize(value)
+	if err != nil {
+		return 0, usageErrorf("--max-memory: %v", err)
+	}
+	return size, nil
+}
+
 func getBufferSize() int {
 	size := viper.GetInt("buffer")
 	if size <= 0 {
@@ -57,3 +174,267 @@ func getBufferSize() int {
 	}
 	return size
 }
+
+// addFormatFlags registers the file format flags on a processing command
+func addFormatFlags(cmd *cobra.Command) {
+	cmd.Flags().String("format", "auto", "file format: 'auto' (by extension), 'json', 'jsonc', 'yaml', 'env' or 'properties'; forces the parser and output encoding")
+	cmd.Flags().String("env-separator", "_", "dotenv files: joins key segments in variable names")
+	cmd.Flags().String("env-prefix", "", "dotenv files: variable name prefix, stripped when reading (e.g. APP_)")
+	cmd.Flags().Bool("env-keep-case", false, "dotenv files: keep the case of keys instead of upper-casing variable names")
+	cmd.Flags().Bool("properties-ascii", false, "properties files: escape non-ASCII characters as \\uXXXX (for Java 8 and earlier)")
+}
+
+// buildPropertiesOptions reads the properties file flags
+func buildPropertiesOptions(cmd *cobra.Command) utils.PropertiesOptions {
+	var options utils.PropertiesOptions
+	options.ASCII, _ = cmd.Flags().GetBool("properties-ascii")
+	return options
+}
+
+// buildEnvOptions reads the dotenv variable name flags
+func buildEnvOptions(cmd *cobra.Command) utils.EnvOptions {
+	var options utils.EnvOptions
+	options.Separator, _ = cmd.Flags().GetString("env-separator")
+	options.Prefix, _ = cmd.Flags().GetString("env-prefix")
+	options.KeepCase, _ = cmd.Flags().GetBool("env-keep-case")
+	return options
+}
+
+// addYAMLFlags registers the YAML input flags on a processing command
+func addYAMLFlags(cmd *cobra.Command) {
+	cmd.Flags().String("yaml-anchors", "expand", "YAML anchors/aliases: 'expand' into copies or 'record' and restore them on output")
+}
+
+// addBulkFlags registers the Elasticsearch bulk output flags on a processing command
+func addBulkFlags(cmd *cobra.Command) {
+	cmd.Flags().String("es-index", "", "write Elasticsearch bulk files (.ndjson) for this index")
+	cmd.Flags().String("es-id-path", "", "key path of the document id in the output (default: generated ids)")
+	cmd.Flags().String("es-action", "index", "bulk action: 'index' or 'create'")
+}
+
+// buildBulkOptions returns the bulk output options, or nil when --es-index is not set
+func buildBulkOptions(cmd *cobra.Command) *utils.BulkOptions {
+	index, _ := cmd.Flags().GetString("es-index")
+	if index == "" {
+		return nil
+	}
+
+	idPath, _ := cmd.Flags().GetString("es-id-path")
+	action, _ := cmd.Flags().GetString("es-action")
+	return &utils.BulkOptions{Index: index, IDPath: idPath, Action: action}
+}
+
+// addArtifactFlags registers the signed artifact output flags on a processing command
+func addArtifactFlags(cmd *cobra.Command) {
+	cmd.Flags().String("artifact", "", "also pack the outputs into a signed .tar.gz artifact")
+}
+
+// addMetricsFlags registers the timing metrics flag on a directory processing command
+func addMetricsFlags(cmd *cobra.Command) {
+	cmd.Flags().String("metrics-file", "", "write file, stage and worker timings of the run in the Prometheus text format (e.g. for the node_exporter textfile collector)")
+}
+
+// addResumeFlags registers the flag resuming an interrupted directory run
+func addResumeFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("resume", false, "skip the files an interrupted run completed (listed in "+processor.JournalFile+" in the output directory) when their inputs are unchanged")
+}
+
+// addForceFlags registers the flag processing documents already in the target form
+func addForceFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("force", false, "also transform documents already in the target form (flat for flatten, nested for unflatten), written as read by default")
+}
+
+// addMatchFlags registers the input selection flag on a directory processing command
+func addMatchFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSlice("match", nil, "only process input paths matching these globs, relative to the input directory; ** matches across subdirectories")
+}
+
+// inputMatch splits a glob input such as './locales/**/en*.json' into the
+// directory walked and the pattern of the paths below it, with the --match
+// patterns. A fixed input is returned as is.
+func inputMatch(cmd *cobra.Command, input string) (string, []string, error) {
+	match, _ := cmd.Flags().GetStringSlice("match")
+	segments := strings.Split(filepath.ToSlash(input), "/")
+	for i, segment := range segments {
+		if !strings.ContainsAny(segment, "*?[") {
+			continue
+		}
+		dir := strings.Join(segments[:i], "/")
+		if dir == "" && i > 0 {
+			dir = "/"
+		} else if dir == "" {
+			dir = "."
+		}
+		match = append(match, strings.Join(segments[i:], "/"))
+		input = filepath.FromSlash(dir)
+		break
+	}
+	if err := processor.ValidateMatchPatterns(match); err != nil {
+		return input, nil, usageErrorf("%v", err)
+	}
+	return input, match, nil
+}
+
+// addProgressFlags registers the progress bar flag on a directory processing command
+func addProgressFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("progress", false, "draw a progress bar with the file count, rate and ETA on stderr instead of a line per file")
+}
+
+// buildProgress returns the progress bar of --progress, nil when it is off
+func buildProgress(cmd *cobra.Command) processor.ProgressReporter {
+	if progress, _ := cmd.Flags().GetBool("progress"); progress {
+		return processor.NewProgressBar(os.Stderr)
+	}
+	return nil
+}
+
+// writeArtifact packs the output directory when --artifact is set, reporting it
+// to w. The manifest is signed with FITOBJ_SIGNING_KEY when it is set.
+func writeArtifact(cmd *cobra.Command, outputDir, mode string, w io.Writer) error {
+	path, _ := cmd.Flags().GetString("artifact")
+	if path == "" {
+		return nil
+	}
+
+	manifest, err := processor.WriteArtifact(outputDir, processor.ArtifactOptions{
+		Path:    path,
+		Key:     []byte(os.Getenv("FITOBJ_SIGNING_KEY")),
+		Mode:    mode,
+		Version: Version,
+	})
+	if err != nil {
+		return err
+	}
+
+	status := "unsigned"
+	if manifest.Signature != "" {
+		status = "signed with " + manifest.Algorithm
+	}
+	fmt.Fprintf(w, "Artifact written: %s (%d files, %s)\n", path, len(manifest.Files), status)
+	return nil
+}
+
+// getOutputFormat reads the --output flag of a command printing a result summary
+func getOutputFormat(cmd *cobra.Command) (string, error) {
+	output, _ := cmd.Flags().GetString("output")
+	if output != "text" && output != "json" {
+		return "", usageErrorf("invalid --output value '%s' (expected text or json)", output)
+	}
+	return output, nil
+}
+
+// printSummary processes a directory for --output=json and prints the summary
+// instead of progress lines. The artifact is reported on standard error. The
+// returned error exits with ExitPartial when some files failed, and ExitFailure
+// when all of them did or the run could not start, as mapped by exitCode.
+func printSummary(cmd *cobra.Command, inputDir, outputDir string, unflatten bool, options processor.Options, mode string) error {
+	summary, err := processor.ProcessDirectoryWithSummary(inputDir, outputDir, unflatten, options)
+	if err != nil {
+		summary.Error, summary.Code = err.Error(), processor.ErrorRun
+	} else if summary.Success {
+		if err := writeArtifact(cmd, outputDir, mode, os.Stderr); err != nil {
+			return err
+		}
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(summary); err != nil {
+		return err
+	}
+
+	if summary.Success {
+		return nil
+	}
+	// The summary holds the details; the usage is not printed for failed runs
+	cmd.SilenceUsage = true
+	if err != nil {
+		return err
+	}
+	return &processor.FilesFailedError{Failed: summary.Failed, Total: len(summary.Files)}
+}
+
+// addLevelFlags registers the depth limit flag on a processing command
+func addLevelFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("level", 0, "only flatten or expand the first N levels of keys, keeping deeper structures intact (0 = all, otherwise at least 2)")
+}
+
+// getMaxDepth converts --level into the MaxDepth of flatten and unflatten options
+func getMaxDepth(cmd *cobra.Command) (int, error) {
+	level, _ := cmd.Flags().GetInt("level")
+	if level < 0 {
+		return 0, usageErrorf("--level must not be negative")
+	}
+	if level == 1 {
+		return 0, usageErrorf("--level=1 would leave every key as it is; use 2 or more levels, or 0 for all")
+	}
+	return level - 1, nil
+}
+
+// addPipeFlags registers the standard stream flags on a processing command
+func addPipeFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("stdin", false, "read a single document from standard input (same as an input of -)")
+	cmd.Flags().Bool("stdout", false, "write the result to standard output (same as an output of -)")
+}
+
+// pipeArgs returns the input and output arguments, --stdin and --stdout standing
+// for "-". pipe is set when either side is a standard stream.
+func pipeArgs(cmd *cobra.Command, args []string) (input, output string, pipe bool, err error) {
+	if stdin, _ := cmd.Flags().GetBool("stdin"); stdin {
+		args = append([]string{"-"}, args...)
+	}
+	if stdout, _ := cmd.Flags().GetBool("stdout"); stdout {
+		args = append(args, "-")
+	}
+	if len(args) != 2 {
+		return "", "", false, usageErrorf("expected an input and an output (use - or --stdin/--stdout for standard streams)")
+	}
+	return args[0], args[1], args[0] == "-" || args[1] == "-", nil
+}
+
+// runPipe processes a single document between standard streams and files. A
+// file side sets the format when it is auto, so the other side uses it as well.
+// Warnings are logged to standard error, standard output carrying the document.
+func runPipe(cmd *cobra.Command, input, output string, unflatten bool, options processor.Options) error {
+	if len(options.Match) > 0 {
+		return usageErrorf("input patterns and --match cannot be used with standard streams")
+	}
+	for _, name := range []string{"to", "from", "artifact", "es-index"} {
+		if flag := cmd.Flags().Lookup(name); flag != nil && flag.Changed {
+			return usageErrorf("--%s cannot be used with standard streams", name)
+		}
+	}
+	options.Logger = newLogger(os.Stderr)
+
+	if options.Format == "" || options.Format == processor.FormatAuto {
+		if input != "-" {
+			options.Format = processor.DetectFormat(input)
+		} else if output != "-" {
+			options.Format = processor.DetectFormat(output)
+		}
+	}
+
+	in := os.Stdin
+	if input != "-" {
+		file, err := os.Open(input)
+		if err != nil {
+			return fmt.Errorf("failed to open input file: %v", err)
+		}
+		defer file.Close()
+		in = file
+	}
+
+	if output == "-" {
+		return processor.ProcessPipe(in, os.Stdout, unflatten, options)
+	}
+
+	out, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %v", err)
+	}
+	err = processor.ProcessPipe(in, out, unflatten, options)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -1,9 +1,14 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 
 	"github.com/haiyon/fitobj/i18n"
+	"github.com/haiyon/fitobj/utils"
 	"github.com/spf13/cobra"
 )
 
@@ -18,21 +23,78 @@ var i18nCheckCmd = &cobra.Command{
 	Use:   "check [source-dir] [json-path]",
 	Short: "Check for missing and unused i18n keys",
 	Long: `Extract and compare i18n keys between source code and JSON files.
-Reports missing keys in JSON and unused keys in source code.
+Reports missing keys in JSON and unused keys in source code. The command exits
+with status 3 when keys are missing, or unused with --strict. To gate CI on a
+backlog, --fail-on-missing=N only fails from N missing keys, and
+--fail-on-unused=N from N unused keys (0 never fails).
+
+Calls whose key is built at runtime, such as t(` + "`errors.${code}`" + `) or
+t('errors.' + code), cannot be checked and are reported as unverifiable with the
+static start of their key. With --keep-dynamic, unused keys starting with one of
+these prefixes are counted as used instead.
+
+Keys only the backend references can be listed in a keep list (--keep-file,
+.fitobjignore by default): a key per line, a prefix ending with * (emails.*) or
+a regexp prefixed with re:. Listed keys are reported as kept, never as unused.
+
+Plural and context variants generated by i18next are used through their base key:
+t('item', { count }) uses item_one and item_other, and with --context-suffixes=male
+t('friend', { context }) uses friend_male and friend_male_one. A key used in
+source is not missing when the JSON files hold its variants.
+
+Keys are merged across the JSON files by default, so a key only translated in
+en.json is not reported for de.json. --per-locale also compares each locale file
+on its own: the source keys it misses, its unused keys, and the keys other
+locales hold but it does not (plural variants count as their base key, as
+languages use different plural forms). The command then also fails when a
+locale misses as many keys as --fail-on-missing, or, with --strict, when
+locales are inconsistent.
+
+--output=json prints the result for scripts instead: key counts, the missing
+and unused keys, the unverifiable calls, the locales and inconsistent keys with
+--per-locale, and an error with its code
+(source_error, json_error or annotate_error) when the check could not run, which
+sets exit status 2.
+
+--report writes the findings for CI instead: json (as --output=json), junit (a
+test case per missing or unused key, unused keys failing only when they fail the
+check) or sarif (for GitHub code scanning), located at the source usages of
+missing keys and the locale files holding unused keys. With --report-out the report goes to
+that file and the text output is kept.
+
+Keys are reported in natural order, numeric segments compared as numbers
+(item.2 before item.10); --sort=alpha orders them by code point instead.
+--collate=<locale> sorts the text around numbers by the rules of a locale, so
+accented and non-Latin keys come where speakers of the language expect them.
 
 Example:
   fitobj i18n check ./src ./translations
-  fitobj i18n check ./app ./locales/en.json`,
+  fitobj i18n check ./src ./translations --output=json | jq '.missing'
+  fitobj i18n check ./app ./locales/en.json
+  fitobj i18n check ./src ./translations --keep-dynamic
+  fitobj i18n check ./src ./locales --per-locale
+  fitobj i18n check ./src ./translations --fail-on-missing=20 --fail-on-unused=100
+  fitobj i18n check ./src ./translations --report junit --report-out i18n-junit.xml
+  fitobj i18n check ./src ./translations --report sarif > i18n.sarif
+  fitobj i18n check ./src ./translations --sort=alpha`,
 	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		sourceDir := args[0]
 		jsonPath := args[1]
 
+		options, err := buildI18nCheckOptions(cmd, false)
+		if err != nil {
+			return err
+		}
+		if options.output != "text" {
+			return printI18nCheckReport(cmd, sourceDir, jsonPath, options)
+		}
+
 		fmt.Printf("Extracting and comparing i18n keys...\n")
 		fmt.Printf("Source directory: %s\n", sourceDir)
 		fmt.Printf("JSON path: %s\n", jsonPath)
 
-		return runI18nCheck(sourceDir, jsonPath, false)
+		return runI18nCheck(cmd, sourceDir, jsonPath, options)
 	},
 }
 
@@ -41,63 +103,369 @@ var i18nCleanCmd = &cobra.Command{
 	Short: "Remove unused keys from JSON files",
 	Long: `Extract, compare, and automatically remove unused i18n keys from JSON files.
 
+Keys built at runtime (t(` + "`errors.${code}`" + `)) make keys look unused when they are
+not; cleanup lists these calls, and --keep-dynamic keeps the keys starting with
+their static prefix (errors.) instead of removing them. Keys on the keep list
+(--keep-file, .fitobjignore by default) are never removed.
+
+--output=json prints the result as 'fitobj i18n check --output=json' does, with
+the keys removed (or to be removed, with --dry-run) from each file; a failed
+cleanup is reported with the cleanup_error code. --report and --report-out write
+the findings as in 'fitobj i18n check'.
+
+Cleaned files are rewritten with their keys in the --sort order, natural by
+default (item.2 before item.10), and collated by --collate as in
+'fitobj i18n check'.
+
 Example:
   fitobj i18n clean ./src ./translations
-  fitobj i18n clean ./app ./locales --separator="__"`,
+  fitobj i18n clean ./app ./locales --separator="__"
+  fitobj i18n clean ./src ./translations --dry-run
+  fitobj i18n clean ./src ./translations --keep-dynamic
+  fitobj i18n clean ./src ./translations --output=json
+  fitobj i18n clean ./src ./locales --collate=sv`,
 	Args: cobra.ExactArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		sourceDir := args[0]
 		jsonPath := args[1]
 
+		options, err := buildI18nCheckOptions(cmd, true)
+		if err != nil {
+			return err
+		}
+		if options.output != "text" {
+			return printI18nCheckReport(cmd, sourceDir, jsonPath, options)
+		}
+
 		fmt.Printf("Extracting and comparing i18n keys...\n")
 		fmt.Printf("Source directory: %s\n", sourceDir)
 		fmt.Printf("JSON path: %s\n", jsonPath)
-		fmt.Printf("Cleanup mode: Enabled (unused keys will be removed)\n")
 
-		return runI18nCheck(sourceDir, jsonPath, true)
+		if options.dryRun {
+			fmt.Printf("Cleanup mode: Dry run (no files will be changed)\n")
+		} else {
+			fmt.Printf("Cleanup mode: Enabled (unused keys will be removed)\n")
+		}
+
+		return runI18nCheck(cmd, sourceDir, jsonPath, options)
 	},
 }
 
+// i18nCheckOptions configures the check and clean commands
+type i18nCheckOptions struct {
+	cleanup     bool
+	dryRun      bool
+	metadata    i18n.Metadata
+	annotate    string // "github" or "codeclimate"
+	annotateOut string
+	output      string // "text", or the report printed instead: "json", "junit" or "sarif"
+	report      string // --report format: "json", "junit" or "sarif"
+	reportOut   string // file the report is written to, next to the text output
+	extract     i18n.ExtractOptions
+	suffixes    i18n.SuffixRules
+	keepDynamic bool            // count unused keys under the prefix of a dynamic key as used
+	keep        *i18n.KeepList  // keys never reported as unused nor removed (optional)
+	perLocale   bool            // also compare each locale file on its own
+	failMissing int             // check fails with at least this many missing keys (0: never)
+	failUnused  int             // check fails with at least this many unused keys (0: never)
+	order       *utils.Ordering // order of reported keys and of the keys of cleaned files
+}
+
+// i18nCheckReport is the --output=json result of the check and clean commands
+type i18nCheckReport struct {
+	Success      bool                 `json:"success"`
+	SourceKeys   int                  `json:"sourceKeys"`
+	JSONKeys     int                  `json:"jsonKeys"`
+	Missing      []string             `json:"missing"`
+	Unused       []string             `json:"unused"`
+	Dynamic      []i18n.DynamicKey    `json:"dynamic"`                // calls whose key is built at runtime
+	Kept         []string             `json:"kept,omitempty"`         // unused keys under a dynamic prefix (--keep-dynamic) or on the keep list
+	Locales      []i18n.LocaleReport  `json:"locales,omitempty"`      // --per-locale: comparison of each locale file
+	Inconsistent []i18n.LocaleGap     `json:"inconsistent,omitempty"` // --per-locale: keys only some locales hold
+	DryRun       bool                 `json:"dryRun,omitempty"`
+	Removed      []i18n.CleanupChange `json:"removed,omitempty"` // clean: changes per file
+	Error        string               `json:"error,omitempty"`
+	Code         string               `json:"code,omitempty"`
+}
+
 func init() {
+	for _, c := range []*cobra.Command{i18nCheckCmd, i18nCleanCmd} {
+		c.Flags().String("metadata", "", "sidecar metadata JSON file shown next to reported keys")
+		c.Flags().String("annotate", "", "emit findings as PR annotations: github or codeclimate")
+		c.Flags().String("annotate-out", "", "annotation output file (default: stdout for github, gl-code-quality-report.json for codeclimate)")
+		c.Flags().String("output", "text", "result format: 'text' or 'json' (key counts, keys and error codes)")
+		c.Flags().String("report", "", "machine-readable report printed instead of the text output, or written to --report-out: json, junit or sarif")
+		c.Flags().String("report-out", "", "write the --report to this file and keep the text output")
+		addSuffixFlags(c)
+		c.Flags().String("keep-file", i18n.KeepFile, "keep list of keys never reported as unused nor removed: exact keys, prefixes ending with * and re:<regexp> lines (read when it exists)")
+		c.Flags().Bool("keep-dynamic", false, "count unused keys starting with the static prefix of a dynamic key (t(`errors.${code}`)) as used")
+		addExtractFlags(c)
+		c.Flags().String("sort", utils.OrderNatural, "order of reported keys and of the keys of cleaned files: 'natural' (item.2 before item.10) or 'alpha' (by code point)")
+		c.Flags().String("collate", "", "collate keys by the rules of a locale, e.g. de, sv or zh-Hans, with --sort=natural (default: by code point)")
+	}
+
+	i18nCheckCmd.Flags().Int("fail-on-missing", 1, "fail when at least this many keys are missing, in total or in a locale with --per-locale (0: never)")
+	i18nCheckCmd.Flags().Int("fail-on-unused", 0, "fail when at least this many keys are unused (0: never; 1 with --strict)")
+	i18nCheckCmd.Flags().Bool("per-locale", false, "also compare each locale file on its own, and the locales with each other")
+	i18nCleanCmd.Flags().Bool("dry-run", false, "show the keys that would be removed from each file without writing")
+
 	i18nCmd.AddCommand(i18nCheckCmd)
 	i18nCmd.AddCommand(i18nCleanCmd)
 	rootCmd.AddCommand(i18nCmd)
 }
 
-func runI18nCheck(sourceDir, jsonPath string, cleanup bool) error {
-	// Extract keys from source files
-	sourceKeys, err := i18n.ExtractKeysFromDir(sourceDir)
+// loadKeepList reads the --keep-file keep list. The default file is optional;
+// one set explicitly must exist.
+func loadKeepList(cmd *cobra.Command) (*i18n.KeepList, error) {
+	path, _ := cmd.Flags().GetString("keep-file")
+	if path == "" {
+		return nil, nil
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) && !cmd.Flags().Changed("keep-file") {
+		return nil, nil
+	}
+	return i18n.LoadKeepList(path)
+}
+
+// addExtractFlags registers the flags configuring key extraction from source files
+func addExtractFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSlice("func-names", []string{"t"}, "translation functions to scan for, e.g. t,i18n.t,$t,translate (prefix with re: for a regexp)")
+	cmd.Flags().StringSlice("go-funcs", nil, "translation functions of Go files, which are parsed instead of scanned, e.g. i18n.T,localizer.MustLocalize (default: --func-names)")
+	cmd.Flags().String("ns-separator", ":", "separator between namespace and key in source, as in t('common:ok')")
+	cmd.Flags().StringSlice("exclude", nil, "source paths to skip, as .gitignore lines relative to the source directory, e.g. dist,build/,src/**/*.test.ts")
+	cmd.Flags().Bool("no-gitignore", false, "also scan source paths excluded by .gitignore files")
+}
+
+// addSuffixFlags registers the flags linking i18next key variants to their base key
+func addSuffixFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSlice("plural-suffixes", i18n.DefaultSuffixRules().Plurals, "plural suffixes of keys used through their base key (item_one for t('item')); empty to compare keys exactly")
+	cmd.Flags().StringSlice("context-suffixes", nil, "context suffixes of keys used through their base key (friend_male for t('friend'))")
+	cmd.Flags().String("suffix-separator", "_", "separator before plural and context suffixes")
+}
+
+// buildSuffixRules reads the plural and context suffix flags
+func buildSuffixRules(cmd *cobra.Command) i18n.SuffixRules {
+	rules := i18n.DefaultSuffixRules()
+	rules.Plurals, _ = cmd.Flags().GetStringSlice("plural-suffixes")
+	rules.Contexts, _ = cmd.Flags().GetStringSlice("context-suffixes")
+	if separator, _ := cmd.Flags().GetString("suffix-separator"); separator != "" {
+		rules.Separator = separator
+	}
+	return rules
+}
+
+// buildExtractOptions reads the key extraction flags
+func buildExtractOptions(cmd *cobra.Command) i18n.ExtractOptions {
+	options := i18n.DefaultExtractOptions()
+	if names, _ := cmd.Flags().GetStringSlice("func-names"); len(names) > 0 {
+		options.FuncNames = names
+	}
+	options.GoFuncNames, _ = cmd.Flags().GetStringSlice("go-funcs")
+	if nsSeparator, _ := cmd.Flags().GetString("ns-separator"); nsSeparator != "" {
+		options.NsSeparator = nsSeparator
+	}
+	options.KeySeparator = getSeparator()
+	options.Workers = getWorkers()
+	options.Exclude, _ = cmd.Flags().GetStringSlice("exclude")
+	options.NoGitignore, _ = cmd.Flags().GetBool("no-gitignore")
+	return options
+}
+
+// buildI18nCheckOptions reads the shared check/clean flags
+func buildI18nCheckOptions(cmd *cobra.Command, cleanup bool) (i18nCheckOptions, error) {
+	options := i18nCheckOptions{cleanup: cleanup, extract: buildExtractOptions(cmd), suffixes: buildSuffixRules(cmd)}
+	if cleanup {
+		options.dryRun, _ = cmd.Flags().GetBool("dry-run")
+	} else {
+		options.perLocale, _ = cmd.Flags().GetBool("per-locale")
+		options.failMissing, _ = cmd.Flags().GetInt("fail-on-missing")
+		options.failUnused, _ = cmd.Flags().GetInt("fail-on-unused")
+		if options.failMissing < 0 || options.failUnused < 0 {
+			return options, usageErrorf("--fail-on-missing and --fail-on-unused must not be negative")
+		}
+		if !cmd.Flags().Changed("fail-on-unused") && isStrict() {
+			options.failUnused = 1
+		}
+	}
+	options.annotate, _ = cmd.Flags().GetString("annotate")
+	options.annotateOut, _ = cmd.Flags().GetString("annotate-out")
+	options.keepDynamic, _ = cmd.Flags().GetBool("keep-dynamic")
+
+	output, err := getOutputFormat(cmd)
 	if err != nil {
-		return fmt.Errorf("extracting keys from source: %v", err)
+		return options, err
 	}
+	options.output = output
 
-	// Extract keys from JSON files
-	jsonKeys, err := i18n.ExtractKeysFromJSONDir(jsonPath)
+	switch options.annotate {
+	case "", "github":
+	case "codeclimate":
+		if options.annotateOut == "" {
+			options.annotateOut = "gl-code-quality-report.json"
+		}
+	default:
+		return options, usageErrorf("invalid --annotate value '%s' (expected github or codeclimate)", options.annotate)
+	}
+
+	options.report, _ = cmd.Flags().GetString("report")
+	options.reportOut, _ = cmd.Flags().GetString("report-out")
+	switch options.report {
+	case "", "json", "junit", "sarif":
+	default:
+		return options, usageErrorf("invalid --report value '%s' (expected json, junit or sarif)", options.report)
+	}
+	if options.reportOut != "" && options.report == "" {
+		return options, usageErrorf("--report-out requires --report")
+	}
+	if options.report != "" && options.reportOut == "" {
+		if options.output == "json" && options.report != "json" {
+			return options, usageErrorf("--report writes to stdout; set --report-out with --output=json")
+		}
+		options.output = options.report
+	}
+
+	if options.annotate == "github" && options.annotateOut == "" && options.output != "text" {
+		return options, usageErrorf("--annotate=github writes to stdout; set --annotate-out with --output=json or --report")
+	}
+
+	metadata, err := loadMetadataFlag(cmd)
 	if err != nil {
-		return fmt.Errorf("extracting keys from JSON: %v", err)
+		return options, err
+	}
+	options.metadata = metadata
+
+	if options.keep, err = loadKeepList(cmd); err != nil {
+		return options, err
+	}
+
+	if options.order, err = buildKeySort(cmd); err != nil {
+		return options, err
+	}
+
+	return options, nil
+}
+
+// buildKeySort reads --sort and --collate
+func buildKeySort(cmd *cobra.Command) (*utils.Ordering, error) {
+	mode, _ := cmd.Flags().GetString("sort")
+	locale, _ := cmd.Flags().GetString("collate")
+	switch mode {
+	case utils.OrderAlpha:
+		if locale != "" {
+			return nil, usageErrorf("--collate requires --sort=natural")
+		}
+		return nil, nil
+	case utils.OrderNatural:
+	default:
+		return nil, usageErrorf("invalid --sort value '%s' (expected natural or alpha)", mode)
+	}
+
+	order := &utils.Ordering{Mode: utils.OrderNatural}
+	if locale != "" {
+		collator, err := utils.NewKeyCollator(locale)
+		if err != nil {
+			return nil, usageErrorf("%v", err)
+		}
+		order.Collator = collator
+	}
+	return order, nil
+}
+
+// cleanupOptions returns the options removing unused keys from the JSON files
+func (o i18nCheckOptions) cleanupOptions() i18n.CleanupOptions {
+	return i18n.CleanupOptions{Separator: getSeparator(), DryRun: o.dryRun, Order: o.order}
+}
+
+// sortI18nReport sorts the keys of a report in the --sort order
+func sortI18nReport(report *i18nCheckReport, order *utils.Ordering) {
+	sortKeys := func(keys []string) { order.SortKeys(keys, "", getSeparator()) }
+	sortKeys(report.Missing)
+	sortKeys(report.Unused)
+	sortKeys(report.Kept)
+	for i := range report.Locales {
+		sortKeys(report.Locales[i].Missing)
+		sortKeys(report.Locales[i].Unused)
+		sortKeys(report.Locales[i].Absent)
+	}
+
+	gaps := make(map[string]i18n.LocaleGap, len(report.Inconsistent))
+	keys := make([]string, 0, len(report.Inconsistent))
+	for _, gap := range report.Inconsistent {
+		gaps[gap.Key] = gap
+		keys = append(keys, gap.Key)
+	}
+	sortKeys(keys)
+	for i, key := range keys {
+		report.Inconsistent[i] = gaps[key]
 	}
+}
+
+func runI18nCheck(cmd *cobra.Command, sourceDir, jsonPath string, options i18nCheckOptions) error {
+	metadata := options.metadata
+	cleanup := options.cleanup
 
-	// Compare and report
-	missingInJSON, unusedInSource := i18n.CompareKeys(sourceKeys, jsonKeys)
+	report, usages, err := compareI18nKeys(sourceDir, jsonPath, options)
+	if err != nil {
+		return err
+	}
+	missingInJSON, unusedInSource := report.Missing, report.Unused
 
-	fmt.Printf("\n🔍 Total keys in source: %d\n", len(sourceKeys))
-	fmt.Printf("📚 Total keys in JSON: %d\n", len(jsonKeys))
+	fmt.Printf("\n🔍 Total keys in source: %d\n", report.SourceKeys)
+	fmt.Printf("📚 Total keys in JSON: %d\n", report.JSONKeys)
 
 	fmt.Printf("\n❌ Missing in JSON (%d):\n", len(missingInJSON))
 	for _, key := range missingInJSON {
-		fmt.Println(key)
+		fmt.Println(metadata.Describe(key))
 	}
 
 	fmt.Printf("\n🟡 Unused in Source (%d):\n", len(unusedInSource))
 	for _, key := range unusedInSource {
-		fmt.Println(key)
+		fmt.Println(metadata.Describe(key))
+	}
+
+	if len(report.Dynamic) > 0 {
+		fmt.Printf("\n❔ Unverifiable dynamic keys (%d):\n", len(report.Dynamic))
+		for _, key := range report.Dynamic {
+			prefix := "any key"
+			if key.Prefix != "" {
+				prefix = fmt.Sprintf("keys starting with '%s'", key.Prefix)
+			}
+			fmt.Printf("%s:%d: %s (%s)\n", key.File, key.Line, key.Expression, prefix)
+		}
+	}
+	if len(report.Kept) > 0 {
+		fmt.Printf("\n🔒 Kept as used by dynamic keys or the keep list (%d):\n", len(report.Kept))
+		for _, key := range report.Kept {
+			fmt.Println(metadata.Describe(key))
+		}
+	}
+
+	if options.perLocale {
+		printLocaleReports(report, metadata)
+	}
+
+	if options.annotate != "" {
+		if err := writeAnnotations(missingInJSON, unusedInSource, usages, jsonPath, options); err != nil {
+			return fmt.Errorf("writing annotations: %v", err)
+		}
+	}
+	if options.reportOut != "" {
+		report.Success = true
+		if err := writeReportFile(report, usages, jsonPath, options); err != nil {
+			return err
+		}
 	}
 
 	// Cleanup if requested
-	if cleanup && len(unusedInSource) > 0 {
+	if cleanup && len(unusedInSource) > 0 && options.dryRun {
+		changes, err := i18n.RemoveUnusedKeysWithOptions(jsonPath, unusedInSource, options.cleanupOptions())
+		if err != nil {
+			return fmt.Errorf("cleanup failed: %v", err)
+		}
+		printCleanupPlan(changes)
+	} else if cleanup && len(unusedInSource) > 0 {
 		fmt.Println("\n🧹 Cleaning up unused keys...")
-		separator := getSeparator()
-		if err := i18n.CleanupUnusedKeys(jsonPath, unusedInSource, separator); err != nil {
+		if err := i18n.CleanupUnusedKeysWithOptions(jsonPath, unusedInSource, options.cleanupOptions()); err != nil {
 			return fmt.Errorf("cleanup failed: %v", err)
 		}
 		fmt.Println("✅ Cleanup completed!")
@@ -105,5 +473,250 @@ func runI18nCheck(sourceDir, jsonPath string, cleanup bool) error {
 		fmt.Println("\n✅ No unused keys to cleanup!")
 	}
 
+	if checkFailed(report, options) {
+		return exitSilently(cmd, ExitCheck)
+	}
+	return nil
+}
+
+// compareI18nKeys extracts the keys used in source files and those of the JSON
+// files, and compares them. On failure, the report holds the error code.
+func compareI18nKeys(sourceDir, jsonPath string, options i18nCheckOptions) (i18nCheckReport, map[string][]i18n.KeyUsage, error) {
+	var report i18nCheckReport
+
+	// Extract keys and their usage locations from source files
+	usages, err := i18n.ExtractKeyUsagesFromDirWithOptions(sourceDir, options.extract)
+	if err != nil {
+		report.Code = "source_error"
+		return report, nil, fmt.Errorf("extracting keys from source: %v", err)
+	}
+
+	sourceKeys := make(map[string]bool, len(usages))
+	for key := range usages {
+		sourceKeys[key] = true
+	}
+
+	// Extract keys from JSON files
+	jsonKeys, err := i18n.ExtractKeysFromJSONDir(jsonPath)
+	if err != nil {
+		report.Code = "json_error"
+		return report, nil, fmt.Errorf("extracting keys from JSON: %v", err)
+	}
+
+	report.Dynamic, err = i18n.ExtractDynamicKeysFromDirWithOptions(sourceDir, options.extract)
+	if err != nil {
+		report.Code = "source_error"
+		return report, nil, fmt.Errorf("extracting keys from source: %v", err)
+	}
+
+	report.SourceKeys, report.JSONKeys = len(sourceKeys), len(jsonKeys)
+	report.Missing, report.Unused = i18n.CompareKeysWithRules(sourceKeys, jsonKeys, options.suffixes)
+	if options.keepDynamic {
+		report.Kept, report.Unused = i18n.SplitByPrefixes(report.Unused, i18n.DynamicPrefixes(report.Dynamic))
+	}
+	if kept, unused := options.keep.Split(report.Unused); len(kept) > 0 {
+		report.Kept, report.Unused = append(report.Kept, kept...), unused
+	}
+
+	if options.perLocale {
+		localeKeys, err := i18n.ExtractKeysPerLocale(jsonPath)
+		if err != nil {
+			report.Code = "json_error"
+			return report, nil, fmt.Errorf("extracting keys from JSON: %v", err)
+		}
+		report.Locales, report.Inconsistent = i18n.CompareLocaleKeys(sourceKeys, localeKeys, options.suffixes)
+		for i := range report.Locales {
+			unused := report.Locales[i].Unused
+			if options.keepDynamic {
+				_, unused = i18n.SplitByPrefixes(unused, i18n.DynamicPrefixes(report.Dynamic))
+			}
+			_, unused = options.keep.Split(unused)
+			report.Locales[i].Unused = append([]string{}, unused...)
+		}
+	}
+	sortI18nReport(&report, options.order)
+	return report, usages, nil
+}
+
+// printI18nCheckReport runs check or clean for --output=json and prints the
+// report, exiting as runI18nCheck does, or with ExitFailure when it could not
+// complete.
+func printI18nCheckReport(cmd *cobra.Command, sourceDir, jsonPath string, options i18nCheckOptions) error {
+	report, usages, err := compareI18nKeys(sourceDir, jsonPath, options)
+	if err == nil && options.annotate != "" {
+		if err = writeAnnotations(report.Missing, report.Unused, usages, jsonPath, options); err != nil {
+			report.Code = "annotate_error"
+			err = fmt.Errorf("writing annotations: %v", err)
+		}
+	}
+	if err == nil && options.cleanup && len(report.Unused) > 0 {
+		report.DryRun = options.dryRun
+		report.Removed, err = i18n.RemoveUnusedKeysWithOptions(jsonPath, report.Unused, options.cleanupOptions())
+		if err != nil {
+			report.Code = "cleanup_error"
+			err = fmt.Errorf("cleanup failed: %v", err)
+		}
+	}
+
+	if err != nil {
+		report.Error = err.Error()
+	}
+	report.Success = err == nil
+
+	// JUnit and SARIF reports only hold findings, so a failed run is reported on stderr
+	if !report.Success && options.output != "json" {
+		cmd.SilenceUsage = true
+		return err
+	}
+	if err := writeI18nReport(os.Stdout, options.output, report, usages, jsonPath, options); err != nil {
+		return err
+	}
+	if report.Success && options.reportOut != "" {
+		if err := writeReportFile(report, usages, jsonPath, options); err != nil {
+			return err
+		}
+	}
+
+	if !report.Success {
+		return exitSilently(cmd, ExitFailure)
+	}
+	if checkFailed(report, options) {
+		return exitSilently(cmd, ExitCheck)
+	}
+	return nil
+}
+
+// writeI18nReport writes the result of check or clean as JSON, or its findings as
+// a JUnit or SARIF report
+func writeI18nReport(w io.Writer, format string, report i18nCheckReport, usages map[string][]i18n.KeyUsage, jsonPath string, options i18nCheckOptions) error {
+	if format == "json" {
+		if report.Missing == nil {
+			report.Missing = []string{}
+		}
+		if report.Unused == nil {
+			report.Unused = []string{}
+		}
+		if report.Dynamic == nil {
+			report.Dynamic = []i18n.DynamicKey{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	findings, err := i18n.BuildFindings(report.Missing, report.Unused, usages, jsonPath, getSeparator())
+	if err != nil {
+		return fmt.Errorf("building report: %v", err)
+	}
+	if format == "sarif" {
+		return i18n.WriteSARIF(w, findings)
+	}
+	return i18n.WriteJUnit(w, findings, options.failUnused > 0)
+}
+
+// writeReportFile writes the --report of check or clean to --report-out
+func writeReportFile(report i18nCheckReport, usages map[string][]i18n.KeyUsage, jsonPath string, options i18nCheckOptions) error {
+	file, err := os.Create(options.reportOut)
+	if err != nil {
+		return fmt.Errorf("writing report: %v", err)
+	}
+	defer file.Close()
+
+	if err := writeI18nReport(file, options.report, report, usages, jsonPath, options); err != nil {
+		return fmt.Errorf("writing report: %v", err)
+	}
 	return nil
 }
+
+// checkFailed reports whether check found as many keys missing from the JSON
+// files or, with --per-locale, from a locale file as --fail-on-missing, as many
+// unused keys as --fail-on-unused, or inconsistent ones with --strict. Clean does
+// not fail on keys.
+func checkFailed(report i18nCheckReport, options i18nCheckOptions) bool {
+	if options.cleanup {
+		return false
+	}
+	if reachesThreshold(len(report.Missing), options.failMissing) || reachesThreshold(len(report.Unused), options.failUnused) ||
+		(isStrict() && len(report.Inconsistent) > 0) {
+		return true
+	}
+	for _, locale := range report.Locales {
+		if reachesThreshold(len(locale.Missing), options.failMissing) || reachesThreshold(len(locale.Unused), options.failUnused) {
+			return true
+		}
+	}
+	return false
+}
+
+// reachesThreshold reports whether a count of findings fails a check with a
+// --fail-on threshold, 0 disabling it
+func reachesThreshold(count, threshold int) bool {
+	return threshold > 0 && count >= threshold
+}
+
+// printLocaleReports prints the --per-locale comparison of check
+func printLocaleReports(report i18nCheckReport, metadata i18n.Metadata) {
+	fmt.Printf("\n🌐 Locales (%d):\n", len(report.Locales))
+	for _, locale := range report.Locales {
+		fmt.Printf("\n%s (%d keys): %d missing, %d unused, %d only in other locales\n",
+			locale.Locale, locale.Keys, len(locale.Missing), len(locale.Unused), len(locale.Absent))
+		for _, key := range locale.Missing {
+			fmt.Printf("   ❌ %s\n", metadata.Describe(key))
+		}
+		for _, key := range locale.Unused {
+			fmt.Printf("   🟡 %s\n", metadata.Describe(key))
+		}
+	}
+
+	if len(report.Inconsistent) > 0 {
+		fmt.Printf("\n⚖️  Inconsistent across locales (%d):\n", len(report.Inconsistent))
+		for _, gap := range report.Inconsistent {
+			fmt.Printf("%s: in %s, not in %s\n", gap.Key, strings.Join(gap.Present, ", "), strings.Join(gap.Absent, ", "))
+		}
+	}
+}
+
+// printCleanupPlan previews a dry-run cleanup as a diff of the removed keys per file
+func printCleanupPlan(changes []i18n.CleanupChange) {
+	fmt.Println("\n🧪 Dry run: no files will be changed")
+
+	total := 0
+	for _, change := range changes {
+		total += len(change.Removed)
+		fmt.Printf("\n--- %s\n+++ %s (%d keys removed, size: %d -> %d bytes)\n",
+			change.File, change.File, len(change.Removed), change.SizeBefore, change.SizeAfter)
+		for _, key := range change.Removed {
+			value, _ := json.Marshal(change.Values[key])
+			fmt.Printf("- %q: %s\n", key, value)
+		}
+	}
+
+	fmt.Printf("\n📝 Would remove %d keys from %d files\n", total, len(changes))
+}
+
+// writeAnnotations emits check findings in the requested annotation format
+func writeAnnotations(missing, unused []string, usages map[string][]i18n.KeyUsage, jsonPath string, options i18nCheckOptions) error {
+	findings, err := i18n.BuildFindings(missing, unused, usages, jsonPath, getSeparator())
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if options.annotateOut != "" {
+		file, err := os.Create(options.annotateOut)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if options.annotate == "codeclimate" {
+		return i18n.WriteCodeClimate(w, findings)
+	}
+
+	if options.annotateOut == "" {
+		fmt.Println()
+	}
+	return i18n.WriteGitHubAnnotations(w, findings)
+}
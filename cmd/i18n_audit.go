@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/haiyon/fitobj/i18n"
+	"github.com/spf13/cobra"
+)
+
+var i18nAuditCmd = &cobra.Command{
+	Use:   "audit [source-dir] [json-path]",
+	Short: "Record i18n health metrics and render trends",
+	Long: `Compute missing and unused key counts and per-locale coverage, append them to
+a history file, and optionally render a trend report of previous runs so teams
+can track whether i18n health is improving. Suited to scheduled CI jobs.
+
+Example:
+  fitobj i18n audit ./src ./locales --history .fitobj-history/
+  fitobj i18n audit ./src ./locales --history .fitobj-history/ --trend --last 10
+  fitobj i18n audit --history .fitobj-history/ --trend-only`,
+	Args: cobra.RangeArgs(0, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		historyDir, _ := cmd.Flags().GetString("history")
+		trend, _ := cmd.Flags().GetBool("trend")
+		trendOnly, _ := cmd.Flags().GetBool("trend-only")
+		last, _ := cmd.Flags().GetInt("last")
+
+		if !trendOnly {
+			if len(args) != 2 {
+				return usageErrorf("audit requires [source-dir] and [json-path] unless --trend-only is set")
+			}
+
+			record, err := i18n.RunAuditWithOptions(args[0], args[1], buildExtractOptions(cmd))
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("🔍 Source keys: %d, JSON keys: %d\n", record.SourceKeys, record.JSONKeys)
+			fmt.Printf("❌ Missing: %d, 🟡 Unused: %d\n", record.Missing, record.Unused)
+
+			locales := make([]string, 0, len(record.Coverage))
+			for locale := range record.Coverage {
+				locales = append(locales, locale)
+			}
+			sort.Strings(locales)
+			for _, locale := range locales {
+				fmt.Printf("   %-10s %6.1f%% coverage\n", locale, record.Coverage[locale])
+			}
+
+			if err := i18n.AppendHistory(historyDir, record); err != nil {
+				return err
+			}
+			fmt.Printf("\n📈 Recorded audit in %s\n", historyDir)
+		}
+
+		if trend || trendOnly {
+			records, err := i18n.LoadHistory(historyDir)
+			if err != nil {
+				return err
+			}
+			if len(records) == 0 {
+				fmt.Println("No audit history yet")
+				return nil
+			}
+			fmt.Println()
+			return i18n.WriteTrend(os.Stdout, records, last)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	i18nAuditCmd.Flags().String("history", ".fitobj-history", "directory holding the audit history")
+	i18nAuditCmd.Flags().Bool("trend", false, "render a trend report after recording")
+	i18nAuditCmd.Flags().Bool("trend-only", false, "render the trend report without running an audit")
+	i18nAuditCmd.Flags().Int("last", 20, "number of runs shown in the trend report (0 = all)")
+	addExtractFlags(i18nAuditCmd)
+
+	i18nCmd.AddCommand(i18nAuditCmd)
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/haiyon/fitobj/i18n"
+	"github.com/spf13/cobra"
+)
+
+var i18nBudgetCmd = &cobra.Command{
+	Use:   "budget [json-dir]",
+	Short: "Report translations exceeding their display length budget",
+	Long: `Check every locale file against per-key display length budgets taken from the
+metadata sidecar (maxLength) or a budget file mapping keys or key globs to a
+max width. Widths are estimated per locale: markup is ignored and East Asian
+wide characters count double.
+
+Example:
+  fitobj i18n budget ./locales --budgets ./budgets.json
+  fitobj i18n budget ./locales --metadata ./locales/meta.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		budgetPath, _ := cmd.Flags().GetString("budgets")
+
+		metadata, err := loadMetadataFlag(cmd)
+		if err != nil {
+			return err
+		}
+
+		budgets := make(i18n.Budgets)
+		if budgetPath != "" {
+			if budgets, err = i18n.LoadBudgets(budgetPath); err != nil {
+				return err
+			}
+		}
+
+		if len(budgets) == 0 && len(metadata) == 0 {
+			return fmt.Errorf("no budgets configured: provide --budgets or --metadata")
+		}
+
+		issues, err := i18n.CheckBudgets(args[0], budgets, metadata, getSeparator())
+		if err != nil {
+			return err
+		}
+
+		for _, issue := range issues {
+			fmt.Printf("%s: %s: width %d exceeds budget %d (+%d)\n",
+				issue.File, issue.Key, issue.Width, issue.Budget, issue.Width-issue.Budget)
+		}
+
+		if len(issues) > 0 {
+			return checkFailedf("found %d translations over budget", len(issues))
+		}
+
+		fmt.Println("✅ All translations fit their budgets!")
+		return nil
+	},
+}
+
+func init() {
+	i18nBudgetCmd.Flags().String("budgets", "", "budget JSON file mapping keys or globs to max widths")
+	i18nBudgetCmd.Flags().String("metadata", "", "sidecar metadata JSON file providing maxLength per key")
+
+	i18nCmd.AddCommand(i18nBudgetCmd)
+}
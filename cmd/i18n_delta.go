@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/haiyon/fitobj/fitter"
+	"github.com/haiyon/fitobj/i18n"
+	"github.com/haiyon/fitobj/utils"
+	"github.com/spf13/cobra"
+)
+
+var i18nDeltaCmd = &cobra.Command{
+	Use:   "delta [old-dir] [new-dir]",
+	Short: "Generate per-locale delta bundles between two releases",
+	Long: `Compare the locale files of two releases and write, for every locale, a delta
+file containing only the keys added or changed in the new release. Over-the-air
+update systems can ship these deltas to clients instead of full bundles.
+
+Locales without changes are skipped. Removed keys are reported but not included
+in the delta files.
+
+Example:
+  fitobj i18n delta ./release-1.2/locales ./release-1.3/locales --out ./dist/delta
+  fitobj i18n delta old/ new/ --flat`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		outDir, _ := cmd.Flags().GetString("out")
+		flat, _ := cmd.Flags().GetBool("flat")
+
+		deltas, err := i18n.BuildDeltas(args[0], args[1], getSeparator())
+		if err != nil {
+			return err
+		}
+
+		if err := utils.EnsureDirectoryExists(outDir); err != nil {
+			return err
+		}
+
+		written := 0
+		for _, delta := range deltas {
+			if len(delta.Values) == 0 {
+				fmt.Printf("⏭️  %s: no changes\n", delta.Locale)
+				continue
+			}
+
+			data := delta.Values
+			if !flat {
+				data = fitter.UnflattenMapWithOptions(data, buildUnflattenOptions())
+			}
+
+			outPath := filepath.Join(outDir, delta.Locale+".json")
+			if err := utils.WriteJSONFile(outPath, data); err != nil {
+				return err
+			}
+			written++
+
+			fmt.Printf("📦 %s: %d added, %d changed, %d removed -> %s\n",
+				delta.Locale, len(delta.Added), len(delta.Changed), len(delta.Removed), outPath)
+		}
+
+		fmt.Printf("\n✅ Wrote %d delta bundles to %s\n", written, outDir)
+		return nil
+	},
+}
+
+func init() {
+	i18nDeltaCmd.Flags().String("out", "delta", "output directory for delta bundles")
+	i18nDeltaCmd.Flags().Bool("flat", false, "emit flattened delta bundles")
+
+	i18nCmd.AddCommand(i18nDeltaCmd)
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/haiyon/fitobj/fitter"
+	"github.com/haiyon/fitobj/i18n"
+	"github.com/haiyon/fitobj/utils"
+	"github.com/spf13/cobra"
+)
+
+var i18nExportCmd = &cobra.Command{
+	Use:   "export [json-dir] [locale]",
+	Short: "Export a locale for translators as XLIFF or PO",
+	Long: `Export the base locale values and the current translations of a locale as an
+XLIFF 1.2 or gettext PO file. Key metadata from a sidecar file (description,
+screenshot URL, max length, do-not-translate) is carried as XLIFF notes and
+attributes or PO extracted comments.
+
+Example:
+  fitobj i18n export ./locales de --format xliff --out de.xlf
+  fitobj i18n export ./locales fr --format po --metadata ./locales/meta.json`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jsonDir, locale := args[0], args[1]
+		base, _ := cmd.Flags().GetString("base")
+		format, _ := cmd.Flags().GetString("format")
+		outPath, _ := cmd.Flags().GetString("out")
+
+		metadata, err := loadMetadataFlag(cmd)
+		if err != nil {
+			return err
+		}
+
+		source, err := readFlatLocale(filepath.Join(jsonDir, base+".json"))
+		if err != nil {
+			return err
+		}
+
+		target := make(map[string]any)
+		targetPath := filepath.Join(jsonDir, locale+".json")
+		if _, err := os.Stat(targetPath); err == nil {
+			if target, err = readFlatLocale(targetPath); err != nil {
+				return err
+			}
+		}
+
+		options := i18n.ExportOptions{
+			SourceLang: base,
+			TargetLang: locale,
+			Original:   locale + ".json",
+			Metadata:   metadata,
+		}
+
+		var w io.Writer = os.Stdout
+		if outPath != "" {
+			file, err := os.Create(outPath)
+			if err != nil {
+				return fmt.Errorf("failed to create output file: %v", err)
+			}
+			defer file.Close()
+			w = file
+		}
+
+		switch format {
+		case "xliff":
+			return i18n.ExportXLIFF(w, source, target, options)
+		case "po":
+			return i18n.ExportPO(w, source, target, options)
+		default:
+			return fmt.Errorf("unsupported export format '%s' (expected xliff or po)", format)
+		}
+	},
+}
+
+// loadMetadataFlag loads the sidecar metadata file given by --metadata, if any
+func loadMetadataFlag(cmd *cobra.Command) (i18n.Metadata, error) {
+	path, _ := cmd.Flags().GetString("metadata")
+	if path == "" {
+		return nil, nil
+	}
+	return i18n.LoadMetadata(path)
+}
+
+// readFlatLocale reads a locale JSON file and flattens it with the configured separator
+func readFlatLocale(filePath string) (map[string]any, error) {
+	data, err := utils.ReadJSONFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", filePath, err)
+	}
+	return fitter.FlattenMapWithOptions(data, "", buildFlattenOptions()), nil
+}
+
+func init() {
+	i18nExportCmd.Flags().String("base", "en", "base locale file name without extension")
+	i18nExportCmd.Flags().String("format", "xliff", "export format: xliff or po")
+	i18nExportCmd.Flags().String("out", "", "output file (default: stdout)")
+	i18nExportCmd.Flags().String("metadata", "", "sidecar metadata JSON file keyed by flattened path")
+
+	i18nCmd.AddCommand(i18nExportCmd)
+}
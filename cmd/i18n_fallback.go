@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/haiyon/fitobj/fitter"
+	"github.com/haiyon/fitobj/i18n"
+	"github.com/haiyon/fitobj/utils"
+	"github.com/spf13/cobra"
+)
+
+var i18nFallbackCmd = &cobra.Command{
+	Use:   "fallback [json-dir] [locale]",
+	Short: "Resolve an effective locale bundle through its fallback chain",
+	Long: `Merge the locale files of a fallback chain (e.g. fr-CA -> fr -> en) into a single
+effective bundle, so runtimes can ship precomputed fallback bundles. By default
+the chain is derived from the locale and ends with the --default locale.
+
+Example:
+  fitobj i18n fallback ./locales fr-CA --out ./dist/fr-CA.json
+  fitobj i18n fallback ./locales pt-BR --chain pt-BR,pt,es,en --flat`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		jsonDir, locale := args[0], args[1]
+		chain, _ := cmd.Flags().GetStringSlice("chain")
+		defaultLocale, _ := cmd.Flags().GetString("default")
+		outPath, _ := cmd.Flags().GetString("out")
+		flat, _ := cmd.Flags().GetBool("flat")
+
+		if len(chain) == 0 {
+			chain = i18n.FallbackChain(locale, defaultLocale)
+		}
+
+		bundle, used, err := i18n.ResolveFallback(jsonDir, chain)
+		if err != nil {
+			return err
+		}
+
+		if flat {
+			bundle = fitter.FlattenMapWithOptions(bundle, "", buildFlattenOptions())
+		}
+
+		if outPath == "" {
+			utils.PrintJSON(bundle)
+			return nil
+		}
+
+		if err := utils.WriteJSONFile(outPath, bundle); err != nil {
+			return err
+		}
+
+		fmt.Printf("Resolved %s using %s -> %s\n", locale, strings.Join(used, " -> "), outPath)
+		return nil
+	},
+}
+
+func init() {
+	i18nFallbackCmd.Flags().StringSlice("chain", nil, "explicit fallback chain, most specific first")
+	i18nFallbackCmd.Flags().String("default", "en", "default locale appended to derived chains")
+	i18nFallbackCmd.Flags().String("out", "", "output file (default: stdout)")
+	i18nFallbackCmd.Flags().Bool("flat", false, "emit a flattened bundle")
+
+	i18nCmd.AddCommand(i18nFallbackCmd)
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/haiyon/fitobj/i18n"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var i18nLintCmd = &cobra.Command{
+	Use:   "lint [json-path]",
+	Short: "Lint locale files against configurable rules",
+	Long: `Lint locale JSON files for key naming, nesting depth, forbidden characters,
+value length, trailing whitespace and HTML tag balance.
+
+Rules and severities can be set with flags or in the config file under "lint".
+The command fails with status 3 when issues at or above the --fail-on severity
+are found; --strict fails on warnings, as --fail-on=warning does.
+
+Example:
+  fitobj i18n lint ./locales --key-case=camel --max-depth=4
+  fitobj i18n lint ./locales/en.json --max-length=120 --severity=max-length=error`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := buildLintConfig()
+		if err != nil {
+			return err
+		}
+
+		failOn := i18n.Severity(viper.GetString("lint.fail-on"))
+		if failOn != i18n.SeverityError && failOn != i18n.SeverityWarning {
+			return usageErrorf("invalid --fail-on value '%s' (expected error or warning)", failOn)
+		}
+		if isStrict() {
+			failOn = i18n.SeverityWarning
+		}
+
+		issues, err := i18n.LintPath(args[0], config)
+		if err != nil {
+			return err
+		}
+
+		for _, issue := range issues {
+			fmt.Printf("%s: [%s] %s %s: %s\n", issue.File, issue.Severity, issue.Rule, issue.Key, issue.Message)
+		}
+
+		counts := i18n.CountBySeverity(issues)
+		fmt.Printf("\n🔎 Lint completed: %d errors, %d warnings, %d info\n",
+			counts[i18n.SeverityError], counts[i18n.SeverityWarning], counts[i18n.SeverityInfo])
+
+		failing := counts[i18n.SeverityError]
+		if failOn == i18n.SeverityWarning {
+			failing += counts[i18n.SeverityWarning]
+		}
+		if failing > 0 {
+			return checkFailedf("lint failed with %d issues at or above '%s' severity", failing, failOn)
+		}
+
+		return nil
+	},
+}
+
+// buildLintConfig assembles the lint configuration from flags and config file
+func buildLintConfig() (i18n.LintConfig, error) {
+	config := i18n.DefaultLintConfig()
+	config.Separator = getSeparator()
+	config.KeyCase = viper.GetString("lint.key-case")
+	config.MaxDepth = viper.GetInt("lint.max-depth")
+	config.ForbiddenChars = viper.GetString("lint.forbidden-chars")
+	config.MaxValueLength = viper.GetInt("lint.max-length")
+
+	for _, override := range viper.GetStringSlice("lint.severity") {
+		rule, severity, ok := strings.Cut(override, "=")
+		if !ok {
+			return config, usageErrorf("invalid severity override '%s' (expected rule=severity)", override)
+		}
+		config.Severities[strings.TrimSpace(rule)] = i18n.Severity(strings.TrimSpace(severity))
+	}
+	if err := i18n.ValidateLintConfig(config); err != nil {
+		return config, usageErrorf("%v", err)
+	}
+
+	return config, nil
+}
+
+func init() {
+	i18nLintCmd.Flags().String("key-case", "", "required key segment case: camel, snake, kebab or pascal")
+	i18nLintCmd.Flags().Int("max-depth", 0, "maximum key nesting depth (0 = no limit)")
+	i18nLintCmd.Flags().String("forbidden-chars", " ", "characters not allowed in keys")
+	i18nLintCmd.Flags().Int("max-length", 0, "maximum value length in characters (0 = no limit)")
+	i18nLintCmd.Flags().StringSlice("severity", nil, "rule severity override, e.g. key-case=error or html-balance=off; rules: "+strings.Join(i18n.LintRules(), ", "))
+	i18nLintCmd.Flags().String("fail-on", "error", "lowest severity that fails the run: error or warning")
+
+	for _, name := range []string{"key-case", "max-depth", "forbidden-chars", "max-length", "severity", "fail-on"} {
+		viper.BindPFlag("lint."+name, i18nLintCmd.Flags().Lookup(name))
+	}
+
+	i18nCmd.AddCommand(i18nLintCmd)
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/haiyon/fitobj/i18n"
+	"github.com/spf13/cobra"
+)
+
+var i18nMarkupCmd = &cobra.Command{
+	Use:   "markup [json-dir]",
+	Short: "Check HTML/markup consistency against the base locale",
+	Long: `Compare tags and component placeholders (<b>, <Link>, <0>) in every translated
+value with the base locale value and report dropped, added or unbalanced markup.
+
+Example:
+  fitobj i18n markup ./locales --base en`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		base, _ := cmd.Flags().GetString("base")
+
+		fmt.Printf("Checking markup in %s against base locale '%s'\n", args[0], base)
+
+		issues, err := i18n.CheckMarkupConsistency(args[0], base, getSeparator())
+		if err != nil {
+			return err
+		}
+
+		for _, issue := range issues {
+			fmt.Printf("%s: %s: %s\n", issue.File, issue.Key, issue)
+		}
+
+		if len(issues) > 0 {
+			return checkFailedf("found %d markup inconsistencies", len(issues))
+		}
+
+		fmt.Println("\n✅ Markup is consistent with the base locale!")
+		return nil
+	},
+}
+
+func init() {
+	i18nMarkupCmd.Flags().String("base", "en", "base locale file name without extension")
+
+	i18nCmd.AddCommand(i18nMarkupCmd)
+}
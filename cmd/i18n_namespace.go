@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/haiyon/fitobj/i18n"
+	"github.com/spf13/cobra"
+)
+
+var i18nExtractNamespaceCmd = &cobra.Command{
+	Use:   "extract-namespace [namespace] [pattern...]",
+	Short: "Move matching keys into a new namespace file",
+	Long: `Move keys matching the given glob patterns out of a monolithic namespace file
+into a new namespace file for every language, and rewrite source references
+to use the namespaced key (e.g. t('buttons.ok') -> t('common:buttons.ok')).
+
+Locales are expected in a <locales>/<language>/<namespace>.json layout.
+
+Example:
+  fitobj i18n extract-namespace common 'buttons.*' 'dialogs.*' --locales ./locales --source ./src
+  fitobj i18n extract-namespace auth 'login.**' --locales ./locales --from messages`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		localesDir, _ := cmd.Flags().GetString("locales")
+		sourceDir, _ := cmd.Flags().GetString("source")
+		from, _ := cmd.Flags().GetString("from")
+		nsSeparator, _ := cmd.Flags().GetString("ns-separator")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		funcNames, _ := cmd.Flags().GetStringSlice("func-names")
+
+		options := i18n.NamespaceOptions{
+			LocalesDir:  localesDir,
+			SourceDir:   sourceDir,
+			From:        from,
+			Namespace:   args[0],
+			Patterns:    args[1:],
+			Separator:   getSeparator(),
+			NsSeparator: nsSeparator,
+			FuncNames:   funcNames,
+			DryRun:      dryRun,
+		}
+
+		fmt.Printf("Extracting namespace '%s' from '%s' in %s\n", options.Namespace, from, localesDir)
+
+		result, err := i18n.ExtractNamespace(options)
+		if err != nil {
+			return err
+		}
+
+		langs := make([]string, 0, len(result.MovedKeys))
+		for lang := range result.MovedKeys {
+			langs = append(langs, lang)
+		}
+		sort.Strings(langs)
+
+		for _, lang := range langs {
+			fmt.Printf("\n📦 %s: moved %d keys\n", lang, len(result.MovedKeys[lang]))
+			for _, key := range result.MovedKeys[lang] {
+				fmt.Println(key)
+			}
+		}
+
+		if sourceDir != "" {
+			total := 0
+			for _, count := range result.RewrittenRefs {
+				total += count
+			}
+			fmt.Printf("\n✏️  Rewrote %d references in %d source files\n", total, len(result.RewrittenRefs))
+		}
+
+		if dryRun {
+			fmt.Println("\nDry run: no files were written")
+		} else {
+			fmt.Println("\n✅ Namespace extraction completed!")
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	i18nExtractNamespaceCmd.Flags().String("locales", "./locales", "locales root directory (<locales>/<language>/<namespace>.json)")
+	i18nExtractNamespaceCmd.Flags().String("source", "", "source directory whose t() references are rewritten")
+	i18nExtractNamespaceCmd.Flags().String("from", "translation", "namespace the keys are moved out of")
+	i18nExtractNamespaceCmd.Flags().String("ns-separator", ":", "separator between namespace and key in source references")
+	i18nExtractNamespaceCmd.Flags().StringSlice("func-names", []string{"t"}, "translation functions whose references are rewritten, e.g. t,i18n.t,$t (prefix with re: for a regexp)")
+	i18nExtractNamespaceCmd.Flags().Bool("dry-run", false, "report changes without writing files")
+
+	i18nCmd.AddCommand(i18nExtractNamespaceCmd)
+}
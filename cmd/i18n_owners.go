@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/haiyon/fitobj/i18n"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var i18nOwnersCmd = &cobra.Command{
+	Use:   "owners [json-path]",
+	Short: "Report missing and unused keys grouped by owning team",
+	Long: `Scan several source roots and report missing and unused keys grouped by the
+team that owns them, as configured under "i18n.owners" in the config file:
+
+  i18n:
+    owners:
+      - team: checkout
+        roots: ["./apps/checkout"]
+        keys: ["checkout.**", "cart.**"]
+      - team: platform
+        roots: ["./packages/ui"]
+        keys: ["common.**"]
+
+Missing keys are routed to the team whose source root uses them; unused keys
+to the team whose key globs match them.
+
+Example:
+  fitobj i18n owners ./locales --config .fitobj.yaml`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var owners []i18n.Owner
+		if err := viper.UnmarshalKey("i18n.owners", &owners); err != nil {
+			return fmt.Errorf("invalid i18n.owners configuration: %v", err)
+		}
+		if len(owners) == 0 {
+			return fmt.Errorf("no owners configured under i18n.owners")
+		}
+
+		usages, err := i18n.ExtractKeyUsagesFromRootsWithOptions(owners, buildExtractOptions(cmd))
+		if err != nil {
+			return err
+		}
+
+		sourceKeys := make(map[string]bool, len(usages))
+		for key := range usages {
+			sourceKeys[key] = true
+		}
+
+		jsonKeys, err := i18n.ExtractKeysFromJSONDir(args[0])
+		if err != nil {
+			return fmt.Errorf("extracting keys from JSON: %v", err)
+		}
+
+		missing, unused := i18n.CompareKeysWithRules(sourceKeys, jsonKeys, buildSuffixRules(cmd))
+		groups := i18n.GroupByOwner(missing, unused, usages, owners, getSeparator())
+
+		for _, group := range groups {
+			fmt.Printf("\n👥 %s: %d missing, %d unused\n", group.Team, len(group.Missing), len(group.Unused))
+			for _, key := range group.Missing {
+				fmt.Printf("  ❌ %s\n", key)
+			}
+			for _, key := range group.Unused {
+				fmt.Printf("  🟡 %s\n", key)
+			}
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	addExtractFlags(i18nOwnersCmd)
+	addSuffixFlags(i18nOwnersCmd)
+
+	i18nCmd.AddCommand(i18nOwnersCmd)
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/haiyon/fitobj/i18n"
+	"github.com/spf13/cobra"
+)
+
+var i18nSizeCmd = &cobra.Command{
+	Use:   "size [json-file]",
+	Short: "Report bundle size and estimated cleanup savings",
+	Long: `Analyze a locale bundle for size: largest namespaces, longest values and
+duplicate values. With --source, also report unused keys and, per --entry
+point, keys shipped but never used there, with estimated raw and gzip savings.
+
+Example:
+  fitobj i18n size ./locales/en.json
+  fitobj i18n size ./locales/en.json --source ./src --entry admin=./src/admin --entry shop=./src/shop`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sourceDir, _ := cmd.Flags().GetString("source")
+		entries, _ := cmd.Flags().GetStringSlice("entry")
+		top, _ := cmd.Flags().GetInt("top")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		options := i18n.BundleOptions{
+			Separator:   getSeparator(),
+			Top:         top,
+			EntryPoints: make(map[string]string),
+		}
+
+		for _, entry := range entries {
+			name, dir, ok := strings.Cut(entry, "=")
+			if !ok {
+				return fmt.Errorf("invalid entry point '%s' (expected name=dir)", entry)
+			}
+			options.EntryPoints[name] = dir
+		}
+
+		if len(options.EntryPoints) > 0 && sourceDir == "" {
+			return usageErrorf("--entry requires --source")
+		}
+
+		if sourceDir != "" {
+			usages, err := i18n.ExtractKeyUsagesFromDirWithOptions(sourceDir, buildExtractOptions(cmd))
+			if err != nil {
+				return fmt.Errorf("extracting keys from source: %v", err)
+			}
+			options.Usages = usages
+		}
+
+		report, err := i18n.AnalyzeBundle(args[0], options)
+		if err != nil {
+			return err
+		}
+
+		if asJSON {
+			encoded, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(encoded))
+			return nil
+		}
+
+		printBundleReport(report)
+		return nil
+	},
+}
+
+// printBundleReport prints a human-readable bundle size report
+func printBundleReport(report *i18n.BundleReport) {
+	fmt.Printf("📦 %s: %d keys, %d bytes (%d gzipped)\n", report.File, report.Keys, report.Bytes, report.GzipBytes)
+
+	fmt.Printf("\nLargest namespaces:\n")
+	for _, ns := range report.Namespaces {
+		fmt.Printf("  %-30s %8d bytes  %5d keys\n", ns.Name, ns.Bytes, ns.Keys)
+	}
+
+	fmt.Printf("\nLongest values:\n")
+	for _, value := range report.LongestValues {
+		fmt.Printf("  %-40s %5d chars\n", value.Key, value.Length)
+	}
+
+	fmt.Printf("\nDuplicate values (%d keys redundant, ~%d bytes / %d gzipped):\n",
+		report.DuplicateSave.Keys, report.DuplicateSave.Bytes, report.DuplicateSave.GzipBytes)
+	for _, dup := range report.Duplicates {
+		fmt.Printf("  %q: %s\n", dup.Value, strings.Join(dup.Keys, ", "))
+	}
+
+	if report.Unused != nil || report.UnusedSave.Keys > 0 {
+		fmt.Printf("\n🟡 Unused keys: %d (saves ~%d bytes / %d gzipped)\n",
+			report.UnusedSave.Keys, report.UnusedSave.Bytes, report.UnusedSave.GzipBytes)
+	}
+
+	for _, entry := range report.EntryPoints {
+		fmt.Printf("  entry %-20s uses %d keys, could drop %d (~%d bytes / %d gzipped)\n",
+			entry.Name, entry.Used, entry.Savings.Keys, entry.Savings.Bytes, entry.Savings.GzipBytes)
+	}
+}
+
+func init() {
+	i18nSizeCmd.Flags().String("source", "", "source directory used to find unused keys")
+	i18nSizeCmd.Flags().StringSlice("entry", nil, "entry point as name=dir (repeatable, requires --source)")
+	i18nSizeCmd.Flags().Int("top", 10, "number of entries listed per section")
+	i18nSizeCmd.Flags().Bool("json", false, "print the report as JSON")
+	addExtractFlags(i18nSizeCmd)
+
+	i18nCmd.AddCommand(i18nSizeCmd)
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/haiyon/fitobj/i18n"
+	"github.com/spf13/cobra"
+)
+
+var i18nSplitCmd = &cobra.Command{
+	Use:   "split [json-file] [output-dir]",
+	Short: "Split a locale bundle into per-route chunks by key usage",
+	Long: `Partition a monolithic locale file into chunks matching where keys are used in
+source code, and write the chunk files plus a manifest.json for lazy loading.
+Keys used by several chunks go to the shared chunk.
+
+Chunks follow the top-level source directories (see --depth) unless explicit
+--route name=dir mappings are given.
+
+Example:
+  fitobj i18n split ./locales/en.json ./dist/en --source ./src/pages
+  fitobj i18n split ./locales/en.json ./dist/en --source ./src --route admin=./src/admin --route shop=./src/shop`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sourceDir, _ := cmd.Flags().GetString("source")
+		routes, _ := cmd.Flags().GetStringSlice("route")
+		depth, _ := cmd.Flags().GetInt("depth")
+		shared, _ := cmd.Flags().GetString("shared")
+		unused, _ := cmd.Flags().GetString("unused")
+
+		options := i18n.SplitOptions{
+			SourceDir:   sourceDir,
+			Routes:      make(map[string]string),
+			Depth:       depth,
+			SharedChunk: shared,
+			UnusedChunk: unused,
+			Separator:   getSeparator(),
+		}
+
+		for _, route := range routes {
+			name, dir, ok := strings.Cut(route, "=")
+			if !ok {
+				return fmt.Errorf("invalid route '%s' (expected name=dir)", route)
+			}
+			options.Routes[name] = dir
+		}
+
+		usages, err := i18n.ExtractKeyUsagesFromDirWithOptions(sourceDir, buildExtractOptions(cmd))
+		if err != nil {
+			return fmt.Errorf("extracting keys from source: %v", err)
+		}
+
+		result, err := i18n.SplitBundle(args[0], usages, options)
+		if err != nil {
+			return err
+		}
+
+		if err := i18n.WriteSplit(args[1], result, options.Separator); err != nil {
+			return err
+		}
+
+		names := make([]string, 0, len(result.Manifest.Chunks))
+		for name := range result.Manifest.Chunks {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			fmt.Printf("📦 %-20s %5d keys\n", name, result.Manifest.Chunks[name].Keys)
+		}
+		if len(result.Dropped) > 0 {
+			fmt.Printf("🟡 Dropped %d unused keys\n", len(result.Dropped))
+		}
+
+		fmt.Printf("\n✅ Wrote %d chunks and manifest.json to %s\n", len(names), args[1])
+		return nil
+	},
+}
+
+func init() {
+	i18nSplitCmd.Flags().String("source", "./src", "source directory scanned for key usage")
+	i18nSplitCmd.Flags().StringSlice("route", nil, "chunk as name=dir (repeatable)")
+	i18nSplitCmd.Flags().Int("depth", 1, "source directory depth used to name chunks without --route")
+	i18nSplitCmd.Flags().String("shared", "shared", "chunk name for keys used by several chunks")
+	i18nSplitCmd.Flags().String("unused", "", "chunk name for unused keys (default: drop them)")
+	addExtractFlags(i18nSplitCmd)
+
+	i18nCmd.AddCommand(i18nSplitCmd)
+}
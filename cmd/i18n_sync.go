@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/haiyon/fitobj/i18n"
+	"github.com/spf13/cobra"
+)
+
+var i18nSyncCmd = &cobra.Command{
+	Use:   "sync [source-dir] [json-path]",
+	Short: "Add the keys used in source but missing from JSON files",
+	Long: `Extract the keys used in source code and add those each JSON file lacks, with
+a placeholder value translators can find:
+
+  empty  an empty string (default)
+  key    the key itself
+  todo   "TODO: " followed by the --base locale value, or the key without one
+  base   the --base locale value, or an empty string without one
+
+Every locale file is completed on its own, so a used key only translated in
+en.json is added to de.json. New keys are nested under their parent objects
+after the existing keys, and files keep their key order and indentation. Keys
+whose parent holds a value instead of an object are reported as skipped. Plural
+and context variants make their base key present, as in 'fitobj i18n check'.
+
+--output=json prints the keys added to each file for scripts.
+
+Example:
+  fitobj i18n sync ./src ./locales
+  fitobj i18n sync ./src ./locales --placeholder todo --base en
+  fitobj i18n sync ./src ./locales/de.json --placeholder key --dry-run`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sourceDir, jsonPath := args[0], args[1]
+
+		output, err := getOutputFormat(cmd)
+		if err != nil {
+			return err
+		}
+		options := i18n.ScaffoldOptions{Separator: getSeparator(), Rules: buildSuffixRules(cmd)}
+		options.Placeholder, _ = cmd.Flags().GetString("placeholder")
+		options.BaseLocale, _ = cmd.Flags().GetString("base")
+		options.DryRun, _ = cmd.Flags().GetBool("dry-run")
+		if err := i18n.ValidatePlaceholder(options.Placeholder); err != nil {
+			return usageErrorf("%v", err)
+		}
+
+		sourceKeys, err := i18n.ExtractKeysFromDirWithOptions(sourceDir, buildExtractOptions(cmd))
+		if err != nil {
+			return fmt.Errorf("extracting keys from source: %v", err)
+		}
+
+		changes, err := i18n.AddMissingKeys(jsonPath, sourceKeys, options)
+		if output == "json" {
+			if changes == nil {
+				changes = []i18n.ScaffoldChange{}
+			}
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if encodeErr := encoder.Encode(changes); encodeErr != nil {
+				return encodeErr
+			}
+			return err
+		}
+
+		if options.DryRun {
+			fmt.Println("🧪 Dry run: no files will be changed")
+		}
+		total := 0
+		for _, change := range changes {
+			total += len(change.Added)
+			fmt.Printf("\n➕ %s: %d keys\n", change.File, len(change.Added))
+			for _, key := range change.Added {
+				value, _ := json.Marshal(change.Values[key])
+				fmt.Printf("+ %q: %s\n", key, value)
+			}
+			for _, key := range change.Skipped {
+				fmt.Printf("⚠️  skipped %q: its parent holds a value\n", key)
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		if options.DryRun {
+			fmt.Printf("\n📝 Would add %d keys to %d files\n", total, len(changes))
+		} else {
+			fmt.Printf("\n✅ Added %d keys to %d files\n", total, len(changes))
+		}
+		return nil
+	},
+}
+
+func init() {
+	i18nSyncCmd.Flags().String("placeholder", i18n.PlaceholderEmpty, "value of added keys: empty, key, todo or base")
+	i18nSyncCmd.Flags().String("base", "", "locale file (without extension) whose values the todo and base placeholders use, e.g. en")
+	i18nSyncCmd.Flags().Bool("dry-run", false, "show the keys that would be added to each file without writing")
+	i18nSyncCmd.Flags().String("output", "text", "result format: 'text' or 'json' (keys added to each file)")
+	addSuffixFlags(i18nSyncCmd)
+	addExtractFlags(i18nSyncCmd)
+
+	i18nCmd.AddCommand(i18nSyncCmd)
+}
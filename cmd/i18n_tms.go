@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/haiyon/fitobj/fitter"
+	"github.com/haiyon/fitobj/i18n"
+	"github.com/haiyon/fitobj/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var i18nPushCmd = &cobra.Command{
+	Use:   "push [json-dir]",
+	Short: "Push untranslated source keys to a translation platform",
+	Long: `Push base locale strings to the configured translation management system.
+By default only keys missing from at least one other locale file are pushed.
+
+The platform is configured with flags or in the config file under "tms";
+the API token can also be given in the FITOBJ_TMS_TOKEN environment variable.
+
+Example:
+  fitobj i18n push ./locales --provider lokalise --project 123abc.45
+  fitobj i18n push ./locales --all`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newTMSClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		jsonDir := args[0]
+		base, _ := cmd.Flags().GetString("base")
+		all, _ := cmd.Flags().GetBool("all")
+
+		baseFlat, err := readFlatLocale(filepath.Join(jsonDir, base+".json"))
+		if err != nil {
+			return err
+		}
+
+		values := make(map[string]string)
+		if all {
+			for key, value := range baseFlat {
+				if s, ok := value.(string); ok {
+					values[key] = s
+				}
+			}
+		} else {
+			targets, err := readOtherLocales(jsonDir, base)
+			if err != nil {
+				return err
+			}
+			values = i18n.UntranslatedStrings(baseFlat, targets)
+		}
+
+		if len(values) == 0 {
+			fmt.Println("✅ Nothing to push!")
+			return nil
+		}
+
+		fmt.Printf("Pushing %d keys from %s to %s...\n", len(values), base, stringFlagOrConfig(cmd, "provider", "tms.provider"))
+		pushed, err := client.Push(context.Background(), base, values)
+		if err != nil {
+			return fmt.Errorf("push failed after %d keys: %v", pushed, err)
+		}
+
+		fmt.Printf("✅ Pushed %d keys\n", pushed)
+		return nil
+	},
+}
+
+var i18nPullCmd = &cobra.Command{
+	Use:   "pull [json-dir] [locale...]",
+	Short: "Pull completed translations into nested locale files",
+	Long: `Pull translations from the configured translation management system and merge
+them into <json-dir>/<locale>.json, preserving the nested structure.
+Without locales, every locale file except the base locale is updated.
+
+Example:
+  fitobj i18n pull ./locales de fr
+  fitobj i18n pull ./locales --provider lokalise --project 123abc.45`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newTMSClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		jsonDir := args[0]
+		base, _ := cmd.Flags().GetString("base")
+
+		locales := args[1:]
+		if len(locales) == 0 {
+			others, err := readOtherLocales(jsonDir, base)
+			if err != nil {
+				return err
+			}
+			for locale := range others {
+				locales = append(locales, locale)
+			}
+		}
+
+		for _, locale := range locales {
+			translations, err := client.Pull(context.Background(), locale)
+			if err != nil {
+				return fmt.Errorf("pull %s failed: %v", locale, err)
+			}
+
+			path := filepath.Join(jsonDir, locale+".json")
+			flat := make(map[string]any)
+			if _, err := os.Stat(path); err == nil {
+				if flat, err = readFlatLocale(path); err != nil {
+					return err
+				}
+			}
+
+			changed := i18n.ApplyTranslations(flat, translations)
+			if changed == 0 {
+				fmt.Printf("%s: up to date\n", locale)
+				continue
+			}
+
+			nested := fitter.UnflattenMapWithOptions(flat, buildUnflattenOptions())
+			if err := utils.WriteJSONFile(path, nested); err != nil {
+				return err
+			}
+			fmt.Printf("✅ %s: updated %d translations\n", locale, changed)
+		}
+
+		return nil
+	},
+}
+
+// newTMSClient builds the translation platform client from flags and config
+func newTMSClient(cmd *cobra.Command) (i18n.TMS, error) {
+	token := stringFlagOrConfig(cmd, "token", "tms.token")
+	if token == "" {
+		token = os.Getenv("FITOBJ_TMS_TOKEN")
+	}
+
+	return i18n.NewTMS(i18n.TMSConfig{
+		Provider: stringFlagOrConfig(cmd, "provider", "tms.provider"),
+		Project:  stringFlagOrConfig(cmd, "project", "tms.project"),
+		Token:    token,
+		BaseURL:  stringFlagOrConfig(cmd, "url", "tms.url"),
+	})
+}
+
+// stringFlagOrConfig returns an explicitly set flag, then the config value, then the flag default
+func stringFlagOrConfig(cmd *cobra.Command, name, key string) string {
+	flag := cmd.Flags().Lookup(name)
+	if flag != nil && flag.Changed {
+		return flag.Value.String()
+	}
+	if value := viper.GetString(key); value != "" {
+		return value
+	}
+	if flag != nil {
+		return flag.DefValue
+	}
+	return ""
+}
+
+// readOtherLocales reads every locale file in a directory except the base locale
+func readOtherLocales(jsonDir, base string) (map[string]map[string]any, error) {
+	entries, err := os.ReadDir(jsonDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %v", err)
+	}
+
+	locales := make(map[string]map[string]any)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		locale := strings.TrimSuffix(entry.Name(), ".json")
+		if locale == base {
+			continue
+		}
+
+		flat, err := readFlatLocale(filepath.Join(jsonDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		locales[locale] = flat
+	}
+
+	return locales, nil
+}
+
+func init() {
+	for _, c := range []*cobra.Command{i18nPushCmd, i18nPullCmd} {
+		c.Flags().String("provider", "lokalise", "translation platform provider")
+		c.Flags().String("project", "", "project identifier on the translation platform")
+		c.Flags().String("token", "", "API token (or FITOBJ_TMS_TOKEN)")
+		c.Flags().String("url", "", "API base URL override")
+		c.Flags().String("base", "en", "base locale file name without extension")
+		i18nCmd.AddCommand(c)
+	}
+
+	i18nPushCmd.Flags().Bool("all", false, "push every base key, not only untranslated ones")
+}
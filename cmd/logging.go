@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/haiyon/fitobj/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// logLevel is the level of the loggers of the command, set by --quiet and --verbose
+var logLevel = new(slog.LevelVar)
+
+// startCommand binds the config keys of local flags, sets up logging, checks the
+// global key escape and records that the command started
+func startCommand(cmd *cobra.Command, args []string) error {
+	bindCommandFlags(cmd)
+	if err := setupLogging(); err != nil {
+		return err
+	}
+	if err := validateKeyEscape(); err != nil {
+		return err
+	}
+	markStarted(cmd, args)
+	return nil
+}
+
+// setupLogging installs the default logger from --quiet, --verbose and --log-format.
+// Text lines go to standard output, where progress has always been printed; JSON
+// records go to standard error, keeping standard output for reports.
+func setupLogging() error {
+	quiet, verbose := viper.GetBool("quiet"), viper.GetBool("verbose")
+	if quiet && verbose {
+		return usageErrorf("--quiet and --verbose cannot be combined")
+	}
+	format := viper.GetString("log-format")
+	if err := utils.ValidateLogFormat(format); err != nil {
+		return usageErrorf("%v", err)
+	}
+
+	switch {
+	case quiet:
+		logLevel.Set(slog.LevelWarn)
+	case verbose:
+		logLevel.Set(slog.LevelDebug)
+	default:
+		logLevel.Set(slog.LevelInfo)
+	}
+
+	w := io.Writer(os.Stdout)
+	if format == utils.LogFormatJSON {
+		w = os.Stderr
+	}
+	slog.SetDefault(newLogger(w))
+	return nil
+}
+
+// newLogger returns a logger writing to w in the --log-format, at the level of the
+// command
+func newLogger(w io.Writer) *slog.Logger {
+	return utils.NewLogger(w, viper.GetString("log-format"), logLevel)
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/haiyon/fitobj/fitter"
+	"github.com/haiyon/fitobj/processor"
+	"github.com/spf13/cobra"
+)
+
+var merge3Cmd = &cobra.Command{
+	Use:   "merge3 [base] [ours] [theirs]",
+	Short: "Three-way merge documents key by key",
+	Long: `Merge the changes made to a base document in two versions of it, key by key
+instead of line by line. A key changed on one side only takes that side's value;
+keys changed differently on both sides are conflicts, shown in a report with
+conflict markers and set to our value. Arrays are merged as whole values.
+
+--prefer=ours or --prefer=theirs resolves every conflict to that side, and
+--interactive asks for each one. The command exits with status 3 when conflicts
+remain unresolved.
+
+The result is written to --out in the format of its extension, or printed as
+JSON when --out is not set (the report then goes to stderr). To merge files
+this way during git merges, see 'fitobj git-merge-driver'.
+
+Example:
+  fitobj merge3 base.json ours.json theirs.json --out merged.json
+  fitobj merge3 base.yaml ours.yaml theirs.yaml --out ours.yaml --interactive
+  fitobj merge3 base.json ours.json theirs.json --prefer=theirs > merged.json`,
+	Args: cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out, _ := cmd.Flags().GetString("out")
+		prefer, _ := cmd.Flags().GetString("prefer")
+		interactive, _ := cmd.Flags().GetBool("interactive")
+
+		switch prefer {
+		case "", fitter.SideOurs, fitter.SideTheirs:
+		default:
+			return usageErrorf("invalid --prefer value '%s' (expected ours or theirs)", prefer)
+		}
+
+		options := buildProcessorOptions()
+		options.Format, _ = cmd.Flags().GetString("format")
+		options.Env = buildEnvOptions(cmd)
+		options.Properties = buildPropertiesOptions(cmd)
+
+		if err := validateOptions(options); err != nil {
+			return err
+		}
+
+		merge, err := processor.Merge3Files(args[0], args[1], args[2], options)
+		if err != nil {
+			return err
+		}
+
+		var report io.Writer = os.Stdout
+		if out == "" {
+			report = os.Stderr
+		}
+
+		unresolved := 0
+		input := bufio.NewReader(os.Stdin)
+		for _, conflict := range merge.Conflicts {
+			printConflict(report, args, conflict)
+			side := prefer
+			if side == "" && interactive {
+				if side, err = askConflictSide(report, input); err != nil {
+					return err
+				}
+			}
+			if side == "" {
+				unresolved++
+				continue
+			}
+			merge.Resolve(conflict, side)
+		}
+
+		if out == "" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(fitter.UnflattenMapWithOptions(merge.Flat, options.UnflattenOpts)); err != nil {
+				return err
+			}
+		} else if err := processor.WriteMerge3(out, merge, options); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(report, "🔀 Merged %d keys: %d conflicts, %d resolved\n",
+			len(merge.Flat), len(merge.Conflicts), len(merge.Conflicts)-unresolved)
+		if unresolved > 0 {
+			fmt.Fprintf(report, "❌ %d conflicts unresolved (our values kept)\n", unresolved)
+			return exitSilently(cmd, ExitCheck)
+		}
+		return nil
+	},
+}
+
+// printConflict prints a conflicting key between conflict markers
+func printConflict(w io.Writer, paths []string, conflict fitter.Merge3Conflict) {
+	value := func(v fitter.Merge3Value) string {
+		if v.Missing {
+			return "(not set)"
+		}
+		data, _ := json.Marshal(v.Value)
+		return string(data)
+	}
+
+	fmt.Fprintf(w, "<<<<<<< ours (%s)\n%s: %s\n", paths[1], conflict.Key, value(conflict.Ours))
+	fmt.Fprintf(w, "||||||| base (%s)\n%s: %s\n", paths[0], conflict.Key, value(conflict.Base))
+	fmt.Fprintf(w, "=======\n%s: %s\n", conflict.Key, value(conflict.Theirs))
+	fmt.Fprintf(w, ">>>>>>> theirs (%s)\n\n", paths[2])
+}
+
+// askConflictSide asks which side resolves a conflict, returning "" to leave it
+func askConflictSide(w io.Writer, input *bufio.Reader) (string, error) {
+	for {
+		fmt.Fprint(w, "Keep [o]urs, [t]heirs, [b]ase or [s]kip? ")
+		line, err := input.ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("reading answer: %v", err)
+		}
+
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "o", "ours":
+			return fitter.SideOurs, nil
+		case "t", "theirs":
+			return fitter.SideTheirs, nil
+		case "b", "base":
+			return fitter.SideBase, nil
+		case "s", "skip":
+			return "", nil
+		}
+	}
+}
+
+func init() {
+	merge3Cmd.Flags().String("out", "", "output file (default: print JSON to stdout)")
+	merge3Cmd.Flags().String("prefer", "", "resolve every conflict to 'ours' or 'theirs'")
+	merge3Cmd.Flags().BoolP("interactive", "i", false, "ask how to resolve each conflict")
+	addFormatFlags(merge3Cmd)
+
+	rootCmd.AddCommand(merge3Cmd)
+}
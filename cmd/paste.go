@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/haiyon/fitobj/processor"
+	"github.com/haiyon/fitobj/utils"
+	"github.com/spf13/cobra"
+)
+
+var pasteCmd = &cobra.Command{
+	Use:   "paste",
+	Short: "Convert a pasted document between flat and nested form",
+	Long: `Read a JSON or YAML object from standard input when it is piped, or from the
+clipboard otherwise, and print it converted: flat documents (no nested objects,
+keys joined with the separator) are unflattened and the others flattened.
+--to=flat or --to=nested forces the direction.
+
+The clipboard is read with pbpaste on macOS, PowerShell on Windows, and
+wl-paste, xclip or xsel elsewhere. The result is printed as JSON, or as YAML
+with --output=yaml.
+
+Example:
+  fitobj paste
+  fitobj paste --to=flat --output=yaml
+  kubectl get configmap app -o json | jq .data | fitobj paste`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		direction, _ := cmd.Flags().GetString("to")
+		output, _ := cmd.Flags().GetString("output")
+		if output != "json" && output != "yaml" {
+			return usageErrorf("invalid --output value '%s' (expected json or yaml)", output)
+		}
+
+		var text []byte
+		var err error
+		if info, statErr := os.Stdin.Stat(); statErr == nil && info.Mode()&os.ModeCharDevice == 0 {
+			text, err = io.ReadAll(os.Stdin)
+		} else {
+			text, err = utils.ReadClipboard()
+		}
+		if err != nil {
+			return err
+		}
+
+		options := buildProcessorOptions()
+		result, unflattened, err := processor.ConvertText(text, direction, options)
+		if err != nil {
+			return err
+		}
+		if direction == "" || direction == processor.ConvertAuto {
+			if unflattened {
+				fmt.Fprintln(os.Stderr, "Detected flat input, unflattened")
+			} else {
+				fmt.Fprintln(os.Stderr, "Detected nested input, flattened")
+			}
+		}
+
+		var data []byte
+		if output == "yaml" {
+			data, err = utils.MarshalYAML(result, nil, nil, nil, options.UnflattenOpts.Separator, nil)
+		} else {
+			data, err = json.MarshalIndent(result, "", "  ")
+			data = append(data, '\n')
+		}
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(data)
+		return err
+	},
+}
+
+func init() {
+	pasteCmd.Flags().String("to", processor.ConvertAuto, "conversion: 'auto' (by detection), 'flat' or 'nested'")
+	pasteCmd.Flags().String("output", "json", "output format: 'json' or 'yaml'")
+
+	rootCmd.AddCommand(pasteCmd)
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/haiyon/fitobj/processor"
+	"github.com/haiyon/fitobj/utils"
+	"github.com/spf13/cobra"
+)
+
+var renderCmd = &cobra.Command{
+	Use:   "render",
+	Short: "Render a Go template with a document as data",
+	Long: `Render a Go text/template with a JSON, JSONC, YAML or other document as data, to
+generate config files, docs or code. The result is printed, or written to --out.
+
+The template sees the document both nested and flattened (with the separator
+and array format flags): {{ .Data.server.port }} and
+{{ index .Flat "server.port" }}. Helper functions look up flattened paths:
+
+  get "a.b"      the value at a path: a leaf, or the object or array below it
+  has "a.b"      whether a path is set
+  keys "a"       the sorted flattened keys below a path ("" for all keys)
+  default d v    v, or d when v is nil or empty
+  json v         v encoded as JSON
+
+Referring to a missing key with the dot syntax is an error; use get or has for
+optional values.
+
+Example:
+  fitobj render --data config.json --template nginx.conf.gotmpl --out nginx.conf
+  fitobj render --data values.yaml --template README.md.gotmpl`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dataPath, _ := cmd.Flags().GetString("data")
+		templatePath, _ := cmd.Flags().GetString("template")
+		outputPath, _ := cmd.Flags().GetString("out")
+		if dataPath == "" || templatePath == "" {
+			return usageErrorf("--data and --template are required")
+		}
+
+		options := buildProcessorOptions()
+		options.Format, _ = cmd.Flags().GetString("format")
+		options.Env = buildEnvOptions(cmd)
+		options.Properties = buildPropertiesOptions(cmd)
+
+		if err := validateOptions(options); err != nil {
+			return err
+		}
+
+		output, err := processor.RenderTemplate(dataPath, templatePath, options)
+		if err != nil {
+			return err
+		}
+
+		if outputPath == "" {
+			_, err := os.Stdout.Write(output)
+			return err
+		}
+		if err := utils.EnsureDirectoryExists(filepath.Dir(outputPath)); err != nil {
+			return fmt.Errorf("failed to create parent directory: %v", err)
+		}
+		if err := os.WriteFile(outputPath, output, 0644); err != nil {
+			return fmt.Errorf("failed to write output file: %v", err)
+		}
+		fmt.Printf("✅ Rendered %s to %s\n", templatePath, outputPath)
+		return nil
+	},
+}
+
+func init() {
+	renderCmd.Flags().String("data", "", "document to render the template with")
+	renderCmd.Flags().String("template", "", "Go text/template file")
+	renderCmd.Flags().String("out", "", "output file (default: standard output)")
+	addFormatFlags(renderCmd)
+
+	rootCmd.AddCommand(renderCmd)
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/haiyon/fitobj/fitter"
+	"github.com/haiyon/fitobj/processor"
+	"github.com/spf13/cobra"
+)
+
+var replaceCmd = &cobra.Command{
+	Use:   "replace [path]",
+	Short: "Find and replace text in the values of documents",
+	Long: `Replace text in the string values of a document, or of every document below a
+directory, and print each changed key as ~ key: old -> new.
+
+--from is replaced by --to everywhere in a value. With --regex, --from is a
+regular expression and --to may refer to its groups ($1, ${name}). --key-glob
+restricts the replacement to keys matching a pattern, with the syntax of
+'fitobj flatten --include'. Keys and non-string values are never changed.
+
+Changed documents are rewritten in place, keeping their format and comments.
+With --out, the documents are written to that file or directory instead,
+changed or not, and the inputs are left untouched. --dry-run only prints the
+changes.
+
+Example:
+  fitobj replace ./config --key-glob '**.apiUrl' --from staging.example.com --to prod.example.com
+  fitobj replace ./config --from 'https?://([a-z]+)\.staging\.internal' --to 'https://$1.prod.internal' --regex --dry-run
+  fitobj replace ./config --from v1 --to v2 --key-glob 'images.*.tag' --out ./config-v2`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		options := processor.ReplaceOptions{Options: buildProcessorOptions()}
+		options.Format, _ = cmd.Flags().GetString("format")
+		options.Env = buildEnvOptions(cmd)
+		options.Properties = buildPropertiesOptions(cmd)
+		options.Replace.From, _ = cmd.Flags().GetString("from")
+		options.Replace.To, _ = cmd.Flags().GetString("to")
+		options.Replace.Regex, _ = cmd.Flags().GetBool("regex")
+		options.Replace.KeyGlobs, _ = cmd.Flags().GetStringSlice("key-glob")
+		options.DryRun, _ = cmd.Flags().GetBool("dry-run")
+		outputPath, _ := cmd.Flags().GetString("out")
+
+		replacements, err := processor.ReplaceInPath(args[0], outputPath, options)
+		count := 0
+		for _, replacement := range replacements {
+			fmt.Printf("=== %s\n", replacement.File)
+			printKeyDiff(fitter.FlatDiff{Changed: replacement.Changes}, false)
+			count += len(replacement.Changes)
+		}
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case options.DryRun:
+			fmt.Printf("Dry run: would replace %d values in %d files\n", count, len(replacements))
+		case outputPath != "":
+			fmt.Printf("✅ Replaced %d values in %d files, written to %s\n", count, len(replacements), outputPath)
+		default:
+			fmt.Printf("✅ Replaced %d values in %d files\n", count, len(replacements))
+		}
+		return nil
+	},
+}
+
+func init() {
+	replaceCmd.Flags().String("from", "", "text to replace (a regular expression with --regex)")
+	replaceCmd.Flags().String("to", "", "replacement text; may refer to regular expression groups ($1, ${name}) with --regex")
+	replaceCmd.Flags().Bool("regex", false, "treat --from as a regular expression")
+	replaceCmd.Flags().StringSlice("key-glob", nil, "only replace in keys matching these patterns (globs like '**.apiUrl', or 're:<regexp>')")
+	replaceCmd.Flags().Bool("dry-run", false, "print the changes without writing anything")
+	replaceCmd.Flags().String("out", "", "write the documents to this file or directory instead of in place")
+	addFormatFlags(replaceCmd)
+
+	rootCmd.AddCommand(replaceCmd)
+}
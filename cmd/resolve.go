@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/haiyon/fitobj/processor"
+	"github.com/spf13/cobra"
+)
+
+var resolveCmd = &cobra.Command{
+	Use:   "resolve [layer...]",
+	Short: "Merge configuration layers into one document",
+	Long: `Deep-merge configuration layers (JSON, JSONC or YAML) in order, later layers
+overriding earlier ones key by key, and report which layer provided each key.
+
+A value replaces an object set at the same path by an earlier layer (and the
+reverse), and arrays are replaced whole. ${NAME} and ${NAME:-default} references
+in string values are then replaced with environment variables; an undefined
+variable without a default fails the resolution, and $${ writes a literal ${.
+
+The result is written to --out in the format of its extension, or printed as
+JSON when --out is not set (the report then goes to stderr). --provenance records
+the layer that provided each key in the output: as a "from <layer>" comment
+above the key in YAML and JSONC, and in an <out>.provenance.json sidecar file
+mapping flattened keys to layers otherwise.
+
+Example:
+  fitobj resolve base.json env/prod.json secrets.json --out final.json
+  fitobj resolve defaults.yaml overrides.yaml --no-env > config.json
+  fitobj resolve base.yaml env/prod.yaml --out final.yaml --provenance`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out, _ := cmd.Flags().GetString("out")
+		noEnv, _ := cmd.Flags().GetBool("no-env")
+		provenance, _ := cmd.Flags().GetBool("provenance")
+		if provenance && out == "" {
+			return usageErrorf("--provenance requires --out")
+		}
+
+		options := processor.ResolveOptions{Options: buildProcessorOptions(), Interpolate: !noEnv}
+		options.Format, _ = cmd.Flags().GetString("format")
+		options.Env = buildEnvOptions(cmd)
+		options.Properties = buildPropertiesOptions(cmd)
+
+		resolution, err := processor.ResolveLayers(args, options)
+		if err != nil {
+			return err
+		}
+
+		var report io.Writer = os.Stdout
+		if out == "" {
+			report = os.Stderr
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(resolution.Data); err != nil {
+				return err
+			}
+		} else if provenance {
+			if err := processor.WriteResolutionWithProvenance(out, resolution, options.Options); err != nil {
+				return err
+			}
+		} else if err := processor.WriteResolution(out, resolution, options.Options); err != nil {
+			return err
+		}
+
+		printResolution(report, resolution)
+		if out != "" {
+			fmt.Fprintf(report, "\n✅ Wrote %s\n", out)
+		}
+		return nil
+	},
+}
+
+// printResolution reports the layer that provided each key and the layers it overrode
+func printResolution(w io.Writer, resolution *processor.Resolution) {
+	provenance := resolution.Provenance()
+
+	keys := make([]string, 0, len(provenance))
+	width := 0
+	for key := range provenance {
+		keys = append(keys, key)
+		width = max(width, len(key))
+	}
+	sort.Strings(keys)
+
+	fmt.Fprintf(w, "🧩 Resolved %d keys from %d layers (%d overridden with a different value)\n",
+		len(keys), len(resolution.Layers), len(resolution.Conflicts))
+	for _, key := range keys {
+		fmt.Fprintf(w, "  %-*s  %s\n", width, key, provenance[key])
+	}
+}
+
+func init() {
+	resolveCmd.Flags().String("out", "", "output file (default: print JSON to stdout)")
+	resolveCmd.Flags().Bool("no-env", false, "do not replace ${NAME} references with environment variables")
+	resolveCmd.Flags().Bool("provenance", false, "record the layer of each key: comments in YAML/JSONC output, a .provenance.json sidecar otherwise")
+	addFormatFlags(resolveCmd)
+
+	rootCmd.AddCommand(resolveCmd)
+}
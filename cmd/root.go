@@ -24,6 +24,7 @@ var rootCmd = &cobra.Command{
 - i18n key management and cleanup
 - RESTful API server mode`,
     Version: Version,
+    PersistentPreRunE: startCommand,
 }
 
 func Execute() {
@@ -31,8 +32,10 @@ func Execute() {
     rootCmd.CompletionOptions.DisableDefaultCmd = true
     // Execute root command
     if err := rootCmd.Execute(); err != nil {
-        fmt.Fprintln(os.Stderr, err)
-        os.Exit(1)
+        if !isSilent(err) {
+            fmt.Fprintln(os.Stderr, err)
+        }
+        os.Exit(exitCode(err))
     }
 }
 
@@ -43,8 +46,18 @@ func init() {
     rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.fitobj.yaml)")
     rootCmd.PersistentFlags().String("separator", ".", "separator character for flattened keys")
     rootCmd.PersistentFlags().String("array-format", "index", "array format: 'index' or 'bracket'")
+    rootCmd.PersistentFlags().String("key-escape", "", "escape for separators and '[' inside keys, e.g. '\\' (default: none)")
+    rootCmd.PersistentFlags().String("key-escape-style", "prefix", "how --key-escape applies: 'prefix' (a\\.b) or 'quote' (the whole segment quoted, e.g. \"a.b\" with --key-escape='\"')")
     rootCmd.PersistentFlags().Int("workers", runtime.NumCPU(), "number of workers for parallel processing")
     rootCmd.PersistentFlags().Int("buffer", 16, "initial buffer size for maps")
+    rootCmd.PersistentFlags().Int("float-precision", -1, "digits after the decimal point for floats (-1: shortest)")
+    rootCmd.PersistentFlags().Bool("integral-as-int", false, "render integral floats as integers")
+    rootCmd.PersistentFlags().String("number-notation", "auto", "number notation: 'auto', 'decimal' or 'scientific'")
+    rootCmd.PersistentFlags().String("key-order", "alpha", "key order of written objects: 'alpha', 'natural' (item2 before item10) or 'preserve' (source order)")
+    rootCmd.PersistentFlags().Bool("verbose", false, "log debug details, such as the files directory runs skip and why")
+    rootCmd.PersistentFlags().Bool("quiet", false, "log warnings and errors only, without the per-file progress lines")
+    rootCmd.PersistentFlags().String("log-format", "text", "log format: 'text' (lines on stdout) or 'json' (records on stderr, for log aggregation)")
+    rootCmd.PersistentFlags().Bool("strict", false, "treat warnings as failures (schema warnings, unused keys, unchecked signatures, ...)")
 
     // Bind flags to viper
     viper.BindPFlags(rootCmd.PersistentFlags())
@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/haiyon/fitobj/processor"
+	"github.com/haiyon/fitobj/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var serveBatchCmd = &cobra.Command{
+	Use:   "serve-batch",
+	Short: "Process documents dropped into an inbox directory",
+	Long: `Serve-batch runs continuously as a directory queue, the usual way to integrate
+with systems that exchange files: documents dropped into --inbox are flattened
+(or unflattened with --unflatten) into --outbox, and documents that fail are
+moved to --error. The inbox is scanned every --interval; files modified less than
+--settle ago are left for the next scan, as they may still be being written.
+
+Each document gets a <name>.manifest.json next to its result or failed copy, with
+its checksum, key counts, the files written and, on failure, the error and its
+code (read_error, transform_error, write_error or schema_error). Manifests are
+written last, so their presence tells the reading side the other files are
+complete. Processed documents are removed from the inbox; other files are left
+alone.
+
+Documents being processed are moved to the .fitobj-processing directory of the
+inbox, and put back on the next start after an interruption. The command stops
+on SIGINT or SIGTERM; --once processes the inbox once and exits instead, for
+cron jobs, with status 4 when some documents failed and 2 when all of them did.
+
+--schedule processes the inbox on a cron schedule ("0 3 * * *": every day at
+03:00, local time) instead of polling it. Other jobs can be scheduled in the
+configuration file, as fitobj arguments run with the same configuration file:
+
+  jobs:
+    - name: audit
+      schedule: "0 3 * * *"
+      args: [i18n, audit, ./src, ./locales]
+
+Scheduled jobs run until the command stops, without the inbox when none is
+given. A run still going at the next scheduled time skips it. Each run writes
+its output to a log file of --log-dir, and the status of every job (last run,
+result, log file, next run) is kept in --log-dir/jobs.json, which
+'fitobj api --jobs-status' serves at /v1/jobs.
+
+Example:
+  fitobj serve-batch --inbox ./in --outbox ./out --error ./err
+  fitobj serve-batch --inbox ./in --outbox ./out --error ./err --unflatten --interval 10s
+  fitobj serve-batch --inbox ./in --outbox ./out --error ./err --format=yaml --once
+  fitobj serve-batch --inbox ./in --outbox ./out --error ./err --schedule "0 3 * * *"
+  fitobj serve-batch --config jobs.yaml --log-dir /var/log/fitobj`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		inbox, _ := cmd.Flags().GetString("inbox")
+		outbox, _ := cmd.Flags().GetString("outbox")
+		errorDir, _ := cmd.Flags().GetString("error")
+		once, _ := cmd.Flags().GetBool("once")
+		schedule, _ := cmd.Flags().GetString("schedule")
+		logDir, _ := cmd.Flags().GetString("log-dir")
+
+		var configs []jobConfig
+		if err := viper.UnmarshalKey("jobs", &configs); err != nil {
+			return usageErrorf("invalid jobs in the configuration file: %v", err)
+		}
+		if once {
+			configs = nil
+		}
+		if (inbox == "" && len(configs) == 0) || (inbox != "" && (outbox == "" || errorDir == "")) {
+			return usageErrorf("--inbox, --outbox and --error are required, unless jobs are configured")
+		}
+		if schedule != "" && inbox == "" {
+			return usageErrorf("--schedule requires --inbox")
+		}
+
+		options := processor.InboxOptions{
+			Inbox:    inbox,
+			Outbox:   outbox,
+			ErrorDir: errorDir,
+			Options:  buildProcessorOptions(),
+		}
+		options.Unflatten, _ = cmd.Flags().GetBool("unflatten")
+		options.Interval, _ = cmd.Flags().GetDuration("interval")
+		options.Settle, _ = cmd.Flags().GetDuration("settle")
+		options.Options.Format, _ = cmd.Flags().GetString("format")
+		options.Options.Env = buildEnvOptions(cmd)
+		options.Options.Properties = buildPropertiesOptions(cmd)
+		if options.Interval <= 0 {
+			return usageErrorf("--interval must be positive")
+		}
+		if err := validateOptions(options.Options); err != nil {
+			return err
+		}
+
+		processed, failed := 0, 0
+		report := func(manifest processor.InboxManifest) {
+			processed++
+			if !manifest.Success {
+				failed++
+				fmt.Printf("❌ %s: %s (%s)\n", manifest.File, manifest.Error, manifest.Code)
+				return
+			}
+			for _, warning := range manifest.Warnings {
+				fmt.Printf("⚠️  Schema warning in '%s': %s\n", manifest.File, warning)
+			}
+			fmt.Printf("✅ %s -> %v (%d keys)\n", manifest.File, manifest.Outputs, manifest.KeysOut)
+		}
+
+		if once {
+			if _, err := processor.ProcessInbox(options, report); err != nil {
+				return err
+			}
+			fmt.Printf("Processed %d files (%d failed)\n", processed, failed)
+			if failed > 0 {
+				return &processor.FilesFailedError{Failed: failed, Total: processed}
+			}
+			return nil
+		}
+
+		var jobs []processor.Job
+		for _, config := range configs {
+			if _, err := utils.ParseSchedule(config.Schedule); err != nil {
+				return usageErrorf("job '%s': %v", config.Name, err)
+			}
+			jobs = append(jobs, commandJob(config))
+		}
+		if schedule != "" {
+			if _, err := utils.ParseSchedule(schedule); err != nil {
+				return usageErrorf("--schedule: %v", err)
+			}
+			jobs = append(jobs, processor.InboxJob("inbox", schedule, options, report))
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		// The inbox is polled unless it is scheduled, alongside the scheduled jobs
+		errs := make(chan error, 2)
+		running := 0
+		if inbox != "" && schedule == "" {
+			running++
+			fmt.Printf("Watching %s (results to %s, errors to %s)\n", inbox, outbox, errorDir)
+			go func() { errs <- processor.ServeInbox(ctx, options, report) }()
+		}
+		if len(jobs) > 0 {
+			scheduler, err := processor.NewScheduler(jobs, logDir)
+			if err != nil {
+				return err
+			}
+			running++
+			for _, job := range jobs {
+				fmt.Printf("Scheduled %s: %s\n", job.Name, job.Schedule)
+			}
+			fmt.Printf("Job logs and status in %s\n", logDir)
+			go func() {
+				errs <- scheduler.Run(ctx, func(status processor.JobStatus) {
+					log := filepath.Join(logDir, status.Log)
+					if status.LastSuccess {
+						fmt.Printf("✅ Job %s succeeded (log: %s)\n", status.Name, log)
+					} else {
+						fmt.Printf("❌ Job %s failed: %s (log: %s)\n", status.Name, status.LastError, log)
+					}
+				})
+			}()
+		}
+
+		var err error
+		for ; running > 0; running-- {
+			if runErr := <-errs; runErr != nil && err == nil {
+				err = runErr
+				stop()
+			}
+		}
+		fmt.Fprintf(os.Stderr, "Stopped. Processed %d files (%d failed)\n", processed, failed)
+		return err
+	},
+}
+
+// jobConfig is a job of the configuration file: fitobj arguments run on a cron schedule
+type jobConfig struct {
+	Name     string   `mapstructure:"name"`
+	Schedule string   `mapstructure:"schedule"`
+	Args     []string `mapstructure:"args"`
+}
+
+// commandJob returns a job running fitobj with the arguments of a configured job,
+// and the configuration file of this process
+func commandJob(config jobConfig) processor.Job {
+	return processor.Job{
+		Name:     config.Name,
+		Schedule: config.Schedule,
+		Run: func(ctx context.Context, log io.Writer) error {
+			executable, err := os.Executable()
+			if err != nil {
+				return fmt.Errorf("failed to locate fitobj: %v", err)
+			}
+			args := config.Args
+			if cfgFile != "" {
+				args = append(append([]string(nil), args...), "--config", cfgFile)
+			}
+
+			command := exec.CommandContext(ctx, executable, args...)
+			command.Stdout = log
+			command.Stderr = log
+			if err := command.Run(); err != nil {
+				return fmt.Errorf("fitobj %s: %v", strings.Join(config.Args, " "), err)
+			}
+			return nil
+		},
+	}
+}
+
+func init() {
+	serveBatchCmd.Flags().String("inbox", "", "directory polled for documents to process")
+	serveBatchCmd.Flags().String("outbox", "", "directory receiving results and their manifests")
+	serveBatchCmd.Flags().String("error", "", "directory receiving failed documents and their manifests")
+	serveBatchCmd.Flags().Bool("unflatten", false, "unflatten documents instead of flattening them")
+	serveBatchCmd.Flags().Duration("interval", 2*time.Second, "delay between inbox scans")
+	serveBatchCmd.Flags().Duration("settle", time.Second, "leave files modified more recently than this for the next scan")
+	serveBatchCmd.Flags().Bool("once", false, "process the inbox once and exit")
+	serveBatchCmd.Flags().String("schedule", "", "process the inbox on a cron schedule (e.g. '0 3 * * *') instead of polling it")
+	serveBatchCmd.Flags().String("log-dir", "fitobj-logs", "directory of the run logs and status (jobs.json) of scheduled jobs")
+	addFormatFlags(serveBatchCmd)
+	addValueFlags(serveBatchCmd)
+
+	rootCmd.AddCommand(serveBatchCmd)
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/haiyon/fitobj/processor"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var streamCmd = &cobra.Command{
+	Use:   "stream [flatten|unflatten]",
+	Short: "Transform a stream of JSON messages",
+	Long: `Stream reads newline-delimited JSON messages, flattens or unflattens each one
+and writes it out as a line, turning fitobj into a lightweight stream transformer.
+
+Messages are read from stdin and written to stdout by default. With --brokers,
+messages are consumed from the --from topic in the --group consumer group and/or
+produced to the --to topic through kcat (https://github.com/edenhill/kcat), which
+must be installed. The output is flushed every --batch-size messages, or earlier
+when the input is idle. Invalid messages are reported on stderr and skipped.
+
+The messages available at once, up to a batch, are transformed by --workers in
+parallel and written in input order, so the output does not depend on the
+number of workers.
+
+Example:
+  kafka-console-consumer ... | fitobj stream flatten > flat.ndjson
+  fitobj stream flatten --brokers localhost:9092 --from events --to events.flat
+  fitobj stream unflatten --brokers b1:9092,b2:9092 --group fitobj-unflatten --from flat --to nested --batch-size 500`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"flatten", "unflatten"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if args[0] != "flatten" && args[0] != "unflatten" {
+			return fmt.Errorf("unknown stream mode '%s' (expected flatten or unflatten)", args[0])
+		}
+
+		brokers := viper.GetString("stream.brokers")
+		group := viper.GetString("stream.group")
+		from := viper.GetString("stream.from")
+		to := viper.GetString("stream.to")
+		batchSize := viper.GetInt("stream.batch-size")
+		kcat := viper.GetString("stream.kcat")
+
+		if brokers == "" && (from != "" || to != "") {
+			return usageErrorf("--from and --to require --brokers")
+		}
+		options := buildProcessorOptions()
+		if err := validateOptions(options); err != nil {
+			return err
+		}
+
+		var in io.Reader = os.Stdin
+		var out io.Writer = os.Stdout
+
+		// Interrupting stops the consumer; the messages read so far are still delivered
+		ctx := context.Background()
+		var consumer *exec.Cmd
+		if from != "" {
+			var stop context.CancelFunc
+			ctx, stop = signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			consumer = exec.CommandContext(ctx, kcat, "-b", brokers, "-G", group, "-q", "-u", from)
+			consumer.Stderr = os.Stderr
+			pipe, err := consumer.StdoutPipe()
+			if err != nil {
+				return fmt.Errorf("failed to start consumer: %v", err)
+			}
+			if err := consumer.Start(); err != nil {
+				return fmt.Errorf("failed to start consumer: %v", err)
+			}
+			in = pipe
+		}
+
+		var producer *exec.Cmd
+		var producerIn io.WriteCloser
+		if to != "" {
+			producer = exec.Command(kcat, "-b", brokers, "-P", "-t", to, "-X", "batch.num.messages="+strconv.Itoa(batchSize))
+			producer.Stdout = os.Stderr
+			producer.Stderr = os.Stderr
+			pipe, err := producer.StdinPipe()
+			if err != nil {
+				return fmt.Errorf("failed to start producer: %v", err)
+			}
+			if err := producer.Start(); err != nil {
+				return fmt.Errorf("failed to start producer: %v", err)
+			}
+			producerIn = pipe
+			out = pipe
+		}
+
+		stats, err := processor.TransformStream(in, out, processor.StreamOptions{
+			Unflatten: args[0] == "unflatten",
+			BatchSize: batchSize,
+			Errors:    os.Stderr,
+			Options:   options,
+		})
+
+		// Closing the producer input lets kcat deliver the remaining messages and exit
+		if producer != nil {
+			producerIn.Close()
+			if waitErr := producer.Wait(); waitErr != nil && err == nil {
+				err = fmt.Errorf("producer failed: %v", waitErr)
+			}
+		}
+		if consumer != nil {
+			if waitErr := consumer.Wait(); waitErr != nil && err == nil && ctx.Err() == nil {
+				err = fmt.Errorf("consumer failed: %v", waitErr)
+			}
+		}
+
+		fmt.Fprintf(os.Stderr, "Stream completed. Processed %d messages (%d failed)\n", stats.Messages, stats.Failed)
+		return err
+	},
+}
+
+func init() {
+	streamCmd.Flags().String("brokers", "", "Kafka bootstrap brokers (comma-separated)")
+	viper.BindPFlag("stream.brokers", streamCmd.Flags().Lookup("brokers"))
+	streamCmd.Flags().String("group", "fitobj", "Kafka consumer group")
+	viper.BindPFlag("stream.group", streamCmd.Flags().Lookup("group"))
+	streamCmd.Flags().String("from", "", "Kafka topic to consume (default: stdin)")
+	viper.BindPFlag("stream.from", streamCmd.Flags().Lookup("from"))
+	streamCmd.Flags().String("to", "", "Kafka topic to produce to (default: stdout)")
+	viper.BindPFlag("stream.to", streamCmd.Flags().Lookup("to"))
+	streamCmd.Flags().Int("batch-size", 100, "messages per output batch")
+	viper.BindPFlag("stream.batch-size", streamCmd.Flags().Lookup("batch-size"))
+	streamCmd.Flags().String("kcat", "kcat", "path of the kcat binary used for Kafka")
+	viper.BindPFlag("stream.kcat", streamCmd.Flags().Lookup("kcat"))
+	addDateFlags(streamCmd)
+
+	rootCmd.AddCommand(streamCmd)
+}
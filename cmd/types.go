@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/haiyon/fitobj/processor"
+	"github.com/spf13/cobra"
+)
+
+var typesCmd = &cobra.Command{
+	Use:   "types [dir]",
+	Short: "Check that keys keep one value type across documents",
+	Long: `Walk every document below a directory and report the keys whose values have
+different types across documents: a string in one file and a number in another,
+or nested keys in one file and a value in another. Typed consumers of the
+documents, such as BigQuery tables loaded from 'fitobj flatten --to=bigquery' or
+typed config structs, break on such drift.
+
+Types are the JSON types: string, number, boolean, object and array. Null values
+fit every type and are ignored. The elements of an array are checked against
+each other under the key of the array followed by [] (items[].id).
+--strict-numbers tells integers from numbers with a fraction, as INTEGER and
+FLOAT columns do.
+
+--output=json prints the drifting keys as [{"key", "types": [{"type", "files"}]}].
+The command exits with status 3 when a key drifts.
+
+Example:
+  fitobj types ./events
+  fitobj types ./config --strict-numbers
+  fitobj types ./exports --output=json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		if output != "text" && output != "json" {
+			return usageErrorf("invalid --output value '%s' (expected text or json)", output)
+		}
+
+		options := processor.TypeCheckOptions{Options: buildProcessorOptions()}
+		options.Format, _ = cmd.Flags().GetString("format")
+		options.Env = buildEnvOptions(cmd)
+		options.Properties = buildPropertiesOptions(cmd)
+		options.StrictNumbers, _ = cmd.Flags().GetBool("strict-numbers")
+
+		drifts, files, err := processor.CheckTypes(args[0], options)
+		if err != nil {
+			return err
+		}
+
+		if output == "json" {
+			if drifts == nil {
+				drifts = []processor.TypeDrift{}
+			}
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(drifts); err != nil {
+				return err
+			}
+		} else {
+			for _, drift := range drifts {
+				types := make([]string, len(drift.Types))
+				for i, use := range drift.Types {
+					types[i] = use.Type + " in " + strings.Join(use.Files, ", ")
+				}
+				fmt.Printf("%s: %s\n", drift.Key, strings.Join(types, "; "))
+			}
+			if len(drifts) == 0 {
+				fmt.Printf("No type drift in %d files\n", files)
+			} else {
+				fmt.Printf("\n%d keys with drifting types in %d files\n", len(drifts), files)
+			}
+		}
+
+		if len(drifts) > 0 {
+			return exitSilently(cmd, ExitCheck)
+		}
+		return nil
+	},
+}
+
+func init() {
+	typesCmd.Flags().Bool("strict-numbers", false, "tell integers from numbers with a fraction")
+	typesCmd.Flags().String("output", "text", "output format: 'text' or 'json'")
+	addFormatFlags(typesCmd)
+
+	rootCmd.AddCommand(typesCmd)
+}
@@ -2,7 +2,10 @@ package cmd
 
 import (
 	"fmt"
+	"log/slog"
+	"os"
 
+	"github.com/haiyon/fitobj/fitter"
 	"github.com/haiyon/fitobj/processor"
 	"github.com/spf13/cobra"
 )
@@ -12,23 +15,182 @@ var unflattenCmd = &cobra.Command{
 	Short: "Unflatten JSON objects back to nested structure",
 	Long: `Unflatten converts flat key-value pairs back into nested JSON objects.
 
+YAML and JSONC files are detected by extension. --format forces the parser for
+every input file and the encoding of the output (--format=yaml writes
+config.json as config.yaml).
+
+Dotenv files (.env, *.env, .env.*) are read with their variable names turned
+back into keys (DB_HOST becomes db.host; see --env-separator, --env-prefix and
+--env-keep-case), so --format=json unflattens them into nested JSON. Use
+--env-separator=__ when keys hold underscores, and --coerce-types to parse
+numbers and booleans.
+
+Properties files (.properties) are read with their escapes and \uXXXX sequences
+resolved, and their keys split on the separator like any flattened key.
+
+With --from=csv or --from=tsv, the first argument is a key/value table file, as
+written by 'fitobj flatten --to=csv': a header naming key and value columns and
+optionally a file column (other columns are ignored). One document is written
+per file named in the table, or <table-name>.json without a file column.
+With --from=bundle, the first argument is a bundle written by 'fitobj flatten
+--to=bundle', split back into its documents with the file names and formats of
+its source map (<name>.map.json); keys added to the bundle go to the document
+whose prefix they start with.
+
+With --schema, values are coerced to the types declared in a JSON Schema
+(e.g. "5" becomes 5 under an integer property), and missing required fields
+and values that cannot be coerced are reported.
+
+--level N only expands the first N levels: keys are split into at most N
+segments and the rest of each key is kept joined (a.b.c.d becomes
+{"a": {"b.c.d": ...}} with --level=2).
+
+--coerce-types parses string values produced by .env or CSV sources back into
+native types: "true"/"false", "null" and JSON numbers such as "42" or "1.5". A
+leading backslash keeps a value a string (\true stays "true", \\x becomes \x).
+
+--output=json prints a summary for scripts instead of progress lines, as
+'fitobj flatten --output=json' does; schema warnings are listed per file.
+--metrics-file writes file, stage and worker timings in the Prometheus text format.
+--progress draws a progress bar on stderr instead of a line per file.
+A quoted glob input such as './flat/**/*.json', or --match, selects the inputs
+in a directory tree, as 'fitobj flatten' does.
+--max-memory holds back file starts while the estimated decode memory of the
+files in progress would exceed it. --resume continues an interrupted run, skipping
+the unchanged files listed in the journal of its output directory, as
+'fitobj flatten --resume' does.
+Documents already nested (no key holds the separator or an array index) are
+written as read, without being unflattened, and listed as skipped (nested);
+--force unflattens them anyway, as do runs with --format conversions,
+--es-index, --coerce-types, --schema or number formatting.
+
+An input or output of - (or --stdin and --stdout) reads or writes a single
+document on the standard streams instead, for use in shell pipelines. Both sides
+use --format, JSON by default, or the format of the file on the other side.
+
 Example:
   fitobj unflatten ./flattened ./nested
-  fitobj unflatten ./flat ./nested --separator="__"`,
-	Args: cobra.ExactArgs(2),
+  fitobj unflatten ./flat ./nested --output=json | jq '.files[] | select(.code)'
+  fitobj flatten - - < config.json | jq 'del(.secret)' | fitobj unflatten - -
+  fitobj unflatten ./flat ./nested --separator="__"
+  fitobj unflatten ./flat ./partial --level=2
+  fitobj unflatten ./flat-json ./config --format=yaml
+  fitobj unflatten ./bundles ./locales --format=json
+  fitobj unflatten ./imported ./config --schema config.schema.json
+  fitobj unflatten ./from-csv ./nested --coerce-types
+  fitobj unflatten ./env ./config --format=json --env-prefix=APP_ --coerce-types
+  fitobj unflatten translations.csv ./locales --from=csv
+  fitobj unflatten dist/i18n.json ./locales --from=bundle
+  FITOBJ_SIGNING_KEY=secret fitobj unflatten ./in ./out --artifact out.tar.gz`,
+	Args: cobra.RangeArgs(0, 2),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		inputDir := args[0]
-		outputDir := args[1]
+		inputDir, outputDir, pipe, err := pipeArgs(cmd, args)
+		if err != nil {
+			return err
+		}
+		output, err := getOutputFormat(cmd)
+		if err != nil {
+			return err
+		}
+		if output == "json" && pipe {
+			return usageErrorf("--output=json cannot be used with standard streams")
+		}
 
-		fmt.Printf("Unflattening JSON files from %s to %s\n", inputDir, outputDir)
-		fmt.Printf("Using separator: '%s', array format: '%s', workers: %d\n",
-			getSeparator(), getArrayFormat(), getWorkers())
+		if from, _ := cmd.Flags().GetString("from"); from != "" && !pipe {
+			if output == "json" {
+				return usageErrorf("--output=json cannot be used with --from")
+			}
+			options := buildProcessorOptions()
+			options.Format, _ = cmd.Flags().GetString("format")
+			options.Env = buildEnvOptions(cmd)
+			options.Properties = buildPropertiesOptions(cmd)
+			options.UnflattenOpts.CoerceTypes, _ = cmd.Flags().GetBool("coerce-types")
+			if from != processor.ExportCSV && from != processor.ExportTSV && from != processor.ExportBundle {
+				return usageErrorf("unknown import format '%s' (expected csv, tsv or bundle)", from)
+			}
+			if err := validateOptions(options); err != nil {
+				return err
+			}
+			slog.Info(fmt.Sprintf("Importing %s into %s (%s)", inputDir, outputDir, from))
+			return processor.ImportTable(inputDir, outputDir, from, options)
+		}
 
 		options := buildProcessorOptions()
-		return processor.ProcessDirectoryWithOptions(inputDir, outputDir, true, options)
+		options.Format, _ = cmd.Flags().GetString("format")
+		options.Env = buildEnvOptions(cmd)
+		if inputDir, options.Match, err = inputMatch(cmd, inputDir); err != nil {
+			return err
+		}
+		options.Properties = buildPropertiesOptions(cmd)
+		options.UnflattenOpts.CoerceTypes, _ = cmd.Flags().GetBool("coerce-types")
+		if options.UnflattenOpts.MaxDepth, err = getMaxDepth(cmd); err != nil {
+			return err
+		}
+
+		if schemaPath, _ := cmd.Flags().GetString("schema"); schemaPath != "" {
+			data, err := os.ReadFile(schemaPath)
+			if err != nil {
+				return fmt.Errorf("failed to read schema: %v", err)
+			}
+			if options.Schema, err = fitter.ParseSchema(data); err != nil {
+				return err
+			}
+		}
+
+		if pipe {
+			if err := validateOptions(options); err != nil {
+				return err
+			}
+			return runPipe(cmd, inputDir, outputDir, true, options)
+		}
+
+		options.YAMLAnchors, _ = cmd.Flags().GetString("yaml-anchors")
+		options.Bulk = buildBulkOptions(cmd)
+		options.MetricsFile, _ = cmd.Flags().GetString("metrics-file")
+		options.Resume, _ = cmd.Flags().GetBool("resume")
+		if force, _ := cmd.Flags().GetBool("force"); !force {
+			options.SkipConverted = true
+		}
+		options.Progress = buildProgress(cmd)
+		if options.MaxMemory, err = getMaxMemory(); err != nil {
+			return err
+		}
+		if err := validateOptions(options); err != nil {
+			return err
+		}
+		if output == "json" {
+			return printSummary(cmd, inputDir, outputDir, true, options, "unflatten")
+		}
+
+		slog.Info(fmt.Sprintf("Unflattening JSON files from %s to %s", inputDir, outputDir))
+		slog.Info(fmt.Sprintf("Using separator: '%s', array format: '%s', workers: %s",
+			getSeparator(), getArrayFormat(), describeWorkers()))
+
+		if err := processor.ProcessDirectoryWithOptions(inputDir, outputDir, true, options); err != nil {
+			return err
+		}
+
+		return writeArtifact(cmd, outputDir, "unflatten", os.Stdout)
 	},
 }
 
 func init() {
+	unflattenCmd.Flags().String("schema", "", "JSON Schema used to type and validate the output")
+	unflattenCmd.Flags().String("from", "", "import a key/value table file instead of a directory: 'csv' or 'tsv', or split a 'bundle'")
+	unflattenCmd.Flags().Bool("coerce-types", false, "parse string values as booleans, numbers and null (prefix with \\ to keep a string)")
+	addFormatFlags(unflattenCmd)
+	addYAMLFlags(unflattenCmd)
+	addBulkFlags(unflattenCmd)
+	addArtifactFlags(unflattenCmd)
+	addResumeFlags(unflattenCmd)
+	addForceFlags(unflattenCmd)
+	addProgressFlags(unflattenCmd)
+	addMatchFlags(unflattenCmd)
+	addMetricsFlags(unflattenCmd)
+	addWorkerFlags(unflattenCmd)
+	addValueFlags(unflattenCmd)
+	addLevelFlags(unflattenCmd)
+	addPipeFlags(unflattenCmd)
+	unflattenCmd.Flags().String("output", "text", "result summary format: 'text' or 'json' (files, key counts and error codes)")
 	rootCmd.AddCommand(unflattenCmd)
 }
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/haiyon/fitobj/processor"
+	"github.com/spf13/cobra"
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify [artifact]",
+	Short: "Verify a processed artifact against its signed manifest",
+	Long: `Verify that every file in an artifact produced with --artifact matches the
+checksums in its manifest. When FITOBJ_SIGNING_KEY is set, the manifest
+signature is verified as well and unsigned artifacts are rejected; otherwise
+--strict fails the command since the signature was not checked. A failed
+verification exits with status 3.
+
+With --against, the documents of the artifact are then compared key by key with
+those of a directory, such as the output of a later run, and the command exits
+with status 3 when they differ. --ignore leaves out volatile keys from that
+comparison, with the patterns of 'fitobj diff --ignore'.
+
+Example:
+  FITOBJ_SIGNING_KEY=secret fitobj verify ./out.tar.gz
+  fitobj verify ./out.tar.gz --against ./out --ignore metadata.updatedAt --ignore '**.timestamp'`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key := []byte(os.Getenv("FITOBJ_SIGNING_KEY"))
+		against, _ := cmd.Flags().GetString("against")
+
+		options := processor.DiffOptions{Options: buildProcessorOptions()}
+		options.Ignore, _ = cmd.Flags().GetStringSlice("ignore")
+		if len(options.Ignore) > 0 && against == "" {
+			return usageErrorf("--ignore requires --against")
+		}
+
+		manifest, err := processor.VerifyArtifact(args[0], key)
+		if err != nil {
+			return checkFailedf("verification failed: %v", err)
+		}
+
+		fmt.Printf("✅ %s: %d files verified (%s by %s %s at %s)\n",
+			args[0], len(manifest.Files), manifest.Mode, manifest.Tool, manifest.Version,
+			manifest.CreatedAt.Format("2006-01-02 15:04:05"))
+		if len(key) == 0 {
+			fmt.Println("⚠️  Signature not checked: FITOBJ_SIGNING_KEY is not set")
+			if isStrict() {
+				cmd.SilenceUsage = true
+				return checkFailedf("signature not checked in strict mode")
+			}
+		}
+
+		if against == "" {
+			return nil
+		}
+		diff, err := processor.CompareArtifact(args[0], against, options)
+		if err != nil {
+			return fmt.Errorf("comparison failed: %v", err)
+		}
+		if diff.Empty() {
+			fmt.Printf("✅ %s matches the artifact\n", against)
+			return nil
+		}
+		printDirDiff(args[0], against, diff, false)
+		cmd.SilenceUsage = true
+		return checkFailedf("%s does not match the artifact", against)
+	},
+}
+
+func init() {
+	verifyCmd.Flags().String("against", "", "compare the documents of the artifact with those of this directory")
+	verifyCmd.Flags().StringSlice("ignore", nil, "leave out keys matching these patterns from the comparison (globs or 're:<regexp>')")
+
+	rootCmd.AddCommand(verifyCmd)
+}
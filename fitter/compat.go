@@ -0,0 +1,153 @@
+package fitter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Database targets for dotted-path compatibility mode
+const (
+	TargetMongoDB   = "mongodb"
+	TargetFirestore = "firestore"
+)
+
+// Field name limits of the supported targets
+const (
+	mongoMaxDepth          = 100
+	firestoreMaxDepth      = 20
+	firestoreMaxFieldBytes = 1500
+)
+
+// firestoreSimpleField matches field names that need no backtick quoting in a field path
+var firestoreSimpleField = regexp.MustCompile(`^[A-Za-z_][A-Za-z_0-9]*$`)
+
+// firestoreReserved matches reserved Firestore field names
+var firestoreReserved = regexp.MustCompile(`^__.*__$`)
+
+// FieldIssue describes a key that the target database cannot address
+type FieldIssue struct {
+	Path    string `json:"path"`
+	Problem string `json:"problem"`
+}
+
+// ValidateTarget checks that a compatibility target is known
+func ValidateTarget(target string) error {
+	switch target {
+	case "", TargetMongoDB, TargetFirestore:
+		return nil
+	}
+	return fmt.Errorf("unknown target '%s' (expected mongodb or firestore)", target)
+}
+
+// TargetFlattenOptions adjusts flatten options to the path syntax of a target:
+// dot separators, and for Firestore arrays kept as whole values since array
+// elements cannot be addressed by field path
+func TargetFlattenOptions(options FlattenOptions, target string) FlattenOptions {
+	options.Separator = "."
+	options.ArrayFormatting = "index"
+	if target == TargetFirestore {
+		options.IncludeArrayIndices = false
+	}
+	return options
+}
+
+// ValidateFieldNames checks the map keys of a nested document against the field
+// name rules of a target database, returning issues sorted by path
+func ValidateFieldNames(obj map[string]any, target string) []FieldIssue {
+	var issues []FieldIssue
+	validateFields(obj, "", 1, target, &issues)
+	sort.Slice(issues, func(i, j int) bool { return issues[i].Path < issues[j].Path })
+	return issues
+}
+
+func validateFields(value any, path string, depth int, target string, issues *[]FieldIssue) {
+	add := func(p, problem string) {
+		*issues = append(*issues, FieldIssue{Path: p, Problem: problem})
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			child := joinPath(path, key, ".")
+
+			switch {
+			case key == "":
+				add(child, "empty field name")
+			case strings.Contains(key, "."):
+				add(child, "field name contains '.' and would be read as a nested path")
+			}
+
+			switch target {
+			case TargetMongoDB:
+				if strings.HasPrefix(key, "$") {
+					add(child, "field name starts with '$'")
+				}
+				if strings.ContainsRune(key, 0) {
+					add(child, "field name contains a null character")
+				}
+				if depth > mongoMaxDepth {
+					add(child, fmt.Sprintf("nesting deeper than %d levels", mongoMaxDepth))
+					continue
+				}
+			case TargetFirestore:
+				if firestoreReserved.MatchString(key) {
+					add(child, "field name matches the reserved pattern __.*__")
+				}
+				if len(key) > firestoreMaxFieldBytes {
+					add(child, fmt.Sprintf("field name longer than %d bytes", firestoreMaxFieldBytes))
+				}
+				if depth > firestoreMaxDepth {
+					add(child, fmt.Sprintf("nesting deeper than %d levels", firestoreMaxDepth))
+					continue
+				}
+			}
+
+			validateFields(item, child, depth+1, target, issues)
+		}
+	case []any:
+		for i, item := range v {
+			validateFields(item, joinPath(path, strconv.Itoa(i), "."), depth+1, target, issues)
+		}
+	}
+}
+
+// UpdateDocument wraps flattened dotted-path values in an update document for a
+// target: {"$set": {...}} for MongoDB, and the field values with an update mask of
+// quoted field paths for Firestore
+func UpdateDocument(flat map[string]any, target string) map[string]any {
+	switch target {
+	case TargetFirestore:
+		paths := make([]string, 0, len(flat))
+		for key := range flat {
+			paths = append(paths, FirestoreFieldPath(key))
+		}
+		sort.Strings(paths)
+
+		fields := make([]any, len(paths))
+		for i, path := range paths {
+			fields[i] = path
+		}
+		return map[string]any{
+			"fields":     flat,
+			"updateMask": map[string]any{"fieldPaths": fields},
+		}
+	default:
+		return map[string]any{"$set": flat}
+	}
+}
+
+// FirestoreFieldPath quotes the segments of a dotted path that are not simple
+// identifiers with backticks, as Firestore field paths require
+func FirestoreFieldPath(key string) string {
+	segments := strings.Split(key, ".")
+	for i, segment := range segments {
+		if !firestoreSimpleField.MatchString(segment) {
+			segment = strings.ReplaceAll(segment, `\`, `\\`)
+			segments[i] = "`" + strings.ReplaceAll(segment, "`", "\\`") + "`"
+		}
+	}
+	return strings.Join(segments, ".")
+}
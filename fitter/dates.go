@@ -0,0 +1,195 @@
+package fitter
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Date normalization targets, and the layouts of timestamps given as numbers
+const (
+	DatesRFC3339     = "rfc3339"  // text such as 2024-05-01T12:30:00Z, with fractional seconds when set
+	DatesEpoch       = "epoch"    // Unix seconds
+	DatesEpochMillis = "epoch-ms" // Unix milliseconds
+)
+
+// DefaultDateLayouts are the layouts of the text values recognized as timestamps
+// when none are configured. Layouts without a zone are read as UTC.
+var DefaultDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC850,
+	time.ANSIC,
+}
+
+// DateOptions configures NormalizeDates
+type DateOptions struct {
+	To              string   // target representation: "rfc3339", "epoch" or "epoch-ms"
+	Layouts         []string // Go time layouts of text values, or "epoch" and "epoch-ms" for numbers (default: DefaultDateLayouts)
+	KeyGlobs        []string // only normalize values whose flattened key matches these patterns (all values when empty)
+	UTC             bool     // write RFC 3339 text in UTC instead of the offset of the value
+	Separator       string   // joins key segments when matching KeyGlobs
+	ArrayFormatting string   // format of array indices in keys: "index" or "bracket"
+}
+
+// ValidateDateOptions checks that a date normalization is usable
+func ValidateDateOptions(options DateOptions) error {
+	switch options.To {
+	case DatesRFC3339, DatesEpoch, DatesEpochMillis:
+	default:
+		return fmt.Errorf("unknown date format '%s' (expected rfc3339, epoch or epoch-ms)", options.To)
+	}
+	for _, layout := range options.Layouts {
+		if strings.TrimSpace(layout) == "" {
+			return fmt.Errorf("date layouts must not be empty")
+		}
+	}
+	return ValidateKeyPatterns(options.KeyGlobs, nil)
+}
+
+// NormalizeDates rewrites the timestamp values of a document, in place, below
+// every map and array: text matching one of the layouts, and integral numbers
+// when a layout is "epoch" or "epoch-ms". Values already in the target
+// representation are left alone. The changes are returned sorted by flattened key.
+func NormalizeDates(data map[string]any, options DateOptions) ([]DiffChange, error) {
+	if err := ValidateDateOptions(options); err != nil {
+		return nil, err
+	}
+	if len(options.Layouts) == 0 {
+		options.Layouts = DefaultDateLayouts
+	}
+
+	n := dateNormalizer{
+		options: options,
+		keys:    newKeyMatcher(options.KeyGlobs, options.Separator),
+		changes: []DiffChange{},
+	}
+	n.object(data, "")
+
+	sort.Slice(n.changes, func(i, j int) bool { return n.changes[i].Key < n.changes[j].Key })
+	return n.changes, nil
+}
+
+// dateNormalizer walks a document, building flattened keys like FlattenMapWithOptions
+type dateNormalizer struct {
+	options DateOptions
+	keys    *keyMatcher
+	changes []DiffChange
+}
+
+func (n *dateNormalizer) object(obj map[string]any, prefix string) {
+	for key, value := range obj {
+		fullKey := key
+		if prefix != "" {
+			fullKey = prefix + n.options.Separator + key
+		}
+		obj[key] = n.value(value, fullKey)
+	}
+}
+
+func (n *dateNormalizer) array(arr []any, prefix string) {
+	for i, item := range arr {
+		var indexedKey string
+		if n.options.ArrayFormatting == "bracket" {
+			indexedKey = fmt.Sprintf("%s[%d]", prefix, i)
+		} else {
+			indexedKey = prefix + n.options.Separator + strconv.Itoa(i)
+		}
+		arr[i] = n.value(item, indexedKey)
+	}
+}
+
+// value returns a value in the target representation when it is a timestamp,
+// recording the change
+func (n *dateNormalizer) value(value any, key string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		n.object(v, key)
+		return value
+	case []any:
+		n.array(v, key)
+		return value
+	}
+
+	if len(n.options.KeyGlobs) > 0 && !n.keys.match(key) {
+		return value
+	}
+	t, ok := n.parse(value)
+	if !ok {
+		return value
+	}
+	normalized := n.format(t)
+	if fmt.Sprint(normalized) == fmt.Sprint(value) {
+		return value
+	}
+	n.changes = append(n.changes, DiffChange{Key: key, Old: value, New: normalized})
+	return normalized
+}
+
+// parse reads a value as a timestamp with the first matching layout
+func (n *dateNormalizer) parse(value any) (time.Time, bool) {
+	text, isText := value.(string)
+	number, isNumber := integralNumber(value)
+	for _, layout := range n.options.Layouts {
+		switch layout {
+		case DatesEpoch:
+			if isNumber {
+				return time.Unix(number, 0).UTC(), true
+			}
+		case DatesEpochMillis:
+			if isNumber {
+				return time.UnixMilli(number).UTC(), true
+			}
+		default:
+			if !isText {
+				continue
+			}
+			if t, err := time.Parse(layout, strings.TrimSpace(text)); err == nil {
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// format writes a timestamp in the target representation
+func (n *dateNormalizer) format(t time.Time) any {
+	switch n.options.To {
+	case DatesEpoch:
+		return t.Unix()
+	case DatesEpochMillis:
+		return t.UnixMilli()
+	}
+	if n.options.UTC {
+		t = t.UTC()
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// integralNumber returns the value of a whole number
+func integralNumber(value any) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v), true
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,32 @@
+package fitter
+
+// NeedsFlatten reports whether flattening would change the shape of a document:
+// one of its values is a non-empty object or array. Unlike IsFlat, documents
+// without joined keys, such as {"name": "x"}, need no flattening either.
+func NeedsFlatten(data map[string]any) bool {
+	for _, value := range data {
+		switch v := value.(type) {
+		case map[string]any:
+			if len(v) > 0 {
+				return true
+			}
+		case []any:
+			if len(v) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NeedsUnflatten reports whether unflattening would change the shape of a
+// document: one of its keys splits into several segments with the options,
+// escaped separators and brackets staying inside their segment
+func NeedsUnflatten(data map[string]any, options UnflattenOptions) bool {
+	for key := range data {
+		if len(SplitKey(key, options)) > 1 {
+			return true
+		}
+	}
+	return false
+}
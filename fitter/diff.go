@@ -0,0 +1,125 @@
+package fitter
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+// DiffEntry is a key present in only one of the compared documents
+type DiffEntry struct {
+	Key   string `json:"key"`
+	Value any    `json:"value"`
+}
+
+// DiffChange is a key whose value differs between the compared documents
+type DiffChange struct {
+	Key string `json:"key"`
+	Old any    `json:"old"`
+	New any    `json:"new"`
+}
+
+// FlatDiff lists the differences between two flattened documents, sorted by key
+type FlatDiff struct {
+	Added   []DiffEntry  `json:"added"`
+	Removed []DiffEntry  `json:"removed"`
+	Changed []DiffChange `json:"changed"`
+}
+
+// Empty reports whether the documents are equal
+func (d FlatDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DiffFlat compares two flattened maps and returns the keys added to, removed from
+// and changed in the second one
+func DiffFlat(a, b map[string]any) FlatDiff {
+	diff := FlatDiff{
+		Added:   []DiffEntry{},
+		Removed: []DiffEntry{},
+		Changed: []DiffChange{},
+	}
+
+	for key, newValue := range b {
+		oldValue, exists := a[key]
+		switch {
+		case !exists:
+			diff.Added = append(diff.Added, DiffEntry{Key: key, Value: newValue})
+		case !EqualValues(oldValue, newValue):
+			diff.Changed = append(diff.Changed, DiffChange{Key: key, Old: oldValue, New: newValue})
+		}
+	}
+
+	for key, oldValue := range a {
+		if _, exists := b[key]; !exists {
+			diff.Removed = append(diff.Removed, DiffEntry{Key: key, Value: oldValue})
+		}
+	}
+
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].Key < diff.Added[j].Key })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].Key < diff.Removed[j].Key })
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].Key < diff.Changed[j].Key })
+	return diff
+}
+
+// DiffFlatKeys compares the structure of two flattened maps, ignoring values: keys
+// added or removed, and keys whose value type changed. Values are replaced with
+// their type names, as returned by ValueType.
+func DiffFlatKeys(a, b map[string]any) FlatDiff {
+	types := func(m map[string]any) map[string]any {
+		result := make(map[string]any, len(m))
+		for key, value := range m {
+			result[key] = ValueType(value)
+		}
+		return result
+	}
+	return DiffFlat(types(a), types(b))
+}
+
+// ValueType returns the JSON type name of a value: "null", "boolean", "number",
+// "string", "array" or "object"
+func ValueType(v any) string {
+	if _, ok := numberValue(v); ok {
+		return "number"
+	}
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return reflect.TypeOf(v).Kind().String()
+}
+
+// EqualValues compares two values, treating numbers of different types as equal
+// when they hold the same value (1 decoded from YAML and 1.0 from JSON)
+func EqualValues(a, b any) bool {
+	x, xok := numberValue(a)
+	y, yok := numberValue(b)
+	if xok && yok {
+		return x == y
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// numberValue returns a value of any numeric type as a float64
+func numberValue(v any) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
@@ -0,0 +1,86 @@
+package fitter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// joinEscaped escapes key segments in a style and joins them with the separator
+func joinEscaped(segments []string, separator, escape, style string) string {
+	escaped := make([]string, len(segments))
+	for i, segment := range segments {
+		escaped[i] = escapeKey(segment, separator, escape, style)
+	}
+	return strings.Join(escaped, separator)
+}
+
+func TestEscapeKeyRoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		segments  []string
+		separator string
+		escape    string // EscapePrefix escape
+		prefixed  string // key written with EscapePrefix
+		quote     string // EscapeQuote quote
+		quoted    string // key written with EscapeQuote
+	}{
+		{"plain", []string{"a", "b"}, ".", `\`, `a.b`, `"`, `a.b`},
+		{"separator", []string{"a.b", "c"}, ".", `\`, `a\.b.c`, `"`, `"a.b".c`},
+		{"numeric", []string{"list", "10", "x"}, ".", `\`, `list.\10.x`, `"`, `list."10".x`},
+		{"negative number", []string{"-1"}, ".", `\`, `\-1`, `"`, `"-1"`},
+		{"escape itself", []string{`a\b`, `c\`}, ".", `\`, `a\\b.c\\`, `"`, `a\b.c\`},
+		{"escape before separator", []string{`a\.b`}, ".", `\`, `a\\\.b`, `"`, `"a\.b"`},
+		{"doubled quotes", []string{`say "hi"`, `"`}, ".", `"`, `say ""hi"".""`, `"`, `"say ""hi""".""""`},
+		{"quote inside", []string{`it's`}, ".", `\`, `it's`, `'`, `'it''s'`},
+		{"bracket", []string{"x[0]", "y"}, ".", `\`, `x\[0].y`, `"`, `"x[0]".y`},
+		{"bracket at start", []string{"[1]"}, ".", `'`, `'[1]`, `'`, `'[1]'`},
+		{"multi-character", []string{"a/b", "%%", "3"}, "/", "%%", `a%%/b/%%%%/%%3`, "%%", `%%a/b%%/%%%%%%%%/%%3%%`},
+		{"empty segment", []string{"", "a"}, ".", `\`, `.a`, `"`, `.a`},
+	}
+
+	for _, test := range tests {
+		for _, style := range []string{EscapePrefix, EscapeQuote} {
+			escape, expected := test.escape, test.prefixed
+			if style == EscapeQuote {
+				escape, expected = test.quote, test.quoted
+			}
+			key := joinEscaped(test.segments, test.separator, escape, style)
+			if key != expected {
+				t.Errorf("%s, %s: expected key %q, got %q", test.name, style, expected, key)
+			}
+
+			options := DefaultUnflattenOptions()
+			options.Separator, options.Escape, options.EscapeStyle = test.separator, escape, style
+			if got := SplitKey(key, options); !reflect.DeepEqual(got, test.segments) {
+				t.Errorf("%s, %s: expected %q split into %q, got %q", test.name, style, key, test.segments, got)
+			}
+		}
+	}
+}
+
+func TestEscapedMapRoundTrip(t *testing.T) {
+	data := map[string]any{
+		"a.b":      map[string]any{"0": "numeric key", "1": "another"},
+		"list":     []any{map[string]any{"x[0]": 1}},
+		`back\`:    map[string]any{`"q"`: true},
+		"[1]":      "bracket",
+		"plain":    map[string]any{"nested": "value"},
+		"10":       []any{"kept", "as", "array"},
+		`say "hi"`: nil,
+	}
+
+	for _, escape := range []string{`\`, `"`, "~"} {
+		for _, style := range []string{EscapePrefix, EscapeQuote} {
+			flattenOpts := DefaultFlattenOptions()
+			flattenOpts.Escape, flattenOpts.EscapeStyle = escape, style
+			flat := FlattenMapWithOptions(data, "", flattenOpts)
+
+			unflattenOpts := DefaultUnflattenOptions()
+			unflattenOpts.Escape, unflattenOpts.EscapeStyle = escape, style
+			if got := UnflattenMapWithOptions(flat, unflattenOpts); !reflect.DeepEqual(got, data) {
+				t.Errorf("escape %q, %s: expected %v back, got %v (flattened %v)", escape, style, data, got, flat)
+			}
+		}
+	}
+}
@@ -3,15 +3,133 @@ package fitter
 import (
 	"fmt"
 	"strconv"
+	"strings"
+
+	"github.com/haiyon/fitobj/utils"
 )
 
 // FlattenOptions configures the flattening process
 type FlattenOptions struct {
-	Separator           string // separator for nested keys (default: ".")
-	MaxDepth            int    // max recursion depth (-1 = no limit)
-	IncludeArrayIndices bool   // whether to include array indices
-	ArrayFormatting     string // "index" or "bracket"
-	BufferSize          int    // initial capacity for result maps
+	Separator           string   // separator for nested keys (default: ".")
+	MaxDepth            int      // max recursion depth: keys join at most MaxDepth+1 segments, array indices included (0 or -1 = no limit)
+	IncludeArrayIndices bool     // whether to include array indices
+	ArrayFormatting     string   // "index" or "bracket"
+	BufferSize          int      // initial capacity for result maps
+	IncludeKeys         []string // keep only keys matching these patterns (globs, or regexps prefixed with "re:")
+	ExcludeKeys         []string // drop keys matching these patterns
+	Nulls               string   // nil values: EmptyKeep (default), EmptyDrop or EmptyPlaceholder
+	EmptyObjects        string   // empty maps: EmptyKeep (default), EmptyDrop or EmptyPlaceholder
+	EmptyArrays         string   // empty arrays: EmptyKeep (default), EmptyDrop or EmptyPlaceholder
+	Placeholder         string   // value emitted for EmptyPlaceholder (default: "")
+	Escape              string   // escapes separators, '[' and itself inside keys, as EscapeKey (default: none)
+	EscapeStyle         string   // how Escape is applied: EscapePrefix (default) or EscapeQuote, as QuoteKey
+}
+
+// Modes of emitting nil values, empty maps and empty arrays when flattening
+const (
+	EmptyKeep        = "keep"        // emit the value as-is
+	EmptyDrop        = "drop"        // omit the key
+	EmptyPlaceholder = "placeholder" // emit the Placeholder string instead
+)
+
+// ValidateEmptyModes checks the null and empty value modes of flatten options
+func ValidateEmptyModes(options FlattenOptions) error {
+	for _, mode := range []string{options.Nulls, options.EmptyObjects, options.EmptyArrays} {
+		switch mode {
+		case "", EmptyKeep, EmptyDrop, EmptyPlaceholder:
+		default:
+			return fmt.Errorf("unknown empty value mode '%s' (expected keep, drop or placeholder)", mode)
+		}
+	}
+	return nil
+}
+
+// Styles of escaping keys that hold the separator
+const (
+	EscapePrefix = "prefix" // prefix separators, '[' and the escape with the escape string: a\.b
+	EscapeQuote  = "quote"  // wrap the whole segment in the escape string, doubling it inside: "a.b"
+)
+
+// ValidateEscape checks that an escape string can be told apart from the
+// separator and from bracket array notation, and that its style is known
+func ValidateEscape(escape, style, separator string) error {
+	switch style {
+	case "", EscapePrefix:
+	case EscapeQuote:
+		if escape == "" {
+			return fmt.Errorf("escape style 'quote' requires an escape string, such as '\"'")
+		}
+	default:
+		return fmt.Errorf("unknown escape style '%s' (expected prefix or quote)", style)
+	}
+	if escape == "" {
+		return nil
+	}
+	if strings.Contains(escape, separator) || strings.Contains(separator, escape) {
+		return fmt.Errorf("escape '%s' must not overlap the separator '%s'", escape, separator)
+	}
+	if strings.ContainsAny(escape, "[]") {
+		return fmt.Errorf("escape '%s' must not contain brackets, which are reserved for array notation", escape)
+	}
+	if strings.TrimSpace(escape) == "" {
+		return fmt.Errorf("escape must not be whitespace only")
+	}
+	return nil
+}
+
+// EscapeKey escapes a key segment so it survives joining with the separator: the
+// escape string, the separator and '[' are prefixed with the escape string, and
+// so are numeric keys, which would otherwise be taken as array indices. Keys are
+// returned unchanged when escape is empty.
+func EscapeKey(key, separator, escape string) string {
+	if escape == "" {
+		return key
+	}
+	if _, err := strconv.Atoi(key); err == nil {
+		return escape + key
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(key); {
+		switch {
+		case strings.HasPrefix(key[i:], escape):
+			b.WriteString(escape + escape)
+			i += len(escape)
+		case strings.HasPrefix(key[i:], separator):
+			b.WriteString(escape + separator)
+			i += len(separator)
+		case key[i] == '[':
+			b.WriteString(escape + "[")
+			i++
+		default:
+			b.WriteByte(key[i])
+			i++
+		}
+	}
+	return b.String()
+}
+
+// QuoteKey quotes a key segment so it survives joining with the separator: keys
+// holding the separator, '[' or the quote, and numeric keys, are wrapped in the
+// quote string, with quotes inside doubled. Other keys, and all keys when quote
+// is empty, are returned unchanged.
+func QuoteKey(key, separator, quote string) string {
+	if quote == "" {
+		return key
+	}
+	_, err := strconv.Atoi(key)
+	if err != nil && !strings.Contains(key, separator) && !strings.Contains(key, "[") && !strings.Contains(key, quote) {
+		return key
+	}
+	return quote + strings.ReplaceAll(key, quote, quote+quote) + quote
+}
+
+// escapeKey escapes a key segment in the given style
+func escapeKey(key, separator, escape, style string) string {
+	if style == EscapeQuote {
+		return QuoteKey(key, separator, escape)
+	}
+	return EscapeKey(key, separator, escape)
 }
 
 // DefaultFlattenOptions returns the default options for flattening
@@ -32,58 +150,110 @@ func FlattenMap(obj map[string]any, prefix string) map[string]any {
 
 // FlattenMapWithOptions converts a nested map into a flattened structure with custom options
 func FlattenMapWithOptions(obj map[string]any, prefix string, options FlattenOptions) map[string]any {
-	result := make(map[string]any, options.BufferSize)
+	result := &flatResult{values: make(map[string]any, options.BufferSize)}
 	flatten(obj, prefix, result, options, 0)
-	return result
+	filterKeys(result.values, options.IncludeKeys, options.ExcludeKeys, options.Separator)
+	return result.values
+}
+
+// flatResult collects flattened keys, in the order they are emitted when ordered
+type flatResult struct {
+	values  map[string]any
+	keys    []string
+	ordered bool
+}
+
+func (r *flatResult) set(key string, value any) {
+	if _, ok := r.values[key]; !ok && r.ordered {
+		r.keys = append(r.keys, key)
+	}
+	r.values[key] = value
 }
 
-// flatten recursively flattens a nested map
-func flatten(obj map[string]any, prefix string, result map[string]any, options FlattenOptions, depth int) {
+// flatten recursively flattens a nested map, either a plain map or a
+// *utils.OrderedMap walked in order
+func flatten(obj any, prefix string, result *flatResult, options FlattenOptions, depth int) {
 	// Check depth limit
-	if options.MaxDepth >= 0 && depth > options.MaxDepth {
+	if options.MaxDepth > 0 && depth > options.MaxDepth {
 		if prefix != "" {
-			result[prefix] = obj
+			result.set(prefix, obj)
 		} else {
-			for k, v := range obj {
-				result[k] = v
-			}
+			eachEntry(obj, func(k string, v any) { result.set(k, v) })
 		}
 		return
 	}
 
-	for key, value := range obj {
-		var fullKey string
-		if prefix == "" {
-			fullKey = key
+	eachEntry(obj, func(key string, value any) {
+		fullKey := escapeKey(key, options.Separator, options.Escape, options.EscapeStyle)
+		if prefix != "" {
+			fullKey = prefix + options.Separator + fullKey
+		}
+		flattenValue(value, fullKey, result, options, depth)
+	})
+}
+
+// eachEntry calls fn for every entry of a plain or ordered map
+func eachEntry(obj any, fn func(key string, value any)) {
+	switch m := obj.(type) {
+	case map[string]any:
+		for key, value := range m {
+			fn(key, value)
+		}
+	case *utils.OrderedMap:
+		for _, key := range m.Keys() {
+			value, _ := m.Get(key)
+			fn(key, value)
+		}
+	}
+}
+
+// flattenValue flattens the value of an object entry or array element found at
+// depth, under key. Objects and arrays below it are one level deeper: an array
+// index counts as a level like an object key, so MaxDepth limits the segments
+// of every key, as UnflattenOptions.MaxDepth does when splitting them. Earlier
+// releases did not count array indices, and kept the values of top-level keys
+// whole with a MaxDepth of 0.
+func flattenValue(value any, key string, result *flatResult, options FlattenOptions, depth int) {
+	switch typedValue := value.(type) {
+	case map[string]any:
+		if len(typedValue) == 0 {
+			emitEmpty(result, key, typedValue, options.EmptyObjects, options)
 		} else {
-			fullKey = prefix + options.Separator + key
+			flatten(typedValue, key, result, options, depth+1)
 		}
 
-		switch typedValue := value.(type) {
-		case map[string]any:
-			if len(typedValue) == 0 {
-				result[fullKey] = typedValue
-			} else {
-				flatten(typedValue, fullKey, result, options, depth+1)
-			}
-
-		case []any:
-			if len(typedValue) == 0 {
-				result[fullKey] = typedValue
-			} else if options.IncludeArrayIndices {
-				flattenArray(typedValue, fullKey, result, options, depth)
-			} else {
-				result[fullKey] = typedValue
-			}
+	case *utils.OrderedMap:
+		if typedValue.Len() == 0 {
+			emitEmpty(result, key, typedValue, options.EmptyObjects, options)
+		} else {
+			flatten(typedValue, key, result, options, depth+1)
+		}
 
-		default:
-			result[fullKey] = value
+	case []any:
+		if len(typedValue) == 0 {
+			emitEmpty(result, key, typedValue, options.EmptyArrays, options)
+		} else if options.IncludeArrayIndices {
+			flattenArray(typedValue, key, result, options, depth+1)
+		} else {
+			result.set(key, typedValue)
 		}
+
+	case nil:
+		emitEmpty(result, key, nil, options.Nulls, options)
+
+	default:
+		result.set(key, value)
 	}
 }
 
-// flattenArray handles array flattening with proper recursion
-func flattenArray(arr []any, prefix string, result map[string]any, options FlattenOptions, depth int) {
+// flattenArray handles array flattening with proper recursion. Arrays below the
+// depth limit are kept whole.
+func flattenArray(arr []any, prefix string, result *flatResult, options FlattenOptions, depth int) {
+	if options.MaxDepth > 0 && depth > options.MaxDepth {
+		result.set(prefix, arr)
+		return
+	}
+
 	for i, item := range arr {
 		var indexedKey string
 		if options.ArrayFormatting == "bracket" {
@@ -91,22 +261,17 @@ func flattenArray(arr []any, prefix string, result map[string]any, options Flatt
 		} else {
 			indexedKey = prefix + options.Separator + strconv.Itoa(i)
 		}
+		flattenValue(item, indexedKey, result, options, depth)
+	}
+}
 
-		switch itemTyped := item.(type) {
-		case map[string]any:
-			if len(itemTyped) == 0 {
-				result[indexedKey] = itemTyped
-			} else {
-				flatten(itemTyped, indexedKey, result, options, depth+1)
-			}
-		case []any:
-			if len(itemTyped) == 0 {
-				result[indexedKey] = itemTyped
-			} else {
-				flattenArray(itemTyped, indexedKey, result, options, depth+1)
-			}
-		default:
-			result[indexedKey] = item
-		}
+// emitEmpty emits a nil value, empty map or empty array according to its mode
+func emitEmpty(result *flatResult, key string, value any, mode string, options FlattenOptions) {
+	switch mode {
+	case EmptyDrop:
+	case EmptyPlaceholder:
+		result.set(key, options.Placeholder)
+	default:
+		result.set(key, value)
 	}
 }
@@ -0,0 +1,79 @@
+package fitter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFlattenMaxDepthCountsArrayIndices(t *testing.T) {
+	data := map[string]any{
+		"a":    map[string]any{"b": map[string]any{"c": 1}},
+		"list": []any{map[string]any{"x": 1}, []any{1, 2}},
+	}
+
+	tests := map[int]map[string]any{
+		-1: {"a.b.c": 1, "list.0.x": 1, "list.1.0": 1, "list.1.1": 2},
+		0:  {"a.b.c": 1, "list.0.x": 1, "list.1.0": 1, "list.1.1": 2},
+		1: {
+			"a.b":    map[string]any{"c": 1},
+			"list.0": map[string]any{"x": 1},
+			"list.1": []any{1, 2},
+		},
+		2: {"a.b.c": 1, "list.0.x": 1, "list.1.0": 1, "list.1.1": 2},
+	}
+	for maxDepth, expected := range tests {
+		options := DefaultFlattenOptions()
+		options.MaxDepth = maxDepth
+		if got := FlattenMapWithOptions(data, "", options); !reflect.DeepEqual(got, expected) {
+			t.Errorf("MaxDepth %d: expected %v, got %v", maxDepth, expected, got)
+		}
+	}
+
+	// Zero-value options flatten fully, as they unflatten fully
+	got := FlattenMapWithOptions(data, "", FlattenOptions{Separator: ".", IncludeArrayIndices: true})
+	if !reflect.DeepEqual(got, tests[-1]) {
+		t.Errorf("Expected zero-value options to flatten fully, got %v", got)
+	}
+}
+
+func TestUnflattenMaxDepth(t *testing.T) {
+	flat := map[string]any{"a.b.c.d": 1, "x": 2}
+
+	tests := map[int]map[string]any{
+		-1: {"a": map[string]any{"b": map[string]any{"c": map[string]any{"d": 1}}}, "x": 2},
+		0:  {"a": map[string]any{"b": map[string]any{"c": map[string]any{"d": 1}}}, "x": 2},
+		1:  {"a": map[string]any{"b.c.d": 1}, "x": 2},
+		2:  {"a": map[string]any{"b": map[string]any{"c.d": 1}}, "x": 2},
+	}
+	for maxDepth, expected := range tests {
+		options := DefaultUnflattenOptions()
+		options.MaxDepth = maxDepth
+		if got := UnflattenMapWithOptions(flat, options); !reflect.DeepEqual(got, expected) {
+			t.Errorf("MaxDepth %d: expected %v, got %v", maxDepth, expected, got)
+		}
+	}
+
+	// Zero-value options, as built by callers setting only a separator, split
+	// every key
+	got := UnflattenMapWithOptions(flat, UnflattenOptions{Separator: "."})
+	if !reflect.DeepEqual(got, tests[-1]) {
+		t.Errorf("Expected zero-value options to unflatten fully, got %v", got)
+	}
+}
+
+func TestMaxDepthRoundTrip(t *testing.T) {
+	data := map[string]any{"a": map[string]any{"b": []any{map[string]any{"c": 1.0}}}}
+
+	flattenOpts := DefaultFlattenOptions()
+	flattenOpts.MaxDepth = 1
+	flat := FlattenMapWithOptions(data, "", flattenOpts)
+	if !reflect.DeepEqual(flat, map[string]any{"a.b": []any{map[string]any{"c": 1.0}}}) {
+		t.Fatalf("Unexpected partial flattening: %v", flat)
+	}
+
+	unflattenOpts := DefaultUnflattenOptions()
+	unflattenOpts.MaxDepth = 1
+	if got := UnflattenMapWithOptions(flat, unflattenOpts); !reflect.DeepEqual(got, data) {
+		t.Errorf("Expected %v back, got %v", data, got)
+	}
+}
@@ -0,0 +1,361 @@
+package fitter
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Helm flags used to pass a value on the command line
+const (
+	HelmSet       = "--set"        // typed: true/false/null and integers are converted
+	HelmSetString = "--set-string" // always a string
+	HelmSetJSON   = "--set-json"   // JSON value, used for floats and empty collections
+)
+
+// HelmValue is a single key=value assignment for a Helm value flag
+type HelmValue struct {
+	Flag  string // --set, --set-string or --set-json
+	Key   string // escaped key path, e.g. ingress.annotations.kubernetes\.io/ingress\.class
+	Value string // escaped value
+}
+
+// String renders the assignment as key=value
+func (v HelmValue) String() string {
+	return v.Key + "=" + v.Value
+}
+
+// FlattenHelmValues converts nested values into Helm --set assignments sorted by key.
+// Dots, brackets, commas, equal signs and backslashes in keys and commas and
+// backslashes in values are escaped per Helm rules. Strings that --set would
+// convert to another type are emitted as --set-string.
+func FlattenHelmValues(obj map[string]any) []HelmValue {
+	var values []HelmValue
+	flattenHelm(obj, "", &values)
+	sort.Slice(values, func(i, j int) bool { return values[i].Key < values[j].Key })
+	return values
+}
+
+func flattenHelm(value any, key string, values *[]HelmValue) {
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 && key != "" {
+			*values = append(*values, HelmValue{Flag: HelmSetJSON, Key: key, Value: "{}"})
+			return
+		}
+		for name, item := range v {
+			child := escapeHelm(name, `\.[]=,`)
+			if key != "" {
+				child = key + "." + child
+			}
+			flattenHelm(item, child, values)
+		}
+	case []any:
+		if len(v) == 0 {
+			*values = append(*values, HelmValue{Flag: HelmSetJSON, Key: key, Value: "[]"})
+			return
+		}
+		for i, item := range v {
+			flattenHelm(item, key+"["+strconv.Itoa(i)+"]", values)
+		}
+	case nil:
+		*values = append(*values, HelmValue{Flag: HelmSet, Key: key, Value: "null"})
+	case bool:
+		*values = append(*values, HelmValue{Flag: HelmSet, Key: key, Value: strconv.FormatBool(v)})
+	case int:
+		*values = append(*values, HelmValue{Flag: HelmSet, Key: key, Value: strconv.Itoa(v)})
+	case int64:
+		*values = append(*values, HelmValue{Flag: HelmSet, Key: key, Value: strconv.FormatInt(v, 10)})
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			*values = append(*values, HelmValue{Flag: HelmSet, Key: key, Value: strconv.FormatInt(int64(v), 10)})
+			return
+		}
+		*values = append(*values, HelmValue{Flag: HelmSetJSON, Key: key, Value: strconv.FormatFloat(v, 'f', -1, 64)})
+	case string:
+		flag := HelmSet
+		if typed := helmTypedValue(v); typed != v || strings.HasPrefix(v, "{") {
+			flag = HelmSetString
+		}
+		*values = append(*values, HelmValue{Flag: flag, Key: key, Value: escapeHelm(v, `\,`)})
+	default:
+		encoded, _ := json.Marshal(v)
+		*values = append(*values, HelmValue{Flag: HelmSetJSON, Key: key, Value: string(encoded)})
+	}
+}
+
+// escapeHelm prefixes the given special characters with a backslash
+func escapeHelm(s, special string) string {
+	if !strings.ContainsAny(s, special) {
+		return s
+	}
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(special, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// helmTypedValue converts a --set value the way Helm does
+func helmTypedValue(value string) any {
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	case "0":
+		return int64(0)
+	}
+	if len(value) > 0 && value[0] != '0' {
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	}
+	return value
+}
+
+// ParseHelmSet applies a --set style expression (a=1,b.c[0]=x) to nested values.
+// With asString every value is kept as a string, like --set-string.
+func ParseHelmSet(obj map[string]any, expr string, asString bool) error {
+	rest := []rune(expr)
+	for len(rest) > 0 {
+		key, stop, remaining := runesUntil(rest, "=,", true)
+		if stop != '=' {
+			return fmt.Errorf("key %q has no value", string(key))
+		}
+		rest = remaining
+
+		path, err := parseHelmKey(key)
+		if err != nil {
+			return err
+		}
+
+		var value any
+		if len(rest) > 0 && rest[0] == '{' && !asString {
+			end := strings.IndexRune(string(rest), '}')
+			if end < 0 {
+				return fmt.Errorf("list value for %q is not closed", string(key))
+			}
+			inner := []rune(string(rest)[1:end])
+			rest = []rune(string(rest)[end+1:])
+			if len(rest) > 0 && rest[0] == ',' {
+				rest = rest[1:]
+			}
+			list := []any{}
+			for len(inner) > 0 {
+				var item []rune
+				item, _, inner = runesUntil(inner, ",", false)
+				list = append(list, helmTypedValue(string(item)))
+			}
+			value = list
+		} else {
+			var raw []rune
+			raw, _, rest = runesUntil(rest, ",", false)
+			if asString {
+				value = string(raw)
+			} else {
+				value = helmTypedValue(string(raw))
+			}
+		}
+
+		if err := setHelmPath(obj, path, value); err != nil {
+			return fmt.Errorf("failed to set %q: %v", string(key), err)
+		}
+	}
+	return nil
+}
+
+// ParseHelmSetJSON applies a --set-json style expression (key=<json>) to nested values
+func ParseHelmSetJSON(obj map[string]any, expr string) error {
+	key, stop, rest := runesUntil([]rune(expr), "=", true)
+	if stop != '=' {
+		return fmt.Errorf("key %q has no value", string(key))
+	}
+
+	path, err := parseHelmKey(key)
+	if err != nil {
+		return err
+	}
+
+	var value any
+	if err := json.Unmarshal([]byte(string(rest)), &value); err != nil {
+		return fmt.Errorf("invalid JSON value for %q: %v", string(key), err)
+	}
+	return setHelmPath(obj, path, value)
+}
+
+// runesUntil reads up to the first unescaped stop rune and returns the text read,
+// the stop rune found (0 at the end) and the remainder. Escapes are removed unless
+// keepEscapes is set, as key paths are unescaped later segment by segment.
+func runesUntil(in []rune, stops string, keepEscapes bool) ([]rune, rune, []rune) {
+	var out []rune
+	for i := 0; i < len(in); i++ {
+		r := in[i]
+		if r == '\\' && i+1 < len(in) {
+			if keepEscapes {
+				out = append(out, r)
+			}
+			i++
+			out = append(out, in[i])
+			continue
+		}
+		if strings.ContainsRune(stops, r) {
+			return out, r, in[i+1:]
+		}
+		out = append(out, r)
+	}
+	return out, 0, nil
+}
+
+// helmSegment is a key path element: a map key or a list index
+type helmSegment struct {
+	name  string
+	index int
+	list  bool
+}
+
+// parseHelmKey splits an escaped key like a.b[0].c\.d into path segments
+func parseHelmKey(key []rune) ([]helmSegment, error) {
+	var path []helmSegment
+	var name []rune
+
+	flush := func() {
+		if len(name) > 0 {
+			path = append(path, helmSegment{name: string(name)})
+			name = nil
+		}
+	}
+
+	for i := 0; i < len(key); i++ {
+		r := key[i]
+		switch {
+		case r == '\\' && i+1 < len(key):
+			i++
+			name = append(name, key[i])
+		case r == '.':
+			flush()
+		case r == '[':
+			flush()
+			end := i + 1
+			for end < len(key) && key[end] != ']' {
+				end++
+			}
+			if end == len(key) {
+				return nil, fmt.Errorf("unclosed index in key %q", string(key))
+			}
+			index, err := strconv.Atoi(string(key[i+1 : end]))
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid index in key %q", string(key))
+			}
+			path = append(path, helmSegment{index: index, list: true})
+			i = end
+		default:
+			name = append(name, r)
+		}
+	}
+	flush()
+
+	if len(path) == 0 || path[0].list {
+		return nil, fmt.Errorf("invalid key %q", string(key))
+	}
+	return path, nil
+}
+
+// setHelmPath assigns a value at a parsed key path, creating maps and lists as needed
+func setHelmPath(obj map[string]any, path []helmSegment, value any) error {
+	var container any = obj
+	for i, segment := range path {
+		last := i == len(path)-1
+
+		var child any
+		if !last {
+			if path[i+1].list {
+				child = []any{}
+			} else {
+				child = map[string]any{}
+			}
+		}
+
+		if segment.list {
+			list, ok := container.([]any)
+			if !ok {
+				return fmt.Errorf("index used on a non-list value")
+			}
+			for len(list) <= segment.index {
+				list = append(list, nil)
+			}
+			if err := replaceContainer(obj, path[:i], list); err != nil {
+				return err
+			}
+			if last {
+				list[segment.index] = value
+				return nil
+			}
+			if existing := list[segment.index]; existing != nil && sameKind(existing, child) {
+				child = existing
+			}
+			list[segment.index] = child
+			container = child
+			continue
+		}
+
+		m, ok := container.(map[string]any)
+		if !ok {
+			return fmt.Errorf("key used on a non-map value")
+		}
+		if last {
+			m[segment.name] = value
+			return nil
+		}
+		if existing, ok := m[segment.name]; ok && sameKind(existing, child) {
+			child = existing
+		}
+		m[segment.name] = child
+		container = child
+	}
+	return nil
+}
+
+// replaceContainer stores a (possibly grown) list back at its path
+func replaceContainer(obj map[string]any, path []helmSegment, list []any) error {
+	var parent any = obj
+	for i, segment := range path {
+		last := i == len(path)-1
+		if segment.list {
+			items := parent.([]any)
+			if last {
+				items[segment.index] = list
+				return nil
+			}
+			parent = items[segment.index]
+			continue
+		}
+		m := parent.(map[string]any)
+		if last {
+			m[segment.name] = list
+			return nil
+		}
+		parent = m[segment.name]
+	}
+	return nil
+}
+
+func sameKind(a, b any) bool {
+	switch a.(type) {
+	case map[string]any:
+		_, ok := b.(map[string]any)
+		return ok
+	case []any:
+		_, ok := b.([]any)
+		return ok
+	}
+	return false
+}
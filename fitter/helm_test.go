@@ -0,0 +1,125 @@
+package fitter
+
+import (
+	"reflect"
+	"testing"
+)
+
+// applyHelmValues applies assignments with the parser of their flag, as helm does
+func applyHelmValues(t *testing.T, values []HelmValue) map[string]any {
+	t.Helper()
+	obj := make(map[string]any)
+	for _, value := range values {
+		var err error
+		switch value.Flag {
+		case HelmSetJSON:
+			err = ParseHelmSetJSON(obj, value.String())
+		default:
+			err = ParseHelmSet(obj, value.String(), value.Flag == HelmSetString)
+		}
+		if err != nil {
+			t.Fatalf("%s %s: %v", value.Flag, value, err)
+		}
+	}
+	return obj
+}
+
+func TestHelmValuesRoundTrip(t *testing.T) {
+	data := map[string]any{
+		"image": map[string]any{"repository": "nginx", "tag": "1.25"},
+		"ingress": map[string]any{"annotations": map[string]any{
+			"kubernetes.io/ingress.class": "nginx",
+			"a,b=c":                       "x,y",
+			"br[0]":                       `v\w`,
+		}},
+		"replicas": int64(3),
+		"ratio":    0.5,
+		"enabled":  true,
+		"version":  "10",
+		"flag":     "true",
+		"octal":    "007",
+		"template": "{not json}",
+		"nothing":  nil,
+		"list":     []any{"a", map[string]any{"name": "x"}, []any{int64(1), "b,c"}},
+		"empty":    map[string]any{},
+		"none":     []any{},
+	}
+
+	values := FlattenHelmValues(data)
+	if got := applyHelmValues(t, values); !reflect.DeepEqual(got, data) {
+		t.Errorf("Expected %v back, got %v", data, got)
+	}
+
+	expected := map[string]HelmValue{
+		"ingress.annotations.kubernetes\\.io/ingress\\.class": {HelmSet, "ingress.annotations.kubernetes\\.io/ingress\\.class", "nginx"},
+		"ingress.annotations.a\\,b\\=c":                       {HelmSet, "ingress.annotations.a\\,b\\=c", "x\\,y"},
+		"ingress.annotations.br\\[0\\]":                       {HelmSet, "ingress.annotations.br\\[0\\]", `v\\w`},
+		"list[2][1]":                                          {HelmSet, "list[2][1]", "b\\,c"},
+		"version":                                             {HelmSetString, "version", "10"},
+		"flag":                                                {HelmSetString, "flag", "true"},
+		"template":                                            {HelmSetString, "template", "{not json}"},
+		"ratio":                                               {HelmSetJSON, "ratio", "0.5"},
+		"empty":                                               {HelmSetJSON, "empty", "{}"},
+		"none":                                                {HelmSetJSON, "none", "[]"},
+		"replicas":                                            {HelmSet, "replicas", "3"},
+	}
+	found := 0
+	for i, value := range values {
+		if i > 0 && values[i-1].Key > value.Key {
+			t.Errorf("Expected assignments sorted by key, got %s before %s", values[i-1].Key, value.Key)
+		}
+		if want, ok := expected[value.Key]; ok {
+			found++
+			if value != want {
+				t.Errorf("Expected %s %s, got %s %s", want.Flag, want, value.Flag, value)
+			}
+		}
+	}
+	if found != len(expected) {
+		t.Errorf("Expected %d checked assignments, found %d in %v", len(expected), found, values)
+	}
+}
+
+func TestParseHelmSet(t *testing.T) {
+	tests := []struct {
+		expr     string
+		asString bool
+		expected map[string]any
+	}{
+		{"a=1,b.c[0]=x,b.c[1]=y", false, map[string]any{"a": int64(1), "b": map[string]any{"c": []any{"x", "y"}}}},
+		{`d=x\,y,e\.f=g`, false, map[string]any{"d": "x,y", "e.f": "g"}},
+		{"a=1,b=true,c=null,d=01", false, map[string]any{"a": int64(1), "b": true, "c": nil, "d": "01"}},
+		{"a=1,b=true", true, map[string]any{"a": "1", "b": "true"}},
+		{"list[1].name=x", false, map[string]any{"list": []any{nil, map[string]any{"name": "x"}}}},
+	}
+	for _, test := range tests {
+		obj := make(map[string]any)
+		if err := ParseHelmSet(obj, test.expr, test.asString); err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.expr, err)
+		}
+		if !reflect.DeepEqual(obj, test.expected) {
+			t.Errorf("%s (string %v): expected %v, got %v", test.expr, test.asString, test.expected, obj)
+		}
+	}
+
+	for _, expr := range []string{"a", "a[x]=1", "a[0=1"} {
+		if err := ParseHelmSet(make(map[string]any), expr, false); err == nil {
+			t.Errorf("Expected an error for '%s'", expr)
+		}
+	}
+}
+
+func TestParseHelmSetJSON(t *testing.T) {
+	obj := map[string]any{"a": map[string]any{"keep": true}}
+	if err := ParseHelmSetJSON(obj, `a.b={"c":[1,"x,y"],"d":null}`); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]any{"a": map[string]any{"keep": true, "b": map[string]any{"c": []any{1.0, "x,y"}, "d": nil}}}
+	if !reflect.DeepEqual(obj, expected) {
+		t.Errorf("Expected %v, got %v", expected, obj)
+	}
+
+	if err := ParseHelmSetJSON(obj, "a.b={not json}"); err == nil {
+		t.Error("Expected an error for invalid JSON")
+	}
+}
@@ -0,0 +1,71 @@
+package fitter
+
+import "fmt"
+
+// ElementKey returns the map key standing for an array element identified by a
+// field value, as in "[id=web]"
+func ElementKey(field string, value any) string {
+	return fmt.Sprintf("[%s=%v]", field, value)
+}
+
+// KeyArrayElements returns a copy of data in which every array of objects holding
+// distinct scalar values of field is replaced by a map from ElementKey to element,
+// so that elements are compared by identity instead of position. Other arrays are
+// kept as they are.
+func KeyArrayElements(data map[string]any, field string) map[string]any {
+	return keyArrayElements(data, field).(map[string]any)
+}
+
+func keyArrayElements(value any, field string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, item := range v {
+			result[key] = keyArrayElements(item, field)
+		}
+		return result
+	case []any:
+		keys, ok := elementKeys(v, field)
+		if ok {
+			result := make(map[string]any, len(v))
+			for i, item := range v {
+				result[keys[i]] = keyArrayElements(item, field)
+			}
+			return result
+		}
+		result := make([]any, len(v))
+		for i, item := range v {
+			result[i] = keyArrayElements(item, field)
+		}
+		return result
+	}
+	return value
+}
+
+// elementKeys returns the ElementKey of every element of an array, reporting false
+// unless all elements are objects holding distinct scalar values of field
+func elementKeys(arr []any, field string) ([]string, bool) {
+	if len(arr) == 0 {
+		return nil, false
+	}
+
+	keys := make([]string, len(arr))
+	seen := make(map[string]bool, len(arr))
+	for i, item := range arr {
+		m, ok := item.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		switch id := m[field].(type) {
+		case string, bool, float64, int, int64:
+			keys[i] = ElementKey(field, id)
+		default:
+			return nil, false
+		}
+		if seen[keys[i]] {
+			return nil, false
+		}
+		seen[keys[i]] = true
+	}
+	return keys, true
+}
@@ -0,0 +1,132 @@
+package fitter
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// MatchPath reports whether a flattened key matches a glob pattern.
+// Patterns are split on the separator; "*" matches within a single segment
+// and "**" matches any number of segments (including none).
+func MatchPath(pattern, key, separator string) bool {
+	if separator == "" {
+		separator = "."
+	}
+	return matchSegments(strings.Split(pattern, separator), strings.Split(key, separator))
+}
+
+// MatchPathPrefix reports whether a key or any of its ancestors matches the pattern,
+// so "buttons.*" selects the whole subtree below each button entry
+func MatchPathPrefix(pattern, key, separator string) bool {
+	if separator == "" {
+		separator = "."
+	}
+	patternParts := strings.Split(pattern, separator)
+	keyParts := strings.Split(key, separator)
+	for i := len(keyParts); i > 0; i-- {
+		if matchSegments(patternParts, keyParts[:i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments matches pattern segments against key segments
+func matchSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			rest := pattern[1:]
+			for i := 0; i <= len(parts); i++ {
+				if matchSegments(rest, parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(parts) == 0 {
+			return false
+		}
+
+		ok, err := path.Match(pattern[0], parts[0])
+		if err != nil || !ok {
+			return false
+		}
+
+		pattern = pattern[1:]
+		parts = parts[1:]
+	}
+
+	return len(parts) == 0
+}
+
+// regexPrefix marks a key pattern as a regular expression instead of a glob
+const regexPrefix = "re:"
+
+// ValidateKeyPatterns checks that include/exclude key patterns compile
+func ValidateKeyPatterns(include, exclude []string) error {
+	for _, pattern := range append(append([]string{}, include...), exclude...) {
+		if strings.HasPrefix(pattern, regexPrefix) {
+			if _, err := regexp.Compile(strings.TrimPrefix(pattern, regexPrefix)); err != nil {
+				return fmt.Errorf("invalid key pattern '%s': %v", pattern, err)
+			}
+		} else if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid key pattern '%s': %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+// keyMatcher matches flattened keys against glob patterns (see MatchPathPrefix) and
+// "re:" prefixed regular expressions. Invalid patterns never match.
+type keyMatcher struct {
+	globs     []string
+	regexps   []*regexp.Regexp
+	separator string
+}
+
+func newKeyMatcher(patterns []string, separator string) *keyMatcher {
+	m := &keyMatcher{separator: separator}
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, regexPrefix) {
+			if re, err := regexp.Compile(strings.TrimPrefix(pattern, regexPrefix)); err == nil {
+				m.regexps = append(m.regexps, re)
+			}
+		} else {
+			m.globs = append(m.globs, pattern)
+		}
+	}
+	return m
+}
+
+func (m *keyMatcher) match(key string) bool {
+	for _, glob := range m.globs {
+		if MatchPathPrefix(glob, key, m.separator) {
+			return true
+		}
+	}
+	for _, re := range m.regexps {
+		if re.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterKeys keeps the flattened keys matching an include pattern (all keys when
+// there are none) and drops those matching an exclude pattern
+func filterKeys(result map[string]any, include, exclude []string, separator string) {
+	if len(include) == 0 && len(exclude) == 0 {
+		return
+	}
+
+	includes := newKeyMatcher(include, separator)
+	excludes := newKeyMatcher(exclude, separator)
+	for key := range result {
+		if (len(include) > 0 && !includes.match(key)) || excludes.match(key) {
+			delete(result, key)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package fitter
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Merge policies deciding which value a conflicting key keeps
+const (
+	MergeLast  = "last"  // later maps override earlier ones, as in defaults < environment < overrides
+	MergeFirst = "first" // the first map setting a key wins
+	MergeError = "error" // any conflict fails the merge
+)
+
+// Array strategies of deep merges
+const (
+	ArraysReplace = "replace" // a later array replaces the earlier one
+	ArraysAppend  = "append"  // a later array is appended to the earlier one
+)
+
+// MergeOptions configures deep merging of nested maps
+type MergeOptions struct {
+	Policy    string // MergeLast (default) overwrites existing values, MergeFirst keeps them, MergeError fails
+	Arrays    string // ArraysReplace (default) or ArraysAppend
+	Separator string // separator of the key paths in conflicts (default: ".")
+	ArrayKey  string // identify elements of arrays of objects by this field, merging them instead of replacing (optional)
+}
+
+// DefaultMergeOptions returns the default options for deep merging
+func DefaultMergeOptions() MergeOptions {
+	return MergeOptions{Policy: MergeLast, Arrays: ArraysReplace, Separator: "."}
+}
+
+// MergeConflict describes a key set to different values by several maps
+type MergeConflict struct {
+	Key     string
+	Sources []int // indexes of the maps setting the key, in merge order
+	Values  []any // the value set by each source
+}
+
+// ValidateMergePolicy checks that a merge policy is known. An empty policy means last.
+func ValidateMergePolicy(policy string) error {
+	switch policy {
+	case "", MergeLast, MergeFirst, MergeError:
+		return nil
+	}
+	return fmt.Errorf("unknown merge policy '%s' (expected last, first or error)", policy)
+}
+
+// MergeFlat combines flattened maps in order. Keys set to equal values by several
+// maps are merged silently; keys set to different values are reported as conflicts,
+// sorted by key, and resolved by the policy. With MergeError the merge fails
+// instead and no map is returned.
+func MergeFlat(policy string, maps ...map[string]any) (map[string]any, []MergeConflict, error) {
+	if err := ValidateMergePolicy(policy); err != nil {
+		return nil, nil, err
+	}
+
+	size := 0
+	for _, m := range maps {
+		size += len(m)
+	}
+
+	result := make(map[string]any, size)
+	sources := make(map[string][]int, size)
+	conflicting := make(map[string]bool)
+
+	for i, m := range maps {
+		for key, value := range m {
+			previous, exists := result[key]
+			sources[key] = append(sources[key], i)
+			if !exists {
+				result[key] = value
+				continue
+			}
+			if !reflect.DeepEqual(previous, value) {
+				conflicting[key] = true
+			}
+			if policy != MergeFirst {
+				result[key] = value
+			}
+		}
+	}
+
+	conflicts := make([]MergeConflict, 0, len(conflicting))
+	for key := range conflicting {
+		conflict := MergeConflict{Key: key, Sources: sources[key]}
+		for _, source := range conflict.Sources {
+			conflict.Values = append(conflict.Values, maps[source][key])
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].Key < conflicts[j].Key
+	})
+
+	if policy == MergeError && len(conflicts) > 0 {
+		return nil, conflicts, conflictError(conflicts)
+	}
+
+	return result, conflicts, nil
+}
+
+// conflictError lists the keys of merge conflicts
+func conflictError(conflicts []MergeConflict) error {
+	keys := make([]string, len(conflicts))
+	for i, conflict := range conflicts {
+		keys[i] = conflict.Key
+	}
+	return fmt.Errorf("%d conflicting keys: %s", len(conflicts), strings.Join(keys, ", "))
+}
+
+// ValidateMergeOptions checks the policy and array strategy of deep merge options
+func ValidateMergeOptions(options MergeOptions) error {
+	if err := ValidateMergePolicy(options.Policy); err != nil {
+		return err
+	}
+	switch options.Arrays {
+	case "", ArraysReplace, ArraysAppend:
+		return nil
+	}
+	return fmt.Errorf("unknown array strategy '%s' (expected replace or append)", options.Arrays)
+}
+
+// MergeMaps deep-merges src into dst and returns the result as a new map; neither
+// input is modified. Nested maps are merged key by key. Other values set on both
+// sides, including a map on one side and a value on the other, are conflicts when
+// they differ (source 0 is dst, source 1 is src) and are resolved by the policy;
+// with ArraysAppend, arrays are concatenated instead. With an ArrayKey, arrays of
+// objects holding distinct values of that field on both sides are merged element
+// by element: elements with the same value are deep-merged in place and the other
+// elements of src are appended.
+func MergeMaps(dst, src map[string]any, options MergeOptions) (map[string]any, []MergeConflict, error) {
+	if err := ValidateMergeOptions(options); err != nil {
+		return nil, nil, err
+	}
+	if options.Separator == "" {
+		options.Separator = "."
+	}
+
+	var conflicts []MergeConflict
+	result := mergeMaps(dst, src, "", options, &conflicts)
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].Key < conflicts[j].Key
+	})
+
+	if options.Policy == MergeError && len(conflicts) > 0 {
+		return nil, conflicts, conflictError(conflicts)
+	}
+
+	return result, conflicts, nil
+}
+
+func mergeMaps(dst, src map[string]any, prefix string, options MergeOptions, conflicts *[]MergeConflict) map[string]any {
+	result := make(map[string]any, len(dst)+len(src))
+	for key, value := range dst {
+		result[key] = copyValue(value)
+	}
+
+	for key, value := range src {
+		path := key
+		if prefix != "" {
+			path = prefix + options.Separator + key
+		}
+
+		existing, exists := dst[key]
+		if !exists {
+			result[key] = copyValue(value)
+			continue
+		}
+
+		existingMap, existingIsMap := existing.(map[string]any)
+		valueMap, valueIsMap := value.(map[string]any)
+		if existingIsMap && valueIsMap {
+			result[key] = mergeMaps(existingMap, valueMap, path, options, conflicts)
+			continue
+		}
+
+		existingArr, existingIsArr := existing.([]any)
+		valueArr, valueIsArr := value.([]any)
+		if existingIsArr && valueIsArr && options.ArrayKey != "" {
+			if merged, ok := mergeKeyedArrays(existingArr, valueArr, path, options, conflicts); ok {
+				result[key] = merged
+				continue
+			}
+		}
+		if existingIsArr && valueIsArr && options.Arrays == ArraysAppend {
+			result[key] = append(copyValue(existingArr).([]any), copyValue(valueArr).([]any)...)
+			continue
+		}
+
+		if reflect.DeepEqual(existing, value) {
+			continue
+		}
+		*conflicts = append(*conflicts, MergeConflict{Key: path, Sources: []int{0, 1}, Values: []any{existing, value}})
+		if options.Policy != MergeFirst {
+			result[key] = copyValue(value)
+		}
+	}
+
+	return result
+}
+
+// mergeKeyedArrays merges arrays of objects identified by options.ArrayKey,
+// reporting false when either array does not identify its elements
+func mergeKeyedArrays(dst, src []any, path string, options MergeOptions, conflicts *[]MergeConflict) ([]any, bool) {
+	dstKeys, ok := elementKeys(dst, options.ArrayKey)
+	if !ok {
+		return nil, false
+	}
+	srcKeys, ok := elementKeys(src, options.ArrayKey)
+	if !ok {
+		return nil, false
+	}
+
+	srcIndex := make(map[string]int, len(src))
+	for i, key := range srcKeys {
+		srcIndex[key] = i
+	}
+
+	result := make([]any, 0, len(dst)+len(src))
+	merged := make(map[string]bool, len(dst))
+	for i, key := range dstKeys {
+		j, ok := srcIndex[key]
+		if !ok {
+			result = append(result, copyValue(dst[i]))
+			continue
+		}
+		merged[key] = true
+		elementPath := path + options.Separator + key
+		result = append(result, mergeMaps(dst[i].(map[string]any), src[j].(map[string]any), elementPath, options, conflicts))
+	}
+	for j, key := range srcKeys {
+		if !merged[key] {
+			result = append(result, copyValue(src[j]))
+		}
+	}
+	return result, true
+}
+
+// copyValue returns a deep copy of the maps and arrays of a value
+func copyValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for key, item := range v {
+			m[key] = copyValue(item)
+		}
+		return m
+	case []any:
+		arr := make([]any, len(v))
+		for i, item := range v {
+			arr[i] = copyValue(item)
+		}
+		return arr
+	}
+	return value
+}
@@ -0,0 +1,118 @@
+package fitter
+
+import (
+	"sort"
+	"strings"
+)
+
+// Sides of a three-way merge
+const (
+	SideBase   = "base"
+	SideOurs   = "ours"
+	SideTheirs = "theirs"
+)
+
+// Merge3Value is the value of a key in one side of a three-way merge
+type Merge3Value struct {
+	Value   any  `json:"value"`
+	Missing bool `json:"missing,omitempty"` // the key is not set on this side
+}
+
+// Merge3Conflict is a key changed differently by both sides of a three-way merge
+type Merge3Conflict struct {
+	Key    string      `json:"key"`
+	Base   Merge3Value `json:"base"`
+	Ours   Merge3Value `json:"ours"`
+	Theirs Merge3Value `json:"theirs"`
+}
+
+// Side returns the value of the conflicting key on a side
+func (c Merge3Conflict) Side(side string) Merge3Value {
+	switch side {
+	case SideBase:
+		return c.Base
+	case SideTheirs:
+		return c.Theirs
+	}
+	return c.Ours
+}
+
+// Merge3Flat performs a key-level three-way merge of flattened maps. A key changed
+// (set, modified or removed) on one side only takes that side's value, and a key
+// changed the same way on both sides takes the shared value. Keys changed
+// differently on both sides are reported as conflicts, sorted by key, and keep
+// our value in the result. A key below a key that the result sets to a value is
+// also a conflict, resolved by dropping it, since the two cannot be unflattened.
+func Merge3Flat(base, ours, theirs map[string]any, separator string) (map[string]any, []Merge3Conflict) {
+	keys := make(map[string]bool, len(ours)+len(theirs))
+	for _, m := range []map[string]any{base, ours, theirs} {
+		for key := range m {
+			keys[key] = true
+		}
+	}
+
+	result := make(map[string]any, len(keys))
+	conflicting := make(map[string]bool)
+	var conflicts []Merge3Conflict
+	for key := range keys {
+		b, o, t := merge3Value(base, key), merge3Value(ours, key), merge3Value(theirs, key)
+
+		var chosen Merge3Value
+		switch {
+		case sameMerge3Value(o, t), sameMerge3Value(t, b):
+			chosen = o
+		case sameMerge3Value(o, b):
+			chosen = t
+		default:
+			chosen = o
+			conflicting[key] = true
+			conflicts = append(conflicts, Merge3Conflict{Key: key, Base: b, Ours: o, Theirs: t})
+		}
+		if !chosen.Missing {
+			result[key] = chosen.Value
+		}
+	}
+
+	for key := range result {
+		if !hasValueAncestor(result, key, separator) {
+			continue
+		}
+		delete(result, key)
+		if !conflicting[key] {
+			conflicts = append(conflicts, Merge3Conflict{
+				Key:    key,
+				Base:   merge3Value(base, key),
+				Ours:   merge3Value(ours, key),
+				Theirs: merge3Value(theirs, key),
+			})
+		}
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].Key < conflicts[j].Key
+	})
+	return result, conflicts
+}
+
+// hasValueAncestor reports whether a flattened map sets a value at a path above a key
+func hasValueAncestor(flat map[string]any, key, separator string) bool {
+	segments := strings.Split(key, separator)
+	for i := 1; i < len(segments); i++ {
+		if _, ok := flat[strings.Join(segments[:i], separator)]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func merge3Value(m map[string]any, key string) Merge3Value {
+	value, ok := m[key]
+	return Merge3Value{Value: value, Missing: !ok}
+}
+
+func sameMerge3Value(a, b Merge3Value) bool {
+	if a.Missing || b.Missing {
+		return a.Missing == b.Missing
+	}
+	return EqualValues(a.Value, b.Value)
+}
@@ -0,0 +1,100 @@
+package fitter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMerge3Flat(t *testing.T) {
+	tests := map[string]struct {
+		base, ours, theirs map[string]any
+		expected           map[string]any
+		conflicts          []Merge3Conflict
+	}{
+		"clean": {
+			base:     map[string]any{"a": 1, "b": 2, "c": 3},
+			ours:     map[string]any{"a": 10, "b": 2, "c": 3, "d": 4},
+			theirs:   map[string]any{"a": 1, "b": 20},
+			expected: map[string]any{"a": 10, "b": 20, "d": 4},
+		},
+		"same change": {
+			base:     map[string]any{"a": 1, "b": 2},
+			ours:     map[string]any{"a": 5, "c": 3},
+			theirs:   map[string]any{"a": 5, "c": 3},
+			expected: map[string]any{"a": 5, "c": 3},
+		},
+		"same array": {
+			base:     map[string]any{"list": []any{1}},
+			ours:     map[string]any{"list": []any{1, 2}},
+			theirs:   map[string]any{"list": []any{1, 2}},
+			expected: map[string]any{"list": []any{1, 2}},
+		},
+		"delete and modify": {
+			base:     map[string]any{"a": 1, "b": 2},
+			ours:     map[string]any{"b": 2},
+			theirs:   map[string]any{"a": 5, "b": 2},
+			expected: map[string]any{"b": 2},
+			conflicts: []Merge3Conflict{{
+				Key:    "a",
+				Base:   Merge3Value{Value: 1},
+				Ours:   Merge3Value{Missing: true},
+				Theirs: Merge3Value{Value: 5},
+			}},
+		},
+		"modify and delete": {
+			base:     map[string]any{"a": 1},
+			ours:     map[string]any{"a": 5},
+			theirs:   map[string]any{},
+			expected: map[string]any{"a": 5},
+			conflicts: []Merge3Conflict{{
+				Key:    "a",
+				Base:   Merge3Value{Value: 1},
+				Ours:   Merge3Value{Value: 5},
+				Theirs: Merge3Value{Missing: true},
+			}},
+		},
+		"both added": {
+			base:     map[string]any{},
+			ours:     map[string]any{"a": "x"},
+			theirs:   map[string]any{"a": "y"},
+			expected: map[string]any{"a": "x"},
+			conflicts: []Merge3Conflict{{
+				Key:    "a",
+				Base:   Merge3Value{Missing: true},
+				Ours:   Merge3Value{Value: "x"},
+				Theirs: Merge3Value{Value: "y"},
+			}},
+		},
+		"value above a key": {
+			base:     map[string]any{"a.b": 1},
+			ours:     map[string]any{"a": "flat"},
+			theirs:   map[string]any{"a.b": 1, "a.c": 2},
+			expected: map[string]any{"a": "flat"},
+			conflicts: []Merge3Conflict{{
+				Key:    "a.c",
+				Base:   Merge3Value{Missing: true},
+				Ours:   Merge3Value{Missing: true},
+				Theirs: Merge3Value{Value: 2},
+			}},
+		},
+	}
+
+	for name, test := range tests {
+		result, conflicts := Merge3Flat(test.base, test.ours, test.theirs, ".")
+		if !reflect.DeepEqual(result, test.expected) {
+			t.Errorf("%s: expected %v, got %v", name, test.expected, result)
+		}
+		if !reflect.DeepEqual(conflicts, test.conflicts) {
+			t.Errorf("%s: expected conflicts %+v, got %+v", name, test.conflicts, conflicts)
+		}
+	}
+}
+
+func TestMerge3ConflictSide(t *testing.T) {
+	conflict := Merge3Conflict{Key: "a", Base: Merge3Value{Value: 1}, Ours: Merge3Value{Value: 2}, Theirs: Merge3Value{Missing: true}}
+	for side, expected := range map[string]Merge3Value{SideBase: conflict.Base, SideOurs: conflict.Ours, SideTheirs: conflict.Theirs} {
+		if got := conflict.Side(side); got != expected {
+			t.Errorf("%s: expected %+v, got %+v", side, expected, got)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package fitter
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Policies for values JSON cannot encode: NaN and infinite floats (.nan and .inf
+// in YAML), complex numbers, functions and channels
+const (
+	NonJSONKeep   = "keep"   // leave the values as they are; writing them as JSON fails
+	NonJSONError  = "error"  // fail with the key path of the first value found
+	NonJSONNull   = "null"   // replace the values with null
+	NonJSONString = "string" // replace the values with their text: "NaN", "+Inf", "-Inf", "(1+2i)"
+)
+
+// ValidateNonJSONPolicy checks that a non-JSON value policy is known. An empty
+// policy means keep.
+func ValidateNonJSONPolicy(policy string) error {
+	switch policy {
+	case "", NonJSONKeep, NonJSONError, NonJSONNull, NonJSONString:
+		return nil
+	}
+	return fmt.Errorf("unknown non-JSON value policy '%s' (expected keep, error, null or string)", policy)
+}
+
+// SanitizeValues applies a policy to the values of a document that JSON cannot
+// encode, in place, below every map and array. Typed slices and maps holding
+// such values, as found in documents built from Go values, are replaced with
+// []any and map[string]any. With NonJSONError the first value found, in key
+// order, fails with its key path joined with the separator.
+func SanitizeValues(data map[string]any, policy, separator string) error {
+	if err := ValidateNonJSONPolicy(policy); err != nil {
+		return err
+	}
+	if policy == "" || policy == NonJSONKeep {
+		return nil
+	}
+	s := sanitizer{policy: policy, separator: separator}
+	_, _, err := s.value(data, "")
+	return err
+}
+
+// sanitizer walks a document, replacing the values JSON cannot encode
+type sanitizer struct {
+	policy    string
+	separator string
+}
+
+// value returns the sanitized value found at a key path, and whether it or a
+// value below it changed. Maps and arrays are sanitized in place.
+func (s sanitizer) value(value any, path string) (any, bool, error) {
+	switch v := value.(type) {
+	case nil, string, bool, int, int64:
+		return value, false, nil
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		changedAny := false
+		for _, key := range keys {
+			item, changed, err := s.value(v[key], joinPath(path, key, s.separator))
+			if err != nil {
+				return value, false, err
+			}
+			if changed {
+				v[key] = item
+				changedAny = true
+			}
+		}
+		return value, changedAny, nil
+	case []any:
+		changedAny := false
+		for i, item := range v {
+			item, changed, err := s.value(item, joinPath(path, strconv.Itoa(i), s.separator))
+			if err != nil {
+				return value, false, err
+			}
+			if changed {
+				v[i] = item
+				changedAny = true
+			}
+		}
+		return value, changedAny, nil
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return s.replace(value, strconv.FormatFloat(v, 'g', -1, 64), path)
+		}
+		return value, false, nil
+	case float32:
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return s.replace(value, strconv.FormatFloat(float64(v), 'g', -1, 32), path)
+		}
+		return value, false, nil
+	case complex64, complex128:
+		return s.replace(value, fmt.Sprint(v), path)
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return s.replace(value, fmt.Sprintf("%T", value), path)
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return value, false, nil // encoded as base64
+		}
+		items := make([]any, rv.Len())
+		for i := range items {
+			items[i] = rv.Index(i).Interface()
+		}
+		return s.rebuilt(value, items, path)
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return value, false, nil
+		}
+		entries := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			entries[iter.Key().String()] = iter.Value().Interface()
+		}
+		return s.rebuilt(value, entries, path)
+	}
+	return value, false, nil
+}
+
+// rebuilt sanitizes the copy of a typed slice or map as []any or map[string]any,
+// returning the copy when one of its values changed and the original otherwise
+func (s sanitizer) rebuilt(value, generic any, path string) (any, bool, error) {
+	if _, changed, err := s.value(generic, path); err != nil || !changed {
+		return value, false, err
+	}
+	return generic, true, nil
+}
+
+// replace applies the policy to a value JSON cannot encode, described by text
+func (s sanitizer) replace(value any, text, path string) (any, bool, error) {
+	switch s.policy {
+	case NonJSONNull:
+		return nil, true, nil
+	case NonJSONString:
+		return text, true, nil
+	}
+	if path == "" {
+		return value, false, fmt.Errorf("value %s cannot be encoded as JSON", text)
+	}
+	return value, false, fmt.Errorf("value of '%s' cannot be encoded as JSON: %s", path, text)
+}
@@ -0,0 +1,61 @@
+package fitter
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestSanitizeValuesPolicies(t *testing.T) {
+	values := map[string]float64{"nan": math.NaN(), "pos": math.Inf(1), "neg": math.Inf(-1)}
+	texts := map[string]string{"nan": "NaN", "pos": "+Inf", "neg": "-Inf"}
+
+	for name, value := range values {
+		newDoc := func() map[string]any {
+			return map[string]any{"a": map[string]any{"b": value, "ok": 1.5}, "list": []any{value}}
+		}
+
+		// keep and an empty policy leave the values alone
+		for _, policy := range []string{"", NonJSONKeep} {
+			doc := newDoc()
+			if err := SanitizeValues(doc, policy, "."); err != nil {
+				t.Fatalf("%s, policy %q: unexpected error: %v", name, policy, err)
+			}
+			got := doc["a"].(map[string]any)["b"].(float64)
+			if math.IsNaN(value) != math.IsNaN(got) || (!math.IsNaN(value) && got != value) {
+				t.Errorf("%s, policy %q: expected the value to be kept, got %v", name, policy, got)
+			}
+		}
+
+		doc := newDoc()
+		err := SanitizeValues(doc, NonJSONError, ".")
+		if err == nil || !strings.Contains(err.Error(), "'a.b'") || !strings.Contains(err.Error(), texts[name]) {
+			t.Errorf("%s, policy error: expected an error naming a.b and %s, got %v", name, texts[name], err)
+		}
+
+		doc = newDoc()
+		if err := SanitizeValues(doc, NonJSONNull, "."); err != nil {
+			t.Fatalf("%s, policy null: unexpected error: %v", name, err)
+		}
+		if doc["a"].(map[string]any)["b"] != nil || doc["list"].([]any)[0] != nil {
+			t.Errorf("%s, policy null: expected nulls, got %v", name, doc)
+		}
+		if doc["a"].(map[string]any)["ok"] != 1.5 {
+			t.Errorf("%s, policy null: expected finite values to be kept, got %v", name, doc)
+		}
+
+		doc = newDoc()
+		if err := SanitizeValues(doc, NonJSONString, "."); err != nil {
+			t.Fatalf("%s, policy string: unexpected error: %v", name, err)
+		}
+		if doc["a"].(map[string]any)["b"] != texts[name] || doc["list"].([]any)[0] != texts[name] {
+			t.Errorf("%s, policy string: expected %q, got %v", name, texts[name], doc)
+		}
+	}
+}
+
+func TestSanitizeValuesUnknownPolicy(t *testing.T) {
+	if err := SanitizeValues(map[string]any{"a": math.NaN()}, "drop", "."); err == nil {
+		t.Error("Expected an error for an unknown policy")
+	}
+}
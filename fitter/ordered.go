@@ -0,0 +1,107 @@
+package fitter
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/haiyon/fitobj/utils"
+)
+
+// FlattenOrdered flattens an ordered map like FlattenMapWithOptions, keeping the
+// flattened keys in the order of the source document
+func FlattenOrdered(obj *utils.OrderedMap, prefix string, options FlattenOptions) *utils.OrderedMap {
+	result := &flatResult{values: make(map[string]any, options.BufferSize), ordered: true}
+	flatten(obj, prefix, result, options, 0)
+	filterKeys(result.values, options.IncludeKeys, options.ExcludeKeys, options.Separator)
+
+	flat := utils.NewOrderedMap()
+	for _, key := range result.keys {
+		if value, ok := result.values[key]; ok {
+			flat.Set(key, value)
+		}
+	}
+	return flat
+}
+
+// UnflattenOrdered unflattens an ordered map like UnflattenMapWithOptions, with
+// every object ordered by the first flattened key below it, so a flattened
+// document unflattens back to its original layout
+func UnflattenOrdered(obj *utils.OrderedMap, options UnflattenOptions) *utils.OrderedMap {
+	ranks := make(map[string]int)
+	rank := func(parts []string) {
+		path := segmentsKey(parts)
+		if _, ok := ranks[path]; !ok {
+			ranks[path] = len(ranks)
+		}
+	}
+
+	for _, key := range obj.Keys() {
+		parts, literal := splitKey(key, options)
+		parts, _ = limitDepth(parts, literal, options)
+		for i := 1; i <= len(parts); i++ {
+			rank(parts[:i])
+		}
+		value, _ := obj.Get(key)
+		rankNested(value, parts, rank)
+	}
+
+	nested := UnflattenMapWithOptions(obj.ToMap(), options)
+	return orderNested(nested, nil, ranks).(*utils.OrderedMap)
+}
+
+// rankNested ranks the paths inside a compound value, as kept by a depth limit
+func rankNested(value any, path []string, rank func(parts []string)) {
+	switch v := value.(type) {
+	case *utils.OrderedMap:
+		for _, key := range v.Keys() {
+			keyPath := append(path[:len(path):len(path)], key)
+			rank(keyPath)
+			child, _ := v.Get(key)
+			rankNested(child, keyPath, rank)
+		}
+	case []any:
+		for i, item := range v {
+			itemPath := append(path[:len(path):len(path)], strconv.Itoa(i))
+			rank(itemPath)
+			rankNested(item, itemPath, rank)
+		}
+	}
+}
+
+// orderNested converts the maps of an unflattened value to ordered maps, sorting
+// keys by rank; unranked keys come last, in natural order
+func orderNested(value any, path []string, ranks map[string]int) any {
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		rankOf := func(key string) int {
+			if r, ok := ranks[segmentsKey(append(path[:len(path):len(path)], key))]; ok {
+				return r
+			}
+			return len(ranks)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			ri, rj := rankOf(keys[i]), rankOf(keys[j])
+			if ri != rj {
+				return ri < rj
+			}
+			return utils.NaturalLess(keys[i], keys[j])
+		})
+
+		m := utils.NewOrderedMap()
+		for _, key := range keys {
+			m.Set(key, orderNested(v[key], append(path[:len(path):len(path)], key), ranks))
+		}
+		return m
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = orderNested(item, append(path[:len(path):len(path)], strconv.Itoa(i)), ranks)
+		}
+		return items
+	}
+	return value
+}
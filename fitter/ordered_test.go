@@ -0,0 +1,54 @@
+package fitter
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/haiyon/fitobj/utils"
+)
+
+func TestOrderedRoundTripKeepsKeyOrder(t *testing.T) {
+	source := `{"zeta":{"b":1,"a":[{"y":2,"x":3}]},"alpha":true,"mid":{"k2":"v","k10":"w"}}`
+	obj, err := utils.ParseOrderedJSON([]byte(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flat := FlattenOrdered(obj, "", DefaultFlattenOptions())
+	expectedKeys := []string{"zeta.b", "zeta.a.0.y", "zeta.a.0.x", "alpha", "mid.k2", "mid.k10"}
+	if !reflect.DeepEqual(flat.Keys(), expectedKeys) {
+		t.Errorf("Expected flattened keys %v, got %v", expectedKeys, flat.Keys())
+	}
+
+	nested := UnflattenOrdered(flat, DefaultUnflattenOptions())
+	data, err := nested.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != source {
+		t.Errorf("Expected %s back, got %s", source, data)
+	}
+}
+
+func TestUnflattenOrderedFollowsFirstKey(t *testing.T) {
+	// Objects take the position of their first flattened key, and values kept
+	// whole by a depth limit keep their own order
+	flat := utils.NewOrderedMap()
+	flat.Set("b.y", 1)
+	flat.Set("a", 2)
+	flat.Set("b.x", 3)
+	limited := utils.NewOrderedMap()
+	limited.Set("q", 1)
+	limited.Set("p", 2)
+	flat.Set("c.d.e", limited)
+
+	options := DefaultUnflattenOptions()
+	options.MaxDepth = 1
+	data, err := UnflattenOrdered(flat, options).MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"b":{"y":1,"x":3},"a":2,"c":{"d.e":{"q":1,"p":2}}}`; string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+}
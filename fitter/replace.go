@@ -0,0 +1,107 @@
+package fitter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ReplaceOptions configures ReplaceValues
+type ReplaceOptions struct {
+	Separator       string   // joins key segments when matching KeyGlobs
+	ArrayFormatting string   // format of array indices in keys: "index" or "bracket"
+	KeyGlobs        []string // only replace in values whose flattened key matches these patterns (all values when empty)
+	From            string   // text to replace
+	To              string   // replacement text
+	Regex           bool     // From is a regular expression and To may refer to its groups ($1, ${name})
+}
+
+// ValidateReplaceOptions checks that a replacement is usable
+func ValidateReplaceOptions(options ReplaceOptions) error {
+	if options.From == "" {
+		return fmt.Errorf("the text to replace must not be empty")
+	}
+	if options.Regex {
+		if _, err := regexp.Compile(options.From); err != nil {
+			return fmt.Errorf("invalid regular expression '%s': %v", options.From, err)
+		}
+	}
+	return ValidateKeyPatterns(options.KeyGlobs, nil)
+}
+
+// ReplaceValues replaces text in the string values of a document, in place, below
+// every map and array. Keys and non-string values are left alone. The changes are
+// returned sorted by flattened key.
+func ReplaceValues(data map[string]any, options ReplaceOptions) ([]DiffChange, error) {
+	if err := ValidateReplaceOptions(options); err != nil {
+		return nil, err
+	}
+
+	replace := func(s string) string { return strings.ReplaceAll(s, options.From, options.To) }
+	if options.Regex {
+		re := regexp.MustCompile(options.From)
+		replace = func(s string) string { return re.ReplaceAllString(s, options.To) }
+	}
+
+	r := replacer{
+		options: options,
+		keys:    newKeyMatcher(options.KeyGlobs, options.Separator),
+		replace: replace,
+		changes: []DiffChange{},
+	}
+	r.object(data, "")
+
+	sort.Slice(r.changes, func(i, j int) bool { return r.changes[i].Key < r.changes[j].Key })
+	return r.changes, nil
+}
+
+// replacer walks a document, building flattened keys like FlattenMapWithOptions
+type replacer struct {
+	options ReplaceOptions
+	keys    *keyMatcher
+	replace func(string) string
+	changes []DiffChange
+}
+
+func (r *replacer) object(obj map[string]any, prefix string) {
+	for key, value := range obj {
+		fullKey := key
+		if prefix != "" {
+			fullKey = prefix + r.options.Separator + key
+		}
+		obj[key] = r.value(value, fullKey)
+	}
+}
+
+func (r *replacer) array(arr []any, prefix string) {
+	for i, item := range arr {
+		var indexedKey string
+		if r.options.ArrayFormatting == "bracket" {
+			indexedKey = fmt.Sprintf("%s[%d]", prefix, i)
+		} else {
+			indexedKey = prefix + r.options.Separator + strconv.Itoa(i)
+		}
+		arr[i] = r.value(item, indexedKey)
+	}
+}
+
+// value returns a value with its text replaced, recording the change
+func (r *replacer) value(value any, key string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		r.object(v, key)
+	case []any:
+		r.array(v, key)
+	case string:
+		if len(r.options.KeyGlobs) > 0 && !r.keys.match(key) {
+			return v
+		}
+		if replaced := r.replace(v); replaced != v {
+			r.changes = append(r.changes, DiffChange{Key: key, Old: v, New: replaced})
+			return replaced
+		}
+	}
+	return value
+}
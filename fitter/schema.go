@@ -0,0 +1,197 @@
+package fitter
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Schema is the subset of JSON Schema used to type unflattened values
+type Schema struct {
+	Type       SchemaType         `json:"type,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+}
+
+// SchemaType holds the declared type(s) of a schema ("type": "integer" or ["integer", "null"])
+type SchemaType []string
+
+// UnmarshalJSON accepts both a single type name and a list of type names
+func (t *SchemaType) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = SchemaType{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("type must be a string or an array of strings")
+	}
+	*t = list
+	return nil
+}
+
+// SchemaIssue describes a value that does not match its schema
+type SchemaIssue struct {
+	Path    string `json:"path"`
+	Problem string `json:"problem"`
+}
+
+// ParseSchema decodes a JSON Schema document
+func ParseSchema(data []byte) (*Schema, error) {
+	var schema Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %v", err)
+	}
+	return &schema, nil
+}
+
+// ApplySchema coerces the values of a nested map to the types declared in a schema
+// (e.g. "5" to 5 under an integer property) and reports missing required fields
+// and values that cannot be coerced. The map is modified in place.
+func ApplySchema(obj map[string]any, schema *Schema, separator string) []SchemaIssue {
+	if schema == nil {
+		return nil
+	}
+
+	var issues []SchemaIssue
+	coerceObject(obj, schema, "", separator, &issues)
+	sort.Slice(issues, func(i, j int) bool { return issues[i].Path < issues[j].Path })
+	return issues
+}
+
+func coerceObject(obj map[string]any, schema *Schema, path, separator string, issues *[]SchemaIssue) {
+	for _, name := range schema.Required {
+		if _, ok := obj[name]; !ok {
+			*issues = append(*issues, SchemaIssue{Path: joinPath(path, name, separator), Problem: "missing required field"})
+		}
+	}
+
+	for name, property := range schema.Properties {
+		if value, ok := obj[name]; ok {
+			obj[name] = coerceValue(value, property, joinPath(path, name, separator), separator, issues)
+		}
+	}
+}
+
+func coerceValue(value any, schema *Schema, path, separator string, issues *[]SchemaIssue) any {
+	if schema == nil {
+		return value
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		if len(schema.Properties) > 0 || len(schema.Required) > 0 {
+			coerceObject(v, schema, path, separator, issues)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = coerceValue(item, schema.Items, joinPath(path, strconv.Itoa(i), separator), separator, issues)
+		}
+	}
+
+	if len(schema.Type) == 0 {
+		return value
+	}
+
+	for _, name := range schema.Type {
+		if matchesType(value, name) {
+			return value
+		}
+	}
+
+	for _, name := range schema.Type {
+		if coerced, ok := coerceTo(value, name); ok {
+			return coerced
+		}
+	}
+
+	*issues = append(*issues, SchemaIssue{
+		Path:    path,
+		Problem: fmt.Sprintf("cannot coerce %s to %s", describeValue(value), strings.Join(schema.Type, " or ")),
+	})
+	return value
+}
+
+// matchesType reports whether a decoded JSON value already has a schema type
+func matchesType(value any, name string) bool {
+	switch v := value.(type) {
+	case nil:
+		return name == "null"
+	case bool:
+		return name == "boolean"
+	case string:
+		return name == "string"
+	case float64:
+		return name == "number" || (name == "integer" && v == math.Trunc(v))
+	case int, int64:
+		return name == "number" || name == "integer"
+	case map[string]any:
+		return name == "object"
+	case []any:
+		return name == "array"
+	}
+	return false
+}
+
+// coerceTo converts a scalar value to a schema type when the conversion is lossless
+func coerceTo(value any, name string) (any, bool) {
+	text, isString := value.(string)
+	if !isString {
+		switch v := value.(type) {
+		case float64:
+			if name == "string" {
+				return strconv.FormatFloat(v, 'f', -1, 64), true
+			}
+		case bool:
+			if name == "string" {
+				return strconv.FormatBool(v), true
+			}
+		}
+		return nil, false
+	}
+
+	text = strings.TrimSpace(text)
+	switch name {
+	case "integer":
+		if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+			return float64(n), true
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(text, 64); err == nil && !math.IsInf(n, 0) && !math.IsNaN(n) {
+			return n, true
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(text); err == nil {
+			return b, true
+		}
+	case "null":
+		if text == "" || text == "null" {
+			return nil, true
+		}
+	}
+	return nil, false
+}
+
+func describeValue(value any) string {
+	if s, ok := value.(string); ok {
+		return strconv.Quote(s)
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(encoded)
+}
+
+func joinPath(path, name, separator string) string {
+	if path == "" {
+		return name
+	}
+	return path + separator + name
+}
@@ -12,6 +12,10 @@ type UnflattenOptions struct {
 	DetectArrays           bool   // auto convert numeric indices to arrays
 	SupportBracketNotation bool   // support key[0] notation
 	BufferSize             int    // initial capacity for result maps
+	CoerceTypes            bool   // parse string values as booleans, numbers and null, as CoerceString
+	Escape                 string // escape of separators and '[' inside keys, as written by FlattenOptions.Escape (default: none)
+	EscapeStyle            string // how Escape is applied: EscapePrefix (default) or EscapeQuote
+	MaxDepth               int    // max nesting depth: keys are split into at most MaxDepth+1 segments, the rest kept joined (0 or -1 = no limit)
 }
 
 // DefaultUnflattenOptions returns the default options for unflattening
@@ -21,6 +25,7 @@ func DefaultUnflattenOptions() UnflattenOptions {
 		DetectArrays:           true,
 		SupportBracketNotation: true,
 		BufferSize:             16,
+		MaxDepth:               -1,
 	}
 }
 
@@ -33,37 +38,228 @@ func UnflattenMap(obj map[string]any) map[string]any {
 func UnflattenMapWithOptions(obj map[string]any, options UnflattenOptions) map[string]any {
 	result := make(map[string]any)
 
-	// Preprocess keys to convert bracket notation if needed
-	processedObj := make(map[string]any, len(obj))
-	for k, v := range obj {
-		if options.SupportBracketNotation {
-			k = convertBracketToDot(k, options.Separator)
-		}
-		processedObj[k] = v
-	}
+	// Maps holding escaped numeric keys, by path, are never turned into arrays
+	var literals map[string]bool
 
 	// Process each key-value pair
-	for key, value := range processedObj {
-		parts := strings.Split(key, options.Separator)
-		assignToNested(result, parts, value, options)
+	for key, value := range obj {
+		if s, ok := value.(string); ok && options.CoerceTypes {
+			value = CoerceString(s)
+		}
+		parts, literal := splitKey(key, options)
+		parts, literal = limitDepth(parts, literal, options)
+		for i, isLiteral := range literal {
+			if isLiteral {
+				if literals == nil {
+					literals = make(map[string]bool)
+				}
+				literals[segmentsKey(parts[:i])] = true
+			}
+		}
+		assignToNested(result, parts, literal, value, options)
 	}
 
 	// Convert numeric maps to arrays
 	if options.DetectArrays {
-		return convertNumericMapsToArrays(result)
+		return convertNumericMapsToArrays(result, nil, literals)
 	}
 
 	return result
 }
 
+// numberPattern matches JSON number literals
+var numberPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// CoerceString parses a string holding a scalar, as read from .env or CSV sources:
+// "true" and "false" become booleans, "null" becomes nil, JSON integers become
+// int64 and other JSON numbers float64. Other strings are returned unchanged. A
+// leading backslash keeps a string literal: \true stays the string "true", and
+// \\x becomes \x; other backslashes are left alone.
+func CoerceString(s string) any {
+	if rest, ok := strings.CutPrefix(s, `\`); ok {
+		if _, scalar := coerceScalar(rest); scalar || strings.HasPrefix(rest, `\`) {
+			return rest
+		}
+		return s
+	}
+
+	if value, ok := coerceScalar(s); ok {
+		return value
+	}
+	return s
+}
+
+// coerceScalar parses a boolean, null or JSON number literal
+func coerceScalar(s string) (any, bool) {
+	switch s {
+	case "true":
+		return true, true
+	case "false":
+		return false, true
+	case "null":
+		return nil, true
+	}
+	if !numberPattern.MatchString(s) {
+		return nil, false
+	}
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i, true
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f, true
+	}
+	return nil, false
+}
+
+// SplitKey splits a flattened key into its segments, converting bracket notation
+// when it is supported. With an escape string, escaped separators and brackets
+// are kept inside their segment and the escapes removed; with EscapeQuote,
+// quoted segments are kept whole and unquoted.
+func SplitKey(key string, options UnflattenOptions) []string {
+	parts, _ := splitKey(key, options)
+	return parts
+}
+
+// splitKey splits a flattened key as SplitKey, also reporting which segments held
+// an escape: these are map keys, even when they are numeric
+func splitKey(key string, options UnflattenOptions) ([]string, []bool) {
+	if options.Escape == "" {
+		if options.SupportBracketNotation {
+			key = convertBracketToDot(key, options.Separator)
+		}
+		return strings.Split(key, options.Separator), nil
+	}
+	if options.EscapeStyle == EscapeQuote {
+		return splitQuotedKey(key, options)
+	}
+
+	var parts []string
+	var literal []bool
+	var part strings.Builder
+	escaped := false
+	for i := 0; i < len(key); {
+		rest := key[i:]
+		switch {
+		case strings.HasPrefix(rest, options.Escape) && len(rest) > len(options.Escape):
+			rest = rest[len(options.Escape):]
+			n := 1
+			if strings.HasPrefix(rest, options.Escape) {
+				n = len(options.Escape)
+			} else if strings.HasPrefix(rest, options.Separator) {
+				n = len(options.Separator)
+			}
+			part.WriteString(rest[:n])
+			escaped = true
+			i += len(options.Escape) + n
+		case strings.HasPrefix(rest, options.Separator):
+			parts, literal = append(parts, part.String()), append(literal, escaped)
+			part.Reset()
+			escaped = false
+			i += len(options.Separator)
+		case options.SupportBracketNotation && bracketIndexPattern.MatchString(rest):
+			index := bracketIndexPattern.FindString(rest)
+			parts, literal = append(parts, part.String()), append(literal, escaped)
+			part.Reset()
+			escaped = false
+			part.WriteString(index[1 : len(index)-1])
+			i += len(index)
+		default:
+			part.WriteByte(key[i])
+			i++
+		}
+	}
+	return append(parts, part.String()), append(literal, escaped)
+}
+
+// splitQuotedKey splits a flattened key written with EscapeQuote. A quote at the
+// start of a segment runs to the next single quote, doubled quotes standing for
+// one; quotes elsewhere are kept as they are.
+func splitQuotedKey(key string, options UnflattenOptions) ([]string, []bool) {
+	quote := options.Escape
+
+	var parts []string
+	var literal []bool
+	var part strings.Builder
+	quoted, start := false, true
+	for i := 0; i < len(key); {
+		rest := key[i:]
+		switch {
+		case start && strings.HasPrefix(rest, quote):
+			i += len(quote)
+			for i < len(key) {
+				if strings.HasPrefix(key[i:], quote+quote) {
+					part.WriteString(quote)
+					i += 2 * len(quote)
+					continue
+				}
+				if strings.HasPrefix(key[i:], quote) {
+					i += len(quote)
+					break
+				}
+				part.WriteByte(key[i])
+				i++
+			}
+			quoted, start = true, false
+		case strings.HasPrefix(rest, options.Separator):
+			parts, literal = append(parts, part.String()), append(literal, quoted)
+			part.Reset()
+			quoted, start = false, true
+			i += len(options.Separator)
+		case options.SupportBracketNotation && bracketIndexPattern.MatchString(rest):
+			index := bracketIndexPattern.FindString(rest)
+			parts, literal = append(parts, part.String()), append(literal, quoted)
+			part.Reset()
+			quoted, start = false, false
+			part.WriteString(index[1 : len(index)-1])
+			i += len(index)
+		default:
+			part.WriteByte(key[i])
+			start = false
+			i++
+		}
+	}
+	return append(parts, part.String()), append(literal, quoted)
+}
+
+// limitDepth joins the segments of a key beyond the depth limit back into a
+// single flattened key, escaping them again. A MaxDepth of 0 means no limit, as
+// for FlattenOptions, so zero-value options still unflatten.
+func limitDepth(parts []string, literal []bool, options UnflattenOptions) ([]string, []bool) {
+	if options.MaxDepth <= 0 || len(parts) <= options.MaxDepth+1 {
+		return parts, literal
+	}
+
+	rest := parts[options.MaxDepth:]
+	if literal != nil {
+		rest = make([]string, len(parts)-options.MaxDepth)
+		for i, part := range parts[options.MaxDepth:] {
+			rest[i] = part
+			if literal[options.MaxDepth+i] {
+				rest[i] = escapeKey(part, options.Separator, options.Escape, options.EscapeStyle)
+			}
+		}
+		literal = append(literal[:options.MaxDepth:options.MaxDepth], false)
+	}
+	return append(parts[:options.MaxDepth:options.MaxDepth], strings.Join(rest, options.Separator)), literal
+}
+
+// segmentsKey joins key segments into a path usable as a map key
+func segmentsKey(parts []string) string {
+	return strings.Join(parts, "\x00")
+}
+
+// bracketIndexPattern matches an array index in bracket notation at the start of a string
+var bracketIndexPattern = regexp.MustCompile(`^\[[0-9]+\]`)
+
 // convertBracketToDot converts "user[0].name" to "user.0.name"
 func convertBracketToDot(key, separator string) string {
 	re := regexp.MustCompile(`\[([0-9]+)\]`)
 	return re.ReplaceAllString(key, separator+"$1")
 }
 
-// assignToNested sets a value at a path in a nested structure
-func assignToNested(obj map[string]any, parts []string, value any, options UnflattenOptions) {
+// assignToNested sets a value at a path in a nested structure. Segments marked
+// literal are never taken as array indices.
+func assignToNested(obj map[string]any, parts []string, literal []bool, value any, options UnflattenOptions) {
 	if len(parts) == 0 {
 		return
 	}
@@ -79,7 +275,7 @@ func assignToNested(obj map[string]any, parts []string, value any, options Unfla
 	nextIsNumeric := false
 	nextIndex := -1
 
-	if options.DetectArrays {
+	if options.DetectArrays && (literal == nil || !literal[1]) {
 		if idx, err := strconv.Atoi(parts[1]); err == nil {
 			nextIsNumeric = true
 			nextIndex = idx
@@ -104,6 +300,13 @@ func assignToNested(obj map[string]any, parts []string, value any, options Unfla
 			arr = append(arr, nil)
 		}
 
+		// Leaf array element
+		if len(parts) == 2 {
+			arr[nextIndex] = value
+			obj[part] = arr
+			return
+		}
+
 		// Get or create map at the index
 		var nextObj map[string]any
 		if arr[nextIndex] == nil {
@@ -117,7 +320,7 @@ func assignToNested(obj map[string]any, parts []string, value any, options Unfla
 		}
 
 		obj[part] = arr
-		assignToNested(nextObj, parts[2:], value, options)
+		assignToNested(nextObj, parts[2:], tail(literal, 2), value, options)
 	} else {
 		// Handle object creation
 		var nextObj map[string]any
@@ -133,19 +336,29 @@ func assignToNested(obj map[string]any, parts []string, value any, options Unfla
 			obj[part] = nextObj
 		}
 
-		assignToNested(nextObj, parts[1:], value, options)
+		assignToNested(nextObj, parts[1:], tail(literal, 1), value, options)
+	}
+}
+
+// tail returns the literal marks of the segments after the first n, or nil
+func tail(literal []bool, n int) []bool {
+	if literal == nil {
+		return nil
 	}
+	return literal[n:]
 }
 
-// convertNumericMapsToArrays recursively converts maps with consecutive numeric keys to arrays
-func convertNumericMapsToArrays(obj map[string]any) map[string]any {
+// convertNumericMapsToArrays recursively converts maps with consecutive numeric keys
+// to arrays, except the maps whose path is in literals
+func convertNumericMapsToArrays(obj map[string]any, path []string, literals map[string]bool) map[string]any {
 	for key, value := range obj {
 		switch val := value.(type) {
 		case map[string]any:
-			processedMap := convertNumericMapsToArrays(val)
+			valPath := append(path[:len(path):len(path)], key)
+			processedMap := convertNumericMapsToArrays(val, valPath, literals)
 
 			// Check if this map should become an array
-			if shouldConvertToArray(processedMap) {
+			if !literals[segmentsKey(valPath)] && shouldConvertToArray(processedMap) {
 				obj[key] = convertMapToArray(processedMap)
 			} else {
 				obj[key] = processedMap
@@ -154,8 +367,9 @@ func convertNumericMapsToArrays(obj map[string]any) map[string]any {
 		case []any:
 			for i, item := range val {
 				if nestedMap, ok := item.(map[string]any); ok {
-					processedItem := convertNumericMapsToArrays(nestedMap)
-					if shouldConvertToArray(processedItem) {
+					itemPath := append(path[:len(path):len(path)], key, strconv.Itoa(i))
+					processedItem := convertNumericMapsToArrays(nestedMap, itemPath, literals)
+					if !literals[segmentsKey(itemPath)] && shouldConvertToArray(processedItem) {
 						val[i] = convertMapToArray(processedItem)
 					} else {
 						val[i] = processedItem
@@ -213,3 +427,18 @@ func convertMapToArray(m map[string]any) []any {
 
 	return arr
 }
+
+// IsFlat reports whether a document looks flattened: no value is a non-empty
+// object and at least one key holds the separator or an array index in brackets
+func IsFlat(obj map[string]any, separator string) bool {
+	joined := false
+	for key, value := range obj {
+		if m, ok := value.(map[string]any); ok && len(m) > 0 {
+			return false
+		}
+		if strings.Contains(key, separator) || strings.HasSuffix(key, "]") {
+			joined = true
+		}
+	}
+	return joined
+}
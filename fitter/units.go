@@ -0,0 +1,170 @@
+package fitter
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Unit parsing modes
+const (
+	UnitsBase  = "base"  // replace the value with a number in its base unit: seconds, bytes, or a fraction for %
+	UnitsSplit = "split" // replace the value with an object holding its number and unit as written
+)
+
+// Keys of the objects UnitsSplit writes
+const (
+	UnitValueKey = "value"
+	UnitUnitKey  = "unit"
+)
+
+// unit is a recognized value suffix: values in the base unit are the number
+// multiplied by mul and divided by div, keeping decimal fractions exact
+type unit struct {
+	mul float64
+	div float64
+}
+
+// units are the recognized suffixes: durations (base s), byte sizes with SI and
+// IEC multiples, including the bare K, M, G... of Kubernetes quantities (base B),
+// and percentages (base fraction). m is minutes, not milli.
+var units = map[string]unit{
+	"ns": {1, 1e9}, "us": {1, 1e6}, "µs": {1, 1e6}, "ms": {1, 1e3},
+	"s": {1, 1}, "m": {60, 1}, "h": {3600, 1}, "d": {86400, 1},
+
+	"B": {1, 1},
+	"k": {1e3, 1}, "K": {1e3, 1}, "kB": {1e3, 1}, "KB": {1e3, 1},
+	"M": {1e6, 1}, "MB": {1e6, 1}, "G": {1e9, 1}, "GB": {1e9, 1},
+	"T": {1e12, 1}, "TB": {1e12, 1}, "P": {1e15, 1}, "PB": {1e15, 1},
+	"E": {1e18, 1}, "EB": {1e18, 1},
+	"Ki": {1 << 10, 1}, "KiB": {1 << 10, 1}, "Mi": {1 << 20, 1}, "MiB": {1 << 20, 1},
+	"Gi": {1 << 30, 1}, "GiB": {1 << 30, 1}, "Ti": {1 << 40, 1}, "TiB": {1 << 40, 1},
+	"Pi": {1 << 50, 1}, "PiB": {1 << 50, 1}, "Ei": {1 << 60, 1}, "EiB": {1 << 60, 1},
+
+	"%": {1, 100},
+}
+
+// unitValue matches a number followed by a unit suffix
+var unitValue = regexp.MustCompile(`^([+-]?(?:\d+\.?\d*|\.\d+))\s*([A-Za-zµ%]+)$`)
+
+// UnitOptions configures ParseUnits
+type UnitOptions struct {
+	Mode            string   // "base" or "split"
+	KeyGlobs        []string // only parse values whose flattened key matches these patterns (all values when empty)
+	Separator       string   // joins key segments when matching KeyGlobs
+	ArrayFormatting string   // format of array indices in keys: "index" or "bracket"
+}
+
+// ValidateUnitOptions checks that a unit parsing is usable
+func ValidateUnitOptions(options UnitOptions) error {
+	if options.Mode != UnitsBase && options.Mode != UnitsSplit {
+		return fmt.Errorf("unknown unit mode '%s' (expected base or split)", options.Mode)
+	}
+	return ValidateKeyPatterns(options.KeyGlobs, nil)
+}
+
+// ParseUnits replaces the unit-suffixed text values of a document, such as "10s",
+// "512Mi", "1h30m" or "80%", with comparable numbers, in place, below every map
+// and array. Text with an unknown suffix is left alone. The changes are returned
+// sorted by flattened key.
+func ParseUnits(data map[string]any, options UnitOptions) ([]DiffChange, error) {
+	if err := ValidateUnitOptions(options); err != nil {
+		return nil, err
+	}
+
+	p := unitParser{
+		options: options,
+		keys:    newKeyMatcher(options.KeyGlobs, options.Separator),
+		changes: []DiffChange{},
+	}
+	p.object(data, "")
+
+	sort.Slice(p.changes, func(i, j int) bool { return p.changes[i].Key < p.changes[j].Key })
+	return p.changes, nil
+}
+
+// ParseUnitValue reads a unit-suffixed text as its number and unit as written,
+// and its number in the base unit. Compound durations such as 1h30m are read in
+// seconds.
+func ParseUnitValue(text string) (number float64, suffix string, base float64, ok bool) {
+	text = strings.TrimSpace(text)
+	if match := unitValue.FindStringSubmatch(text); match != nil {
+		if u, known := units[match[2]]; known {
+			number, err := strconv.ParseFloat(match[1], 64)
+			if err == nil {
+				return number, match[2], number * u.mul / u.div, true
+			}
+		}
+	}
+	if d, err := time.ParseDuration(text); err == nil && strings.ContainsAny(text, "hms") {
+		return d.Seconds(), "s", d.Seconds(), true
+	}
+	return 0, "", 0, false
+}
+
+// unitParser walks a document, building flattened keys like FlattenMapWithOptions
+type unitParser struct {
+	options UnitOptions
+	keys    *keyMatcher
+	changes []DiffChange
+}
+
+func (p *unitParser) object(obj map[string]any, prefix string) {
+	for key, value := range obj {
+		fullKey := key
+		if prefix != "" {
+			fullKey = prefix + p.options.Separator + key
+		}
+		obj[key] = p.value(value, fullKey)
+	}
+}
+
+func (p *unitParser) array(arr []any, prefix string) {
+	for i, item := range arr {
+		var indexedKey string
+		if p.options.ArrayFormatting == "bracket" {
+			indexedKey = fmt.Sprintf("%s[%d]", prefix, i)
+		} else {
+			indexedKey = prefix + p.options.Separator + strconv.Itoa(i)
+		}
+		arr[i] = p.value(item, indexedKey)
+	}
+}
+
+// value returns a value with its unit parsed, recording the change
+func (p *unitParser) value(value any, key string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		p.object(v, key)
+	case []any:
+		p.array(v, key)
+	case string:
+		if len(p.options.KeyGlobs) > 0 && !p.keys.match(key) {
+			return v
+		}
+		number, suffix, base, ok := ParseUnitValue(v)
+		if !ok {
+			return v
+		}
+		parsed := unitNumber(base)
+		if p.options.Mode == UnitsSplit {
+			parsed = map[string]any{UnitValueKey: unitNumber(number), UnitUnitKey: suffix}
+		}
+		p.changes = append(p.changes, DiffChange{Key: key, Old: v, New: parsed})
+		return parsed
+	}
+	return value
+}
+
+// unitNumber returns whole numbers as int64, so that byte sizes are not written
+// in exponent notation
+func unitNumber(number float64) any {
+	if number == math.Trunc(number) && math.Abs(number) < 1<<63 {
+		return int64(number)
+	}
+	return number
+}
@@ -0,0 +1,203 @@
+package i18n
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Finding kinds
+const (
+	FindingMissing = "missing"
+	FindingUnused  = "unused"
+)
+
+// Finding is a missing or unused key tied to a source or locale file location
+type Finding struct {
+	Kind    string `json:"kind"`
+	Key     string `json:"key"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+// BuildFindings locates missing keys at their source usages and unused keys at
+// their definition in the locale files
+func BuildFindings(missing, unused []string, usages map[string][]KeyUsage, jsonPath, separator string) ([]Finding, error) {
+	var findings []Finding
+
+	for _, key := range missing {
+		message := fmt.Sprintf("i18n key '%s' is used but missing from translations", key)
+		locations := usages[key]
+		if len(locations) == 0 {
+			findings = append(findings, Finding{Kind: FindingMissing, Key: key, Message: message})
+			continue
+		}
+		for _, location := range locations {
+			findings = append(findings, Finding{
+				Kind:    FindingMissing,
+				Key:     key,
+				File:    location.File,
+				Line:    location.Line,
+				Message: message,
+			})
+		}
+	}
+
+	if len(unused) == 0 {
+		return findings, nil
+	}
+
+	files, err := listJSONFiles(jsonPath)
+	if err != nil {
+		return nil, err
+	}
+
+	contents := make(map[string][]byte, len(files))
+	for _, file := range files {
+		if contents[file], err = os.ReadFile(file); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", file, err)
+		}
+	}
+
+	for _, key := range unused {
+		finding := Finding{
+			Kind:    FindingUnused,
+			Key:     key,
+			Message: fmt.Sprintf("i18n key '%s' is defined but never used in source", key),
+		}
+		for _, file := range files {
+			if line := FindKeyLine(contents[file], key, separator); line > 0 {
+				finding.File, finding.Line = file, line
+				break
+			}
+		}
+		findings = append(findings, finding)
+	}
+
+	return findings, nil
+}
+
+// Pattern to match a JSON object key with its colon
+var jsonKeyPattern = regexp.MustCompile(`"(?:[^"\\]|\\.)*"\s*:`)
+
+// FindKeyLine returns the 1-based line where a flattened key is defined in JSON
+// content, by locating each key segment in turn, or 0 when not found
+func FindKeyLine(content []byte, keyPath, separator string) int {
+	offset := 0
+	for _, segment := range splitKeyPath(keyPath, separator) {
+		end := findJSONKey(content[offset:], segment)
+		if end < 0 {
+			return 0
+		}
+		offset += end
+	}
+	return bytes.Count(content[:offset], []byte("\n")) + 1
+}
+
+// findJSONKey returns the offset past the colon of the first object key equal
+// to key in JSON content, or -1 when not found
+func findJSONKey(content []byte, key string) int {
+	offset := 0
+	for {
+		loc := jsonKeyPattern.FindIndex(content[offset:])
+		if loc == nil {
+			return -1
+		}
+		match := content[offset+loc[0] : offset+loc[1]]
+		offset += loc[1]
+
+		var name string
+		quoted := bytes.TrimRight(match[:len(match)-1], " \t\r\n")
+		if json.Unmarshal(quoted, &name) == nil && name == key {
+			return offset
+		}
+	}
+}
+
+// WriteGitHubAnnotations writes findings as GitHub Actions workflow commands
+func WriteGitHubAnnotations(w io.Writer, findings []Finding) error {
+	for _, finding := range findings {
+		level, title := "error", "Missing i18n key"
+		if finding.Kind == FindingUnused {
+			level, title = "warning", "Unused i18n key"
+		}
+
+		var props []string
+		if finding.File != "" {
+			props = append(props, "file="+escapeWorkflowProperty(finding.File))
+			if finding.Line > 0 {
+				props = append(props, fmt.Sprintf("line=%d", finding.Line))
+			}
+		}
+		props = append(props, "title="+escapeWorkflowProperty(title))
+
+		if _, err := fmt.Fprintf(w, "::%s %s::%s\n", level, strings.Join(props, ","), escapeWorkflowData(finding.Message)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type codeClimateIssue struct {
+	Type        string              `json:"type"`
+	CheckName   string              `json:"check_name"`
+	Description string              `json:"description"`
+	Categories  []string            `json:"categories"`
+	Severity    string              `json:"severity"`
+	Fingerprint string              `json:"fingerprint"`
+	Location    codeClimateLocation `json:"location"`
+}
+
+type codeClimateLocation struct {
+	Path  string `json:"path"`
+	Lines struct {
+		Begin int `json:"begin"`
+	} `json:"lines"`
+}
+
+// WriteCodeClimate writes findings as a Code Climate / GitLab code quality report
+func WriteCodeClimate(w io.Writer, findings []Finding) error {
+	issues := make([]codeClimateIssue, 0, len(findings))
+	for _, finding := range findings {
+		issue := codeClimateIssue{
+			Type:        "issue",
+			CheckName:   "i18n-" + finding.Kind + "-key",
+			Description: finding.Message,
+			Categories:  []string{"Bug Risk"},
+			Severity:    "major",
+		}
+		if finding.Kind == FindingUnused {
+			issue.Categories = []string{"Clarity"}
+			issue.Severity = "minor"
+		}
+
+		issue.Location.Path = finding.File
+		issue.Location.Lines.Begin = max(finding.Line, 1)
+
+		sum := md5.Sum([]byte(finding.Kind + "\x00" + finding.Key + "\x00" + finding.File))
+		issue.Fingerprint = hex.EncodeToString(sum[:])
+
+		issues = append(issues, issue)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(issues)
+}
+
+// escapeWorkflowData escapes a workflow command message
+func escapeWorkflowData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeWorkflowProperty escapes a workflow command property value
+func escapeWorkflowProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,64 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindKeyLine(t *testing.T) {
+	content := []byte(`{
+  "title": "Home",
+  "buttons": {
+    "title": "Buttons",
+    "note": "say \"ok\": later",
+    "ok": "OK",
+    "a<b": "escaped \u003c",
+    "say \"hi\"": "quoted"
+  }
+}`)
+
+	tests := map[string]int{
+		"title":            2,
+		"buttons.title":    4,
+		"buttons.ok":       6,
+		"buttons.a<b":      7,
+		`buttons.say "hi"`: 8,
+		"missing.key":      0,
+	}
+
+	for key, expected := range tests {
+		if line := FindKeyLine(content, key, "."); line != expected {
+			t.Fatalf("FindKeyLine(%s) = %d, expected %d", key, line, expected)
+		}
+	}
+}
+
+func TestBuildFindingsAndAnnotations(t *testing.T) {
+	tmpDir := t.TempDir()
+	jsonFile := filepath.Join(tmpDir, "en.json")
+	if err := os.WriteFile(jsonFile, []byte("{\n  \"old\": {\n    \"key\": \"x\"\n  }\n}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	usages := map[string][]KeyUsage{
+		"new.key": {{File: "src/app.js", Line: 12}},
+	}
+
+	findings, err := BuildFindings([]string{"new.key"}, []string{"old.key"}, usages, jsonFile, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	if err := WriteGitHubAnnotations(&b, findings); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "::error file=src/app.js,line=12,title=Missing i18n key::i18n key 'new.key' is used but missing from translations\n" +
+		"::warning file=" + escapeWorkflowProperty(jsonFile) + ",line=3,title=Unused i18n key::i18n key 'old.key' is defined but never used in source\n"
+	if b.String() != expected {
+		t.Fatalf("Expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+}
@@ -0,0 +1,222 @@
+package i18n
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/haiyon/fitobj/utils"
+)
+
+// AuditRecord summarizes the i18n health of a project at a point in time
+type AuditRecord struct {
+	Time       time.Time          `json:"time"`
+	SourceKeys int                `json:"sourceKeys"`
+	JSONKeys   int                `json:"jsonKeys"`
+	Missing    int                `json:"missing"`
+	Unused     int                `json:"unused"`
+	Coverage   map[string]float64 `json:"coverage"` // locale -> share of source keys translated (0-100)
+}
+
+// historyFile is the audit history file name within the history directory
+const historyFile = "audit.jsonl"
+
+// RunAudit computes audit metrics for a source directory and locale directory
+func RunAudit(sourceDir, jsonPath string) (*AuditRecord, error) {
+	return RunAuditWithOptions(sourceDir, jsonPath, DefaultExtractOptions())
+}
+
+// RunAuditWithOptions computes audit metrics, scanning sources for the configured
+// function calls
+func RunAuditWithOptions(sourceDir, jsonPath string, options ExtractOptions) (*AuditRecord, error) {
+	sourceKeys, err := ExtractKeysFromDirWithOptions(sourceDir, options)
+	if err != nil {
+		return nil, fmt.Errorf("extracting keys from source: %v", err)
+	}
+
+	jsonKeys, err := ExtractKeysFromJSONDir(jsonPath)
+	if err != nil {
+		return nil, fmt.Errorf("extracting keys from JSON: %v", err)
+	}
+
+	missing, unused := CompareKeys(sourceKeys, jsonKeys)
+
+	record := &AuditRecord{
+		Time:       time.Now().UTC(),
+		SourceKeys: len(sourceKeys),
+		JSONKeys:   len(jsonKeys),
+		Missing:    len(missing),
+		Unused:     len(unused),
+		Coverage:   make(map[string]float64),
+	}
+
+	files, err := listJSONFiles(jsonPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		localeKeys, err := ExtractKeysFromJSON(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", file, err)
+		}
+
+		locale := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		record.Coverage[locale] = coverage(sourceKeys, localeKeys)
+	}
+
+	return record, nil
+}
+
+// coverage returns the percentage of source keys present in a locale
+func coverage(sourceKeys, localeKeys map[string]bool) float64 {
+	if len(sourceKeys) == 0 {
+		return 100
+	}
+
+	present := 0
+	for key := range sourceKeys {
+		if localeKeys[key] {
+			present++
+		}
+	}
+
+	return float64(present) * 100 / float64(len(sourceKeys))
+}
+
+// AppendHistory appends an audit record to the history directory
+func AppendHistory(historyDir string, record *AuditRecord) error {
+	if err := utils.EnsureDirectoryExists(historyDir); err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %v", err)
+	}
+
+	file, err := os.OpenFile(filepath.Join(historyDir, historyFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %v", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write history file: %v", err)
+	}
+
+	return nil
+}
+
+// LoadHistory reads all audit records from the history directory in time order
+func LoadHistory(historyDir string) ([]AuditRecord, error) {
+	file, err := os.Open(filepath.Join(historyDir, historyFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file: %v", err)
+	}
+	defer file.Close()
+
+	var records []AuditRecord
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var record AuditRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			return nil, fmt.Errorf("invalid history record on line %d: %v", lineNo, err)
+		}
+		records = append(records, record)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file: %v", err)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Time.Before(records[j].Time) })
+	return records, nil
+}
+
+// WriteTrend renders the last n audit records as a table with changes between runs
+func WriteTrend(w io.Writer, records []AuditRecord, n int) error {
+	if n > 0 && len(records) > n {
+		records = records[len(records)-n:]
+	}
+
+	localeSet := make(map[string]bool)
+	for _, record := range records {
+		for locale := range record.Coverage {
+			localeSet[locale] = true
+		}
+	}
+	locales := make([]string, 0, len(localeSet))
+	for locale := range localeSet {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+
+	header := fmt.Sprintf("%-20s %10s %10s", "time", "missing", "unused")
+	for _, locale := range locales {
+		header += fmt.Sprintf(" %8s", locale)
+	}
+	if _, err := fmt.Fprintln(w, header); err != nil {
+		return err
+	}
+
+	for i, record := range records {
+		row := fmt.Sprintf("%-20s %10s %10s", record.Time.Format("2006-01-02 15:04"),
+			withDelta(record.Missing, records, i, func(r AuditRecord) int { return r.Missing }),
+			withDelta(record.Unused, records, i, func(r AuditRecord) int { return r.Unused }))
+		for _, locale := range locales {
+			if value, ok := record.Coverage[locale]; ok {
+				row += fmt.Sprintf(" %7.1f%%", value)
+			} else {
+				row += fmt.Sprintf(" %8s", "-")
+			}
+		}
+		if _, err := fmt.Fprintln(w, row); err != nil {
+			return err
+		}
+	}
+
+	if len(records) >= 2 {
+		first, last := records[0], records[len(records)-1]
+		trend := "stable"
+		switch {
+		case last.Missing+last.Unused < first.Missing+first.Unused:
+			trend = "improving"
+		case last.Missing+last.Unused > first.Missing+first.Unused:
+			trend = "degrading"
+		}
+		if _, err := fmt.Fprintf(w, "\nTrend over %d runs: %s (missing %+d, unused %+d)\n",
+			len(records), trend, last.Missing-first.Missing, last.Unused-first.Unused); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// withDelta formats a value with its change from the previous record
+func withDelta(value int, records []AuditRecord, i int, field func(AuditRecord) int) string {
+	if i == 0 {
+		return fmt.Sprint(value)
+	}
+	delta := value - field(records[i-1])
+	if delta == 0 {
+		return fmt.Sprint(value)
+	}
+	return fmt.Sprintf("%d(%+d)", value, delta)
+}
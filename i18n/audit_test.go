@@ -0,0 +1,67 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/haiyon/fitobj/utils"
+)
+
+func TestAuditHistory(t *testing.T) {
+	tmpDir := t.TempDir()
+	sourceDir := filepath.Join(tmpDir, "src")
+	jsonDir := filepath.Join(tmpDir, "locales")
+	historyDir := filepath.Join(tmpDir, ".fitobj-history")
+
+	if err := os.MkdirAll(sourceDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sourceDir, "app.js"), []byte(`t('a'); t('b'); t('c'); t('d')`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := utils.WriteJSONFile(filepath.Join(jsonDir, "en.json"), map[string]any{"a": "A", "b": "B", "c": "C", "z": "Z"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := utils.WriteJSONFile(filepath.Join(jsonDir, "de.json"), map[string]any{"a": "A"}); err != nil {
+		t.Fatal(err)
+	}
+
+	record, err := RunAudit(sourceDir, jsonDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if record.Missing != 1 || record.Unused != 1 || record.Coverage["en"] != 75 || record.Coverage["de"] != 25 {
+		t.Fatalf("Unexpected audit record: %+v", record)
+	}
+
+	earlier := *record
+	earlier.Time = record.Time.Add(-24 * time.Hour)
+	earlier.Missing = 5
+
+	if err := AppendHistory(historyDir, record); err != nil {
+		t.Fatal(err)
+	}
+	if err := AppendHistory(historyDir, &earlier); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := LoadHistory(historyDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0].Missing != 5 {
+		t.Fatalf("Expected 2 records in time order, got %+v", records)
+	}
+
+	var b strings.Builder
+	if err := WriteTrend(&b, records, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), "1(-4)") || !strings.Contains(b.String(), "improving") {
+		t.Fatalf("Unexpected trend report:\n%s", b.String())
+	}
+}
@@ -0,0 +1,151 @@
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Budgets maps flattened keys or glob patterns to a max display width
+type Budgets map[string]int
+
+// BudgetIssue describes a translation that exceeds its display budget
+type BudgetIssue struct {
+	File   string `json:"file"`
+	Locale string `json:"locale"`
+	Key    string `json:"key"`
+	Width  int    `json:"width"`
+	Budget int    `json:"budget"`
+}
+
+// LoadBudgets reads a budget JSON file mapping keys or key globs to max widths
+func LoadBudgets(filePath string) (Budgets, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read budget file: %v", err)
+	}
+
+	budgets := make(Budgets)
+	if len(data) == 0 {
+		return budgets, nil
+	}
+
+	if err := json.Unmarshal(data, &budgets); err != nil {
+		return nil, fmt.Errorf("failed to parse budget file: %v", err)
+	}
+
+	return budgets, nil
+}
+
+// Lookup returns the budget for a key: metadata max length first, then an exact
+// budget entry, then the most specific matching glob
+func (b Budgets) Lookup(key string, metadata Metadata, separator string) (int, bool) {
+	if meta, ok := metadata[key]; ok && meta.MaxLength > 0 {
+		return meta.MaxLength, true
+	}
+	if budget, ok := b[key]; ok {
+		return budget, true
+	}
+
+	best, bestLen := 0, -1
+	for pattern, budget := range b {
+		if len(pattern) > bestLen && matchesAnyPattern(key, []string{pattern}, separator) {
+			best, bestLen = budget, len(pattern)
+		}
+	}
+	return best, bestLen >= 0
+}
+
+// CheckBudgets reports translations in a locale directory exceeding their budget
+func CheckBudgets(jsonDir string, budgets Budgets, metadata Metadata, separator string) ([]BudgetIssue, error) {
+	files, err := listJSONFiles(jsonDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []BudgetIssue
+	for _, file := range files {
+		flat, err := readFlatJSON(file, separator)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", file, err)
+		}
+
+		locale := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		for _, key := range sortedStringKeys(flat) {
+			budget, ok := budgets.Lookup(key, metadata, separator)
+			if !ok || budget <= 0 {
+				continue
+			}
+
+			if width := DisplayWidth(flat[key].(string), locale); width > budget {
+				issues = append(issues, BudgetIssue{
+					File:   file,
+					Locale: locale,
+					Key:    key,
+					Width:  width,
+					Budget: budget,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Width-issues[i].Budget > issues[j].Width-issues[j].Budget
+	})
+
+	return issues, nil
+}
+
+// DisplayWidth estimates the rendered width of a value in character cells.
+// Markup is ignored, combining marks take no space, East Asian wide characters
+// take two cells, and ambiguous-width characters are wide in CJK locales.
+func DisplayWidth(value, locale string) int {
+	// Markup tags, matched as lint does, take no display space
+	value = tagPattern.ReplaceAllString(value, "")
+	cjk := isCJKLocale(locale)
+
+	width := 0
+	for _, r := range value {
+		switch {
+		case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+			// zero width
+		case isWideRune(r):
+			width += 2
+		case cjk && isAmbiguousRune(r):
+			width += 2
+		default:
+			width++
+		}
+	}
+	return width
+}
+
+// isCJKLocale reports whether a locale renders ambiguous-width characters wide
+func isCJKLocale(locale string) bool {
+	lang := strings.ToLower(locale)
+	if i := strings.IndexAny(lang, "-_"); i >= 0 {
+		lang = lang[:i]
+	}
+	return lang == "zh" || lang == "ja" || lang == "ko"
+}
+
+// isWideRune reports whether a rune is East Asian wide or fullwidth
+func isWideRune(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hangul, unicode.Hiragana, unicode.Katakana) ||
+		(r >= 0x3000 && r <= 0x303F) || // CJK symbols and punctuation
+		(r >= 0xFF01 && r <= 0xFF60) || // fullwidth forms
+		(r >= 0xFFE0 && r <= 0xFFE6) ||
+		(r >= 0x1F300 && r <= 0x1FAFF) // emoji
+}
+
+// isAmbiguousRune reports whether a rune has ambiguous East Asian width
+func isAmbiguousRune(r rune) bool {
+	return unicode.In(r, unicode.Greek, unicode.Cyrillic) ||
+		(r >= 0x2010 && r <= 0x2027) || // dashes, quotes, ellipsis
+		(r >= 0x2460 && r <= 0x24FF) || // enclosed alphanumerics
+		(r >= 0x25A0 && r <= 0x25FF) // geometric shapes
+}
@@ -0,0 +1,64 @@
+package i18n
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/haiyon/fitobj/utils"
+)
+
+func TestDisplayWidth(t *testing.T) {
+	tests := []struct {
+		value    string
+		locale   string
+		expected int
+	}{
+		{"Save", "en", 4},
+		{"<b>Save</b>", "en", 4},
+		{"<br/>Save", "en", 4},
+		{"1 < 2 > 0", "en", 9},
+		{"保存", "zh", 4},
+		{"é", "fr", 1},
+		{"Привет", "ru", 6},
+		{"Привет", "ja", 12},
+	}
+
+	for _, test := range tests {
+		if width := DisplayWidth(test.value, test.locale); width != test.expected {
+			t.Fatalf("DisplayWidth(%q, %s) = %d, expected %d", test.value, test.locale, width, test.expected)
+		}
+	}
+}
+
+func TestCheckBudgets(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string]map[string]any{
+		"en.json": {"buttons": map[string]any{"save": "Save", "cancel": "Cancel"}},
+		"de.json": {"buttons": map[string]any{"save": "Speichern", "cancel": "Abbrechen"}},
+		"ja.json": {"buttons": map[string]any{"save": "保存する", "cancel": "取消"}},
+	}
+	for name, content := range files {
+		if err := utils.WriteJSONFile(filepath.Join(tmpDir, name), content); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	budgets := Budgets{"buttons.*": 8}
+	metadata := Metadata{"buttons.cancel": {MaxLength: 10}}
+
+	issues, err := CheckBudgets(tmpDir, budgets, metadata, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := make(map[string]int)
+	for _, issue := range issues {
+		found[issue.Locale+":"+issue.Key] = issue.Budget
+	}
+
+	expected := map[string]int{"de:buttons.save": 8}
+	if len(found) != len(expected) || found["de:buttons.save"] != 8 {
+		t.Fatalf("Expected %v, got %v", expected, found)
+	}
+}
@@ -0,0 +1,255 @@
+package i18n
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/haiyon/fitobj/fitter"
+	"github.com/haiyon/fitobj/utils"
+)
+
+// BundleOptions configures the bundle size analysis
+type BundleOptions struct {
+	Separator   string                // separator for flattened keys
+	Top         int                   // number of entries listed per section (default: 10)
+	Usages      map[string][]KeyUsage // key usage locations from source (optional)
+	EntryPoints map[string]string     // entry-point name -> source directory (requires Usages)
+}
+
+// SizeEntry reports the size of a top-level namespace
+type SizeEntry struct {
+	Name  string `json:"name"`
+	Bytes int    `json:"bytes"`
+	Keys  int    `json:"keys"`
+}
+
+// ValueEntry reports the length of a single value
+type ValueEntry struct {
+	Key    string `json:"key"`
+	Length int    `json:"length"`
+}
+
+// DuplicateValue reports a value repeated under several keys
+type DuplicateValue struct {
+	Value string   `json:"value"`
+	Keys  []string `json:"keys"`
+}
+
+// Savings estimates the size reduction of a proposed cleanup
+type Savings struct {
+	Keys      int `json:"keys"`
+	Bytes     int `json:"bytes"`
+	GzipBytes int `json:"gzipBytes"`
+}
+
+// EntryPointReport reports keys an entry point ships but never uses
+type EntryPointReport struct {
+	Name    string  `json:"name"`
+	Dir     string  `json:"dir"`
+	Used    int     `json:"used"`
+	Savings Savings `json:"savings"`
+}
+
+// BundleReport summarizes the size profile of a locale bundle
+type BundleReport struct {
+	File          string             `json:"file"`
+	Keys          int                `json:"keys"`
+	Bytes         int                `json:"bytes"`
+	GzipBytes     int                `json:"gzipBytes"`
+	Namespaces    []SizeEntry        `json:"namespaces"`
+	LongestValues []ValueEntry       `json:"longestValues"`
+	Duplicates    []DuplicateValue   `json:"duplicates"`
+	DuplicateSave Savings            `json:"duplicateSavings"`
+	Unused        []string           `json:"unused,omitempty"`
+	UnusedSave    Savings            `json:"unusedSavings"`
+	EntryPoints   []EntryPointReport `json:"entryPoints,omitempty"`
+}
+
+// AnalyzeBundle reports namespace sizes, long and duplicate values, and the estimated
+// savings of removing unused keys from a locale bundle
+func AnalyzeBundle(filePath string, options BundleOptions) (*BundleReport, error) {
+	if options.Separator == "" {
+		options.Separator = "."
+	}
+	if options.Top <= 0 {
+		options.Top = 10
+	}
+
+	data, err := utils.ReadJSONFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	flattenOpts := fitter.DefaultFlattenOptions()
+	flattenOpts.Separator = options.Separator
+	flat := fitter.FlattenMapWithOptions(data, "", flattenOpts)
+
+	full, gz, err := bundleSize(flat, options.Separator)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &BundleReport{
+		File:      filePath,
+		Keys:      len(flat),
+		Bytes:     full,
+		GzipBytes: gz,
+	}
+
+	// Largest namespaces
+	for name, value := range data {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode namespace %s: %v", name, err)
+		}
+		keys := 1
+		if nested, ok := value.(map[string]any); ok {
+			keys = len(fitter.FlattenMapWithOptions(nested, "", flattenOpts))
+		}
+		report.Namespaces = append(report.Namespaces, SizeEntry{Name: name, Bytes: len(encoded), Keys: keys})
+	}
+	sort.Slice(report.Namespaces, func(i, j int) bool {
+		if report.Namespaces[i].Bytes != report.Namespaces[j].Bytes {
+			return report.Namespaces[i].Bytes > report.Namespaces[j].Bytes
+		}
+		return report.Namespaces[i].Name < report.Namespaces[j].Name
+	})
+	report.Namespaces = truncate(report.Namespaces, options.Top)
+
+	// Longest values and duplicates
+	byValue := make(map[string][]string)
+	for _, key := range sortedStringKeys(flat) {
+		value := flat[key].(string)
+		report.LongestValues = append(report.LongestValues, ValueEntry{Key: key, Length: utf8.RuneCountInString(value)})
+		byValue[value] = append(byValue[value], key)
+	}
+	sort.SliceStable(report.LongestValues, func(i, j int) bool {
+		return report.LongestValues[i].Length > report.LongestValues[j].Length
+	})
+	report.LongestValues = truncate(report.LongestValues, options.Top)
+
+	redundant := make(map[string]bool)
+	for value, keys := range byValue {
+		if len(keys) > 1 && value != "" {
+			report.Duplicates = append(report.Duplicates, DuplicateValue{Value: value, Keys: keys})
+			for _, key := range keys[1:] {
+				redundant[key] = true
+			}
+		}
+	}
+	sort.Slice(report.Duplicates, func(i, j int) bool {
+		wi := len(report.Duplicates[i].Value) * (len(report.Duplicates[i].Keys) - 1)
+		wj := len(report.Duplicates[j].Value) * (len(report.Duplicates[j].Keys) - 1)
+		if wi != wj {
+			return wi > wj
+		}
+		return report.Duplicates[i].Value < report.Duplicates[j].Value
+	})
+	if report.DuplicateSave, err = estimateSavings(flat, redundant, full, gz, options.Separator); err != nil {
+		return nil, err
+	}
+	report.Duplicates = truncate(report.Duplicates, options.Top)
+
+	if options.Usages == nil {
+		return report, nil
+	}
+
+	// Globally unused keys
+	unused := make(map[string]bool)
+	for key := range flat {
+		if _, used := options.Usages[key]; !used {
+			unused[key] = true
+			report.Unused = append(report.Unused, key)
+		}
+	}
+	sort.Strings(report.Unused)
+	if report.UnusedSave, err = estimateSavings(flat, unused, full, gz, options.Separator); err != nil {
+		return nil, err
+	}
+
+	// Keys shipped to each entry point but never used by it
+	names := make([]string, 0, len(options.EntryPoints))
+	for name := range options.EntryPoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		dir := options.EntryPoints[name]
+		used := UsedKeysUnder(options.Usages, dir)
+
+		drop := make(map[string]bool)
+		for key := range flat {
+			if !used[key] {
+				drop[key] = true
+			}
+		}
+
+		savings, err := estimateSavings(flat, drop, full, gz, options.Separator)
+		if err != nil {
+			return nil, err
+		}
+		report.EntryPoints = append(report.EntryPoints, EntryPointReport{
+			Name:    name,
+			Dir:     dir,
+			Used:    len(flat) - len(drop),
+			Savings: savings,
+		})
+	}
+
+	return report, nil
+}
+
+// estimateSavings measures the bundle size with the given keys removed
+func estimateSavings(flat map[string]any, drop map[string]bool, full, gz int, separator string) (Savings, error) {
+	if len(drop) == 0 {
+		return Savings{}, nil
+	}
+
+	remaining := make(map[string]any, len(flat))
+	for key, value := range flat {
+		if !drop[key] {
+			remaining[key] = value
+		}
+	}
+
+	size, gzSize, err := bundleSize(remaining, separator)
+	if err != nil {
+		return Savings{}, err
+	}
+
+	return Savings{Keys: len(drop), Bytes: full - size, GzipBytes: gz - gzSize}, nil
+}
+
+// bundleSize returns the raw and gzip-compressed size of the nested bundle
+func bundleSize(flat map[string]any, separator string) (int, int, error) {
+	options := fitter.DefaultUnflattenOptions()
+	options.Separator = separator
+	options.SupportBracketNotation = false
+
+	encoded, err := json.Marshal(fitter.UnflattenMapWithOptions(flat, options))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to encode bundle: %v", err)
+	}
+
+	var buf bytes.Buffer
+	writer, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	writer.Write(encoded)
+	if err := writer.Close(); err != nil {
+		return 0, 0, fmt.Errorf("failed to compress bundle: %v", err)
+	}
+
+	return len(encoded), buf.Len(), nil
+}
+
+// truncate limits a slice to at most n entries
+func truncate[T any](items []T, n int) []T {
+	if len(items) > n {
+		return items[:n]
+	}
+	return items
+}
@@ -0,0 +1,71 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/haiyon/fitobj/utils"
+)
+
+func TestAnalyzeBundle(t *testing.T) {
+	tmpDir := t.TempDir()
+	bundlePath := filepath.Join(tmpDir, "en.json")
+
+	content := map[string]any{
+		"admin": map[string]any{"title": "Administration dashboard", "save": "Save"},
+		"shop":  map[string]any{"title": "Shop", "save": "Save", "legacy": "Old checkout flow"},
+	}
+	if err := utils.WriteJSONFile(bundlePath, content); err != nil {
+		t.Fatal(err)
+	}
+
+	sources := map[string]string{
+		"admin/page.js": `t('admin.title'); t('admin.save')`,
+		"shop/page.js":  "t('shop.title')\nt('shop.save')",
+	}
+	for name, source := range sources {
+		path := filepath.Join(tmpDir, "src", name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	usages, err := ExtractKeyUsagesFromDir(filepath.Join(tmpDir, "src"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loc := usages["shop.save"]; len(loc) != 1 || loc[0].Line != 2 {
+		t.Fatalf("Expected shop.save on line 2, got %v", loc)
+	}
+
+	report, err := AnalyzeBundle(bundlePath, BundleOptions{
+		Usages:      usages,
+		EntryPoints: map[string]string{"admin": filepath.Join(tmpDir, "src", "admin")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Keys != 5 {
+		t.Fatalf("Expected 5 keys, got %d", report.Keys)
+	}
+	if report.Namespaces[0].Name != "shop" || report.Namespaces[0].Keys != 3 {
+		t.Fatalf("Expected shop to be the largest namespace, got %v", report.Namespaces)
+	}
+	if report.LongestValues[0].Key != "admin.title" {
+		t.Fatalf("Expected admin.title to be the longest value, got %v", report.LongestValues)
+	}
+	if len(report.Duplicates) != 1 || report.Duplicates[0].Value != "Save" || report.DuplicateSave.Keys != 1 {
+		t.Fatalf("Expected one duplicate 'Save', got %v", report.Duplicates)
+	}
+	if len(report.Unused) != 1 || report.Unused[0] != "shop.legacy" || report.UnusedSave.Bytes <= 0 {
+		t.Fatalf("Expected shop.legacy to be unused with savings, got %v %v", report.Unused, report.UnusedSave)
+	}
+	if len(report.EntryPoints) != 1 || report.EntryPoints[0].Used != 2 || report.EntryPoints[0].Savings.Keys != 3 {
+		t.Fatalf("Unexpected entry point report: %v", report.EntryPoints)
+	}
+}
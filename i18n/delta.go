@@ -0,0 +1,85 @@
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+)
+
+// LocaleDelta holds the keys added or changed in a locale between two releases
+type LocaleDelta struct {
+	Locale  string         `json:"locale"`
+	Values  map[string]any `json:"values"`
+	Added   []string       `json:"added"`
+	Changed []string       `json:"changed"`
+	Removed []string       `json:"removed"`
+}
+
+// ComputeDelta compares two flattened bundles and returns the added and changed
+// values of the new bundle along with the keys removed from the old one
+func ComputeDelta(oldFlat, newFlat map[string]any) LocaleDelta {
+	delta := LocaleDelta{
+		Values:  make(map[string]any),
+		Added:   []string{},
+		Changed: []string{},
+		Removed: []string{},
+	}
+
+	for key, value := range newFlat {
+		oldValue, exists := oldFlat[key]
+		switch {
+		case !exists:
+			delta.Added = append(delta.Added, key)
+		case !reflect.DeepEqual(oldValue, value):
+			delta.Changed = append(delta.Changed, key)
+		default:
+			continue
+		}
+		delta.Values[key] = value
+	}
+
+	for key := range oldFlat {
+		if _, exists := newFlat[key]; !exists {
+			delta.Removed = append(delta.Removed, key)
+		}
+	}
+
+	sort.Strings(delta.Added)
+	sort.Strings(delta.Changed)
+	sort.Strings(delta.Removed)
+	return delta
+}
+
+// BuildDeltas computes per-locale deltas between the locale files of two release
+// directories. Locales missing from the old release are treated as entirely new.
+func BuildDeltas(oldDir, newDir, separator string) ([]LocaleDelta, error) {
+	files, err := listJSONFiles(newDir)
+	if err != nil {
+		return nil, err
+	}
+
+	deltas := make([]LocaleDelta, 0, len(files))
+	for _, file := range files {
+		newFlat, err := readFlatJSON(file, separator)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", file, err)
+		}
+
+		oldFlat := map[string]any{}
+		oldFile := filepath.Join(oldDir, filepath.Base(file))
+		if _, err := os.Stat(oldFile); err == nil {
+			if oldFlat, err = readFlatJSON(oldFile, separator); err != nil {
+				return nil, fmt.Errorf("failed to read %s: %v", oldFile, err)
+			}
+		}
+
+		delta := ComputeDelta(oldFlat, newFlat)
+		delta.Locale = localeFromPath(file)
+		deltas = append(deltas, delta)
+	}
+
+	sort.Slice(deltas, func(i, j int) bool { return deltas[i].Locale < deltas[j].Locale })
+	return deltas, nil
+}
@@ -0,0 +1,49 @@
+package i18n
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/haiyon/fitobj/utils"
+)
+
+func TestBuildDeltas(t *testing.T) {
+	oldDir, newDir := t.TempDir(), t.TempDir()
+
+	files := map[string]map[string]any{
+		filepath.Join(oldDir, "en.json"): {"home": map[string]any{"title": "Home", "intro": "Hi"}, "old": "Gone"},
+		filepath.Join(newDir, "en.json"): {"home": map[string]any{"title": "Home", "intro": "Hello"}, "cart": "Cart"},
+		filepath.Join(newDir, "de.json"): {"cart": "Warenkorb"},
+	}
+	for path, data := range files {
+		if err := utils.WriteJSONFile(path, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deltas, err := BuildDeltas(oldDir, newDir, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []LocaleDelta{
+		{
+			Locale:  "de",
+			Values:  map[string]any{"cart": "Warenkorb"},
+			Added:   []string{"cart"},
+			Changed: []string{},
+			Removed: []string{},
+		},
+		{
+			Locale:  "en",
+			Values:  map[string]any{"cart": "Cart", "home.intro": "Hello"},
+			Added:   []string{"cart"},
+			Changed: []string{"home.intro"},
+			Removed: []string{"old"},
+		},
+	}
+	if !reflect.DeepEqual(deltas, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, deltas)
+	}
+}
@@ -0,0 +1,97 @@
+package i18n
+
+import (
+	"bytes"
+	"os"
+	"sort"
+	"strings"
+)
+
+// DynamicKey is a translation call whose key is built at runtime, such as
+// t(`errors.${code}`) or t('errors.' + code). Its keys cannot be verified against
+// the locale files; Prefix is the static start of the key, resolved against
+// namespaces and keyPrefix like other keys, and empty when nothing is known.
+type DynamicKey struct {
+	File       string `json:"file"`
+	Line       int    `json:"line"`
+	Column     int    `json:"column"`
+	Expression string `json:"expression"` // source of the key argument
+	Prefix     string `json:"prefix"`
+}
+
+// ExtractDynamicKeysFromDir recursively collects the t() calls of a directory
+// whose key is built at runtime
+func ExtractDynamicKeysFromDir(rootDir string) ([]DynamicKey, error) {
+	return extractDynamicKeysFromDir(rootDir, defaultScanner)
+}
+
+// ExtractDynamicKeysFromDirWithOptions recursively collects the calls to the
+// configured functions whose key is built at runtime, in file and line order
+func ExtractDynamicKeysFromDirWithOptions(rootDir string, options ExtractOptions) ([]DynamicKey, error) {
+	scanner, err := options.scanner()
+	if err != nil {
+		return nil, err
+	}
+	return extractDynamicKeysFromDir(rootDir, scanner)
+}
+
+func extractDynamicKeysFromDir(rootDir string, scanner *keyScanner) ([]DynamicKey, error) {
+	var dynamic []DynamicKey
+
+	err := scanSourceFiles(rootDir, scanner, func(path string) []DynamicKey {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil // Ignore read errors (e.g., binary files)
+		}
+
+		var fileDynamic []DynamicKey
+		for _, ref := range scanner.scanFile(path, content) {
+			if !ref.dynamic {
+				continue
+			}
+			line := bytes.Count(content[:ref.offset], []byte("\n")) + 1
+			column := ref.offset - bytes.LastIndexByte(content[:ref.offset], '\n')
+			fileDynamic = append(fileDynamic, DynamicKey{File: path, Line: line, Column: column, Expression: ref.expr, Prefix: ref.key})
+		}
+		return fileDynamic
+	}, func(_ string, fileDynamic []DynamicKey) {
+		dynamic = append(dynamic, fileDynamic...)
+	})
+
+	return dynamic, err
+}
+
+// DynamicPrefixes returns the distinct non-empty prefixes of dynamic keys, sorted.
+// Calls without a known prefix could build any key and are left out.
+func DynamicPrefixes(dynamic []DynamicKey) []string {
+	seen := make(map[string]bool)
+	var prefixes []string
+	for _, key := range dynamic {
+		if key.Prefix != "" && !seen[key.Prefix] {
+			seen[key.Prefix] = true
+			prefixes = append(prefixes, key.Prefix)
+		}
+	}
+	sort.Strings(prefixes)
+	return prefixes
+}
+
+// SplitByPrefixes splits keys into those starting with one of the prefixes, which
+// dynamic calls may use, and the others
+func SplitByPrefixes(keys, prefixes []string) (matched, rest []string) {
+	for _, key := range keys {
+		found := false
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(key, prefix) {
+				found = true
+				break
+			}
+		}
+		if found {
+			matched = append(matched, key)
+		} else {
+			rest = append(rest, key)
+		}
+	}
+	return matched, rest
+}
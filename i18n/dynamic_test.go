@@ -0,0 +1,60 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExtractDynamicKeys(t *testing.T) {
+	content := "const { t } = useTranslation();\n" +
+		"t('title');\n" +
+		"t(`errors.${code}`);\n" +
+		"t('status.' + state, { count: 1 });\n" +
+		"t(`plain.key`);\n" +
+		"t(labelKey);\n" +
+		"function Settings() {\n" +
+		"  const { t } = useTranslation('common', { keyPrefix: 'settings' });\n" +
+		"  t(`common:tabs.${tab}`);\n" +
+		"}\n"
+
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "app.tsx")
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := ExtractKeysFromFile(testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedKeys := map[string]bool{"title": true, "plain.key": true}
+	if !reflect.DeepEqual(keys, expectedKeys) {
+		t.Fatalf("Expected static keys %v, got %v", expectedKeys, keys)
+	}
+
+	dynamic, err := ExtractDynamicKeysFromDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []DynamicKey{
+		{File: testFile, Line: 3, Column: 1, Expression: "`errors.${code}`", Prefix: "errors."},
+		{File: testFile, Line: 4, Column: 1, Expression: "'status.' + state", Prefix: "status."},
+		{File: testFile, Line: 6, Column: 1, Expression: "labelKey", Prefix: ""},
+		{File: testFile, Line: 9, Column: 3, Expression: "`common:tabs.${tab}`", Prefix: "settings.tabs."},
+	}
+	if !reflect.DeepEqual(dynamic, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, dynamic)
+	}
+
+	prefixes := DynamicPrefixes(dynamic)
+	if want := []string{"errors.", "settings.tabs.", "status."}; !reflect.DeepEqual(prefixes, want) {
+		t.Fatalf("Expected prefixes %v, got %v", want, prefixes)
+	}
+
+	kept, unused := SplitByPrefixes([]string{"errors.404", "old", "settings.tabs.a", "statusbar"}, prefixes)
+	if !reflect.DeepEqual(kept, []string{"errors.404", "settings.tabs.a"}) || !reflect.DeepEqual(unused, []string{"old", "statusbar"}) {
+		t.Fatalf("Unexpected split: kept %v, unused %v", kept, unused)
+	}
+}
@@ -0,0 +1,168 @@
+package i18n
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ExportOptions configures exporting a locale pair for translators
+type ExportOptions struct {
+	SourceLang string   // language of the base locale
+	TargetLang string   // language being translated
+	Original   string   // original file name recorded in the export
+	Metadata   Metadata // per-key translator context (optional)
+}
+
+type xliffDoc struct {
+	XMLName xml.Name  `xml:"xliff"`
+	Version string    `xml:"version,attr"`
+	Xmlns   string    `xml:"xmlns,attr"`
+	File    xliffFile `xml:"file"`
+}
+
+type xliffFile struct {
+	SourceLanguage string      `xml:"source-language,attr"`
+	TargetLanguage string      `xml:"target-language,attr,omitempty"`
+	Datatype       string      `xml:"datatype,attr"`
+	Original       string      `xml:"original,attr"`
+	Units          []xliffUnit `xml:"body>trans-unit"`
+}
+
+type xliffUnit struct {
+	ID        string      `xml:"id,attr"`
+	Translate string      `xml:"translate,attr,omitempty"`
+	MaxWidth  string      `xml:"maxwidth,attr,omitempty"`
+	SizeUnit  string      `xml:"size-unit,attr,omitempty"`
+	Source    string      `xml:"source"`
+	Target    string      `xml:"target,omitempty"`
+	Notes     []xliffNote `xml:"note"`
+}
+
+type xliffNote struct {
+	From string `xml:"from,attr,omitempty"`
+	Text string `xml:",chardata"`
+}
+
+// ExportXLIFF writes flattened source and target values as an XLIFF 1.2 document,
+// carrying key metadata as notes and translate/maxwidth attributes
+func ExportXLIFF(w io.Writer, source, target map[string]any, options ExportOptions) error {
+	doc := xliffDoc{
+		Version: "1.2",
+		Xmlns:   "urn:oasis:names:tc:xliff:document:1.2",
+		File: xliffFile{
+			SourceLanguage: options.SourceLang,
+			TargetLanguage: options.TargetLang,
+			Datatype:       "plaintext",
+			Original:       options.Original,
+		},
+	}
+
+	for _, key := range sortedStringKeys(source) {
+		unit := xliffUnit{
+			ID:     key,
+			Source: source[key].(string),
+		}
+		if value, ok := target[key].(string); ok {
+			unit.Target = value
+		}
+
+		if meta, ok := options.Metadata[key]; ok {
+			if meta.DoNotTranslate {
+				unit.Translate = "no"
+			}
+			if meta.MaxLength > 0 {
+				unit.MaxWidth = strconv.Itoa(meta.MaxLength)
+				unit.SizeUnit = "char"
+			}
+			if meta.Description != "" {
+				unit.Notes = append(unit.Notes, xliffNote{From: "developer", Text: meta.Description})
+			}
+			if meta.Screenshot != "" {
+				unit.Notes = append(unit.Notes, xliffNote{From: "screenshot", Text: meta.Screenshot})
+			}
+		}
+
+		doc.File.Units = append(doc.File.Units, unit)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode XLIFF: %v", err)
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// ExportPO writes flattened source and target values as a gettext PO file,
+// using the key as msgctxt and carrying key metadata as extracted comments
+func ExportPO(w io.Writer, source, target map[string]any, options ExportOptions) error {
+	var b strings.Builder
+
+	b.WriteString("msgid \"\"\n")
+	b.WriteString("msgstr \"\"\n")
+	b.WriteString("\"Content-Type: text/plain; charset=UTF-8\\n\"\n")
+	if options.TargetLang != "" {
+		fmt.Fprintf(&b, "\"Language: %s\\n\"\n", options.TargetLang)
+	}
+
+	for _, key := range sortedStringKeys(source) {
+		b.WriteString("\n")
+
+		if meta, ok := options.Metadata[key]; ok {
+			if meta.Description != "" {
+				for _, line := range strings.Split(meta.Description, "\n") {
+					fmt.Fprintf(&b, "#. %s\n", line)
+				}
+			}
+			if meta.Screenshot != "" {
+				fmt.Fprintf(&b, "#. Screenshot: %s\n", meta.Screenshot)
+			}
+			if meta.MaxLength > 0 {
+				fmt.Fprintf(&b, "#. Max length: %d\n", meta.MaxLength)
+			}
+			if meta.DoNotTranslate {
+				b.WriteString("#. Do not translate\n")
+			}
+		}
+
+		if options.Original != "" {
+			fmt.Fprintf(&b, "#: %s\n", options.Original)
+		}
+
+		targetValue, _ := target[key].(string)
+		fmt.Fprintf(&b, "msgctxt %s\n", poQuote(key))
+		fmt.Fprintf(&b, "msgid %s\n", poQuote(source[key].(string)))
+		fmt.Fprintf(&b, "msgstr %s\n", poQuote(targetValue))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// poQuote escapes a string as a PO string literal
+func poQuote(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+	return `"` + replacer.Replace(s) + `"`
+}
+
+// sortedStringKeys returns the keys with string values in sorted order
+func sortedStringKeys(flat map[string]any) []string {
+	keys := make([]string, 0, len(flat))
+	for key, value := range flat {
+		if _, ok := value.(string); ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
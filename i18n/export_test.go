@@ -0,0 +1,57 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExportXLIFF(t *testing.T) {
+	source := map[string]any{"buttons.ok": "OK", "brand": "Acme", "count": 3.0}
+	target := map[string]any{"buttons.ok": "D'accord"}
+	metadata := Metadata{
+		"buttons.ok": {Description: "Confirm button", MaxLength: 10},
+		"brand":      {DoNotTranslate: true},
+	}
+
+	var b strings.Builder
+	err := ExportXLIFF(&b, source, target, ExportOptions{SourceLang: "en", TargetLang: "fr", Metadata: metadata})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := b.String()
+	for _, expected := range []string{
+		`<trans-unit id="brand" translate="no">`,
+		`<trans-unit id="buttons.ok" maxwidth="10" size-unit="char">`,
+		`<target>D&#39;accord</target>`,
+		`<note from="developer">Confirm button</note>`,
+	} {
+		if !strings.Contains(out, expected) {
+			t.Fatalf("Expected XLIFF to contain %s, got:\n%s", expected, out)
+		}
+	}
+
+	if strings.Contains(out, `id="count"`) {
+		t.Fatalf("Non-string values should not be exported, got:\n%s", out)
+	}
+}
+
+func TestExportPO(t *testing.T) {
+	source := map[string]any{"greeting": "Say \"hi\"\nnow"}
+	metadata := Metadata{"greeting": {Description: "Home page greeting", Screenshot: "https://example.com/s.png"}}
+
+	var b strings.Builder
+	if err := ExportPO(&b, source, nil, ExportOptions{TargetLang: "de", Metadata: metadata}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `#. Home page greeting
+#. Screenshot: https://example.com/s.png
+msgctxt "greeting"
+msgid "Say \"hi\"\nnow"
+msgstr ""
+`
+	if !strings.Contains(b.String(), expected) {
+		t.Fatalf("Expected PO entry:\n%s\ngot:\n%s", expected, b.String())
+	}
+}
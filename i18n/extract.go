@@ -3,7 +3,7 @@ package i18n
 import (
 	"encoding/json"
 	"fmt"
-	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -11,13 +11,126 @@ import (
 	"strings"
 
 	"github.com/haiyon/fitobj/fitter"
+	"github.com/haiyon/fitobj/utils"
 )
 
+// ExtractOptions configures which function calls are scanned for keys
+type ExtractOptions struct {
+	// FuncNames lists the translation functions, e.g. t, i18n.t, $t or translate.
+	// Names prefixed with "re:" are regular expressions matched against the callee.
+	FuncNames []string
+	// GoFuncNames lists the translation functions of Go files, which are parsed
+	// instead of scanned: selectors such as i18n.T or localizer.MustLocalize, or
+	// "re:" expressions. FuncNames are used when it is empty.
+	GoFuncNames []string
+
+	NsSeparator  string // separator between namespace and key, as in t('common:ok') (default: ":")
+	KeySeparator string // separator joining a keyPrefix and a key (default: ".")
+	Workers      int    // source files scanned in parallel (default: the number of CPUs)
+
+	// Exclude lists the paths left out of directory scans, with the syntax of
+	// .gitignore lines relative to the scanned directory: dist, build/, /legacy or
+	// src/**/*.test.ts. Paths excluded by .gitignore files are left out too, unless
+	// NoGitignore is set.
+	Exclude     []string
+	NoGitignore bool
+}
+
+// DefaultExtractOptions returns options that scan for t() calls
+func DefaultExtractOptions() ExtractOptions {
+	return ExtractOptions{FuncNames: []string{"t"}, NsSeparator: ":", KeySeparator: "."}
+}
+
 // Pattern to match t('key') or t("key") function calls in source files
 var tPattern = regexp.MustCompile(`\bt\(\s*['"]([^'"]+?)['"]`)
 
+// Pattern to match t() calls whose key is a template literal or an expression
+var tDynamicPattern = regexp.MustCompile(dynamicCall(`\bt`))
+
+// CallPattern compiles the pattern matching calls to the configured functions. The
+// key is the last capture group, so "re:" names may contain groups of their own.
+func (o ExtractOptions) CallPattern() (*regexp.Regexp, error) {
+	if o.defaultCallees() {
+		return tPattern, nil
+	}
+	callees, err := o.callees()
+	if err != nil {
+		return nil, err
+	}
+	return regexp.Compile(`(?:` + callees + `)\(\s*['"]([^'"]+?)['"]`)
+}
+
+// DynamicCallPattern compiles the pattern matching calls to the configured
+// functions whose key is not a string literal: a template literal (`errors.${code}`)
+// or an expression starting with an identifier. The argument is the last capture
+// group. Keys concatenated to a string literal ('errors.' + code) are matched by
+// CallPattern and told apart by the '+' following the literal.
+func (o ExtractOptions) DynamicCallPattern() (*regexp.Regexp, error) {
+	if o.defaultCallees() {
+		return tDynamicPattern, nil
+	}
+	callees, err := o.callees()
+	if err != nil {
+		return nil, err
+	}
+	return regexp.Compile(dynamicCall(callees))
+}
+
+func dynamicCall(callees string) string {
+	return `(?:` + callees + `)\(\s*(` + "`[^`]*`" + `|[A-Za-z_$][^,)]*)`
+}
+
+func (o ExtractOptions) defaultCallees() bool {
+	return len(o.FuncNames) == 0 || (len(o.FuncNames) == 1 && o.FuncNames[0] == "t")
+}
+
+// callees returns the alternatives matching the names of the configured functions
+func (o ExtractOptions) callees() (string, error) {
+	alternatives := make([]string, 0, len(o.FuncNames))
+	for _, name := range o.FuncNames {
+		if expr, ok := strings.CutPrefix(name, "re:"); ok {
+			if _, err := regexp.Compile(expr); err != nil {
+				return "", fmt.Errorf("invalid function pattern %q: %v", name, err)
+			}
+			alternatives = append(alternatives, "(?:"+expr+")")
+			continue
+		}
+
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return "", fmt.Errorf("empty function name")
+		}
+		quoted := regexp.QuoteMeta(name)
+		// Names starting with a word character must not match the tail of a longer
+		// identifier; names such as $t carry their own boundary
+		if isWordByte(name[0]) {
+			quoted = `\b` + quoted
+		}
+		alternatives = append(alternatives, quoted)
+	}
+	return strings.Join(alternatives, "|"), nil
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
 // ExtractKeysFromFile extracts all t() function call keys from a single file
 func ExtractKeysFromFile(filePath string) (map[string]bool, error) {
+	return extractKeysFromFile(filePath, defaultScanner)
+}
+
+// ExtractKeysFromFileWithOptions extracts the keys of the configured function calls
+// from a single file
+func ExtractKeysFromFileWithOptions(filePath string, options ExtractOptions) (map[string]bool, error) {
+	scanner, err := options.scanner()
+	if err != nil {
+		return nil, err
+	}
+	return extractKeysFromFile(filePath, scanner)
+}
+
+func extractKeysFromFile(filePath string, scanner *keyScanner) (map[string]bool, error) {
 	keys := make(map[string]bool)
 
 	content, err := os.ReadFile(filePath)
@@ -25,14 +138,11 @@ func ExtractKeysFromFile(filePath string) (map[string]bool, error) {
 		return keys, nil // Ignore read errors (e.g., binary files)
 	}
 
-	matches := tPattern.FindAllSubmatch(content, -1)
-	for _, match := range matches {
-		if len(match) >= 2 {
-			key := strings.TrimSpace(string(match[1]))
-			if key != "" {
-				keys[key] = true
-			}
+	for _, ref := range scanner.scanFile(filePath, content) {
+		if ref.dynamic {
+			continue
 		}
+		keys[ref.key] = true
 	}
 
 	return keys, nil
@@ -40,34 +150,29 @@ func ExtractKeysFromFile(filePath string) (map[string]bool, error) {
 
 // ExtractKeysFromDir recursively extracts all t() function call keys from a directory
 func ExtractKeysFromDir(rootDir string) (map[string]bool, error) {
-	keys := make(map[string]bool)
-
-	err := filepath.WalkDir(rootDir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
+	return extractKeysFromDir(rootDir, defaultScanner)
+}
 
-		// Skip hidden directories and files
-		if strings.HasPrefix(d.Name(), ".") {
-			if d.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
-		}
+// ExtractKeysFromDirWithOptions recursively extracts the keys of the configured
+// function calls from a directory
+func ExtractKeysFromDirWithOptions(rootDir string, options ExtractOptions) (map[string]bool, error) {
+	scanner, err := options.scanner()
+	if err != nil {
+		return nil, err
+	}
+	return extractKeysFromDir(rootDir, scanner)
+}
 
-		// Only process text-like files
-		if !d.IsDir() && isTextFile(path) {
-			fileKeys, err := ExtractKeysFromFile(path)
-			if err != nil {
-				return err
-			}
+func extractKeysFromDir(rootDir string, scanner *keyScanner) (map[string]bool, error) {
+	keys := make(map[string]bool)
 
-			for key := range fileKeys {
-				keys[key] = true
-			}
+	err := scanSourceFiles(rootDir, scanner, func(path string) map[string]bool {
+		fileKeys, _ := extractKeysFromFile(path, scanner)
+		return fileKeys
+	}, func(_ string, fileKeys map[string]bool) {
+		for key := range fileKeys {
+			keys[key] = true
 		}
-
-		return nil
 	})
 
 	return keys, err
@@ -140,7 +245,7 @@ func ExtractKeysFromJSONDir(jsonPath string) (map[string]bool, error) {
 				fullPath := filepath.Join(jsonPath, entry.Name())
 				jsonKeys, err := ExtractKeysFromJSON(fullPath)
 				if err != nil {
-					fmt.Printf("Warning: Failed to process %s: %v\n", fullPath, err)
+					slog.Warn(fmt.Sprintf("Warning: Failed to process %s: %v", fullPath, err), "file", fullPath, "error", err)
 					continue
 				}
 
@@ -277,78 +382,186 @@ func splitKeyPath(keyPath, separator string) []string {
 	return parts
 }
 
-// CleanupUnusedKeys removes unused keys from JSON files in the specified path
+// CleanupChange describes the keys removed, or to be removed, from one JSON file
+type CleanupChange struct {
+	File       string         `json:"file"`
+	Removed    []string       `json:"removed"` // removed keys in the order they were given
+	Values     map[string]any `json:"values"`  // removed key -> its value before removal
+	SizeBefore int            `json:"sizeBefore"`
+	SizeAfter  int            `json:"sizeAfter"`
+}
+
+// CleanupOptions configures the removal of unused keys from JSON files
+type CleanupOptions struct {
+	Separator string          // joins the segments of nested keys
+	DryRun    bool            // report the keys to be removed without writing
+	Order     *utils.Ordering // key order of the rewritten files (nil: alphabetical; preserve keeps the order of each file)
+}
+
+// CleanupUnusedKeys removes unused keys from JSON files in the specified path,
+// logging each changed file to the default slog logger
 func CleanupUnusedKeys(jsonPath string, unusedKeys []string, separator string) error {
+	return CleanupUnusedKeysWithOptions(jsonPath, unusedKeys, CleanupOptions{Separator: separator})
+}
+
+// CleanupUnusedKeysWithOptions removes unused keys like CleanupUnusedKeys, with
+// the given options
+func CleanupUnusedKeysWithOptions(jsonPath string, unusedKeys []string, options CleanupOptions) error {
+	changes, err := RemoveUnusedKeysWithOptions(jsonPath, unusedKeys, options)
+	for _, change := range changes {
+		slog.Info(fmt.Sprintf("✅ Removed %d unused keys from %s (size: %d -> %d bytes)",
+			len(change.Removed), change.File, change.SizeBefore, change.SizeAfter),
+			"file", change.File, "removed", len(change.Removed), "size_before", change.SizeBefore, "size_after", change.SizeAfter)
+	}
+	return err
+}
+
+// RemoveUnusedKeys removes unused keys like CleanupUnusedKeys, returning the
+// changes made to each JSON file instead of printing them
+func RemoveUnusedKeys(jsonPath string, unusedKeys []string, separator string) ([]CleanupChange, error) {
+	return RemoveUnusedKeysWithOptions(jsonPath, unusedKeys, CleanupOptions{Separator: separator})
+}
+
+// PlanCleanup reports the keys CleanupUnusedKeys would remove from each JSON file
+// in the specified path without writing anything
+func PlanCleanup(jsonPath string, unusedKeys []string, separator string) ([]CleanupChange, error) {
+	return RemoveUnusedKeysWithOptions(jsonPath, unusedKeys, CleanupOptions{Separator: separator, DryRun: true})
+}
+
+// RemoveUnusedKeysWithOptions removes unused keys like RemoveUnusedKeys, or
+// plans their removal like PlanCleanup with DryRun, writing the files in the
+// given key order
+func RemoveUnusedKeysWithOptions(jsonPath string, unusedKeys []string, options CleanupOptions) ([]CleanupChange, error) {
+	if err := utils.ValidateOrder(orderMode(options.Order)); err != nil {
+		return nil, err
+	}
+	return cleanupUnusedKeys(jsonPath, unusedKeys, options)
+}
+
+func cleanupUnusedKeys(jsonPath string, unusedKeys []string, options CleanupOptions) ([]CleanupChange, error) {
 	if len(unusedKeys) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	fileInfo, err := os.Stat(jsonPath)
 	if err != nil {
-		return fmt.Errorf("failed to stat path: %v", err)
+		return nil, fmt.Errorf("failed to stat path: %v", err)
 	}
 
+	files := []string{jsonPath}
 	if fileInfo.IsDir() {
 		entries, err := os.ReadDir(jsonPath)
 		if err != nil {
-			return fmt.Errorf("failed to read directory: %v", err)
+			return nil, fmt.Errorf("failed to read directory: %v", err)
 		}
 
+		files = nil
 		for _, entry := range entries {
 			if !entry.IsDir() && filepath.Ext(entry.Name()) == ".json" {
-				fullPath := filepath.Join(jsonPath, entry.Name())
-				if err := cleanupJSONFile(fullPath, unusedKeys, separator); err != nil {
-					return fmt.Errorf("failed to cleanup file %s: %v", fullPath, err)
-				}
+				files = append(files, filepath.Join(jsonPath, entry.Name()))
 			}
 		}
-	} else {
-		if err := cleanupJSONFile(jsonPath, unusedKeys, separator); err != nil {
-			return fmt.Errorf("failed to cleanup file %s: %v", jsonPath, err)
+	}
+
+	var changes []CleanupChange
+	for _, file := range files {
+		change, err := cleanupJSONFile(file, unusedKeys, options)
+		if err != nil {
+			// Report the files already changed along with the error
+			return changes, fmt.Errorf("failed to cleanup file %s: %v", file, err)
+		}
+		if change != nil {
+			changes = append(changes, *change)
 		}
 	}
 
-	return nil
+	return changes, nil
 }
 
-// cleanupJSONFile removes unused keys from a single JSON file
-func cleanupJSONFile(filePath string, unusedKeys []string, separator string) error {
+// cleanupJSONFile removes unused keys from a single JSON file, returning nil when
+// the file holds none of them
+func cleanupJSONFile(filePath string, unusedKeys []string, options CleanupOptions) (*CleanupChange, error) {
+	separator := options.Separator
 	jsonData, err := os.ReadFile(filePath)
 	if err != nil {
-		return fmt.Errorf("failed to read JSON file: %v", err)
+		return nil, fmt.Errorf("failed to read JSON file: %v", err)
 	}
 
 	if len(jsonData) == 0 {
-		return nil // Skip empty files
+		return nil, nil // Skip empty files
 	}
 
 	var jsonObj map[string]any
 	if err := json.Unmarshal(jsonData, &jsonObj); err != nil {
-		return fmt.Errorf("failed to parse JSON: %v", err)
+		return nil, fmt.Errorf("failed to parse JSON: %v", err)
 	}
 
-	originalSize := len(jsonData)
-	removedCount := 0
+	change := &CleanupChange{File: filePath, Values: make(map[string]any), SizeBefore: len(jsonData)}
 
 	for _, key := range unusedKeys {
+		value, _ := valueAtPath(jsonObj, splitKeyPath(key, separator))
 		if RemoveKeysFromPath(jsonObj, key, separator) {
-			removedCount++
+			change.Removed = append(change.Removed, key)
+			change.Values[key] = value
 		}
 	}
 
-	if removedCount > 0 {
-		updatedData, err := json.MarshalIndent(jsonObj, "", "  ")
-		if err != nil {
-			return fmt.Errorf("failed to marshal JSON: %v", err)
-		}
+	if len(change.Removed) == 0 {
+		return nil, nil
+	}
 
-		if err := os.WriteFile(filePath, updatedData, 0644); err != nil {
-			return fmt.Errorf("failed to write JSON file: %v", err)
-		}
+	order, err := fileOrdering(jsonData, options)
+	if err != nil {
+		return nil, err
+	}
+	updatedData, err := utils.MarshalJSONOrdered(jsonObj, separator, order)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON: %v", err)
+	}
+	change.SizeAfter = len(updatedData)
+
+	if options.DryRun {
+		return change, nil
+	}
+
+	if err := os.WriteFile(filePath, updatedData, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write JSON file: %v", err)
+	}
+
+	return change, nil
+}
+
+// fileOrdering returns the key order of a rewritten file: the cleanup order,
+// with the positions of the file's own keys in preserve mode
+func fileOrdering(jsonData []byte, options CleanupOptions) (*utils.Ordering, error) {
+	if orderMode(options.Order) != utils.OrderPreserve {
+		return options.Order, nil
+	}
+	source, err := utils.JSONKeyOrder(jsonData, options.Separator)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %v", err)
+	}
+	return &utils.Ordering{Mode: utils.OrderPreserve, Source: source, Collator: options.Order.Collator}, nil
+}
 
-		fmt.Printf("✅ Removed %d unused keys from %s (size: %d -> %d bytes)\n",
-			removedCount, filePath, originalSize, len(updatedData))
+func orderMode(order *utils.Ordering) string {
+	if order == nil {
+		return ""
 	}
+	return order.Mode
+}
 
-	return nil
+// valueAtPath returns the value at a key path of a nested JSON structure
+func valueAtPath(value map[string]any, parts []string) (any, bool) {
+	var current any = value
+	for _, part := range parts {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return current, len(parts) > 0
 }
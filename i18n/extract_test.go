@@ -492,3 +492,117 @@ func TestCleanupEmptyFile(t *testing.T) {
 		t.Fatalf("Expected empty object, got %v", obj)
 	}
 }
+
+func TestExtractKeysFromFileWithOptions(t *testing.T) {
+	content := `
+	const { t } = useTranslation();
+	i18n.t('app.title');
+	$t("menu.open");
+	translate('buttons.save');
+	format('not.a.key');
+	this.$tc('items.count', 2);
+	`
+
+	testFile := filepath.Join(t.TempDir(), "test.vue")
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	options := ExtractOptions{FuncNames: []string{"i18n.t", "$t", "translate", `re:\$t(c|e)`}}
+	keys, err := ExtractKeysFromFileWithOptions(testFile, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]bool{
+		"app.title":    true,
+		"menu.open":    true,
+		"buttons.save": true,
+		"items.count":  true,
+	}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("Expected %v, got %v", expected, keys)
+	}
+
+	usages, err := ExtractKeyUsagesFromFileWithOptions(testFile, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := usages["menu.open"]; len(got) != 1 || got[0].Line != 4 || got[0].Column != 2 {
+		t.Fatalf("Unexpected usage of menu.open: %+v", got)
+	}
+
+	if _, err := ExtractKeysFromFileWithOptions(testFile, ExtractOptions{FuncNames: []string{"re:("}}); err == nil {
+		t.Fatal("Expected an error for an invalid function pattern")
+	}
+}
+
+func TestExtractKeysWithNamespacesAndKeyPrefix(t *testing.T) {
+	content := `
+	function Settings() {
+		const { t } = useTranslation('account', { keyPrefix: 'settings' });
+		return <h1>{t('title')}{t('common:buttons.save')}</h1>;
+	}
+
+	function Header() {
+		const { t } = useTranslation();
+		return <span>{t('header.title')}{t('nav:home')}</span>;
+	}
+	`
+
+	testFile := filepath.Join(t.TempDir(), "page.tsx")
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := ExtractKeysFromFile(testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]bool{
+		"settings.title":        true,
+		"settings.buttons.save": true,
+		"header.title":          true,
+		"home":                  true,
+	}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("Expected %v, got %v", expected, keys)
+	}
+}
+
+func TestPlanCleanup(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := `{"a": {"b": "B", "c": "C"}, "d": {"e": 1}}`
+	testFile := filepath.Join(tmpDir, "en.json")
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err := PlanCleanup(tmpDir, []string{"a.c", "d.e", "missing"}, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(changes) != 1 {
+		t.Fatalf("Expected 1 change, got %d", len(changes))
+	}
+	change := changes[0]
+	if !reflect.DeepEqual(change.Removed, []string{"a.c", "d.e"}) {
+		t.Errorf("Unexpected removed keys: %v", change.Removed)
+	}
+	if change.Values["a.c"] != "C" || change.Values["d.e"] != float64(1) {
+		t.Errorf("Unexpected removed values: %v", change.Values)
+	}
+	if change.SizeAfter >= change.SizeBefore {
+		t.Errorf("Expected the file to shrink, got %d -> %d bytes", change.SizeBefore, change.SizeAfter)
+	}
+
+	data, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != content {
+		t.Errorf("Dry run modified the file: %s", data)
+	}
+}
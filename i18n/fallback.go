@@ -0,0 +1,87 @@
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/haiyon/fitobj/utils"
+)
+
+// FallbackChain returns the default fallback chain for a locale, from most to least
+// specific, ending with the default locale (e.g. fr-CA -> fr-CA, fr, en)
+func FallbackChain(locale, defaultLocale string) []string {
+	var chain []string
+	seen := make(map[string]bool)
+
+	add := func(l string) {
+		if l != "" && !seen[l] {
+			seen[l] = true
+			chain = append(chain, l)
+		}
+	}
+
+	current := locale
+	for {
+		add(current)
+		i := strings.LastIndexAny(current, "-_")
+		if i < 0 {
+			break
+		}
+		current = current[:i]
+	}
+	add(defaultLocale)
+
+	return chain
+}
+
+// ResolveFallback merges the locale files of a chain into an effective nested bundle.
+// Earlier locales in the chain take precedence; missing files are skipped.
+func ResolveFallback(jsonDir string, chain []string) (map[string]any, []string, error) {
+	if len(chain) == 0 {
+		return nil, nil, fmt.Errorf("fallback chain is empty")
+	}
+
+	result := make(map[string]any)
+	var used []string
+
+	for _, locale := range chain {
+		filePath := filepath.Join(jsonDir, locale+".json")
+		if _, err := os.Stat(filePath); os.IsNotExist(err) {
+			continue
+		}
+
+		data, err := utils.ReadJSONFile(filePath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s: %v", filePath, err)
+		}
+
+		fillMissing(result, data)
+		used = append(used, locale)
+	}
+
+	if len(used) == 0 {
+		return nil, nil, fmt.Errorf("no locale files found for chain %s in %s", strings.Join(chain, " -> "), jsonDir)
+	}
+
+	return result, used, nil
+}
+
+// fillMissing copies values from src into dst where dst has no value yet,
+// recursing into nested objects present in both
+func fillMissing(dst, src map[string]any) {
+	for key, srcValue := range src {
+		dstValue, exists := dst[key]
+		if !exists {
+			dst[key] = srcValue
+			continue
+		}
+
+		dstMap, dstIsMap := dstValue.(map[string]any)
+		srcMap, srcIsMap := srcValue.(map[string]any)
+		if dstIsMap && srcIsMap {
+			fillMissing(dstMap, srcMap)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package i18n
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/haiyon/fitobj/utils"
+)
+
+func TestFallbackChain(t *testing.T) {
+	tests := []struct {
+		locale   string
+		expected []string
+	}{
+		{"fr-CA", []string{"fr-CA", "fr", "en"}},
+		{"zh_Hant_TW", []string{"zh_Hant_TW", "zh_Hant", "zh", "en"}},
+		{"en", []string{"en"}},
+	}
+
+	for _, test := range tests {
+		if chain := FallbackChain(test.locale, "en"); !reflect.DeepEqual(chain, test.expected) {
+			t.Fatalf("FallbackChain(%s) = %v, expected %v", test.locale, chain, test.expected)
+		}
+	}
+}
+
+func TestResolveFallback(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	files := map[string]map[string]any{
+		"en.json":    {"home": map[string]any{"title": "Home", "color": "Color", "help": "Help"}},
+		"fr.json":    {"home": map[string]any{"title": "Accueil", "color": "Couleur"}},
+		"fr-CA.json": {"home": map[string]any{"color": "Couleur (CA)"}},
+	}
+	for name, content := range files {
+		if err := utils.WriteJSONFile(filepath.Join(tmpDir, name), content); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bundle, used, err := ResolveFallback(tmpDir, []string{"fr-CA", "fr", "en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]any{
+		"home": map[string]any{"title": "Accueil", "color": "Couleur (CA)", "help": "Help"},
+	}
+	if !reflect.DeepEqual(bundle, expected) {
+		t.Fatalf("Expected %v, got %v", expected, bundle)
+	}
+	if !reflect.DeepEqual(used, []string{"fr-CA", "fr", "en"}) {
+		t.Fatalf("Unexpected locales used: %v", used)
+	}
+}
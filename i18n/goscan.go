@@ -0,0 +1,185 @@
+package i18n
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// goCallees matches the callees of translation calls in Go source, written as
+// selectors (i18n.T, localizer.MustLocalize) or plain function names (T)
+type goCallees struct {
+	names    map[string]bool
+	patterns []*regexp.Regexp
+}
+
+// goKeyFields are the fields of a composite literal argument holding the key, as
+// in localizer.MustLocalize(&i18n.LocalizeConfig{MessageID: "welcome"})
+var goKeyFields = map[string]bool{"MessageID": true, "ID": true}
+
+// newGoCallees compiles the Go translation functions: names prefixed with "re:"
+// are regular expressions matched against the whole callee
+func newGoCallees(names []string) (*goCallees, error) {
+	callees := &goCallees{names: make(map[string]bool)}
+	for _, name := range names {
+		if expr, ok := strings.CutPrefix(name, "re:"); ok {
+			pattern, err := regexp.Compile(`^(?:` + expr + `)$`)
+			if err != nil {
+				return nil, fmt.Errorf("invalid function pattern %q: %v", name, err)
+			}
+			callees.patterns = append(callees.patterns, pattern)
+			continue
+		}
+		if name = strings.TrimSpace(name); name == "" {
+			return nil, fmt.Errorf("empty function name")
+		}
+		callees.names[name] = true
+	}
+	return callees, nil
+}
+
+func (c *goCallees) match(callee string) bool {
+	if c.names[callee] {
+		return true
+	}
+	for _, pattern := range c.patterns {
+		if pattern.MatchString(callee) {
+			return true
+		}
+	}
+	return false
+}
+
+// scanFile returns the keys of the translation calls of a source file. Go files
+// are parsed, so that only real calls count and imports renamed with an alias
+// still match; the other files, and Go files that do not parse, are scanned
+// with patterns.
+func (s *keyScanner) scanFile(filePath string, content []byte) []keyRef {
+	if s.goCallees != nil && filepath.Ext(filePath) == ".go" {
+		if refs, err := s.scanGo(content); err == nil {
+			return refs
+		}
+	}
+	return s.scan(content)
+}
+
+// scanGo returns the keys of the calls to the Go translation functions whose
+// first argument is a string literal, or a composite literal with a MessageID or
+// ID field. Arguments concatenating a literal ("errors." + code) or built
+// otherwise are returned as dynamic refs.
+func (s *keyScanner) scanGo(content []byte) ([]keyRef, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", content, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+
+	// Imports renamed with an alias are matched by their package name
+	aliases := make(map[string]string)
+	for _, spec := range file.Imports {
+		if spec.Name == nil || spec.Name.Name == "_" || spec.Name.Name == "." {
+			continue
+		}
+		if importPath, err := strconv.Unquote(spec.Path.Value); err == nil {
+			aliases[spec.Name.Name] = path.Base(importPath)
+		}
+	}
+
+	var refs []keyRef
+	ast.Inspect(file, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+		callee, ok := goCalleeName(call.Fun, aliases)
+		if !ok || !s.goCallees.match(callee) {
+			return true
+		}
+
+		offset := fset.Position(call.Pos()).Offset
+		arg := goKeyArgument(call.Args[0])
+		if key, ok := goStringLiteral(arg); ok {
+			if key = strings.TrimSpace(key); key != "" {
+				refs = append(refs, keyRef{key: s.resolve(key, hookScope{}), offset: offset})
+			}
+			return true
+		}
+
+		start, end := fset.Position(arg.Pos()).Offset, fset.Position(arg.End()).Offset
+		ref := keyRef{offset: offset, dynamic: true, expr: string(content[start:end])}
+		if binary, ok := arg.(*ast.BinaryExpr); ok && binary.Op == token.ADD {
+			for left := ast.Expr(binary); ; {
+				if next, ok := left.(*ast.BinaryExpr); ok && next.Op == token.ADD {
+					left = next.X
+					continue
+				}
+				if prefix, ok := goStringLiteral(left); ok {
+					ref.key = s.resolve(prefix, hookScope{})
+				}
+				break
+			}
+		}
+		refs = append(refs, ref)
+		return true
+	})
+	return refs, nil
+}
+
+// goCalleeName renders the callee of a call as a dotted name, with import
+// aliases replaced by their package name
+func goCalleeName(fun ast.Expr, aliases map[string]string) (string, bool) {
+	switch f := fun.(type) {
+	case *ast.Ident:
+		return f.Name, true
+	case *ast.SelectorExpr:
+		if ident, ok := f.X.(*ast.Ident); ok {
+			name := ident.Name
+			if pkg, ok := aliases[name]; ok {
+				name = pkg
+			}
+			return name + "." + f.Sel.Name, true
+		}
+		if x, ok := goCalleeName(f.X, aliases); ok {
+			return x + "." + f.Sel.Name, true
+		}
+	case *ast.IndexExpr:
+		return goCalleeName(f.X, aliases)
+	}
+	return "", false
+}
+
+// goKeyArgument returns the key of a call argument: the argument itself, or the
+// MessageID or ID field of a composite literal
+func goKeyArgument(arg ast.Expr) ast.Expr {
+	if unary, ok := arg.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+		arg = unary.X
+	}
+	literal, ok := arg.(*ast.CompositeLit)
+	if !ok {
+		return arg
+	}
+	for _, element := range literal.Elts {
+		if field, ok := element.(*ast.KeyValueExpr); ok {
+			if name, ok := field.Key.(*ast.Ident); ok && goKeyFields[name.Name] {
+				return field.Value
+			}
+		}
+	}
+	return arg
+}
+
+// goStringLiteral returns the value of a string literal
+func goStringLiteral(expr ast.Expr) (string, bool) {
+	literal, ok := expr.(*ast.BasicLit)
+	if !ok || literal.Kind != token.STRING {
+		return "", false
+	}
+	value, err := strconv.Unquote(literal.Value)
+	return value, err == nil
+}
@@ -0,0 +1,72 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExtractKeysFromGoSource(t *testing.T) {
+	content := `package main
+
+import (
+	"fmt"
+
+	loc "github.com/nicksnyder/go-i18n/v2/i18n"
+	"example.com/app/i18n"
+	tr "example.com/app/i18n"
+)
+
+func handler(localizer *loc.Localizer, code string) {
+	fmt.Println(i18n.T("greeting"))
+	fmt.Sprintf("t(%q)", "not.a.key")
+	// i18n.T("commented.out")
+	localizer.MustLocalize(&loc.LocalizeConfig{MessageID: "welcome"})
+	i18n.T("errors." + code)
+	i18n.T(code)
+	other.T("other.key")
+	tr.T("aliased")
+}
+`
+	dir := t.TempDir()
+	file := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	options := DefaultExtractOptions()
+	options.GoFuncNames = []string{"i18n.T", "localizer.MustLocalize"}
+
+	keys, err := ExtractKeysFromDirWithOptions(dir, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[string]bool{"greeting": true, "welcome": true, "aliased": true}; !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expected keys %v, got %v", expected, keys)
+	}
+
+	usages, err := ExtractKeyUsagesFromDirWithOptions(dir, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := usages["greeting"]; len(got) != 1 || got[0].Line != 12 || got[0].Column != 14 {
+		t.Errorf("Expected greeting used at 12:14, got %v", got)
+	}
+
+	dynamic, err := ExtractDynamicKeysFromDirWithOptions(dir, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dynamic) != 2 || dynamic[0].Prefix != "errors." || dynamic[0].Expression != `"errors." + code` || dynamic[1].Expression != "code" {
+		t.Errorf("Expected the concatenated and variable keys as dynamic, got %+v", dynamic)
+	}
+
+	options.GoFuncNames = []string{"re:[a-z0-9]+\\.T"}
+	keys, err = ExtractKeysFromDirWithOptions(dir, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[string]bool{"greeting": true, "other.key": true, "aliased": true}; !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expected keys %v, got %v", expected, keys)
+	}
+}
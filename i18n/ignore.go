@@ -0,0 +1,167 @@
+package i18n
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/haiyon/fitobj/fitter"
+)
+
+// ignoreRule is a line of a .gitignore file, or an exclude glob, applying to the
+// paths below dir
+type ignoreRule struct {
+	dir      string // absolute directory the pattern is relative to
+	pattern  string
+	negate   bool // the line starts with !, including paths again
+	dirOnly  bool // the line ends with /, matching directories only
+	anchored bool // the pattern holds a /, matching paths from dir instead of names
+}
+
+// parseIgnoreRule parses a .gitignore line, returning false for blank lines and
+// comments
+func parseIgnoreRule(dir, line string) (ignoreRule, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	rule := ignoreRule{dir: dir}
+	if pattern, ok := strings.CutPrefix(line, "!"); ok {
+		rule.negate = true
+		line = pattern
+	}
+	if pattern, ok := strings.CutSuffix(line, "/"); ok {
+		rule.dirOnly = true
+		line = pattern
+	}
+	rule.anchored = strings.Contains(line, "/")
+	rule.pattern = strings.TrimPrefix(line, "/")
+	return rule, rule.pattern != ""
+}
+
+// match reports whether the rule matches a path
+func (r ignoreRule) match(filePath string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	rel, err := filepath.Rel(r.dir, filePath)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	if r.anchored {
+		return fitter.MatchPath(r.pattern, rel, "/")
+	}
+	matched, _ := path.Match(r.pattern, path.Base(rel))
+	return matched
+}
+
+// sourceFilter decides which files of a source directory are scanned, from the
+// .gitignore files of the directory and its parents, and the exclude globs
+type sourceFilter struct {
+	gitRules  []ignoreRule // outer files first, so that closer ones take precedence
+	exclude   []ignoreRule
+	gitignore bool
+}
+
+// newSourceFilter returns the filter of a source directory. The .gitignore files
+// of its parent directories are read up to the root of the repository holding it;
+// those of the directory and below are read as they are walked.
+func newSourceFilter(rootDir string, exclude []string, gitignore bool) (*sourceFilter, error) {
+	root, err := filepath.Abs(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	filter := &sourceFilter{gitignore: gitignore}
+
+	if gitignore && !isRepositoryRoot(root) {
+		var parents []string
+		for dir := filepath.Dir(root); dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+			parents = append(parents, dir)
+			if !isRepositoryRoot(dir) {
+				continue
+			}
+			for i := len(parents) - 1; i >= 0; i-- {
+				if err := filter.load(parents[i]); err != nil {
+					return nil, err
+				}
+			}
+			break
+		}
+	}
+
+	for _, pattern := range exclude {
+		if rule, ok := parseIgnoreRule(root, pattern); ok {
+			filter.exclude = append(filter.exclude, rule)
+		}
+	}
+	return filter, nil
+}
+
+func isRepositoryRoot(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// load adds the rules of the .gitignore file of a directory, if any
+func (f *sourceFilter) load(dir string) error {
+	data, err := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read .gitignore: %v", err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if rule, ok := parseIgnoreRule(dir, scanner.Text()); ok {
+			f.gitRules = append(f.gitRules, rule)
+		}
+	}
+	return scanner.Err()
+}
+
+// enter loads the .gitignore file of a directory being walked
+func (f *sourceFilter) enter(dir string) error {
+	if !f.gitignore {
+		return nil
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	return f.load(abs)
+}
+
+// ignored reports whether a path is excluded: the last matching rule decides
+func (f *sourceFilter) ignored(filePath string, isDir bool) bool {
+	abs, err := filepath.Abs(filePath)
+	if err != nil {
+		return false
+	}
+	ignored := false
+	for _, rules := range [][]ignoreRule{f.gitRules, f.exclude} {
+		for _, rule := range rules {
+			if rule.match(abs, isDir) {
+				ignored = !rule.negate
+			}
+		}
+	}
+	return ignored
+}
+
+// validateExcludes checks that exclude globs compile
+func validateExcludes(exclude []string) error {
+	for _, pattern := range exclude {
+		if _, err := path.Match(strings.Trim(strings.TrimPrefix(pattern, "!"), "/"), ""); err != nil {
+			return fmt.Errorf("invalid exclude pattern '%s': %v", pattern, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package i18n
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// KeepFile is the keep list check and clean read by default, from the working
+// directory
+const KeepFile = ".fitobjignore"
+
+// KeepList holds the keys that are never reported as unused nor removed, such as
+// keys only the backend references: exact keys, prefixes and regexps
+type KeepList struct {
+	exact    map[string]bool
+	prefixes []string
+	patterns []*regexp.Regexp
+}
+
+// ParseKeepList parses a keep list with an entry per line: an exact key, a prefix
+// ending with * (errors.*) or a regexp prefixed with re: (re:^legacy\.). Blank
+// lines and lines starting with # are ignored.
+func ParseKeepList(data []byte) (*KeepList, error) {
+	keep := &KeepList{exact: make(map[string]bool)}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "re:"):
+			pattern, err := regexp.Compile(strings.TrimPrefix(line, "re:"))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid regexp: %v", lineNum, err)
+			}
+			keep.patterns = append(keep.patterns, pattern)
+		case strings.HasSuffix(line, "*"):
+			keep.prefixes = append(keep.prefixes, strings.TrimSuffix(line, "*"))
+		default:
+			keep.exact[line] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return keep, nil
+}
+
+// LoadKeepList reads a keep list file
+func LoadKeepList(filePath string) (*KeepList, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keep list: %v", err)
+	}
+	keep, err := ParseKeepList(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse keep list %s: %v", filePath, err)
+	}
+	return keep, nil
+}
+
+// Keeps reports whether a key is on the keep list. A nil list keeps no key.
+func (k *KeepList) Keeps(key string) bool {
+	if k == nil {
+		return false
+	}
+	if k.exact[key] {
+		return true
+	}
+	for _, prefix := range k.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	for _, pattern := range k.patterns {
+		if pattern.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// Split splits keys into those on the keep list and the others
+func (k *KeepList) Split(keys []string) (kept, rest []string) {
+	for _, key := range keys {
+		if k.Keeps(key) {
+			kept = append(kept, key)
+		} else {
+			rest = append(rest, key)
+		}
+	}
+	return kept, rest
+}
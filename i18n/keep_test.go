@@ -0,0 +1,31 @@
+package i18n
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKeepList(t *testing.T) {
+	keep, err := ParseKeepList([]byte("# backend keys\nemail.subject\n\nerrors.*\nre:^legacy\\.(a|b)$\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := []string{"email.subject", "email.body", "errors.notFound", "legacy.a", "legacy.c", "title"}
+	kept, rest := keep.Split(keys)
+	if expected := []string{"email.subject", "errors.notFound", "legacy.a"}; !reflect.DeepEqual(kept, expected) {
+		t.Errorf("Expected kept keys %v, got %v", expected, kept)
+	}
+	if expected := []string{"email.body", "legacy.c", "title"}; !reflect.DeepEqual(rest, expected) {
+		t.Errorf("Expected other keys %v, got %v", expected, rest)
+	}
+
+	var none *KeepList
+	if none.Keeps("title") {
+		t.Error("Expected a nil keep list to keep no key")
+	}
+
+	if _, err := ParseKeepList([]byte("re:(")); err == nil {
+		t.Error("Expected an error for an invalid regexp")
+	}
+}
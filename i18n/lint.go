@@ -0,0 +1,298 @@
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/haiyon/fitobj/fitter"
+	"github.com/haiyon/fitobj/utils"
+)
+
+// Severity ranks lint findings
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+	SeverityOff     Severity = "off"
+)
+
+// Lint rule names
+const (
+	RuleKeyCase            = "key-case"
+	RuleMaxDepth           = "max-depth"
+	RuleForbiddenChars     = "forbidden-chars"
+	RuleMaxLength          = "max-length"
+	RuleTrailingWhitespace = "trailing-whitespace"
+	RuleHTMLBalance        = "html-balance"
+)
+
+// LintRules returns the names of the lint rules, sorted
+func LintRules() []string {
+	rules := []string{RuleKeyCase, RuleMaxDepth, RuleForbiddenChars, RuleMaxLength, RuleTrailingWhitespace, RuleHTMLBalance}
+	sort.Strings(rules)
+	return rules
+}
+
+// LintConfig configures the locale file lint rules
+type LintConfig struct {
+	KeyCase        string              // "camel", "snake", "kebab", "pascal" or "" to disable
+	MaxDepth       int                 // max nesting depth of keys (0 = no limit)
+	ForbiddenChars string              // characters not allowed in key segments
+	MaxValueLength int                 // max value length in characters (0 = no limit)
+	Separator      string              // separator for flattened keys
+	Severities     map[string]Severity // per-rule severity overrides
+}
+
+// DefaultLintConfig returns the default lint configuration
+func DefaultLintConfig() LintConfig {
+	return LintConfig{
+		KeyCase:        "",
+		MaxDepth:       0,
+		ForbiddenChars: " ",
+		MaxValueLength: 0,
+		Separator:      ".",
+		Severities: map[string]Severity{
+			RuleKeyCase:            SeverityWarning,
+			RuleMaxDepth:           SeverityWarning,
+			RuleForbiddenChars:     SeverityError,
+			RuleMaxLength:          SeverityWarning,
+			RuleTrailingWhitespace: SeverityWarning,
+			RuleHTMLBalance:        SeverityError,
+		},
+	}
+}
+
+// LintIssue describes a single rule violation
+type LintIssue struct {
+	File     string   `json:"file"`
+	Key      string   `json:"key"`
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+var keyCasePatterns = map[string]*regexp.Regexp{
+	"camel":  regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`),
+	"snake":  regexp.MustCompile(`^[a-z][a-z0-9_]*$`),
+	"kebab":  regexp.MustCompile(`^[a-z][a-z0-9-]*$`),
+	"pascal": regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`),
+}
+
+// ValidateLintConfig checks that the configuration is usable: severities are
+// known and set for rules that exist
+func ValidateLintConfig(config LintConfig) error {
+	if config.KeyCase != "" {
+		if _, ok := keyCasePatterns[config.KeyCase]; !ok {
+			return fmt.Errorf("unknown key case '%s' (expected camel, snake, kebab or pascal)", config.KeyCase)
+		}
+	}
+	rules := LintRules()
+	overridden := make([]string, 0, len(config.Severities))
+	for rule := range config.Severities {
+		overridden = append(overridden, rule)
+	}
+	sort.Strings(overridden)
+	for _, rule := range overridden {
+		if !slices.Contains(rules, rule) {
+			return fmt.Errorf("unknown lint rule '%s' (expected %s)", rule, strings.Join(rules, ", "))
+		}
+		switch severity := config.Severities[rule]; severity {
+		case SeverityError, SeverityWarning, SeverityInfo, SeverityOff:
+		default:
+			return fmt.Errorf("unknown severity '%s' for rule %s", severity, rule)
+		}
+	}
+	return nil
+}
+
+// LintPath lints a locale JSON file or every JSON file in a directory
+func LintPath(jsonPath string, config LintConfig) ([]LintIssue, error) {
+	if err := ValidateLintConfig(config); err != nil {
+		return nil, err
+	}
+
+	files, err := listJSONFiles(jsonPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []LintIssue
+	for _, file := range files {
+		fileIssues, err := LintFile(file, config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lint %s: %v", file, err)
+		}
+		issues = append(issues, fileIssues...)
+	}
+
+	return issues, nil
+}
+
+// LintFile lints a single locale JSON file
+func LintFile(filePath string, config LintConfig) ([]LintIssue, error) {
+	data, err := utils.ReadJSONFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	separator := config.Separator
+	if separator == "" {
+		separator = "."
+	}
+
+	options := fitter.DefaultFlattenOptions()
+	options.Separator = separator
+	flat := fitter.FlattenMapWithOptions(data, "", options)
+
+	keys := make([]string, 0, len(flat))
+	for key := range flat {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var issues []LintIssue
+	report := func(key, rule, message string) {
+		severity := config.severity(rule)
+		if severity == SeverityOff {
+			return
+		}
+		issues = append(issues, LintIssue{
+			File:     filePath,
+			Key:      key,
+			Rule:     rule,
+			Severity: severity,
+			Message:  message,
+		})
+	}
+
+	for _, key := range keys {
+		segments := splitKeyPath(key, separator)
+
+		if config.MaxDepth > 0 && len(segments) > config.MaxDepth {
+			report(key, RuleMaxDepth, fmt.Sprintf("key depth %d exceeds maximum of %d", len(segments), config.MaxDepth))
+		}
+
+		for _, segment := range segments {
+			if _, err := strconv.Atoi(segment); err == nil {
+				continue // Array indices are not subject to naming rules
+			}
+
+			if config.KeyCase != "" && !keyCasePatterns[config.KeyCase].MatchString(segment) {
+				report(key, RuleKeyCase, fmt.Sprintf("segment '%s' is not %s case", segment, config.KeyCase))
+			}
+
+			if config.ForbiddenChars != "" && strings.ContainsAny(segment, config.ForbiddenChars) {
+				report(key, RuleForbiddenChars, fmt.Sprintf("segment '%s' contains forbidden characters", segment))
+			}
+		}
+
+		value, ok := flat[key].(string)
+		if !ok {
+			continue
+		}
+
+		if config.MaxValueLength > 0 {
+			if length := utf8.RuneCountInString(value); length > config.MaxValueLength {
+				report(key, RuleMaxLength, fmt.Sprintf("value length %d exceeds maximum of %d", length, config.MaxValueLength))
+			}
+		}
+
+		if value != strings.TrimRight(value, " \t\r\n") {
+			report(key, RuleTrailingWhitespace, "value has trailing whitespace")
+		}
+
+		if problem := checkTagBalance(value); problem != "" {
+			report(key, RuleHTMLBalance, problem)
+		}
+	}
+
+	return issues, nil
+}
+
+// severity returns the configured severity for a rule
+func (c LintConfig) severity(rule string) Severity {
+	if severity, ok := c.Severities[rule]; ok {
+		return severity
+	}
+	return SeverityWarning
+}
+
+// CountBySeverity tallies lint issues per severity
+func CountBySeverity(issues []LintIssue) map[Severity]int {
+	counts := make(map[Severity]int)
+	for _, issue := range issues {
+		counts[issue.Severity]++
+	}
+	return counts
+}
+
+// Pattern to match opening, closing and self-closing markup tags
+var tagPattern = regexp.MustCompile(`<(/?)([A-Za-z0-9][\w.-]*)[^<>]*?(/?)>`)
+
+// Tags that never have a closing counterpart
+var voidTags = map[string]bool{
+	"br": true, "hr": true, "img": true, "input": true, "meta": true, "link": true, "wbr": true,
+}
+
+// checkTagBalance reports the first unbalanced tag in a value
+func checkTagBalance(value string) string {
+	var stack []string
+
+	for _, match := range tagPattern.FindAllStringSubmatch(value, -1) {
+		closing, name, selfClosing := match[1] == "/", match[2], match[3] == "/"
+		if selfClosing || (!closing && voidTags[name]) {
+			continue
+		}
+
+		if !closing {
+			stack = append(stack, name)
+			continue
+		}
+
+		if len(stack) == 0 || stack[len(stack)-1] != name {
+			return fmt.Sprintf("unexpected closing tag </%s>", name)
+		}
+		stack = stack[:len(stack)-1]
+	}
+
+	if len(stack) > 0 {
+		return fmt.Sprintf("unclosed tag <%s>", stack[len(stack)-1])
+	}
+
+	return ""
+}
+
+// listJSONFiles returns the JSON file itself or the JSON files in a directory
+func listJSONFiles(jsonPath string) ([]string, error) {
+	fileInfo, err := os.Stat(jsonPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat path: %v", err)
+	}
+
+	if !fileInfo.IsDir() {
+		return []string{jsonPath}, nil
+	}
+
+	entries, err := os.ReadDir(jsonPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %v", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".json" {
+			files = append(files, filepath.Join(jsonPath, entry.Name()))
+		}
+	}
+
+	return files, nil
+}
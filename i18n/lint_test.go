@@ -0,0 +1,104 @@
+package i18n
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/haiyon/fitobj/utils"
+)
+
+func TestLintFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "en.json")
+
+	content := map[string]any{
+		"buttons": map[string]any{
+			"submitForm": "Submit",
+			"cancel_btn": "Cancel ",
+		},
+		"deep": map[string]any{
+			"nested": map[string]any{
+				"key": "Value",
+			},
+		},
+		"markup": map[string]any{
+			"ok":     "Click <b>here</b><br>",
+			"broken": "Click <b>here",
+		},
+	}
+
+	if err := utils.WriteJSONFile(testFile, content); err != nil {
+		t.Fatal(err)
+	}
+
+	config := DefaultLintConfig()
+	config.KeyCase = "camel"
+	config.MaxDepth = 2
+	config.MaxValueLength = 12
+
+	issues, err := LintFile(testFile, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := make(map[string]string)
+	for _, issue := range issues {
+		found[issue.Rule+" "+issue.Key] = string(issue.Severity)
+	}
+
+	expected := map[string]string{
+		"key-case buttons.cancel_btn":            "warning",
+		"trailing-whitespace buttons.cancel_btn": "warning",
+		"max-depth deep.nested.key":              "warning",
+		"max-length markup.ok":                   "warning",
+		"max-length markup.broken":               "warning",
+		"html-balance markup.broken":             "error",
+	}
+
+	for key, severity := range expected {
+		if found[key] != severity {
+			t.Fatalf("Expected issue %q with severity %s, got issues %v", key, severity, found)
+		}
+	}
+
+	if len(issues) != len(expected) {
+		t.Fatalf("Expected %d issues, got %d: %v", len(expected), len(issues), found)
+	}
+}
+
+func TestCheckTagBalance(t *testing.T) {
+	tests := []struct {
+		value    string
+		balanced bool
+	}{
+		{"plain text", true},
+		{"<b>bold</b> and <Link to=\"/x\">link</Link>", true},
+		{"<0>placeholder</0>", true},
+		{"line<br/>break<br>", true},
+		{"<b>unclosed", false},
+		{"<i>wrong</b>", false},
+		{"stray</span>", false},
+	}
+
+	for _, test := range tests {
+		problem := checkTagBalance(test.value)
+		if (problem == "") != test.balanced {
+			t.Fatalf("For %q expected balanced=%v, got problem %q", test.value, test.balanced, problem)
+		}
+	}
+}
+
+func TestValidateLintConfigRules(t *testing.T) {
+	config := DefaultLintConfig()
+	config.Severities[RuleMaxLength] = SeverityError
+	if err := ValidateLintConfig(config); err != nil {
+		t.Fatalf("Unexpected error for a known rule: %v", err)
+	}
+
+	config.Severities["max-lenght"] = SeverityError
+	err := ValidateLintConfig(config)
+	if err == nil || !strings.Contains(err.Error(), "max-lenght") || !strings.Contains(err.Error(), strings.Join(LintRules(), ", ")) {
+		t.Fatalf("Expected an error naming the rule and listing the valid ones, got %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+)
+
+// LocaleReport compares the keys of one locale file with the keys used in source
+type LocaleReport struct {
+	Locale  string   `json:"locale"` // file name without extension
+	Keys    int      `json:"keys"`
+	Missing []string `json:"missing"` // used in source, absent from the locale
+	Unused  []string `json:"unused"`  // in the locale, not used in source
+	Absent  []string `json:"absent"`  // held by other locales, absent from this one
+}
+
+// LocaleGap is a key that only some locales hold. Plural variants count as their
+// base key, since languages use different plural forms.
+type LocaleGap struct {
+	Key     string   `json:"key"`
+	Present []string `json:"present"`
+	Absent  []string `json:"absent"`
+}
+
+// ExtractKeysPerLocale extracts the keys of every JSON file of a path separately,
+// by locale (the file name without extension)
+func ExtractKeysPerLocale(jsonPath string) (map[string]map[string]bool, error) {
+	files, err := listJSONFiles(jsonPath)
+	if err != nil {
+		return nil, err
+	}
+
+	locales := make(map[string]map[string]bool, len(files))
+	for _, file := range files {
+		keys, err := ExtractKeysFromJSON(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", file, err)
+		}
+		locales[localeFromPath(file)] = keys
+	}
+	return locales, nil
+}
+
+// CompareLocaleKeys compares source keys with the keys of each locale, as
+// CompareKeysWithRules does for merged keys, and the locales with each other.
+// Reports and gaps are sorted by locale and key.
+func CompareLocaleKeys(sourceKeys map[string]bool, localeKeys map[string]map[string]bool, rules SuffixRules) ([]LocaleReport, []LocaleGap) {
+	locales := make([]string, 0, len(localeKeys))
+	for locale := range localeKeys {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+
+	// Locales holding each key, plural variants folded into their base key
+	holders := make(map[string][]string)
+	for _, locale := range locales {
+		seen := make(map[string]bool)
+		for key := range localeKeys[locale] {
+			base := rules.stripPlural(key)
+			if !seen[base] {
+				seen[base] = true
+				holders[base] = append(holders[base], locale)
+			}
+		}
+	}
+
+	var gaps []LocaleGap
+	absent := make(map[string][]string)
+	for key, present := range holders {
+		if len(present) == len(locales) {
+			continue
+		}
+		gap := LocaleGap{Key: key, Present: present}
+		for _, locale := range locales {
+			if !containsString(present, locale) {
+				gap.Absent = append(gap.Absent, locale)
+				absent[locale] = append(absent[locale], key)
+			}
+		}
+		gaps = append(gaps, gap)
+	}
+	sort.Slice(gaps, func(i, j int) bool { return gaps[i].Key < gaps[j].Key })
+
+	reports := make([]LocaleReport, 0, len(locales))
+	for _, locale := range locales {
+		missing, unused := CompareKeysWithRules(sourceKeys, localeKeys[locale], rules)
+		sort.Strings(absent[locale])
+		reports = append(reports, LocaleReport{
+			Locale:  locale,
+			Keys:    len(localeKeys[locale]),
+			Missing: nonNil(missing),
+			Unused:  nonNil(unused),
+			Absent:  nonNil(absent[locale]),
+		})
+	}
+	return reports, gaps
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
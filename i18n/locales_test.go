@@ -0,0 +1,50 @@
+package i18n
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/haiyon/fitobj/utils"
+)
+
+func TestCompareLocaleKeys(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]map[string]any{
+		"en.json": {"home": map[string]any{"title": "Home"}, "item_one": "item", "item_other": "items", "legacy": "Old"},
+		"de.json": {"item_one": "Artikel", "item_other": "Artikel"},
+		"ja.json": {"home": map[string]any{"title": "ホーム"}, "item_other": "アイテム", "extra": "余分"},
+	}
+	for name, data := range files {
+		if err := utils.WriteJSONFile(filepath.Join(dir, name), data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	localeKeys, err := ExtractKeysPerLocale(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sourceKeys := map[string]bool{"home.title": true, "item": true}
+	reports, gaps := CompareLocaleKeys(sourceKeys, localeKeys, DefaultSuffixRules())
+
+	expected := []LocaleReport{
+		{Locale: "de", Keys: 2, Missing: []string{"home.title"}, Unused: []string{}, Absent: []string{"extra", "home.title", "legacy"}},
+		{Locale: "en", Keys: 4, Missing: []string{}, Unused: []string{"legacy"}, Absent: []string{"extra"}},
+		{Locale: "ja", Keys: 3, Missing: []string{}, Unused: []string{"extra"}, Absent: []string{"legacy"}},
+	}
+	if !reflect.DeepEqual(reports, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, reports)
+	}
+
+	// ja only has the other plural form, which is not a gap
+	expectedGaps := []LocaleGap{
+		{Key: "extra", Present: []string{"ja"}, Absent: []string{"de", "en"}},
+		{Key: "home.title", Present: []string{"en", "ja"}, Absent: []string{"de"}},
+		{Key: "legacy", Present: []string{"en"}, Absent: []string{"de", "ja"}},
+	}
+	if !reflect.DeepEqual(gaps, expectedGaps) {
+		t.Errorf("Expected gaps %+v, got %+v", expectedGaps, gaps)
+	}
+}
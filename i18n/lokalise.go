@@ -0,0 +1,179 @@
+package i18n
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+func init() {
+	RegisterTMS("lokalise", newLokalise)
+}
+
+// lokalise implements TMS on top of the Lokalise API v2
+type lokalise struct {
+	baseURL string
+	project string
+	token   string
+	client  *http.Client
+}
+
+type lokaliseTranslation struct {
+	LanguageISO string `json:"language_iso"`
+	Translation string `json:"translation"`
+}
+
+type lokaliseKey struct {
+	KeyName      any                   `json:"key_name"`
+	Platforms    []string              `json:"platforms,omitempty"`
+	Translations []lokaliseTranslation `json:"translations,omitempty"`
+}
+
+// newLokalise creates a Lokalise client
+func newLokalise(config TMSConfig) (TMS, error) {
+	if config.Project == "" || config.Token == "" {
+		return nil, fmt.Errorf("lokalise requires a project ID and API token")
+	}
+
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = "https://api.lokalise.com/api2"
+	}
+
+	return &lokalise{
+		baseURL: baseURL,
+		project: config.Project,
+		token:   config.Token,
+		client:  &http.Client{Timeout: 60 * time.Second},
+	}, nil
+}
+
+// Push creates keys with their base translations in batches
+func (l *lokalise) Push(ctx context.Context, locale string, values map[string]string) (int, error) {
+	const batchSize = 500
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	keys := make([]lokaliseKey, 0, len(names))
+	for _, name := range names {
+		keys = append(keys, lokaliseKey{
+			KeyName:      name,
+			Platforms:    []string{"web"},
+			Translations: []lokaliseTranslation{{LanguageISO: locale, Translation: values[name]}},
+		})
+	}
+
+	pushed := 0
+	for start := 0; start < len(keys); start += batchSize {
+		end := min(start+batchSize, len(keys))
+
+		var response struct {
+			Keys []json.RawMessage `json:"keys"`
+		}
+		body := map[string]any{"keys": keys[start:end], "use_automations": true}
+		if err := l.do(ctx, http.MethodPost, "/projects/"+l.project+"/keys", nil, body, &response); err != nil {
+			return pushed, err
+		}
+		pushed += len(response.Keys)
+	}
+
+	return pushed, nil
+}
+
+// Pull lists all keys with translations for a locale, following pagination
+func (l *lokalise) Pull(ctx context.Context, locale string) (map[string]string, error) {
+	const pageSize = 500
+	result := make(map[string]string)
+
+	for page := 1; ; page++ {
+		query := url.Values{
+			"include_translations": {"1"},
+			"limit":                {strconv.Itoa(pageSize)},
+			"page":                 {strconv.Itoa(page)},
+		}
+
+		var response struct {
+			Keys []struct {
+				KeyName      map[string]string     `json:"key_name"`
+				Translations []lokaliseTranslation `json:"translations"`
+			} `json:"keys"`
+		}
+		if err := l.do(ctx, http.MethodGet, "/projects/"+l.project+"/keys", query, nil, &response); err != nil {
+			return nil, err
+		}
+
+		for _, key := range response.Keys {
+			name := key.KeyName["web"]
+			for _, translation := range key.Translations {
+				if translation.LanguageISO == locale && translation.Translation != "" {
+					result[name] = translation.Translation
+				}
+			}
+		}
+
+		if len(response.Keys) < pageSize {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// do performs an authenticated API request and decodes the JSON response
+func (l *lokalise) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	endpoint := l.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %v", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Api-Token", l.token)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("lokalise request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read lokalise response: %v", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("lokalise returned %s: %s", resp.Status, bytes.TrimSpace(data))
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to parse lokalise response: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package i18n
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/haiyon/fitobj/fitter"
+	"github.com/haiyon/fitobj/utils"
+)
+
+// MarkupIssue describes a markup difference between a translation and the base locale
+type MarkupIssue struct {
+	File    string   `json:"file"`
+	Key     string   `json:"key"`
+	Missing []string `json:"missing,omitempty"` // tags in the base value but not the translation
+	Extra   []string `json:"extra,omitempty"`   // tags in the translation but not the base value
+	Problem string   `json:"problem,omitempty"` // balance problem in the translation
+}
+
+// CheckMarkupConsistency compares the markup of every locale file in a directory
+// against the base locale file (e.g. "en" for en.json)
+func CheckMarkupConsistency(jsonDir, baseLocale, separator string) ([]MarkupIssue, error) {
+	files, err := listJSONFiles(jsonDir)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := filepath.Join(jsonDir, baseLocale+".json")
+	baseFlat, err := readFlatJSON(basePath, separator)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read base locale: %v", err)
+	}
+
+	var issues []MarkupIssue
+	for _, file := range files {
+		if file == basePath {
+			continue
+		}
+
+		flat, err := readFlatJSON(file, separator)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", file, err)
+		}
+
+		for _, issue := range CompareMarkup(baseFlat, flat) {
+			issue.File = file
+			issues = append(issues, issue)
+		}
+	}
+
+	return issues, nil
+}
+
+// CompareMarkup compares tags of translated values against base values with the same key
+func CompareMarkup(base, target map[string]any) []MarkupIssue {
+	keys := make([]string, 0, len(target))
+	for key := range target {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var issues []MarkupIssue
+	for _, key := range keys {
+		value, ok := target[key].(string)
+		if !ok {
+			continue
+		}
+		baseValue, ok := base[key].(string)
+		if !ok {
+			continue
+		}
+
+		missing, extra := diffTags(extractTags(baseValue), extractTags(value))
+		problem := ""
+		if checkTagBalance(baseValue) == "" {
+			problem = checkTagBalance(value)
+		}
+
+		if len(missing) > 0 || len(extra) > 0 || problem != "" {
+			issues = append(issues, MarkupIssue{
+				Key:     key,
+				Missing: missing,
+				Extra:   extra,
+				Problem: problem,
+			})
+		}
+	}
+
+	return issues
+}
+
+// String formats the issue for human-readable output
+func (i MarkupIssue) String() string {
+	var parts []string
+	if len(i.Missing) > 0 {
+		parts = append(parts, "missing "+strings.Join(i.Missing, " "))
+	}
+	if len(i.Extra) > 0 {
+		parts = append(parts, "extra "+strings.Join(i.Extra, " "))
+	}
+	if i.Problem != "" {
+		parts = append(parts, i.Problem)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// extractTags returns the normalized tags of a value in document order
+func extractTags(value string) []string {
+	var tags []string
+	for _, match := range tagPattern.FindAllStringSubmatch(value, -1) {
+		closing, name, selfClosing := match[1], match[2], match[3]
+		tags = append(tags, "<"+closing+name+selfClosing+">")
+	}
+	return tags
+}
+
+// diffTags returns tags missing from and extra in the target, as multisets
+func diffTags(base, target []string) ([]string, []string) {
+	counts := make(map[string]int)
+	for _, tag := range base {
+		counts[tag]++
+	}
+	for _, tag := range target {
+		counts[tag]--
+	}
+
+	var missing, extra []string
+	for tag, count := range counts {
+		for ; count > 0; count-- {
+			missing = append(missing, tag)
+		}
+		for ; count < 0; count++ {
+			extra = append(extra, tag)
+		}
+	}
+
+	sort.Strings(missing)
+	sort.Strings(extra)
+	return missing, extra
+}
+
+// readFlatJSON reads a JSON file and flattens it with the given separator
+func readFlatJSON(filePath, separator string) (map[string]any, error) {
+	data, err := utils.ReadJSONFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	options := fitter.DefaultFlattenOptions()
+	if separator != "" {
+		options.Separator = separator
+	}
+	return fitter.FlattenMapWithOptions(data, "", options), nil
+}
@@ -0,0 +1,44 @@
+package i18n
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareMarkup(t *testing.T) {
+	base := map[string]any{
+		"intro":   "Read the <Link>terms</Link> <b>now</b>",
+		"trans":   "Hello <0>{{name}}</0>",
+		"plain":   "No markup",
+		"swapped": "<b>bold</b> then <i>italic</i>",
+	}
+	target := map[string]any{
+		"intro":   "Lies die <Link>Bedingungen</Link> jetzt",
+		"trans":   "Hallo <0>{{name}}</0> <1>!</1>",
+		"plain":   "Kein Markup",
+		"swapped": "<i>kursiv</b> dann <b>fett</i>",
+	}
+
+	issues := CompareMarkup(base, target)
+
+	byKey := make(map[string]MarkupIssue)
+	for _, issue := range issues {
+		byKey[issue.Key] = issue
+	}
+
+	if len(issues) != 3 {
+		t.Fatalf("Expected 3 issues, got %v", issues)
+	}
+
+	if !reflect.DeepEqual(byKey["intro"].Missing, []string{"</b>", "<b>"}) {
+		t.Fatalf("Expected dropped <b> tags, got %v", byKey["intro"])
+	}
+
+	if !reflect.DeepEqual(byKey["trans"].Extra, []string{"</1>", "<1>"}) {
+		t.Fatalf("Expected added <1> placeholder, got %v", byKey["trans"])
+	}
+
+	if byKey["swapped"].Problem == "" {
+		t.Fatalf("Expected mismatched nesting to be reported, got %v", byKey["swapped"])
+	}
+}
@@ -0,0 +1,66 @@
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// KeyMetadata carries translator context for a single flattened key
+type KeyMetadata struct {
+	Description    string `json:"description,omitempty"`
+	Screenshot     string `json:"screenshot,omitempty"`
+	MaxLength      int    `json:"maxLength,omitempty"`
+	DoNotTranslate bool   `json:"doNotTranslate,omitempty"`
+}
+
+// Metadata maps flattened keys to their metadata
+type Metadata map[string]KeyMetadata
+
+// LoadMetadata reads a sidecar metadata JSON file keyed by flattened path
+func LoadMetadata(filePath string) (Metadata, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata file: %v", err)
+	}
+
+	metadata := make(Metadata)
+	if len(data) == 0 {
+		return metadata, nil
+	}
+
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata file: %v", err)
+	}
+
+	return metadata, nil
+}
+
+// Summary returns a one-line description of the metadata for reports
+func (m KeyMetadata) Summary() string {
+	var parts []string
+	if m.Description != "" {
+		parts = append(parts, m.Description)
+	}
+	if m.MaxLength > 0 {
+		parts = append(parts, fmt.Sprintf("max %d chars", m.MaxLength))
+	}
+	if m.DoNotTranslate {
+		parts = append(parts, "do not translate")
+	}
+	if m.Screenshot != "" {
+		parts = append(parts, m.Screenshot)
+	}
+	return strings.Join(parts, " | ")
+}
+
+// Describe returns the key annotated with its metadata summary, if any
+func (m Metadata) Describe(key string) string {
+	if meta, ok := m[key]; ok {
+		if summary := meta.Summary(); summary != "" {
+			return key + "  # " + summary
+		}
+	}
+	return key
+}
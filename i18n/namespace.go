@@ -0,0 +1,295 @@
+package i18n
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/haiyon/fitobj/fitter"
+	"github.com/haiyon/fitobj/utils"
+)
+
+// NamespaceOptions configures moving keys into a separate namespace file
+type NamespaceOptions struct {
+	LocalesDir  string   // root directory containing one sub-directory per language
+	SourceDir   string   // source directory whose references are rewritten (optional)
+	From        string   // namespace the keys are moved out of (default: "translation")
+	Namespace   string   // namespace the keys are moved into
+	Patterns    []string // glob patterns selecting keys to move
+	Separator   string   // separator for flattened keys
+	NsSeparator string   // separator between namespace and key in source (default: ":")
+	FuncNames   []string // translation functions whose references are rewritten: names, or expressions prefixed with "re:" (default: t)
+	DryRun      bool     // report changes without writing files
+}
+
+// NamespaceResult reports what a namespace extraction changed
+type NamespaceResult struct {
+	MovedKeys     map[string][]string // language -> keys moved
+	RewrittenRefs map[string]int      // source file -> references rewritten
+}
+
+// namespaceMove is the result of moving keys for a single language, written
+// once every language has been checked for conflicts
+type namespaceMove struct {
+	keys       []string
+	fromPath   string
+	remaining  map[string]any
+	targetPath string
+	target     map[string]any
+}
+
+// ExtractNamespace moves keys matching the patterns from the source namespace file
+// of every language into a new namespace file and rewrites source references.
+// Every language is checked for conflicting keys before any file is written.
+func ExtractNamespace(options NamespaceOptions) (*NamespaceResult, error) {
+	if options.Namespace == "" {
+		return nil, fmt.Errorf("namespace is required")
+	}
+	if len(options.Patterns) == 0 {
+		return nil, fmt.Errorf("at least one key pattern is required")
+	}
+	if options.From == "" {
+		options.From = "translation"
+	}
+	if options.From == options.Namespace {
+		return nil, fmt.Errorf("target namespace must differ from '%s'", options.From)
+	}
+	if options.Separator == "" {
+		options.Separator = "."
+	}
+	if options.NsSeparator == "" {
+		options.NsSeparator = ":"
+	}
+	if len(options.FuncNames) == 0 {
+		options.FuncNames = defaultNamespaceFuncNames
+	}
+	rewrite, err := namespaceRefPattern(options.FuncNames)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(options.LocalesDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read locales directory: %v", err)
+	}
+
+	result := &NamespaceResult{
+		MovedKeys:     make(map[string][]string),
+		RewrittenRefs: make(map[string]int),
+	}
+	moved := make(map[string]bool)
+
+	// Plan the moves of every language, so a conflict leaves every file untouched
+	var moves []namespaceMove
+	var conflicts []string
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		lang := entry.Name()
+		move, langConflicts, err := planNamespaceMove(filepath.Join(options.LocalesDir, lang), options)
+		if err != nil {
+			return nil, fmt.Errorf("language %s: %v", lang, err)
+		}
+		for _, conflict := range langConflicts {
+			conflicts = append(conflicts, fmt.Sprintf("language %s: %s", lang, conflict))
+		}
+		if len(move.keys) > 0 {
+			moves = append(moves, move)
+			result.MovedKeys[lang] = move.keys
+			for _, key := range move.keys {
+				moved[key] = true
+			}
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("%d conflicting keys, nothing was moved: %s", len(conflicts), strings.Join(conflicts, "; "))
+	}
+	if len(result.MovedKeys) == 0 {
+		return nil, fmt.Errorf("no keys matched in '%s' namespace files under %s", options.From, options.LocalesDir)
+	}
+
+	if !options.DryRun {
+		unflattenOpts := fitter.DefaultUnflattenOptions()
+		unflattenOpts.Separator = options.Separator
+		unflattenOpts.SupportBracketNotation = false
+		for _, move := range moves {
+			if err := utils.WriteJSONFile(move.targetPath, fitter.UnflattenMapWithOptions(move.target, unflattenOpts)); err != nil {
+				return nil, err
+			}
+			if err := utils.WriteJSONFile(move.fromPath, fitter.UnflattenMapWithOptions(move.remaining, unflattenOpts)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if options.SourceDir != "" && len(moved) > 0 {
+		refs, err := rewriteNamespaceRefs(options.SourceDir, moved, rewrite, options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to rewrite source references: %v", err)
+		}
+		result.RewrittenRefs = refs
+	}
+
+	return result, nil
+}
+
+// planNamespaceMove computes the moves of matching keys for a single language
+// directory without writing anything, with the keys already holding a
+// different value in the target namespace file
+func planNamespaceMove(langDir string, options NamespaceOptions) (namespaceMove, []string, error) {
+	move := namespaceMove{
+		fromPath:   filepath.Join(langDir, options.From+".json"),
+		targetPath: filepath.Join(langDir, options.Namespace+".json"),
+	}
+	if _, err := os.Stat(move.fromPath); os.IsNotExist(err) {
+		return move, nil, nil
+	}
+
+	fromData, err := utils.ReadJSONFile(move.fromPath)
+	if err != nil {
+		return move, nil, err
+	}
+
+	targetData := make(map[string]any)
+	if _, err := os.Stat(move.targetPath); err == nil {
+		if targetData, err = utils.ReadJSONFile(move.targetPath); err != nil {
+			return move, nil, err
+		}
+	}
+
+	flattenOpts := fitter.DefaultFlattenOptions()
+	flattenOpts.Separator = options.Separator
+
+	flatFrom := fitter.FlattenMapWithOptions(fromData, "", flattenOpts)
+	move.target = fitter.FlattenMapWithOptions(targetData, "", flattenOpts)
+	move.remaining = make(map[string]any, len(flatFrom))
+
+	var conflicts []string
+	for key, value := range flatFrom {
+		if !matchesAnyPattern(key, options.Patterns, options.Separator) {
+			move.remaining[key] = value
+			continue
+		}
+		if existing, ok := move.target[key]; ok && fmt.Sprint(existing) != fmt.Sprint(value) {
+			conflicts = append(conflicts, fmt.Sprintf("key '%s' already exists in %s with a different value", key, move.targetPath))
+			continue
+		}
+		move.target[key] = value
+		move.keys = append(move.keys, key)
+	}
+
+	sort.Strings(move.keys)
+	sort.Strings(conflicts)
+	return move, conflicts, nil
+}
+
+// matchesAnyPattern checks a flattened key against the namespace patterns
+func matchesAnyPattern(key string, patterns []string, separator string) bool {
+	for _, pattern := range patterns {
+		if fitter.MatchPathPrefix(pattern, key, separator) {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultNamespaceFuncNames are the translation functions rewritten by default
+var defaultNamespaceFuncNames = []string{"t"}
+
+// namespaceRefPattern matches calls of the translation functions while capturing
+// the key, quoted with single or double quotes, for rewriting. Functions are
+// names, or regular expressions prefixed with "re:".
+func namespaceRefPattern(funcNames []string) (*regexp.Regexp, error) {
+	alternatives := make([]string, 0, len(funcNames))
+	for _, name := range funcNames {
+		if expr, ok := strings.CutPrefix(name, "re:"); ok {
+			if _, err := regexp.Compile(expr); err != nil {
+				return nil, fmt.Errorf("invalid function pattern %q: %v", name, err)
+			}
+			alternatives = append(alternatives, "(?:"+expr+")")
+			continue
+		}
+
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("empty function name")
+		}
+		quoted := regexp.QuoteMeta(name)
+		// Names starting with a word character must not match the tail of a
+		// longer identifier
+		if c := name[0]; c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' {
+			quoted = `\b` + quoted
+		}
+		alternatives = append(alternatives, quoted)
+	}
+
+	// Named groups, as function patterns may hold groups of their own; each quote
+	// has its own alternative so a key closes with the quote it opened with
+	return regexp.Compile(`(?P<call>(?:` + strings.Join(alternatives, "|") + `)\(\s*)(?:'(?P<single>[^']+?)'|"(?P<double>[^"]+?)")`)
+}
+
+// rewriteNamespaceRefs prefixes moved keys in source files with the new namespace
+func rewriteNamespaceRefs(sourceDir string, moved map[string]bool, rewrite *regexp.Regexp, options NamespaceOptions) (map[string]int, error) {
+	refs := make(map[string]int)
+
+	err := filepath.WalkDir(sourceDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.IsDir() || !isTextFile(path) {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil // Ignore unreadable files
+		}
+
+		count := 0
+		updated := rewrite.ReplaceAllFunc(content, func(match []byte) []byte {
+			parts := rewrite.FindSubmatch(match)
+			quote, key := "'", parts[rewrite.SubexpIndex("single")]
+			if key == nil {
+				quote, key = `"`, parts[rewrite.SubexpIndex("double")]
+			}
+			if !moved[strings.TrimSpace(string(key))] {
+				return match
+			}
+			count++
+			return []byte(string(parts[rewrite.SubexpIndex("call")]) + quote +
+				options.Namespace + options.NsSeparator + string(key) + quote)
+		})
+
+		if count == 0 {
+			return nil
+		}
+
+		refs[path] = count
+		if options.DryRun {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(path, updated, info.Mode().Perm())
+	})
+
+	return refs, err
+}
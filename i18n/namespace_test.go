@@ -0,0 +1,205 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/haiyon/fitobj/utils"
+)
+
+func TestExtractNamespace(t *testing.T) {
+	tmpDir := t.TempDir()
+	localesDir := filepath.Join(tmpDir, "locales")
+	sourceDir := filepath.Join(tmpDir, "src")
+
+	locales := map[string]map[string]any{
+		"en": {
+			"buttons": map[string]any{"ok": "OK", "cancel": "Cancel"},
+			"title":   "Home",
+		},
+		"de": {
+			"buttons": map[string]any{"ok": "OK", "cancel": "Abbrechen"},
+			"title":   "Startseite",
+		},
+	}
+
+	for lang, content := range locales {
+		if err := utils.WriteJSONFile(filepath.Join(localesDir, lang, "translation.json"), content); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.MkdirAll(sourceDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	source := `const a = t('buttons.ok'); const b = t("title");`
+	sourceFile := filepath.Join(sourceDir, "app.js")
+	if err := os.WriteFile(sourceFile, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ExtractNamespace(NamespaceOptions{
+		LocalesDir: localesDir,
+		SourceDir:  sourceDir,
+		Namespace:  "common",
+		Patterns:   []string{"buttons.*"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedKeys := []string{"buttons.cancel", "buttons.ok"}
+	for lang := range locales {
+		if !reflect.DeepEqual(result.MovedKeys[lang], expectedKeys) {
+			t.Fatalf("Expected %v moved for %s, got %v", expectedKeys, lang, result.MovedKeys[lang])
+		}
+
+		remaining, err := utils.ReadJSONFile(filepath.Join(localesDir, lang, "translation.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, exists := remaining["buttons"]; exists {
+			t.Fatalf("buttons should have been moved out of %s/translation.json", lang)
+		}
+		if _, exists := remaining["title"]; !exists {
+			t.Fatalf("title should remain in %s/translation.json", lang)
+		}
+
+		common, err := utils.ReadJSONFile(filepath.Join(localesDir, lang, "common.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(common["buttons"], locales[lang]["buttons"]) {
+			t.Fatalf("Expected buttons in %s/common.json, got %v", lang, common)
+		}
+	}
+
+	updated, err := os.ReadFile(sourceFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(updated), "t('common:buttons.ok')") {
+		t.Fatalf("Expected rewritten reference, got %s", updated)
+	}
+	if !strings.Contains(string(updated), `t("title")`) {
+		t.Fatalf("Unmoved reference should be untouched, got %s", updated)
+	}
+	if result.RewrittenRefs[sourceFile] != 1 {
+		t.Fatalf("Expected 1 rewritten reference, got %v", result.RewrittenRefs)
+	}
+}
+
+func TestExtractNamespaceConflictWritesNothing(t *testing.T) {
+	localesDir := t.TempDir()
+	files := map[string]map[string]any{
+		"de/translation.json": {"buttons": map[string]any{"ok": "OK"}},
+		"en/translation.json": {"buttons": map[string]any{"ok": "OK"}},
+		// A later language holds a conflicting key in the target namespace
+		"fr/translation.json": {"buttons": map[string]any{"ok": "Valider"}},
+		"fr/common.json":      {"buttons": map[string]any{"ok": "D'accord"}},
+	}
+	for name, content := range files {
+		if err := utils.WriteJSONFile(filepath.Join(localesDir, name), content); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err := ExtractNamespace(NamespaceOptions{LocalesDir: localesDir, Namespace: "common", Patterns: []string{"buttons.*"}})
+	if err == nil || !strings.Contains(err.Error(), "language fr") {
+		t.Fatalf("Expected a conflict in fr, got %v", err)
+	}
+
+	for name, content := range files {
+		data, err := utils.ReadJSONFile(filepath.Join(localesDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(data, content) {
+			t.Errorf("Expected %s to be untouched, got %v", name, data)
+		}
+	}
+	for _, lang := range []string{"de", "en"} {
+		if _, err := os.Stat(filepath.Join(localesDir, lang, "common.json")); !os.IsNotExist(err) {
+			t.Errorf("Expected no common.json written for %s", lang)
+		}
+	}
+}
+
+func TestExtractNamespaceFuncNames(t *testing.T) {
+	tmpDir := t.TempDir()
+	localesDir := filepath.Join(tmpDir, "locales")
+	sourceDir := filepath.Join(tmpDir, "src")
+	if err := utils.WriteJSONFile(filepath.Join(localesDir, "en", "translation.json"), map[string]any{"buttons": map[string]any{"ok": "OK"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(sourceDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	sourceFile := filepath.Join(sourceDir, "app.js")
+	source := `i18n.t('buttons.ok'); $t("buttons.ok"); translate('buttons.ok'); format('buttons.ok')`
+	if err := os.WriteFile(sourceFile, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := ExtractNamespace(NamespaceOptions{
+		LocalesDir: localesDir,
+		SourceDir:  sourceDir,
+		Namespace:  "common",
+		Patterns:   []string{"buttons.*"},
+		FuncNames:  []string{"i18n.t", "$t", "re:trans(?:late)?"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := os.ReadFile(sourceFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `i18n.t('common:buttons.ok'); $t("common:buttons.ok"); translate('common:buttons.ok'); format('buttons.ok')`
+	if string(updated) != expected {
+		t.Errorf("Expected %s, got %s", expected, updated)
+	}
+}
+
+func TestNamespaceRefPatternQuotes(t *testing.T) {
+	rewrite, err := namespaceRefPattern(defaultNamespaceFuncNames)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		`t('buttons.ok')`:             `t('common:buttons.ok')`,
+		`t( "buttons.ok" )`:           `t( "common:buttons.ok" )`,
+		`t('buttons.ok")`:             `t('buttons.ok")`,
+		`t("buttons.ok')`:             `t("buttons.ok')`,
+		`t("it's")`:                   `t("common:it's")`,
+		`st('buttons.ok')`:            `st('buttons.ok')`,
+		`t('other'); t('buttons.ok')`: `t('other'); t('common:buttons.ok')`,
+	}
+	moved := map[string]bool{"buttons.ok": true, "it's": true}
+	for source, expected := range tests {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "app.js")
+		if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := rewriteNamespaceRefs(dir, moved, rewrite, NamespaceOptions{Namespace: "common", NsSeparator: ":"}); err != nil {
+			t.Fatal(err)
+		}
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != expected {
+			t.Errorf("%s: expected %s, got %s", source, expected, got)
+		}
+	}
+
+	if _, err := namespaceRefPattern([]string{"re:("}); err == nil {
+		t.Error("Expected an error for an invalid function pattern")
+	}
+}
@@ -0,0 +1,128 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Owner maps a team to the source roots it maintains and the keys it owns
+type Owner struct {
+	Team  string   `json:"team" mapstructure:"team"`
+	Roots []string `json:"roots" mapstructure:"roots"`
+	Keys  []string `json:"keys" mapstructure:"keys"` // key glob patterns
+}
+
+// Unowned is the team name used for findings no owner claims
+const Unowned = "(unowned)"
+
+// OwnerFindings holds the missing and unused keys routed to a team
+type OwnerFindings struct {
+	Team    string   `json:"team"`
+	Missing []string `json:"missing"`
+	Unused  []string `json:"unused"`
+}
+
+// ExtractKeyUsagesFromRoots collects key usages across several source roots
+func ExtractKeyUsagesFromRoots(owners []Owner) (map[string][]KeyUsage, error) {
+	return ExtractKeyUsagesFromRootsWithOptions(owners, DefaultExtractOptions())
+}
+
+// ExtractKeyUsagesFromRootsWithOptions collects usages of the configured function
+// calls across several source roots
+func ExtractKeyUsagesFromRootsWithOptions(owners []Owner, options ExtractOptions) (map[string][]KeyUsage, error) {
+	scanner, err := options.scanner()
+	if err != nil {
+		return nil, err
+	}
+
+	usages := make(map[string][]KeyUsage)
+	seen := make(map[string]bool)
+
+	for _, owner := range owners {
+		for _, root := range owner.Roots {
+			if seen[root] {
+				continue
+			}
+			seen[root] = true
+
+			rootUsages, err := extractKeyUsagesFromDir(root, scanner)
+			if err != nil {
+				return nil, fmt.Errorf("scanning %s for %s: %v", root, owner.Team, err)
+			}
+			for key, locations := range rootUsages {
+				usages[key] = append(usages[key], locations...)
+			}
+		}
+	}
+
+	return usages, nil
+}
+
+// KeyOwner returns the team owning a key by the most specific matching key glob
+func KeyOwner(key string, owners []Owner, separator string) string {
+	team, bestLen := Unowned, -1
+	for _, owner := range owners {
+		for _, pattern := range owner.Keys {
+			if len(pattern) > bestLen && matchesAnyPattern(key, []string{pattern}, separator) {
+				team, bestLen = owner.Team, len(pattern)
+			}
+		}
+	}
+	return team
+}
+
+// usageOwner returns the team whose source root contains a usage location
+func usageOwner(locations []KeyUsage, owners []Owner) string {
+	team, bestLen := "", -1
+	for _, location := range locations {
+		for _, owner := range owners {
+			for _, root := range owner.Roots {
+				if len(root) > bestLen && isUnder(location.File, root) {
+					team, bestLen = owner.Team, len(root)
+				}
+			}
+		}
+	}
+	return team
+}
+
+// GroupByOwner routes missing and unused keys to owning teams. Missing keys belong
+// to the team whose source root uses them, falling back to key ownership; unused
+// keys belong to the team owning the key.
+func GroupByOwner(missing, unused []string, usages map[string][]KeyUsage, owners []Owner, separator string) []OwnerFindings {
+	groups := make(map[string]*OwnerFindings)
+	group := func(team string) *OwnerFindings {
+		if groups[team] == nil {
+			groups[team] = &OwnerFindings{Team: team, Missing: []string{}, Unused: []string{}}
+		}
+		return groups[team]
+	}
+
+	for _, owner := range owners {
+		group(owner.Team)
+	}
+
+	for _, key := range missing {
+		team := usageOwner(usages[key], owners)
+		if team == "" {
+			team = KeyOwner(key, owners, separator)
+		}
+		g := group(team)
+		g.Missing = append(g.Missing, key)
+	}
+
+	for _, key := range unused {
+		g := group(KeyOwner(key, owners, separator))
+		g.Unused = append(g.Unused, key)
+	}
+
+	result := make([]OwnerFindings, 0, len(groups))
+	for _, g := range groups {
+		sort.Strings(g.Missing)
+		sort.Strings(g.Unused)
+		result = append(result, *g)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Team < result[j].Team })
+
+	return result
+}
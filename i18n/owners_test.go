@@ -0,0 +1,35 @@
+package i18n
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGroupByOwner(t *testing.T) {
+	owners := []Owner{
+		{Team: "checkout", Roots: []string{filepath.Join("apps", "checkout")}, Keys: []string{"checkout.**", "cart.**"}},
+		{Team: "platform", Roots: []string{filepath.Join("packages", "ui")}, Keys: []string{"common.**"}},
+	}
+
+	usages := map[string][]KeyUsage{
+		"common.newButton": {{File: filepath.Join("apps", "checkout", "pay.js")}},
+		"misc.title":       {{File: filepath.Join("other", "x.js")}},
+	}
+
+	groups := GroupByOwner(
+		[]string{"common.newButton", "misc.title"},
+		[]string{"cart.legacy", "common.old", "stray"},
+		usages, owners, ".",
+	)
+
+	expected := []OwnerFindings{
+		{Team: "(unowned)", Missing: []string{"misc.title"}, Unused: []string{"stray"}},
+		{Team: "checkout", Missing: []string{"common.newButton"}, Unused: []string{"cart.legacy"}},
+		{Team: "platform", Missing: []string{}, Unused: []string{"common.old"}},
+	}
+
+	if !reflect.DeepEqual(groups, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, groups)
+	}
+}
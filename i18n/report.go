@@ -0,0 +1,210 @@
+package i18n
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes findings as a JUnit XML report, with a test case per key in a
+// suite per kind. Missing keys are failures; unused keys are failures when
+// failUnused is set (as with --strict), and skipped test cases otherwise. The
+// locations of a key are listed in its failure.
+func WriteJUnit(w io.Writer, findings []Finding, failUnused bool) error {
+	report := junitTestSuites{Name: "fitobj i18n check"}
+	for _, kind := range []string{FindingMissing, FindingUnused} {
+		suite := junitTestSuite{Name: "i18n " + kind + " keys", Cases: []junitTestCase{}}
+		index := make(map[string]int)
+
+		for _, finding := range findings {
+			if finding.Kind != kind {
+				continue
+			}
+			location := findingLocation(finding)
+			if i, ok := index[finding.Key]; ok {
+				message := suite.Cases[i].Failure
+				if message == nil {
+					message = suite.Cases[i].Skipped
+				}
+				if location != "" {
+					message.Text += "\n" + location
+				}
+				continue
+			}
+
+			testCase := junitTestCase{Name: finding.Key, ClassName: "i18n." + kind, File: finding.File, Line: finding.Line}
+			message := &junitMessage{Message: finding.Message, Text: location}
+			if kind == FindingMissing || failUnused {
+				message.Type = kind
+				testCase.Failure = message
+				suite.Failures++
+			} else {
+				testCase.Skipped = message
+				suite.Skipped++
+			}
+			index[finding.Key] = len(suite.Cases)
+			suite.Cases = append(suite.Cases, testCase)
+		}
+
+		suite.Tests = len(suite.Cases)
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Suites = append(report.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// findingLocation formats the location of a finding as file:line
+func findingLocation(finding Finding) string {
+	if finding.File == "" {
+		return ""
+	}
+	if finding.Line > 0 {
+		return fmt.Sprintf("%s:%d", finding.File, finding.Line)
+	}
+	return finding.File
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver struct {
+		Name           string      `json:"name"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	} `json:"driver"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	DefaultLevel     struct {
+		Level string `json:"level"`
+	} `json:"defaultConfiguration"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			URI string `json:"uri"`
+		} `json:"artifactLocation"`
+		Region *sarifRegion `json:"region,omitempty"`
+	} `json:"physicalLocation"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// WriteSARIF writes findings as a SARIF 2.1.0 log, as read by GitHub code
+// scanning: missing keys are errors and unused keys warnings, located at their
+// source usage or locale file definition
+func WriteSARIF(w io.Writer, findings []Finding) error {
+	run := sarifRun{Results: []sarifResult{}}
+	run.Tool.Driver.Name = "fitobj"
+	run.Tool.Driver.InformationURI = "https://github.com/haiyon/fitobj"
+	for _, kind := range []string{FindingMissing, FindingUnused} {
+		rule := sarifRule{ID: sarifRuleID(kind), ShortDescription: sarifMessage{Text: "i18n key used but missing from translations"}}
+		rule.DefaultLevel.Level = sarifLevel(kind)
+		if kind == FindingUnused {
+			rule.ShortDescription.Text = "i18n key defined but never used in source"
+		}
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+	}
+
+	for _, finding := range findings {
+		result := sarifResult{RuleID: sarifRuleID(finding.Kind), Level: sarifLevel(finding.Kind), Message: sarifMessage{Text: finding.Message}}
+		if finding.File != "" {
+			var location sarifLocation
+			location.PhysicalLocation.ArtifactLocation.URI = strings.TrimPrefix(filepath.ToSlash(finding.File), "./")
+			if finding.Line > 0 {
+				location.PhysicalLocation.Region = &sarifRegion{StartLine: finding.Line}
+			}
+			result.Locations = []sarifLocation{location}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
+
+func sarifRuleID(kind string) string {
+	return "i18n-" + kind + "-key"
+}
+
+func sarifLevel(kind string) string {
+	if kind == FindingUnused {
+		return "warning"
+	}
+	return "error"
+}
@@ -0,0 +1,72 @@
+package i18n
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	findings := []Finding{
+		{Kind: FindingMissing, Key: "new.key", File: "src/app.js", Line: 12, Message: "i18n key 'new.key' is used but missing from translations"},
+		{Kind: FindingMissing, Key: "new.key", File: "src/page.js", Line: 3, Message: "i18n key 'new.key' is used but missing from translations"},
+		{Kind: FindingUnused, Key: "old.key", File: "en.json", Line: 2, Message: "i18n key 'old.key' is defined but never used in source"},
+	}
+
+	var b strings.Builder
+	if err := WriteJUnit(&b, findings, false); err != nil {
+		t.Fatal(err)
+	}
+	report := b.String()
+
+	for _, expected := range []string{
+		`<testsuites name="fitobj i18n check" tests="2" failures="1">`,
+		`<testcase name="new.key" classname="i18n.missing" file="src/app.js" line="12">`,
+		"<failure message=\"i18n key &#39;new.key&#39; is used but missing from translations\" type=\"missing\">src/app.js:12&#xA;src/page.js:3</failure>",
+		`<skipped message="i18n key &#39;old.key&#39; is defined but never used in source">en.json:2</skipped>`,
+	} {
+		if !strings.Contains(report, expected) {
+			t.Errorf("Expected report to contain %s, got:\n%s", expected, report)
+		}
+	}
+
+	b.Reset()
+	if err := WriteJUnit(&b, findings, true); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), `failures="2"`) {
+		t.Errorf("Expected unused keys to fail, got:\n%s", b.String())
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	findings := []Finding{
+		{Kind: FindingMissing, Key: "new.key", File: "./src/app.js", Line: 12, Message: "missing"},
+		{Kind: FindingUnused, Key: "old.key", Message: "unused"},
+	}
+
+	var b strings.Builder
+	if err := WriteSARIF(&b, findings); err != nil {
+		t.Fatal(err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal([]byte(b.String()), &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || len(log.Runs[0].Tool.Driver.Rules) != 2 {
+		t.Fatalf("Unexpected SARIF log: %s", b.String())
+	}
+
+	results := log.Runs[0].Results
+	if len(results) != 2 || results[0].RuleID != "i18n-missing-key" || results[0].Level != "error" || results[1].Level != "warning" {
+		t.Fatalf("Unexpected results: %+v", results)
+	}
+	location := results[0].Locations[0].PhysicalLocation
+	if location.ArtifactLocation.URI != "src/app.js" || location.Region.StartLine != 12 {
+		t.Errorf("Unexpected location: %+v", location)
+	}
+	if len(results[1].Locations) != 0 {
+		t.Errorf("Expected no location for a finding without file, got %+v", results[1].Locations)
+	}
+}
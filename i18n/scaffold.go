@@ -0,0 +1,228 @@
+package i18n
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/haiyon/fitobj/utils"
+)
+
+// ScaffoldOptions configures adding the keys used in source to locale files
+type ScaffoldOptions struct {
+	Placeholder string      // value of added keys: PlaceholderEmpty (default), PlaceholderKey, PlaceholderTODO or PlaceholderBase
+	BaseLocale  string      // locale file whose values PlaceholderBase and PlaceholderTODO use (optional)
+	Separator   string      // separator of key paths (default ".")
+	Rules       SuffixRules // variants that make a key present, as in CompareKeysWithRules
+	DryRun      bool        // report the keys without writing
+}
+
+// ScaffoldChange describes the keys added, or to be added, to one locale file
+type ScaffoldChange struct {
+	File    string         `json:"file"`
+	Added   []string       `json:"added"`
+	Values  map[string]any `json:"values"`            // added key -> its placeholder value
+	Skipped []string       `json:"skipped,omitempty"` // keys whose parent holds a value instead of an object
+}
+
+// AddMissingKeys adds the source keys each locale file of a path lacks, with a
+// placeholder value. New keys are nested under their parent objects after the
+// existing keys, and files keep their key order and indentation.
+func AddMissingKeys(jsonPath string, sourceKeys map[string]bool, options ScaffoldOptions) ([]ScaffoldChange, error) {
+	if err := ValidatePlaceholder(options.Placeholder); err != nil {
+		return nil, err
+	}
+	separator := options.Separator
+	if separator == "" {
+		separator = "."
+	}
+
+	files, err := listJSONFiles(jsonPath)
+	if err != nil {
+		return nil, err
+	}
+
+	baseValues := map[string]any{}
+	if options.BaseLocale != "" {
+		dir := jsonPath
+		if info, err := os.Stat(jsonPath); err == nil && !info.IsDir() {
+			dir = filepath.Dir(jsonPath)
+		}
+		basePath := filepath.Join(dir, options.BaseLocale+".json")
+		if _, err := os.Stat(basePath); err == nil {
+			if baseValues, err = readFlatJSON(basePath, separator); err != nil {
+				return nil, fmt.Errorf("failed to read base locale: %v", err)
+			}
+		}
+	}
+
+	var changes []ScaffoldChange
+	for _, file := range files {
+		change, err := scaffoldJSONFile(file, sourceKeys, baseValues, separator, options)
+		if err != nil {
+			// Report the files already changed along with the error
+			return changes, fmt.Errorf("failed to add keys to %s: %v", file, err)
+		}
+		if change != nil {
+			changes = append(changes, *change)
+		}
+	}
+	return changes, nil
+}
+
+// scaffoldJSONFile adds the missing keys to a single locale file, returning nil
+// when it misses none
+func scaffoldJSONFile(filePath string, sourceKeys map[string]bool, baseValues map[string]any, separator string, options ScaffoldOptions) (*ScaffoldChange, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JSON file: %v", err)
+	}
+	data, err := utils.ParseOrderedJSON(content)
+	if err != nil {
+		return nil, err
+	}
+
+	flat, err := readFlatJSON(filePath, separator)
+	if err != nil && len(bytes.TrimSpace(content)) > 0 {
+		return nil, err
+	}
+	localeKeys := make(map[string]bool, len(flat))
+	for key := range flat {
+		localeKeys[key] = true
+	}
+	missing, _ := CompareKeysWithRules(sourceKeys, localeKeys, options.Rules)
+	if len(missing) == 0 {
+		return nil, nil
+	}
+
+	change := &ScaffoldChange{File: filePath, Added: []string{}, Values: make(map[string]any)}
+	for _, key := range missing {
+		baseValue, ok := baseValues[key]
+		if !ok {
+			baseValue = key
+			if options.Placeholder == PlaceholderBase {
+				baseValue = ""
+			}
+		}
+		value := PlaceholderValue(options.Placeholder, key, baseValue)
+		if insertKey(data, splitKeyPath(key, separator), value) {
+			change.Added = append(change.Added, key)
+			change.Values[key] = value
+		} else {
+			change.Skipped = append(change.Skipped, key)
+		}
+	}
+	sort.Strings(change.Skipped)
+
+	if options.DryRun || len(change.Added) == 0 {
+		return change, nil
+	}
+
+	var buf bytes.Buffer
+	if err := writeIndentedJSON(&buf, data, "", detectIndent(content)); err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON: %v", err)
+	}
+	if len(content) == 0 || bytes.HasSuffix(content, []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+	if err := os.WriteFile(filePath, buf.Bytes(), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write JSON file: %v", err)
+	}
+	return change, nil
+}
+
+// insertKey sets the value of a key path, creating the missing parent objects.
+// It reports false when a parent holds another value.
+func insertKey(data *utils.OrderedMap, parts []string, value any) bool {
+	if len(parts) == 0 {
+		return false
+	}
+	current := data
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current.Get(part)
+		if !ok {
+			child := utils.NewOrderedMap()
+			current.Set(part, child)
+			current = child
+			continue
+		}
+		if current, ok = next.(*utils.OrderedMap); !ok {
+			return false
+		}
+	}
+	if _, exists := current.Get(parts[len(parts)-1]); exists {
+		return false
+	}
+	current.Set(parts[len(parts)-1], value)
+	return true
+}
+
+// detectIndent returns the indentation of the first nested line of a JSON
+// document, two spaces when it has none
+func detectIndent(content []byte) string {
+	for _, line := range strings.Split(string(content), "\n")[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed != "" && len(trimmed) < len(line) {
+			return line[:len(line)-len(trimmed)]
+		}
+	}
+	return "  "
+}
+
+// writeIndentedJSON writes a value with objects in their order and strings
+// without HTML escaping, as translations often hold markup
+func writeIndentedJSON(buf *bytes.Buffer, value any, prefix, indent string) error {
+	inner := prefix + indent
+	switch v := value.(type) {
+	case *utils.OrderedMap:
+		if v.Len() == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+		buf.WriteString("{")
+		for i, key := range v.Keys() {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n" + inner)
+			if err := writeIndentedJSON(buf, key, inner, indent); err != nil {
+				return err
+			}
+			buf.WriteString(": ")
+			item, _ := v.Get(key)
+			if err := writeIndentedJSON(buf, item, inner, indent); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("\n" + prefix + "}")
+	case []any:
+		if len(v) == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+		buf.WriteString("[")
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n" + inner)
+			if err := writeIndentedJSON(buf, item, inner, indent); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("\n" + prefix + "]")
+	default:
+		var scalar bytes.Buffer
+		encoder := json.NewEncoder(&scalar)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(v); err != nil {
+			return err
+		}
+		buf.Write(bytes.TrimSuffix(scalar.Bytes(), []byte("\n")))
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAddMissingKeys(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"en.json": "{\n    \"zeta\": \"Last <b>bold</b>\",\n    \"home\": {\n        \"title\": \"Home\"\n    },\n    \"item_one\": \"item\"\n}\n",
+		"de.json": "{\n  \"zeta\": \"Letzte\",\n  \"label\": \"Text\"\n}",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sourceKeys := map[string]bool{"zeta": true, "home.title": true, "home.intro": true, "item": true, "label.short": true}
+	changes, err := AddMissingKeys(dir, sourceKeys, ScaffoldOptions{Placeholder: PlaceholderTODO, BaseLocale: "en", Rules: DefaultSuffixRules()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []ScaffoldChange{
+		{
+			File:    filepath.Join(dir, "de.json"),
+			Added:   []string{"home.intro", "home.title", "item"},
+			Values:  map[string]any{"home.intro": "TODO: home.intro", "home.title": "TODO: Home", "item": "TODO: item"},
+			Skipped: []string{"label.short"},
+		},
+		{
+			File:   filepath.Join(dir, "en.json"),
+			Added:  []string{"home.intro", "label.short"},
+			Values: map[string]any{"home.intro": "TODO: home.intro", "label.short": "TODO: label.short"},
+		},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, changes)
+	}
+
+	// Key order, indentation, markup and the trailing newline are kept
+	en, _ := os.ReadFile(filepath.Join(dir, "en.json"))
+	expectedEN := "{\n    \"zeta\": \"Last <b>bold</b>\",\n    \"home\": {\n        \"title\": \"Home\",\n        \"intro\": \"TODO: home.intro\"\n    },\n    \"item_one\": \"item\",\n    \"label\": {\n        \"short\": \"TODO: label.short\"\n    }\n}\n"
+	if string(en) != expectedEN {
+		t.Errorf("Expected en.json:\n%s\ngot:\n%s", expectedEN, en)
+	}
+	de, _ := os.ReadFile(filepath.Join(dir, "de.json"))
+	expectedDE := "{\n  \"zeta\": \"Letzte\",\n  \"label\": \"Text\",\n  \"home\": {\n    \"intro\": \"TODO: home.intro\",\n    \"title\": \"TODO: Home\"\n  },\n  \"item\": \"TODO: item\"\n}"
+	if string(de) != expectedDE {
+		t.Errorf("Expected de.json:\n%s\ngot:\n%s", expectedDE, de)
+	}
+}
@@ -0,0 +1,190 @@
+package i18n
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// keyScanner finds translation calls in source files and resolves their keys,
+// dropping explicit namespaces and applying the keyPrefix of the hook in scope
+type keyScanner struct {
+	calls        *regexp.Regexp
+	dynamic      *regexp.Regexp // calls whose key is built at runtime
+	nsSeparator  string
+	keySeparator string
+	goCallees    *goCallees // translation functions of parsed Go files
+	workers      int        // files scanned in parallel by directory scans
+	exclude      []string   // paths left out of directory scans, as .gitignore lines
+	noGitignore  bool       // scan the paths .gitignore files exclude too
+}
+
+// keyRef is a resolved key referenced at an offset of a source file. For keys
+// built at runtime, key is the static prefix and expr the source of the argument.
+type keyRef struct {
+	key     string
+	offset  int
+	dynamic bool
+	expr    string
+}
+
+// hookScope is the keyPrefix set by a useTranslation call
+type hookScope struct {
+	offset    int
+	keyPrefix string
+}
+
+var defaultScanner = &keyScanner{calls: tPattern, dynamic: tDynamicPattern, nsSeparator: ":", keySeparator: ".",
+	goCallees: &goCallees{names: map[string]bool{"t": true}}}
+
+// Patterns to match react-i18next hooks such as useTranslation('ns', { keyPrefix: 'settings' })
+var (
+	hookPattern      = regexp.MustCompile(`\buseTranslation\(([^)]*)\)`)
+	keyPrefixPattern = regexp.MustCompile(`\bkeyPrefix\s*:\s*['"]([^'"]+)['"]`)
+)
+
+// scanner builds the key scanner for the options
+func (o ExtractOptions) scanner() (*keyScanner, error) {
+	calls, err := o.CallPattern()
+	if err != nil {
+		return nil, err
+	}
+	dynamic, err := o.DynamicCallPattern()
+	if err != nil {
+		return nil, err
+	}
+
+	goNames := o.GoFuncNames
+	if len(goNames) == 0 {
+		goNames = o.FuncNames
+	}
+	if len(goNames) == 0 {
+		goNames = []string{"t"}
+	}
+	goCallees, err := newGoCallees(goNames)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateExcludes(o.Exclude); err != nil {
+		return nil, err
+	}
+
+	scanner := &keyScanner{calls: calls, dynamic: dynamic, nsSeparator: o.NsSeparator, keySeparator: o.KeySeparator, goCallees: goCallees, workers: o.Workers,
+		exclude: o.Exclude, noGitignore: o.NoGitignore}
+	if scanner.nsSeparator == "" {
+		scanner.nsSeparator = ":"
+	}
+	if scanner.keySeparator == "" {
+		scanner.keySeparator = "."
+	}
+	return scanner, nil
+}
+
+// scan returns the keys of the translation calls in content, in source order.
+// Each call is resolved against the closest useTranslation hook before it, so
+// components in one file may use different prefixes. Calls whose key is built at
+// runtime are returned as dynamic refs holding the static start of the key.
+func (s *keyScanner) scan(content []byte) []keyRef {
+	var scopes []hookScope
+	for _, match := range hookPattern.FindAllSubmatchIndex(content, -1) {
+		scope := hookScope{offset: match[0]}
+		if prefix := keyPrefixPattern.FindSubmatch(content[match[2]:match[3]]); prefix != nil {
+			scope.keyPrefix = string(prefix[1])
+		}
+		scopes = append(scopes, scope)
+	}
+
+	var refs []keyRef
+	for _, match := range s.calls.FindAllSubmatchIndex(content, -1) {
+		if len(match) < 4 {
+			continue
+		}
+
+		key := strings.TrimSpace(string(content[match[len(match)-2]:match[len(match)-1]]))
+		if key == "" {
+			continue
+		}
+
+		// 'errors.' + code: the literal is only the start of the key
+		if rest := bytes.TrimLeft(content[match[1]:], " \t\r\n"); len(rest) > 0 && rest[0] == '+' {
+			start := match[len(match)-2] - 1
+			end := match[1] + argumentEnd(content[match[1]:])
+			refs = append(refs, keyRef{key: key, offset: match[0], dynamic: true, expr: strings.TrimSpace(string(content[start:end]))})
+			continue
+		}
+		refs = append(refs, keyRef{key: key, offset: match[0]})
+	}
+
+	if s.dynamic != nil {
+		for _, match := range s.dynamic.FindAllSubmatchIndex(content, -1) {
+			if len(match) < 4 {
+				continue
+			}
+
+			arg := strings.TrimSpace(string(content[match[len(match)-2]:match[len(match)-1]]))
+			if template, ok := strings.CutPrefix(arg, "`"); ok {
+				template = strings.TrimSuffix(template, "`")
+				prefix, _, interpolated := strings.Cut(template, "${")
+				if !interpolated {
+					// A template literal without placeholders is a plain key
+					if key := strings.TrimSpace(template); key != "" {
+						refs = append(refs, keyRef{key: key, offset: match[0]})
+					}
+					continue
+				}
+				refs = append(refs, keyRef{key: prefix, offset: match[0], dynamic: true, expr: arg})
+				continue
+			}
+			refs = append(refs, keyRef{offset: match[0], dynamic: true, expr: arg})
+		}
+		sort.SliceStable(refs, func(i, j int) bool { return refs[i].offset < refs[j].offset })
+	}
+
+	current := hookScope{}
+	for i := range refs {
+		for len(scopes) > 0 && scopes[0].offset < refs[i].offset {
+			current, scopes = scopes[0], scopes[1:]
+		}
+		refs[i].key = s.resolve(refs[i].key, current)
+	}
+
+	return refs
+}
+
+// argumentEnd returns the length of the rest of a call argument, up to the comma
+// or closing parenthesis ending it outside of nested parentheses and strings
+func argumentEnd(content []byte) int {
+	depth := 0
+	var quote byte
+	for i, c := range content {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case (c == ')' || c == ']' || c == '}') && depth > 0:
+			depth--
+		case (c == ',' || c == ')' || c == '\n') && depth == 0:
+			return i
+		}
+	}
+	return len(content)
+}
+
+// resolve strips an explicit namespace ("common:buttons.ok") from a key and applies
+// the keyPrefix of its scope. Locale files hold keys without namespaces, so keys
+// are compared without them.
+func (s *keyScanner) resolve(key string, scope hookScope) string {
+	if ns, rest, ok := strings.Cut(key, s.nsSeparator); ok && ns != "" && rest != "" && !strings.ContainsAny(ns, " \t") {
+		key = rest
+	}
+	if scope.keyPrefix != "" {
+		key = scope.keyPrefix + s.keySeparator + key
+	}
+	return key
+}
@@ -0,0 +1,185 @@
+package i18n
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/haiyon/fitobj/fitter"
+	"github.com/haiyon/fitobj/utils"
+)
+
+// SplitOptions configures partitioning a bundle by where keys are used
+type SplitOptions struct {
+	SourceDir   string            // source root the usage locations are relative to
+	Routes      map[string]string // chunk name -> directory; when empty, chunks follow directories
+	Depth       int               // directory depth used to name chunks when Routes is empty (default: 1)
+	SharedChunk string            // chunk for keys used by several chunks (default: "shared")
+	UnusedChunk string            // chunk for keys used nowhere ("" = drop them)
+	Separator   string            // separator for flattened keys
+}
+
+// ChunkManifest describes a single emitted chunk
+type ChunkManifest struct {
+	File string   `json:"file"`
+	Keys int      `json:"keys"`
+	Dirs []string `json:"dirs,omitempty"`
+}
+
+// SplitManifest maps chunks to their files, for loading translations lazily
+type SplitManifest struct {
+	Source string                   `json:"source"`
+	Shared string                   `json:"shared"`
+	Chunks map[string]ChunkManifest `json:"chunks"`
+}
+
+// SplitResult holds the flattened contents of every chunk and the manifest
+type SplitResult struct {
+	Chunks   map[string]map[string]any
+	Manifest SplitManifest
+	Dropped  []string
+}
+
+// SplitBundle partitions a locale bundle into chunks matching where keys are used.
+// Keys used from a single chunk go to that chunk, keys shared between chunks go to
+// the shared chunk, and unused keys go to the unused chunk or are dropped.
+func SplitBundle(filePath string, usages map[string][]KeyUsage, options SplitOptions) (*SplitResult, error) {
+	if options.Separator == "" {
+		options.Separator = "."
+	}
+	if options.Depth <= 0 {
+		options.Depth = 1
+	}
+	if options.SharedChunk == "" {
+		options.SharedChunk = "shared"
+	}
+
+	flat, err := readFlatJSON(filePath, options.Separator)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SplitResult{
+		Chunks: make(map[string]map[string]any),
+		Manifest: SplitManifest{
+			Source: filepath.Base(filePath),
+			Shared: options.SharedChunk,
+			Chunks: make(map[string]ChunkManifest),
+		},
+	}
+	dirs := make(map[string]map[string]bool)
+
+	for key, value := range flat {
+		chunks := make(map[string]bool)
+		for _, usage := range usages[key] {
+			if chunk, dir := chunkFor(usage.File, options); chunk != "" {
+				chunks[chunk] = true
+				if dirs[chunk] == nil {
+					dirs[chunk] = make(map[string]bool)
+				}
+				dirs[chunk][dir] = true
+			}
+		}
+
+		var target string
+		switch len(chunks) {
+		case 0:
+			if options.UnusedChunk == "" {
+				result.Dropped = append(result.Dropped, key)
+				continue
+			}
+			target = options.UnusedChunk
+		case 1:
+			for chunk := range chunks {
+				target = chunk
+			}
+		default:
+			target = options.SharedChunk
+		}
+
+		if result.Chunks[target] == nil {
+			result.Chunks[target] = make(map[string]any)
+		}
+		result.Chunks[target][key] = value
+	}
+
+	for chunk, keys := range result.Chunks {
+		manifest := ChunkManifest{File: chunk + ".json", Keys: len(keys)}
+		for dir := range dirs[chunk] {
+			manifest.Dirs = append(manifest.Dirs, dir)
+		}
+		sort.Strings(manifest.Dirs)
+		result.Manifest.Chunks[chunk] = manifest
+	}
+	sort.Strings(result.Dropped)
+
+	return result, nil
+}
+
+// chunkFor returns the chunk name and matched directory for a source file
+func chunkFor(file string, options SplitOptions) (string, string) {
+	if len(options.Routes) > 0 {
+		// Prefer the most specific (longest) matching route directory
+		best, bestDir := "", ""
+		for name, dir := range options.Routes {
+			if isUnder(file, dir) && len(dir) > len(bestDir) {
+				best, bestDir = name, dir
+			}
+		}
+		return best, bestDir
+	}
+
+	rel, err := filepath.Rel(options.SourceDir, file)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", ""
+	}
+
+	parts := strings.Split(filepath.ToSlash(filepath.Dir(rel)), "/")
+	if len(parts) == 1 && parts[0] == "." {
+		return "root", "."
+	}
+	if len(parts) > options.Depth {
+		parts = parts[:options.Depth]
+	}
+	dir := strings.Join(parts, "/")
+	return strings.Join(parts, "-"), dir
+}
+
+// isUnder reports whether a file lies within a directory
+func isUnder(file, dir string) bool {
+	rel, err := filepath.Rel(filepath.Clean(dir), file)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// WriteSplit writes every chunk as a nested JSON file plus manifest.json to a directory
+func WriteSplit(outDir string, result *SplitResult, separator string) error {
+	options := fitter.DefaultUnflattenOptions()
+	if separator != "" {
+		options.Separator = separator
+	}
+	options.SupportBracketNotation = false
+
+	for chunk, flat := range result.Chunks {
+		path := filepath.Join(outDir, result.Manifest.Chunks[chunk].File)
+		if err := utils.WriteJSONFile(path, fitter.UnflattenMapWithOptions(flat, options)); err != nil {
+			return fmt.Errorf("failed to write chunk %s: %v", chunk, err)
+		}
+	}
+
+	manifest := map[string]any{
+		"source": result.Manifest.Source,
+		"shared": result.Manifest.Shared,
+	}
+	chunks := make(map[string]any, len(result.Manifest.Chunks))
+	for name, chunk := range result.Manifest.Chunks {
+		entry := map[string]any{"file": chunk.File, "keys": chunk.Keys}
+		if len(chunk.Dirs) > 0 {
+			entry["dirs"] = chunk.Dirs
+		}
+		chunks[name] = entry
+	}
+	manifest["chunks"] = chunks
+
+	return utils.WriteJSONFile(filepath.Join(outDir, "manifest.json"), manifest)
+}
@@ -0,0 +1,61 @@
+package i18n
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/haiyon/fitobj/utils"
+)
+
+func TestSplitBundle(t *testing.T) {
+	tmpDir := t.TempDir()
+	bundlePath := filepath.Join(tmpDir, "en.json")
+	content := map[string]any{
+		"admin":  map[string]any{"title": "Admin"},
+		"shop":   map[string]any{"title": "Shop"},
+		"common": map[string]any{"save": "Save"},
+		"legacy": "Old",
+	}
+	if err := utils.WriteJSONFile(bundlePath, content); err != nil {
+		t.Fatal(err)
+	}
+
+	src := filepath.Join(tmpDir, "src")
+	usages := map[string][]KeyUsage{
+		"admin.title": {{File: filepath.Join(src, "admin", "page.js")}},
+		"shop.title":  {{File: filepath.Join(src, "shop", "cart", "page.js")}},
+		"common.save": {
+			{File: filepath.Join(src, "admin", "form.js")},
+			{File: filepath.Join(src, "shop", "form.js")},
+		},
+	}
+
+	result, err := SplitBundle(bundlePath, usages, SplitOptions{SourceDir: src})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]map[string]any{
+		"admin":  {"admin.title": "Admin"},
+		"shop":   {"shop.title": "Shop"},
+		"shared": {"common.save": "Save"},
+	}
+	if !reflect.DeepEqual(result.Chunks, expected) {
+		t.Fatalf("Expected chunks %v, got %v", expected, result.Chunks)
+	}
+	if !reflect.DeepEqual(result.Dropped, []string{"legacy"}) {
+		t.Fatalf("Expected legacy to be dropped, got %v", result.Dropped)
+	}
+	if result.Manifest.Chunks["shop"].File != "shop.json" {
+		t.Fatalf("Unexpected manifest: %v", result.Manifest)
+	}
+
+	outDir := filepath.Join(tmpDir, "out")
+	if err := WriteSplit(outDir, result, "."); err != nil {
+		t.Fatal(err)
+	}
+	if keys, err := ExtractKeysFromJSON(filepath.Join(outDir, "shared.json")); err != nil || !keys["common.save"] {
+		t.Fatalf("Expected shared.json to contain common.save, got %v (%v)", keys, err)
+	}
+}
@@ -0,0 +1,203 @@
+package i18n
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/haiyon/fitobj/fitter"
+)
+
+// bundle is a loaded locale file
+type bundle struct {
+	flat map[string]any
+	etag string
+}
+
+// BundleStore holds flattened locale bundles in memory for concurrent lookups
+// and reloads them when the locale files change
+type BundleStore struct {
+	mu      sync.RWMutex
+	dir     string
+	options fitter.FlattenOptions
+	bundles map[string]*bundle
+}
+
+// NewBundleStore loads every locale JSON file in a directory
+func NewBundleStore(dir string, options fitter.FlattenOptions) (*BundleStore, error) {
+	store := &BundleStore{
+		dir:     dir,
+		options: options,
+		bundles: make(map[string]*bundle),
+	}
+
+	if err := store.Reload(); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// Reload re-reads every locale file, replacing the loaded bundles atomically
+func (s *BundleStore) Reload() error {
+	files, err := listJSONFiles(s.dir)
+	if err != nil {
+		return err
+	}
+
+	bundles := make(map[string]*bundle, len(files))
+	for _, file := range files {
+		b, err := s.load(file)
+		if err != nil {
+			return err
+		}
+		bundles[localeFromPath(file)] = b
+	}
+
+	s.mu.Lock()
+	s.bundles = bundles
+	s.mu.Unlock()
+
+	return nil
+}
+
+// reloadFile re-reads a single locale file, or drops it when it no longer exists
+func (s *BundleStore) reloadFile(file string) error {
+	locale := localeFromPath(file)
+
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		s.mu.Lock()
+		delete(s.bundles, locale)
+		s.mu.Unlock()
+		return nil
+	}
+
+	b, err := s.load(file)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.bundles[locale] = b
+	s.mu.Unlock()
+
+	return nil
+}
+
+// load reads and flattens a locale file and computes its ETag
+func (s *BundleStore) load(file string) (*bundle, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", file, err)
+	}
+
+	nested := make(map[string]any)
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &nested); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", file, err)
+		}
+	}
+
+	sum := sha256.Sum256(data)
+	return &bundle{
+		flat: fitter.FlattenMapWithOptions(nested, "", s.options),
+		etag: `"` + hex.EncodeToString(sum[:16]) + `"`,
+	}, nil
+}
+
+// Lookup returns the value of a flattened key in a locale with the bundle ETag
+func (s *BundleStore) Lookup(locale, key string) (any, string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	b, ok := s.bundles[locale]
+	if !ok {
+		return nil, "", false
+	}
+
+	value, ok := b.flat[key]
+	return value, b.etag, ok
+}
+
+// Bundle returns a copy of the flattened bundle of a locale with its ETag
+func (s *BundleStore) Bundle(locale string) (map[string]any, string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	b, ok := s.bundles[locale]
+	if !ok {
+		return nil, "", false
+	}
+
+	flat := make(map[string]any, len(b.flat))
+	for key, value := range b.flat {
+		flat[key] = value
+	}
+	return flat, b.etag, true
+}
+
+// Locales returns the loaded locales with their ETags
+func (s *BundleStore) Locales() map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	locales := make(map[string]string, len(s.bundles))
+	for locale, b := range s.bundles {
+		locales[locale] = b.etag
+	}
+	return locales
+}
+
+// Watch reloads locale files as they change until stop is closed. Reload errors
+// are passed to onError and the previous bundle is kept.
+func (s *BundleStore) Watch(stop <-chan struct{}, onError func(error)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create watcher: %v", err)
+	}
+
+	if err := watcher.Add(s.dir); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch %s: %v", s.dir, err)
+	}
+
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case <-stop:
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Ext(event.Name) != ".json" || event.Op == fsnotify.Chmod {
+					continue
+				}
+				if err := s.reloadFile(event.Name); err != nil && onError != nil {
+					onError(err)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				if onError != nil {
+					onError(err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// localeFromPath derives the locale name from a locale file path
+func localeFromPath(file string) string {
+	return strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+}
@@ -0,0 +1,63 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/haiyon/fitobj/fitter"
+)
+
+func TestBundleStore(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "en.json")
+	if err := os.WriteFile(file, []byte(`{"home": {"title": "Home"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := NewBundleStore(dir, fitter.DefaultFlattenOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value, etag, ok := store.Lookup("en", "home.title")
+	if !ok || value != "Home" || etag == "" {
+		t.Fatalf("Unexpected lookup: %v %q %v", value, etag, ok)
+	}
+
+	if _, etag, _ := store.Lookup("fr", "home.title"); etag != "" {
+		t.Fatalf("Expected no ETag for unknown locale, got %q", etag)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			store.Lookup("en", "home.title")
+			store.Bundle("en")
+		}()
+	}
+
+	if err := os.WriteFile(file, []byte(`{"home": {"title": "Start"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.reloadFile(file); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+
+	value, newEtag, _ := store.Lookup("en", "home.title")
+	if value != "Start" || newEtag == etag {
+		t.Fatalf("Expected reloaded value with new ETag, got %v %q", value, newEtag)
+	}
+
+	os.Remove(file)
+	if err := store.reloadFile(file); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := store.Bundle("en"); ok {
+		t.Fatal("Expected removed locale to be dropped")
+	}
+}
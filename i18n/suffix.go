@@ -0,0 +1,109 @@
+package i18n
+
+import (
+	"sort"
+	"strings"
+)
+
+// SuffixRules links the key variants generated by i18next to the base key used in
+// source: plural forms (item_one, item_other, item_ordinal_two) and contexts
+// (friend_male, friend_male_one), so that t('item', { count }) uses them all
+type SuffixRules struct {
+	Separator string   // separator before a suffix (default "_")
+	Plurals   []string // plural suffixes; each also links its ordinal form (ordinal_one)
+	Contexts  []string // context suffixes, e.g. male, female
+}
+
+// DefaultSuffixRules returns the plural suffixes of i18next: the CLDR plural
+// categories, and "plural" of the v3 JSON format. No contexts are known by default.
+func DefaultSuffixRules() SuffixRules {
+	return SuffixRules{
+		Separator: "_",
+		Plurals:   []string{"zero", "one", "two", "few", "many", "other", "plural"},
+	}
+}
+
+// BaseKey returns the key a variant belongs to, stripping a plural suffix and
+// then a context suffix. Other keys are returned unchanged.
+func (r SuffixRules) BaseKey(key string) string {
+	return r.stripContext(r.stripPlural(key))
+}
+
+// bases returns the keys that use a key: itself, its base key, and its form with
+// only the plural or the context stripped
+func (r SuffixRules) bases(key string) []string {
+	bases := []string{key}
+	add := func(base string) {
+		for _, existing := range bases {
+			if existing == base {
+				return
+			}
+		}
+		bases = append(bases, base)
+	}
+
+	plural := r.stripPlural(key)
+	add(plural)
+	add(r.stripContext(plural))
+	add(r.stripContext(key))
+	return bases
+}
+
+func (r SuffixRules) stripPlural(key string) string {
+	separator := r.separator()
+	for _, suffix := range r.Plurals {
+		for _, form := range []string{"ordinal" + separator + suffix, suffix} {
+			if base, ok := strings.CutSuffix(key, separator+form); ok && base != "" {
+				return base
+			}
+		}
+	}
+	return key
+}
+
+func (r SuffixRules) stripContext(key string) string {
+	separator := r.separator()
+	for _, suffix := range r.Contexts {
+		if base, ok := strings.CutSuffix(key, separator+suffix); ok && base != "" {
+			return base
+		}
+	}
+	return key
+}
+
+func (r SuffixRules) separator() string {
+	if r.Separator == "" {
+		return "_"
+	}
+	return r.Separator
+}
+
+// CompareKeysWithRules compares keys like CompareKeys, counting plural and context
+// variants as used when source references their base key, and source keys as
+// present when the JSON files hold variants of them
+func CompareKeysWithRules(sourceKeys, jsonKeys map[string]bool, rules SuffixRules) ([]string, []string) {
+	var missingInJSON, unusedInSource []string
+
+	covered := make(map[string]bool, len(jsonKeys))
+	for key := range jsonKeys {
+		used := false
+		for _, base := range rules.bases(key) {
+			covered[base] = true
+			used = used || sourceKeys[base]
+		}
+		if !used {
+			unusedInSource = append(unusedInSource, key)
+		}
+	}
+
+	for key := range sourceKeys {
+		if !covered[key] {
+			missingInJSON = append(missingInJSON, key)
+		}
+	}
+
+	sort.Strings(missingInJSON)
+	sort.Strings(unusedInSource)
+
+	return missingInJSON, unusedInSource
+}
@@ -0,0 +1,55 @@
+package i18n
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareKeysWithRules(t *testing.T) {
+	sourceKeys := map[string]bool{"item": true, "friend": true, "solo": true, "missing": true}
+	jsonKeys := map[string]bool{
+		"item_one":         true,
+		"item_other":       true,
+		"item_ordinal_two": true,
+		"friend_male":      true,
+		"friend_male_one":  true,
+		"solo_plural":      true,
+		"stale_one":        true,
+		"friendly":         true,
+	}
+
+	rules := DefaultSuffixRules()
+	rules.Contexts = []string{"male", "female"}
+
+	missing, unused := CompareKeysWithRules(sourceKeys, jsonKeys, rules)
+	if !reflect.DeepEqual(missing, []string{"missing"}) {
+		t.Errorf("Expected only 'missing' to be missing, got %v", missing)
+	}
+	if !reflect.DeepEqual(unused, []string{"friendly", "stale_one"}) {
+		t.Errorf("Expected friendly and stale_one to be unused, got %v", unused)
+	}
+
+	// Without rules, variants are compared exactly
+	missing, unused = CompareKeysWithRules(sourceKeys, jsonKeys, SuffixRules{})
+	if len(missing) != 4 || len(unused) != len(jsonKeys) {
+		t.Errorf("Expected exact comparison without rules, got missing %v, unused %v", missing, unused)
+	}
+}
+
+func TestSuffixRulesBaseKey(t *testing.T) {
+	rules := SuffixRules{Separator: "_", Plurals: []string{"one", "other"}, Contexts: []string{"male"}}
+
+	tests := map[string]string{
+		"item_one":          "item",
+		"item_ordinal_one":  "item",
+		"friend_male_other": "friend",
+		"friend_male":       "friend",
+		"_one":              "_one",
+		"plain":             "plain",
+	}
+	for key, expected := range tests {
+		if got := rules.BaseKey(key); got != expected {
+			t.Errorf("BaseKey(%q) = %q, expected %q", key, got, expected)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/haiyon/fitobj/fitter"
+)
+
+// Placeholder modes for keys added to target locales
+const (
+	PlaceholderEmpty = "empty" // empty string
+	PlaceholderKey   = "key"   // the flattened key itself
+	PlaceholderBase  = "base"  // the base locale value
+	PlaceholderTODO  = "todo"  // "TODO: " followed by the base value
+)
+
+// SyncOptions configures synchronizing target locales with a base locale
+type SyncOptions struct {
+	AddMissing  bool   // add keys present in the base but missing in a target
+	RemoveExtra bool   // remove keys present in a target but not in the base
+	Placeholder string // value used for added keys (default: empty)
+	Separator   string // separator for flattened keys
+}
+
+// SyncChanges lists the keys added to and removed from a single locale
+type SyncChanges struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+// ValidatePlaceholder checks that a placeholder mode is known
+func ValidatePlaceholder(mode string) error {
+	switch mode {
+	case "", PlaceholderEmpty, PlaceholderKey, PlaceholderBase, PlaceholderTODO:
+		return nil
+	}
+	return fmt.Errorf("unknown placeholder '%s' (expected empty, key, base or todo)", mode)
+}
+
+// PlaceholderValue returns the value inserted for a missing key
+func PlaceholderValue(mode, key string, baseValue any) any {
+	switch mode {
+	case PlaceholderKey:
+		return key
+	case PlaceholderBase:
+		return baseValue
+	case PlaceholderTODO:
+		if s, ok := baseValue.(string); ok {
+			return "TODO: " + s
+		}
+		return "TODO"
+	default:
+		return ""
+	}
+}
+
+// SyncBundles brings nested target bundles in line with a nested base bundle and
+// returns the updated targets with a per-locale change report. Inputs are not modified.
+func SyncBundles(base map[string]any, targets map[string]map[string]any, options SyncOptions) (map[string]map[string]any, map[string]SyncChanges, error) {
+	if err := ValidatePlaceholder(options.Placeholder); err != nil {
+		return nil, nil, err
+	}
+
+	flattenOpts := fitter.DefaultFlattenOptions()
+	unflattenOpts := fitter.DefaultUnflattenOptions()
+	unflattenOpts.SupportBracketNotation = false
+	if options.Separator != "" {
+		flattenOpts.Separator = options.Separator
+		unflattenOpts.Separator = options.Separator
+	}
+
+	baseFlat := fitter.FlattenMapWithOptions(base, "", flattenOpts)
+	updated := make(map[string]map[string]any, len(targets))
+	report := make(map[string]SyncChanges, len(targets))
+
+	for locale, target := range targets {
+		flat := fitter.FlattenMapWithOptions(target, "", flattenOpts)
+		changes := SyncChanges{Added: []string{}, Removed: []string{}}
+
+		if options.AddMissing {
+			for key, value := range baseFlat {
+				if _, exists := flat[key]; !exists {
+					flat[key] = PlaceholderValue(options.Placeholder, key, value)
+					changes.Added = append(changes.Added, key)
+				}
+			}
+		}
+
+		if options.RemoveExtra {
+			for key := range flat {
+				if _, exists := baseFlat[key]; !exists {
+					delete(flat, key)
+					changes.Removed = append(changes.Removed, key)
+				}
+			}
+		}
+
+		sort.Strings(changes.Added)
+		sort.Strings(changes.Removed)
+		updated[locale] = fitter.UnflattenMapWithOptions(flat, unflattenOpts)
+		report[locale] = changes
+	}
+
+	return updated, report, nil
+}
@@ -0,0 +1,49 @@
+package i18n
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSyncBundles(t *testing.T) {
+	base := map[string]any{
+		"buttons": map[string]any{"ok": "OK", "cancel": "Cancel"},
+		"title":   "Home",
+	}
+	targets := map[string]map[string]any{
+		"de": {
+			"buttons": map[string]any{"ok": "OK"},
+			"title":   "Startseite",
+			"old":     "Alt",
+		},
+	}
+
+	updated, changes, err := SyncBundles(base, targets, SyncOptions{
+		AddMissing:  true,
+		RemoveExtra: true,
+		Placeholder: PlaceholderTODO,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]any{
+		"buttons": map[string]any{"ok": "OK", "cancel": "TODO: Cancel"},
+		"title":   "Startseite",
+	}
+	if !reflect.DeepEqual(updated["de"], expected) {
+		t.Fatalf("Expected %v, got %v", expected, updated["de"])
+	}
+
+	if !reflect.DeepEqual(changes["de"], SyncChanges{Added: []string{"buttons.cancel"}, Removed: []string{"old"}}) {
+		t.Fatalf("Unexpected changes: %+v", changes["de"])
+	}
+
+	if _, exists := targets["de"]["old"]; !exists {
+		t.Fatal("Input bundles should not be modified")
+	}
+
+	if _, _, err := SyncBundles(base, targets, SyncOptions{Placeholder: "bogus"}); err == nil {
+		t.Fatal("Expected an error for an unknown placeholder")
+	}
+}
@@ -0,0 +1,95 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// TMSConfig configures a translation management system connection
+type TMSConfig struct {
+	Provider string // registered provider name (e.g. "lokalise")
+	Project  string // project identifier on the platform
+	Token    string // API token
+	BaseURL  string // API base URL override (optional)
+}
+
+// TMS is a translation management system that source strings are pushed to
+// and completed translations are pulled from. Keys are flattened paths.
+type TMS interface {
+	// Push uploads source strings of the base locale for the given keys
+	Push(ctx context.Context, locale string, values map[string]string) (int, error)
+	// Pull downloads the translations available for a locale
+	Pull(ctx context.Context, locale string) (map[string]string, error)
+}
+
+// TMSFactory creates a TMS client from its configuration
+type TMSFactory func(config TMSConfig) (TMS, error)
+
+var (
+	tmsMu        sync.RWMutex
+	tmsProviders = make(map[string]TMSFactory)
+)
+
+// RegisterTMS makes a TMS provider available by name
+func RegisterTMS(name string, factory TMSFactory) {
+	tmsMu.Lock()
+	defer tmsMu.Unlock()
+	tmsProviders[name] = factory
+}
+
+// NewTMS creates a client for the configured provider
+func NewTMS(config TMSConfig) (TMS, error) {
+	tmsMu.RLock()
+	factory, ok := tmsProviders[config.Provider]
+	tmsMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown TMS provider '%s' (available: %v)", config.Provider, TMSProviders())
+	}
+	return factory(config)
+}
+
+// TMSProviders returns the names of registered providers
+func TMSProviders() []string {
+	tmsMu.RLock()
+	defer tmsMu.RUnlock()
+
+	names := make([]string, 0, len(tmsProviders))
+	for name := range tmsProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// UntranslatedStrings returns base strings whose keys are missing from any target locale
+func UntranslatedStrings(base map[string]any, targets map[string]map[string]any) map[string]string {
+	result := make(map[string]string)
+	for _, key := range sortedStringKeys(base) {
+		for _, target := range targets {
+			if value, ok := target[key].(string); !ok || value == "" {
+				result[key] = base[key].(string)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// ApplyTranslations writes pulled translations into a flattened locale map and
+// returns the number of values added or changed
+func ApplyTranslations(flat map[string]any, translations map[string]string) int {
+	changed := 0
+	for key, value := range translations {
+		if value == "" {
+			continue
+		}
+		if existing, ok := flat[key].(string); !ok || existing != value {
+			flat[key] = value
+			changed++
+		}
+	}
+	return changed
+}
@@ -0,0 +1,85 @@
+package i18n
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestLokalisePushPull(t *testing.T) {
+	var pushed []lokaliseKey
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Token") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/projects/p1/keys" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodPost:
+			var body struct {
+				Keys []lokaliseKey `json:"keys"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			pushed = body.Keys
+			json.NewEncoder(w).Encode(map[string]any{"keys": body.Keys})
+		case http.MethodGet:
+			w.Write([]byte(`{"keys": [
+				{"key_name": {"web": "buttons.ok"}, "translations": [
+					{"language_iso": "en", "translation": "OK"},
+					{"language_iso": "de", "translation": "Okay"}
+				]},
+				{"key_name": {"web": "buttons.cancel"}, "translations": [
+					{"language_iso": "de", "translation": ""}
+				]}
+			]}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewTMS(TMSConfig{Provider: "lokalise", Project: "p1", Token: "secret", BaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := client.Push(context.Background(), "en", map[string]string{"buttons.ok": "OK", "buttons.cancel": "Cancel"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 || pushed[0].KeyName != "buttons.cancel" || pushed[0].Translations[0].LanguageISO != "en" {
+		t.Fatalf("Unexpected push result %d: %+v", count, pushed)
+	}
+
+	translations, err := client.Pull(context.Background(), "de")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(translations, map[string]string{"buttons.ok": "Okay"}) {
+		t.Fatalf("Unexpected pulled translations: %v", translations)
+	}
+
+	flat := map[string]any{"buttons.ok": "OK?", "title": "Titel"}
+	if changed := ApplyTranslations(flat, translations); changed != 1 || flat["buttons.ok"] != "Okay" {
+		t.Fatalf("Expected translation to be applied, got %v", flat)
+	}
+}
+
+func TestUntranslatedStrings(t *testing.T) {
+	base := map[string]any{"a": "A", "b": "B", "c": "C"}
+	targets := map[string]map[string]any{
+		"de": {"a": "A", "b": "B"},
+		"fr": {"a": "A", "b": "", "c": "C"},
+	}
+
+	expected := map[string]string{"b": "B", "c": "C"}
+	if result := UntranslatedStrings(base, targets); !reflect.DeepEqual(result, expected) {
+		t.Fatalf("Expected %v, got %v", expected, result)
+	}
+}
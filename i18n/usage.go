@@ -0,0 +1,108 @@
+package i18n
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// KeyUsage records where a key is referenced in source code
+type KeyUsage struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// ExtractKeyUsagesFromFile returns every t() call in a file with its location
+func ExtractKeyUsagesFromFile(filePath string) (map[string][]KeyUsage, error) {
+	return extractKeyUsagesFromFile(filePath, defaultScanner)
+}
+
+// ExtractKeyUsagesFromFileWithOptions returns every call to the configured functions
+// in a file with its location
+func ExtractKeyUsagesFromFileWithOptions(filePath string, options ExtractOptions) (map[string][]KeyUsage, error) {
+	scanner, err := options.scanner()
+	if err != nil {
+		return nil, err
+	}
+	return extractKeyUsagesFromFile(filePath, scanner)
+}
+
+func extractKeyUsagesFromFile(filePath string, scanner *keyScanner) (map[string][]KeyUsage, error) {
+	usages := make(map[string][]KeyUsage)
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return usages, nil // Ignore read errors (e.g., binary files)
+	}
+
+	for _, ref := range scanner.scanFile(filePath, content) {
+		if ref.dynamic {
+			continue
+		}
+		line := bytes.Count(content[:ref.offset], []byte("\n")) + 1
+		column := ref.offset - bytes.LastIndexByte(content[:ref.offset], '\n')
+		usages[ref.key] = append(usages[ref.key], KeyUsage{File: filePath, Line: line, Column: column})
+	}
+
+	return usages, nil
+}
+
+// ExtractKeyUsagesFromDir recursively collects key usage locations from a directory
+func ExtractKeyUsagesFromDir(rootDir string) (map[string][]KeyUsage, error) {
+	return extractKeyUsagesFromDir(rootDir, defaultScanner)
+}
+
+// ExtractKeyUsagesFromDirWithOptions recursively collects the usage locations of
+// the configured function calls from a directory
+func ExtractKeyUsagesFromDirWithOptions(rootDir string, options ExtractOptions) (map[string][]KeyUsage, error) {
+	scanner, err := options.scanner()
+	if err != nil {
+		return nil, err
+	}
+	return extractKeyUsagesFromDir(rootDir, scanner)
+}
+
+func extractKeyUsagesFromDir(rootDir string, scanner *keyScanner) (map[string][]KeyUsage, error) {
+	usages := make(map[string][]KeyUsage)
+
+	err := scanSourceFiles(rootDir, scanner, func(path string) map[string][]KeyUsage {
+		fileUsages, _ := extractKeyUsagesFromFile(path, scanner)
+		return fileUsages
+	}, func(_ string, fileUsages map[string][]KeyUsage) {
+		for key, locations := range fileUsages {
+			usages[key] = append(usages[key], locations...)
+		}
+	})
+
+	for _, locations := range usages {
+		sort.Slice(locations, func(i, j int) bool {
+			if locations[i].File != locations[j].File {
+				return locations[i].File < locations[j].File
+			}
+			return locations[i].Line < locations[j].Line
+		})
+	}
+
+	return usages, err
+}
+
+// UsedKeysUnder returns the keys referenced from files within a directory
+func UsedKeysUnder(usages map[string][]KeyUsage, dir string) map[string]bool {
+	dir = filepath.Clean(dir)
+	keys := make(map[string]bool)
+
+	for key, locations := range usages {
+		for _, location := range locations {
+			rel, err := filepath.Rel(dir, location.File)
+			if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				keys[key] = true
+				break
+			}
+		}
+	}
+
+	return keys
+}
@@ -0,0 +1,85 @@
+package i18n
+
+import (
+	"io/fs"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// sourceFiles lists the text files below a directory in walk order, skipping
+// hidden files and directories, and the paths excluded by the scanner
+func (s *keyScanner) sourceFiles(rootDir string) ([]string, error) {
+	filter, err := newSourceFilter(rootDir, s.exclude, !s.noGitignore)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	err = filepath.WalkDir(rootDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Skip hidden directories and files
+		if path != rootDir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if path != rootDir && filter.ignored(path, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return filter.enter(path)
+		}
+
+		if isTextFile(path) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// scanSourceFiles runs scan on the source files below a directory with a pool of
+// workers (the number of CPUs when the scanner sets none), then passes each
+// result to merge in walk order, so results are identical whatever the number of
+// workers. The files listed before a walk error are still scanned.
+func scanSourceFiles[T any](rootDir string, scanner *keyScanner, scan func(path string) T, merge func(path string, value T)) error {
+	files, err := scanner.sourceFiles(rootDir)
+	workers := scanner.workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	workers = max(min(workers, len(files)), 1)
+
+	results := make([]T, len(files))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = scan(files[i])
+			}
+		}()
+	}
+	for i := range files {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i, file := range files {
+		merge(file, results[i])
+	}
+	return err
+}
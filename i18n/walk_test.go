@@ -0,0 +1,119 @@
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestScanSourceFilesInParallel(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 40; i++ {
+		sub := filepath.Join(dir, fmt.Sprintf("pkg%d", i%4))
+		if err := os.MkdirAll(sub, 0755); err != nil {
+			t.Fatal(err)
+		}
+		content := fmt.Sprintf("t('key.%d');\nt('shared');\nt(`dynamic.${id}`);\n", i)
+		if err := os.WriteFile(filepath.Join(sub, fmt.Sprintf("file%02d.ts", i)), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".hidden.ts"), []byte("t('hidden');"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	scan := func(workers int) (map[string]bool, map[string][]KeyUsage, []DynamicKey) {
+		options := DefaultExtractOptions()
+		options.Workers = workers
+		keys, err := ExtractKeysFromDirWithOptions(dir, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		usages, err := ExtractKeyUsagesFromDirWithOptions(dir, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dynamic, err := ExtractDynamicKeysFromDirWithOptions(dir, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return keys, usages, dynamic
+	}
+
+	keys, usages, dynamic := scan(1)
+	if len(keys) != 41 || !keys["shared"] || keys["hidden"] {
+		t.Fatalf("Expected 40 file keys and shared, got %v", keys)
+	}
+	if len(usages["shared"]) != 40 || len(dynamic) != 40 {
+		t.Fatalf("Expected 40 usages of shared and 40 dynamic keys, got %d and %d", len(usages["shared"]), len(dynamic))
+	}
+
+	parallelKeys, parallelUsages, parallelDynamic := scan(8)
+	if !reflect.DeepEqual(parallelKeys, keys) || !reflect.DeepEqual(parallelUsages, usages) || !reflect.DeepEqual(parallelDynamic, dynamic) {
+		t.Fatal("Expected the same results with 8 workers as with 1")
+	}
+}
+
+func TestScanSourceFilesExcludes(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		".gitignore":              "node_modules/\n*.gen.ts\n!keep.gen.ts\n",
+		"app/.gitignore":          "/build\n",
+		"app/src/app.ts":          "t('app')",
+		"app/src/keep.gen.ts":     "t('keep')",
+		"app/src/types.gen.ts":    "t('generated')",
+		"app/src/app.test.ts":     "t('test')",
+		"app/build/bundle.js":     "t('build')",
+		"app/src/build/nested.ts": "t('nested.build')",
+		"node_modules/lib/i.js":   "t('vendor')",
+	}
+	for name, content := range files {
+		filePath := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		root     string
+		options  func(*ExtractOptions)
+		expected map[string]bool
+	}{
+		{"gitignore", dir, func(*ExtractOptions) {},
+			map[string]bool{"app": true, "keep": true, "test": true, "nested.build": true}},
+		{"parent gitignore", filepath.Join(dir, "app", "src"), func(*ExtractOptions) {},
+			map[string]bool{"app": true, "keep": true, "test": true, "nested.build": true}},
+		{"exclude", dir, func(o *ExtractOptions) { o.Exclude = []string{"**/*.test.ts", "app/src/build"} },
+			map[string]bool{"app": true, "keep": true}},
+		{"no gitignore", dir, func(o *ExtractOptions) { o.NoGitignore = true },
+			map[string]bool{"app": true, "keep": true, "generated": true, "test": true, "build": true, "nested.build": true, "vendor": true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := DefaultExtractOptions()
+			tt.options(&options)
+			keys, err := ExtractKeysFromDirWithOptions(tt.root, options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(keys, tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, keys)
+			}
+		})
+	}
+
+	options := DefaultExtractOptions()
+	options.Exclude = []string{"src/[a-"}
+	if _, err := ExtractKeysFromDirWithOptions(dir, options); err == nil {
+		t.Fatal("Expected an error for an invalid exclude pattern")
+	}
+}
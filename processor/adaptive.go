@@ -0,0 +1,79 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Sizing of adaptive batches
+const (
+	minBatchBytes    = 64 << 10 // smallest amount of work worth a batch, and a worker
+	maxBatchFiles    = 256      // files grouped into one batch at most
+	batchesPerWorker = 4        // batches planned per worker, so that workers finish together
+)
+
+// planBatches splits the files of a directory into the batches handed to workers,
+// returning them with the number of workers to start. Without adaptive sizing,
+// every file is a batch and all workers start. With it, files of at least the
+// target batch size get a batch of their own, smaller files are grouped up to the
+// target size, batches are ordered largest first, and the pool shrinks to the
+// number of workers the total size keeps busy.
+func planBatches(dir string, files []string, workers int, adaptive bool) ([][]string, int) {
+	if !adaptive {
+		batches := make([][]string, len(files))
+		for i, file := range files {
+			batches[i] = []string{file}
+		}
+		return batches, workers
+	}
+
+	sizes := make(map[string]int64, len(files))
+	var total int64
+	for _, file := range files {
+		if info, err := os.Stat(filepath.Join(dir, file)); err == nil {
+			sizes[file] = info.Size()
+			total += info.Size()
+		}
+	}
+
+	target := total / int64(workers*batchesPerWorker)
+	if target < minBatchBytes {
+		target = minBatchBytes
+	}
+
+	// Large files first, so they start early and small batches fill the gaps
+	ordered := append([]string(nil), files...)
+	sort.SliceStable(ordered, func(i, j int) bool { return sizes[ordered[i]] > sizes[ordered[j]] })
+
+	var batches [][]string
+	var batch []string
+	var batchSize int64
+	for _, file := range ordered {
+		if sizes[file] >= target {
+			batches = append(batches, []string{file})
+			continue
+		}
+		if len(batch) > 0 && (batchSize+sizes[file] > target || len(batch) >= maxBatchFiles) {
+			batches = append(batches, batch)
+			batch, batchSize = nil, 0
+		}
+		batch = append(batch, file)
+		batchSize += sizes[file]
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+
+	pool := int((total + minBatchBytes - 1) / minBatchBytes)
+	if pool > workers {
+		pool = workers
+	}
+	if pool > len(batches) {
+		pool = len(batches)
+	}
+	if pool < 1 {
+		pool = 1
+	}
+	return batches, pool
+}
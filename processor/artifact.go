@@ -0,0 +1,297 @@
+package processor
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/haiyon/fitobj/utils"
+)
+
+// ManifestName is the name of the manifest entry inside an artifact tarball
+const ManifestName = "manifest.json"
+
+// SignatureAlgorithm identifies how artifact manifests are signed
+const SignatureAlgorithm = "hmac-sha256"
+
+// ArtifactFile describes a single file packed into an artifact
+type ArtifactFile struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// ArtifactManifest records what an artifact contains and how it was produced
+type ArtifactManifest struct {
+	Tool      string         `json:"tool"`
+	Version   string         `json:"version"`
+	Mode      string         `json:"mode"`
+	CreatedAt time.Time      `json:"createdAt"`
+	Files     []ArtifactFile `json:"files"`
+	Algorithm string         `json:"algorithm,omitempty"`
+	Signature string         `json:"signature,omitempty"`
+}
+
+// ArtifactOptions configures artifact output
+type ArtifactOptions struct {
+	Path    string // tarball path (.tar.gz)
+	Key     []byte // HMAC signing key; the manifest is unsigned when empty
+	Mode    string // processing mode recorded in the manifest (flatten/unflatten)
+	Version string // fitobj version recorded in the manifest
+}
+
+// WriteArtifact packs the output files of a directory into a gzipped tarball together
+// with a signed manifest, and writes the manifest next to the tarball so it can also
+// be signed externally (e.g. cosign sign-blob). Existing artifacts are never overwritten.
+func WriteArtifact(dir string, options ArtifactOptions) (ArtifactManifest, error) {
+	manifest := ArtifactManifest{
+		Tool:      "fitobj",
+		Version:   options.Version,
+		Mode:      options.Mode,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return manifest, fmt.Errorf("failed to read directory: %v", err)
+	}
+
+	contents := make(map[string][]byte)
+	for _, entry := range entries {
+		if entry.IsDir() || !isOutputFile(entry.Name()) {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return manifest, fmt.Errorf("failed to read %s: %v", entry.Name(), err)
+		}
+
+		sum := sha256.Sum256(data)
+		contents[entry.Name()] = data
+		manifest.Files = append(manifest.Files, ArtifactFile{
+			Path:   entry.Name(),
+			SHA256: hex.EncodeToString(sum[:]),
+			Size:   int64(len(data)),
+		})
+	}
+	sort.Slice(manifest.Files, func(i, j int) bool { return manifest.Files[i].Path < manifest.Files[j].Path })
+
+	if len(options.Key) > 0 {
+		manifest.Algorithm = SignatureAlgorithm
+		signature, err := signManifest(manifest, options.Key)
+		if err != nil {
+			return manifest, err
+		}
+		manifest.Signature = signature
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return manifest, fmt.Errorf("failed to encode manifest: %v", err)
+	}
+
+	out, err := os.OpenFile(options.Path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0444)
+	if err != nil {
+		return manifest, fmt.Errorf("failed to create artifact: %v", err)
+	}
+	defer out.Close()
+
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+
+	addEntry := func(name string, data []byte) error {
+		header := &tar.Header{
+			Name:    name,
+			Mode:    0444,
+			Size:    int64(len(data)),
+			ModTime: manifest.CreatedAt,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+
+	for _, file := range manifest.Files {
+		if err := addEntry(file.Path, contents[file.Path]); err != nil {
+			return manifest, fmt.Errorf("failed to write %s to artifact: %v", file.Path, err)
+		}
+	}
+	if err := addEntry(ManifestName, manifestData); err != nil {
+		return manifest, fmt.Errorf("failed to write manifest to artifact: %v", err)
+	}
+
+	if err := tw.Close(); err != nil {
+		return manifest, fmt.Errorf("failed to finalize artifact: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		return manifest, fmt.Errorf("failed to finalize artifact: %v", err)
+	}
+
+	if err := os.WriteFile(options.Path+".manifest.json", manifestData, 0444); err != nil {
+		return manifest, fmt.Errorf("failed to write manifest: %v", err)
+	}
+
+	return manifest, nil
+}
+
+// isOutputFile reports whether a file is a processing output (JSON, JSONC, YAML,
+// bulk NDJSON or an anchors sidecar)
+func isOutputFile(name string) bool {
+	return utils.IsJSONFile(name) || utils.IsJSONCFile(name) || utils.IsYAMLFile(name) || strings.HasSuffix(name, ".ndjson")
+}
+
+// VerifyArtifact checks that every file in an artifact matches its manifest entry
+// and, when a key is given, that the manifest signature is valid
+func VerifyArtifact(path string, key []byte) (ArtifactManifest, error) {
+	var manifest ArtifactManifest
+
+	in, err := os.Open(path)
+	if err != nil {
+		return manifest, fmt.Errorf("failed to open artifact: %v", err)
+	}
+	defer in.Close()
+
+	gz, err := gzip.NewReader(in)
+	if err != nil {
+		return manifest, fmt.Errorf("failed to read artifact: %v", err)
+	}
+
+	sums := make(map[string]string)
+	var manifestData []byte
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return manifest, fmt.Errorf("failed to read artifact: %v", err)
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return manifest, fmt.Errorf("failed to read %s from artifact: %v", header.Name, err)
+		}
+
+		if header.Name == ManifestName {
+			manifestData = data
+			continue
+		}
+		sum := sha256.Sum256(data)
+		sums[header.Name] = hex.EncodeToString(sum[:])
+	}
+
+	if manifestData == nil {
+		return manifest, fmt.Errorf("artifact has no %s", ManifestName)
+	}
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return manifest, fmt.Errorf("failed to parse manifest: %v", err)
+	}
+
+	if len(key) > 0 {
+		if manifest.Signature == "" {
+			return manifest, fmt.Errorf("manifest is not signed")
+		}
+		if manifest.Algorithm != SignatureAlgorithm {
+			return manifest, fmt.Errorf("unsupported signature algorithm '%s'", manifest.Algorithm)
+		}
+		expected, err := signManifest(manifest, key)
+		if err != nil {
+			return manifest, err
+		}
+		if !hmac.Equal([]byte(expected), []byte(manifest.Signature)) {
+			return manifest, fmt.Errorf("manifest signature does not match")
+		}
+	}
+
+	for _, file := range manifest.Files {
+		sum, ok := sums[file.Path]
+		if !ok {
+			return manifest, fmt.Errorf("file %s listed in manifest is missing", file.Path)
+		}
+		if sum != file.SHA256 {
+			return manifest, fmt.Errorf("checksum mismatch for %s", file.Path)
+		}
+		delete(sums, file.Path)
+	}
+	for name := range sums {
+		return manifest, fmt.Errorf("file %s is not listed in manifest", name)
+	}
+
+	return manifest, nil
+}
+
+// CompareArtifact compares the documents packed into an artifact with those of a
+// directory, such as the output of a later run, as DiffDirectories. The artifact
+// is the old side. Its checksums are not verified; see VerifyArtifact.
+func CompareArtifact(path, dir string, options DiffOptions) (DirDiff, error) {
+	tmpDir, err := os.MkdirTemp("", "fitobj-artifact-")
+	if err != nil {
+		return DirDiff{}, fmt.Errorf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	in, err := os.Open(path)
+	if err != nil {
+		return DirDiff{}, fmt.Errorf("failed to open artifact: %v", err)
+	}
+	defer in.Close()
+
+	gz, err := gzip.NewReader(in)
+	if err != nil {
+		return DirDiff{}, fmt.Errorf("failed to read artifact: %v", err)
+	}
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return DirDiff{}, fmt.Errorf("failed to read artifact: %v", err)
+		}
+
+		// Artifacts hold the files of a single directory; anything else is skipped
+		if header.Name == ManifestName || filepath.Base(header.Name) != header.Name || header.Name == ".." {
+			continue
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return DirDiff{}, fmt.Errorf("failed to read %s from artifact: %v", header.Name, err)
+		}
+		if err := os.WriteFile(filepath.Join(tmpDir, header.Name), data, 0644); err != nil {
+			return DirDiff{}, fmt.Errorf("failed to extract %s: %v", header.Name, err)
+		}
+	}
+
+	return DiffDirectories(tmpDir, dir, options)
+}
+
+// signManifest computes the HMAC of a manifest encoded without its signature
+func signManifest(manifest ArtifactManifest, key []byte) (string, error) {
+	manifest.Signature = ""
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode manifest: %v", err)
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
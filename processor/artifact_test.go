@@ -0,0 +1,154 @@
+package processor
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// rewriteArtifact copies an artifact, passing the content of every entry through
+// change
+func rewriteArtifact(t *testing.T, src, dst string, change func(name string, data []byte) []byte) {
+	t.Helper()
+	in, err := os.Open(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	gzr, err := gzip.NewReader(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+	tr := tar.NewReader(gzr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = change(header.Name, data)
+		header.Size = int64(len(data))
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dst, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// changeManifest returns a change rewriting the manifest entry of an artifact
+func changeManifest(t *testing.T, edit func(*ArtifactManifest)) func(string, []byte) []byte {
+	return func(name string, data []byte) []byte {
+		if name != ManifestName {
+			return data
+		}
+		var manifest ArtifactManifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			t.Fatal(err)
+		}
+		edit(&manifest)
+		data, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+}
+
+func TestVerifyArtifactDetectsTampering(t *testing.T) {
+	inputDir := writeInputs(t, map[string]string{"a.json": `{"a.b":1}`, "b.json": `{"c":2}`})
+	key := []byte("secret")
+	path := filepath.Join(t.TempDir(), "out.tar.gz")
+	if _, err := WriteArtifact(inputDir, ArtifactOptions{Path: path, Key: key, Mode: "flatten", Version: "test"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyArtifact(path, key); err != nil {
+		t.Fatalf("Expected the artifact to verify, got %v", err)
+	}
+
+	tampered := "{\"a.b\":2}"
+	sum := sha256.Sum256([]byte(tampered))
+	tests := map[string]struct {
+		change   func(string, []byte) []byte
+		key      []byte
+		expected string
+	}{
+		"wrong key": {
+			change:   func(_ string, data []byte) []byte { return data },
+			key:      []byte("other"),
+			expected: "signature does not match",
+		},
+		"manifest mode": {
+			change:   changeManifest(t, func(m *ArtifactManifest) { m.Mode = "unflatten" }),
+			key:      key,
+			expected: "signature does not match",
+		},
+		"manifest checksum updated with the file": {
+			change: func(name string, data []byte) []byte {
+				if name == "a.json" {
+					return []byte(tampered)
+				}
+				return changeManifest(t, func(m *ArtifactManifest) {
+					for i := range m.Files {
+						if m.Files[i].Path == "a.json" {
+							m.Files[i].SHA256 = hex.EncodeToString(sum[:])
+						}
+					}
+				})(name, data)
+			},
+			key:      key,
+			expected: "signature does not match",
+		},
+		"signature removed": {
+			change:   changeManifest(t, func(m *ArtifactManifest) { m.Signature = "" }),
+			key:      key,
+			expected: "not signed",
+		},
+		"file content": {
+			change: func(name string, data []byte) []byte {
+				if name == "a.json" {
+					return []byte(tampered)
+				}
+				return data
+			},
+			key:      nil,
+			expected: "checksum mismatch for a.json",
+		},
+	}
+	for name, test := range tests {
+		dst := filepath.Join(t.TempDir(), "tampered.tar.gz")
+		rewriteArtifact(t, path, dst, test.change)
+		_, err := VerifyArtifact(dst, test.key)
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("%s: expected an error containing %q, got %v", name, test.expected, err)
+		}
+	}
+}
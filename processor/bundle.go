@@ -0,0 +1,218 @@
+package processor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/haiyon/fitobj/fitter"
+	"github.com/haiyon/fitobj/utils"
+)
+
+// ExportBundle combines the documents of a directory into one flat map, with a
+// source map to split it back
+const ExportBundle = "bundle"
+
+// BundleMap is the source map of a bundle: the documents it combines, with the
+// prefix of their keys and the keys each holds, so the bundle can be split back
+// into the same files
+type BundleMap struct {
+	Separator string       `json:"separator"`
+	Files     []BundleFile `json:"files"`
+}
+
+// BundleFile is a document of a bundle
+type BundleFile struct {
+	File   string   `json:"file"`   // path relative to the bundled directory
+	Prefix string   `json:"prefix"` // prefix of its keys in the bundle, joined with the separator
+	Format string   `json:"format"`
+	Keys   []string `json:"keys"` // flattened keys, without the prefix
+}
+
+// BundleMapPath returns the path of the source map written next to a bundle
+func BundleMapPath(bundlePath string) string {
+	return strings.TrimSuffix(bundlePath, filepath.Ext(bundlePath)) + ".map.json"
+}
+
+// bundlePrefixes returns the key prefix of each file: its name without the
+// extension, or the whole name when another file has the same stem
+func bundlePrefixes(files []string) []string {
+	stems := make(map[string]int, len(files))
+	for _, file := range files {
+		stems[strings.TrimSuffix(file, filepath.Ext(file))]++
+	}
+	prefixes := make([]string, len(files))
+	for i, file := range files {
+		prefixes[i] = strings.TrimSuffix(file, filepath.Ext(file))
+		if prefixes[i] == "" || stems[prefixes[i]] > 1 {
+			prefixes[i] = file
+		}
+	}
+	return prefixes
+}
+
+// BundleDirectory flattens every document of a directory into one flat map
+// written to outputPath, in the format of its extension, with the keys of each
+// document prefixed by its file name without the extension (en.json holds
+// en.title). The source map is written to BundleMapPath. Keys of two documents
+// that end up the same in the bundle fail the run.
+func BundleDirectory(inputDir, outputPath string, options Options) error {
+	if err := options.Validate(); err != nil {
+		return err
+	}
+
+	inputInfo, err := os.Stat(inputDir)
+	if err != nil {
+		return fmt.Errorf("input directory error: %v", err)
+	}
+	if !inputInfo.IsDir() {
+		return fmt.Errorf("'%s' is not a directory", inputDir)
+	}
+
+	files, err := listInputFiles(inputDir, options.Match)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no JSON files found in '%s'", inputDir)
+	}
+
+	separator := options.FlattenOpts.Separator
+	prefixes := bundlePrefixes(files)
+	bundle := make(map[string]any)
+	owners := make(map[string]string)
+	sourceMap := BundleMap{Separator: separator, Files: make([]BundleFile, 0, len(files))}
+
+	// Read documents in parallel, combining them in file name order
+	type bundledFile struct {
+		flat map[string]any
+		err  error
+	}
+	err = collectOrdered(len(files), options.Workers, func(i int) bundledFile {
+		inputPath := filepath.Join(inputDir, files[i])
+		doc, err := readDocument(inputPath, resolveFormat(files[i], options.Format), separator, utils.AnchorsExpand, options)
+		if err != nil {
+			return bundledFile{err: fmt.Errorf("failed to read input file %s: %v", inputPath, err)}
+		}
+		flat := fitter.FlattenMapWithOptions(doc.data, "", options.FlattenOpts)
+		return bundledFile{flat: utils.FormatNumbers(flat, options.NumberFormat)}
+	}, func(i int, bundled bundledFile) error {
+		if bundled.err != nil {
+			return bundled.err
+		}
+
+		file := BundleFile{File: files[i], Prefix: prefixes[i], Format: DetectFormat(files[i]), Keys: make([]string, 0, len(bundled.flat))}
+		for key := range bundled.flat {
+			file.Keys = append(file.Keys, key)
+		}
+		sort.Strings(file.Keys)
+		for _, key := range file.Keys {
+			bundleKey := file.Prefix + separator + key
+			if owner, ok := owners[bundleKey]; ok {
+				return fmt.Errorf("key '%s' of %s is also a key of %s in the bundle", key, files[i], owner)
+			}
+			owners[bundleKey] = files[i]
+			bundle[bundleKey] = bundled.flat[key]
+		}
+		sourceMap.Files = append(sourceMap.Files, file)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := writeDocument(outputPath, resolveFormat(outputPath, options.Format), document{data: bundle}, separator, options); err != nil {
+		return fmt.Errorf("failed to write output file %s: %v", outputPath, err)
+	}
+	mapPath := BundleMapPath(outputPath)
+	data, err := json.MarshalIndent(sourceMap, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize source map: %v", err)
+	}
+	if err := os.WriteFile(mapPath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write source map %s: %v", mapPath, err)
+	}
+
+	options.logger().Info(fmt.Sprintf("Bundled %d keys from %d documents into %s (source map: %s)", len(bundle), len(files), outputPath, mapPath),
+		"keys", len(bundle), "documents", len(files), "output", outputPath)
+	return nil
+}
+
+// SplitBundle splits a bundle written by BundleDirectory back into its documents
+// in outputDir, with the file names and formats recorded in its source map (the
+// forced format, when set, changes the output format). Keys added to the bundle
+// since go to the document with the longest matching prefix; keys matching none
+// fail the split.
+func SplitBundle(inputPath, outputDir string, options Options) error {
+	if err := options.Validate(); err != nil {
+		return err
+	}
+
+	mapPath := BundleMapPath(inputPath)
+	data, err := os.ReadFile(mapPath)
+	if err != nil {
+		return fmt.Errorf("failed to read source map: %v", err)
+	}
+	var sourceMap BundleMap
+	if err := json.Unmarshal(data, &sourceMap); err != nil {
+		return fmt.Errorf("failed to parse source map %s: %v", mapPath, err)
+	}
+	separator := sourceMap.Separator
+	if separator == "" {
+		separator = options.UnflattenOpts.Separator
+	}
+
+	doc, err := readDocument(inputPath, DetectFormat(inputPath), separator, utils.AnchorsExpand, options)
+	if err != nil {
+		return fmt.Errorf("failed to read input file %s: %v", inputPath, err)
+	}
+
+	// Assign recorded keys to their document, then the others by prefix
+	docs := make([]map[string]any, len(sourceMap.Files))
+	for i, file := range sourceMap.Files {
+		if filepath.IsAbs(file.File) || strings.HasPrefix(filepath.Clean(file.File), "..") {
+			return fmt.Errorf("file '%s' is outside the output directory", file.File)
+		}
+		docs[i] = make(map[string]any, len(file.Keys))
+		for _, key := range file.Keys {
+			bundleKey := file.Prefix + separator + key
+			if value, ok := doc.data[bundleKey]; ok {
+				docs[i][key] = value
+				delete(doc.data, bundleKey)
+			}
+		}
+	}
+	for bundleKey, value := range doc.data {
+		best := -1
+		for i, file := range sourceMap.Files {
+			if strings.HasPrefix(bundleKey, file.Prefix+separator) && (best < 0 || len(file.Prefix) > len(sourceMap.Files[best].Prefix)) {
+				best = i
+			}
+		}
+		if best < 0 {
+			return fmt.Errorf("key '%s' matches no document of the source map", bundleKey)
+		}
+		docs[best][strings.TrimPrefix(bundleKey, sourceMap.Files[best].Prefix+separator)] = value
+	}
+
+	unflattenOpts := options.UnflattenOpts
+	unflattenOpts.Separator = separator
+	for i, file := range sourceMap.Files {
+		format := options.Format
+		if format == "" || format == FormatAuto {
+			format = file.Format
+		}
+		data := fitter.UnflattenMapWithOptions(docs[i], unflattenOpts)
+		out := document{data: utils.FormatNumbers(data, options.NumberFormat)}
+
+		outputPath := OutputPath(filepath.Join(outputDir, file.File), format)
+		if err := writeDocument(outputPath, format, out, separator, options); err != nil {
+			return fmt.Errorf("failed to write output file %s: %v", outputPath, err)
+		}
+		options.logger().Info(fmt.Sprintf("Split %d keys into %s", len(docs[i]), outputPath), "keys", len(docs[i]), "output", outputPath)
+	}
+	return nil
+}
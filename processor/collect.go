@@ -0,0 +1,93 @@
+package processor
+
+import "sync"
+
+// orderedWindowPerWorker bounds the results held back waiting for an earlier,
+// slower item, per worker
+const orderedWindowPerWorker = 4
+
+// indexedResult is the result of the item at an index
+type indexedResult[T any] struct {
+	index int
+	value T
+}
+
+// collectOrdered runs work on n items with a pool of workers and passes each
+// result to emit in item order, as soon as the results before it were emitted,
+// so combined outputs are identical whatever the number of workers. Items are
+// only started a bounded distance ahead of the next one to emit. The first error
+// of emit stops the run and is returned.
+func collectOrdered[T any](n, workers int, work func(index int) T, emit func(index int, value T) error) error {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > n {
+		workers = max(n, 1)
+	}
+
+	window := make(chan struct{}, workers*orderedWindowPerWorker)
+	done := make(chan struct{})
+	jobs := make(chan int)
+	results := make(chan indexedResult[T], workers)
+
+	// Start items in order while the window has room
+	go func() {
+		defer close(jobs)
+		for i := 0; i < n; i++ {
+			select {
+			case window <- struct{}{}:
+			case <-done:
+				return
+			}
+			select {
+			case jobs <- i:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				select {
+				case results <- indexedResult[T]{index: i, value: work(i)}:
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Emit results in order, holding back those that arrive early
+	held := make(map[int]T)
+	next := 0
+	var err error
+	for result := range results {
+		if err != nil {
+			continue
+		}
+		held[result.index] = result.value
+		for {
+			value, ok := held[next]
+			if !ok {
+				break
+			}
+			delete(held, next)
+			if err = emit(next, value); err != nil {
+				close(done)
+				break
+			}
+			next++
+			<-window
+		}
+	}
+	return err
+}
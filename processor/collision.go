@@ -0,0 +1,124 @@
+package processor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/haiyon/fitobj/fitter"
+	"github.com/haiyon/fitobj/utils"
+)
+
+// Kinds of key collisions
+const (
+	CollisionType  = "type"  // the files set the key to values of different types
+	CollisionValue = "value" // the files set the key to different values of the same type
+)
+
+// CollisionOptions configures a search for keys colliding across documents
+type CollisionOptions struct {
+	Options
+	KeyGlobs  []string // only compare keys matching these patterns, like FlattenOptions.IncludeKeys (optional)
+	TypesOnly bool     // only report keys set to values of different types
+}
+
+// KeyCollision is a flattened key set to conflicting values by several documents
+type KeyCollision struct {
+	Key     string           `json:"key"`
+	Kind    string           `json:"kind"`    // CollisionType or CollisionValue
+	Entries []CollisionEntry `json:"entries"` // every document setting the key, in file order
+}
+
+// CollisionEntry is the value a document sets a colliding key to
+type CollisionEntry struct {
+	File  string `json:"file"`
+	Type  string `json:"type"` // JSON type name of the value
+	Value any    `json:"value"`
+}
+
+// FindCollisions flattens every document below a directory and returns the keys
+// that several documents set to conflicting values or types, sorted by key, with
+// the number of documents compared. Keys set to equal values everywhere are not
+// collisions, so only the keys a merge of the documents would lose a value of are
+// reported.
+func FindCollisions(dir string, options CollisionOptions) ([]KeyCollision, int, error) {
+	if err := fitter.ValidateKeyPatterns(options.KeyGlobs, nil); err != nil {
+		return nil, 0, err
+	}
+	if err := ValidateFormat(options.Format); err != nil {
+		return nil, 0, err
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, 0, fmt.Errorf("input directory error: %v", err)
+	}
+	if !info.IsDir() {
+		return nil, 0, fmt.Errorf("'%s' is not a directory", dir)
+	}
+	tree, err := listInputTree(dir)
+	if err != nil {
+		return nil, 0, err
+	}
+	files := make([]string, 0, len(tree))
+	for file := range tree {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	flattenOpts := options.FlattenOpts
+	flattenOpts.IncludeKeys = options.KeyGlobs
+
+	// Read documents in parallel, grouping their keys in file order
+	type flatFile struct {
+		flat map[string]any
+		err  error
+	}
+	entries := make(map[string][]CollisionEntry)
+	err = collectOrdered(len(files), options.Workers, func(i int) flatFile {
+		filePath := filepath.Join(dir, filepath.FromSlash(files[i]))
+		doc, err := readDocument(filePath, resolveFormat(filePath, options.Format), flattenOpts.Separator, utils.AnchorsExpand, options.Options)
+		if err != nil {
+			return flatFile{err: fmt.Errorf("failed to read input file %s: %v", filePath, err)}
+		}
+		return flatFile{flat: fitter.FlattenMapWithOptions(doc.data, "", flattenOpts)}
+	}, func(i int, result flatFile) error {
+		if result.err != nil {
+			return result.err
+		}
+		filePath := filepath.Join(dir, filepath.FromSlash(files[i]))
+		for key, value := range result.flat {
+			entries[key] = append(entries[key], CollisionEntry{File: filePath, Type: fitter.ValueType(value), Value: value})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var collisions []KeyCollision
+	for key, keyEntries := range entries {
+		if len(keyEntries) < 2 {
+			continue
+		}
+		kind := ""
+		for _, entry := range keyEntries[1:] {
+			if entry.Type != keyEntries[0].Type {
+				kind = CollisionType
+				break
+			}
+			if !fitter.EqualValues(entry.Value, keyEntries[0].Value) {
+				kind = CollisionValue
+			}
+		}
+		if kind == "" || (options.TypesOnly && kind != CollisionType) {
+			continue
+		}
+		collisions = append(collisions, KeyCollision{Key: key, Kind: kind, Entries: keyEntries})
+	}
+	sort.Slice(collisions, func(i, j int) bool {
+		return collisions[i].Key < collisions[j].Key
+	})
+	return collisions, len(files), nil
+}
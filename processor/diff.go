@@ -0,0 +1,146 @@
+package processor
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+
+	"github.com/haiyon/fitobj/fitter"
+	"github.com/haiyon/fitobj/utils"
+)
+
+// DiffOptions configures document comparison
+type DiffOptions struct {
+	Options
+	KeysOnly bool     // compare key presence and value types only, as fitter.DiffFlatKeys
+	ArrayKey string   // identify elements of arrays of objects by this field instead of their index (optional)
+	Ignore   []string // leave out keys matching these patterns, like FlattenOptions.ExcludeKeys
+}
+
+// DiffFiles flattens two documents with the flatten options and compares them. The
+// documents may be in different formats, such as a JSON file and a YAML file.
+func DiffFiles(oldPath, newPath string, options DiffOptions) (fitter.FlatDiff, error) {
+	if err := ValidateFormat(options.Format); err != nil {
+		return fitter.FlatDiff{}, err
+	}
+	if err := fitter.ValidateKeyPatterns(nil, options.Ignore); err != nil {
+		return fitter.FlatDiff{}, err
+	}
+
+	oldFlat, err := readFlat(oldPath, options)
+	if err != nil {
+		return fitter.FlatDiff{}, err
+	}
+	newFlat, err := readFlat(newPath, options)
+	if err != nil {
+		return fitter.FlatDiff{}, err
+	}
+
+	if options.KeysOnly {
+		return fitter.DiffFlatKeys(oldFlat, newFlat), nil
+	}
+	return fitter.DiffFlat(oldFlat, newFlat), nil
+}
+
+// readFlat reads a document and flattens it without the ignored keys, keying array
+// elements by identity when an array key is set
+func readFlat(path string, options DiffOptions) (map[string]any, error) {
+	doc, err := readDocument(path, resolveFormat(path, options.Format), options.FlattenOpts.Separator, utils.AnchorsExpand, options.Options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input file %s: %v", path, err)
+	}
+	if options.ArrayKey != "" {
+		doc.data = fitter.KeyArrayElements(doc.data, options.ArrayKey)
+	}
+
+	flattenOpts := options.FlattenOpts
+	flattenOpts.ExcludeKeys = append(append([]string{}, flattenOpts.ExcludeKeys...), options.Ignore...)
+	return fitter.FlattenMapWithOptions(doc.data, "", flattenOpts), nil
+}
+
+// FileDiff is the comparison of a file present in both compared directories
+type FileDiff struct {
+	File string `json:"file"` // path relative to the directories, with forward slashes
+	fitter.FlatDiff
+}
+
+// DirDiff lists the differences between two directories, sorted by path
+type DirDiff struct {
+	AddedFiles   []string   `json:"addedFiles"`
+	RemovedFiles []string   `json:"removedFiles"`
+	ChangedFiles []FileDiff `json:"changedFiles"`
+}
+
+// Empty reports whether the directories hold the same files with equal documents
+func (d DirDiff) Empty() bool {
+	return len(d.AddedFiles) == 0 && len(d.RemovedFiles) == 0 && len(d.ChangedFiles) == 0
+}
+
+// DiffDirectories compares the documents of two directory trees, matching files by
+// relative path. Files present on one side only are reported as added or removed;
+// files present on both sides are compared as DiffFiles and listed when they differ.
+func DiffDirectories(oldDir, newDir string, options DiffOptions) (DirDiff, error) {
+	diff := DirDiff{AddedFiles: []string{}, RemovedFiles: []string{}, ChangedFiles: []FileDiff{}}
+	if err := fitter.ValidateKeyPatterns(nil, options.Ignore); err != nil {
+		return diff, err
+	}
+
+	oldFiles, err := listInputTree(oldDir)
+	if err != nil {
+		return diff, err
+	}
+	newFiles, err := listInputTree(newDir)
+	if err != nil {
+		return diff, err
+	}
+
+	for file := range oldFiles {
+		if !newFiles[file] {
+			diff.RemovedFiles = append(diff.RemovedFiles, file)
+		}
+	}
+	for file := range newFiles {
+		if !oldFiles[file] {
+			diff.AddedFiles = append(diff.AddedFiles, file)
+			continue
+		}
+
+		fileDiff, err := DiffFiles(filepath.Join(oldDir, filepath.FromSlash(file)), filepath.Join(newDir, filepath.FromSlash(file)), options)
+		if err != nil {
+			return diff, err
+		}
+		if !fileDiff.Empty() {
+			diff.ChangedFiles = append(diff.ChangedFiles, FileDiff{File: file, FlatDiff: fileDiff})
+		}
+	}
+
+	sort.Strings(diff.AddedFiles)
+	sort.Strings(diff.RemovedFiles)
+	sort.Slice(diff.ChangedFiles, func(i, j int) bool { return diff.ChangedFiles[i].File < diff.ChangedFiles[j].File })
+	return diff, nil
+}
+
+// listInputTree returns the documents below a directory as a set of relative
+// paths with forward slashes
+func listInputTree(dir string) (map[string]bool, error) {
+	files := make(map[string]bool)
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !isInputFile(entry.Name()) {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = true
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory %s: %v", dir, err)
+	}
+	return files, nil
+}
@@ -0,0 +1,138 @@
+package processor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/haiyon/fitobj/fitter"
+	"github.com/haiyon/fitobj/utils"
+)
+
+// Tabular export formats
+const (
+	ExportParquet  = "parquet"
+	ExportBigQuery = "bigquery"
+	ExportCSV      = "csv" // key,value rows
+	ExportTSV      = "tsv" // key<TAB>value rows
+)
+
+// ValidateExportFormat checks that a tabular export format is known
+func ValidateExportFormat(format string) error {
+	switch format {
+	case ExportParquet, ExportBigQuery, ExportCSV, ExportTSV, ExportBundle:
+		return nil
+	}
+	return fmt.Errorf("unknown export format '%s' (expected parquet, bigquery, csv, tsv or bundle)", format)
+}
+
+// delimiter returns the field delimiter of a key/value table format
+func delimiter(format string) rune {
+	if format == ExportTSV {
+		return '\t'
+	}
+	return ','
+}
+
+// ExportDirectory flattens every document of a directory and writes the collection
+// as a single table with one row per file (in file name order) and one column per
+// distinct key. The bigquery format writes newline-delimited JSON rows and a
+// <name>.schema.json next to them. The csv and tsv formats write one row per key
+// instead, sorted by file and key, with a file column when options.SourceColumn
+// is set. The bundle format is written by BundleDirectory.
+func ExportDirectory(inputDir, outputPath, format string, options Options) error {
+	if err := ValidateExportFormat(format); err != nil {
+		return err
+	}
+	if format == ExportBundle {
+		return BundleDirectory(inputDir, outputPath, options)
+	}
+	if err := options.Validate(); err != nil {
+		return err
+	}
+
+	inputInfo, err := os.Stat(inputDir)
+	if err != nil {
+		return fmt.Errorf("input directory error: %v", err)
+	}
+	if !inputInfo.IsDir() {
+		return fmt.Errorf("'%s' is not a directory", inputDir)
+	}
+
+	files, err := listInputFiles(inputDir, options.Match)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no JSON files found in '%s'", inputDir)
+	}
+
+	// Read documents in parallel, combining them in file name order
+	type exportedFile struct {
+		flat map[string]any
+		err  error
+	}
+	docs := make([]map[string]any, 0, len(files))
+	var rows []utils.KeyValueRow
+	err = collectOrdered(len(files), options.Workers, func(i int) exportedFile {
+		inputPath := filepath.Join(inputDir, files[i])
+		doc, err := readDocument(inputPath, resolveFormat(files[i], options.Format), options.FlattenOpts.Separator, utils.AnchorsExpand, options)
+		if err != nil {
+			return exportedFile{err: fmt.Errorf("failed to read input file %s: %v", inputPath, err)}
+		}
+		if err := parseUnits(doc.data, options); err != nil {
+			return exportedFile{err: err}
+		}
+		flat := fitter.FlattenMapWithOptions(doc.data, "", options.FlattenOpts)
+		return exportedFile{flat: utils.FormatNumbers(flat, options.NumberFormat)}
+	}, func(i int, exported exportedFile) error {
+		if exported.err != nil {
+			return exported.err
+		}
+		docs = append(docs, exported.flat)
+
+		keys := make([]string, 0, len(exported.flat))
+		for key := range exported.flat {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			rows = append(rows, utils.KeyValueRow{File: files[i], Key: key, Value: exported.flat[key]})
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if format == ExportCSV || format == ExportTSV {
+		if err := utils.WriteKeyValueFile(outputPath, rows, delimiter(format), options.SourceColumn); err != nil {
+			return fmt.Errorf("failed to write output file %s: %v", outputPath, err)
+		}
+		options.logger().Info(fmt.Sprintf("Exported %d keys from %d documents to %s", len(rows), len(docs), outputPath),
+			"keys", len(rows), "documents", len(docs), "output", outputPath)
+		return nil
+	}
+
+	table := utils.BuildTable(docs)
+	switch format {
+	case ExportBigQuery:
+		err = utils.WriteBigQueryFiles(outputPath, SchemaPath(outputPath), table)
+	default:
+		err = utils.WriteParquetFile(outputPath, table)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write output file %s: %v", outputPath, err)
+	}
+
+	options.logger().Info(fmt.Sprintf("Exported %d documents with %d columns to %s", len(table.Rows), len(table.Columns), outputPath),
+		"documents", len(table.Rows), "columns", len(table.Columns), "output", outputPath)
+	return nil
+}
+
+// SchemaPath returns the path of the schema file written next to an export
+func SchemaPath(outputPath string) string {
+	return strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + ".schema.json"
+}
@@ -0,0 +1,31 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExportDirectoryValidatesOptions(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.json"), []byte(`{"a":{"b":1}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	outputPath := filepath.Join(t.TempDir(), "out.csv")
+
+	tests := map[string]func(*Options){
+		"non-json": func(o *Options) { o.NonJSON = "bogus" },
+		"order":    func(o *Options) { o.Order = "bogus" },
+		"escape":   func(o *Options) { o.FlattenOpts.Escape = "." },
+	}
+	for name, change := range tests {
+		options := DefaultOptions()
+		change(&options)
+		if err := ExportDirectory(dir, outputPath, ExportCSV, options); err == nil {
+			t.Errorf("%s: expected an invalid option to fail the export", name)
+		}
+		if _, err := os.Stat(outputPath); !os.IsNotExist(err) {
+			t.Fatalf("%s: expected no output file, got %v", name, err)
+		}
+	}
+}
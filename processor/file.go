@@ -1,12 +1,17 @@
 package processor
 
 import (
+	"context"
 	"fmt"
+	"io/fs"
+	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
-	"sync/atomic"
+	"time"
 
 	"github.com/haiyon/fitobj/fitter"
 	"github.com/haiyon/fitobj/utils"
@@ -17,6 +22,28 @@ type Options struct {
 	Workers       int
 	FlattenOpts   fitter.FlattenOptions
 	UnflattenOpts fitter.UnflattenOptions
+	NumberFormat  utils.NumberFormat
+	Schema        *fitter.Schema          // JSON Schema applied to unflattened output (optional)
+	YAMLAnchors   string                  // YAML anchor handling: "expand" (default) or "record"
+	Target        string                  // database update format for flattened output: "mongodb" or "firestore" (optional)
+	Bulk          *utils.BulkOptions      // write Elasticsearch bulk files (.ndjson) instead of JSON (optional)
+	Format        string                  // force the input parser and output encoding: "json", "jsonc", "yaml", "env", "properties" or a registered format (default: by extension)
+	Env           utils.EnvOptions        // variable name mangling of dotenv files
+	Properties    utils.PropertiesOptions // escaping of properties files
+	SourceColumn  bool                    // csv/tsv export: add a file column naming the source document
+	Strict        bool                    // fail files with schema warnings, and directory runs without input files
+	Order         string                  // key order of written objects: "alpha" (default), "natural" or "preserve"
+	MetricsFile   string                  // write the timing of directory runs to this file in the Prometheus text format (optional)
+	Adaptive      bool                    // size the worker pool and batch files by file size, Workers being the maximum
+	MaxMemory     int64                   // bytes of estimated decode memory in use at once; file starts wait beyond it (0: unlimited)
+	Resume        bool                    // skip the files the JournalFile of an interrupted run lists, when their inputs are unchanged
+	NonJSON       string                  // values JSON cannot encode, such as YAML .nan and .inf: "keep" (default), "error", "null" or "string"
+	Dates         *fitter.DateOptions     // normalize timestamp values to RFC 3339 or Unix time as documents are read (optional)
+	Units         *fitter.UnitOptions     // parse unit-suffixed values such as "10s" and "512Mi" into numbers when flattening (optional)
+	SkipConverted bool                    // write documents already flat when flattening, or nested when unflattening, as read (reported as skipped) unless options change their values
+	Match         []string                // glob patterns of the input paths processed, relative to the input directory, with ** across subdirectories (default: the documents at its top)
+	Logger        *slog.Logger            // receives progress at Info, with documents skipped by SkipConverted, other skipped entries at Debug and warnings at Warn (default: slog.Default())
+	Progress      ProgressReporter        // follows directory runs, whose per-file lines are then logged at Debug (optional)
 }
 
 // DefaultOptions returns the default options for processing
@@ -25,9 +52,67 @@ func DefaultOptions() Options {
 		Workers:       4,
 		FlattenOpts:   fitter.DefaultFlattenOptions(),
 		UnflattenOpts: fitter.DefaultUnflattenOptions(),
+		NumberFormat:  utils.DefaultNumberFormat(),
 	}
 }
 
+// Validate checks that the options are usable, so that runs fail before any
+// file is read. Every entry point taking Options calls it.
+func (o Options) Validate() error {
+	if err := ValidateFormat(o.Format); err != nil {
+		return err
+	}
+	if err := utils.ValidateNumberFormat(o.NumberFormat); err != nil {
+		return err
+	}
+	if err := utils.ValidateAnchorMode(o.YAMLAnchors); err != nil {
+		return err
+	}
+	if err := utils.ValidateOrder(o.Order); err != nil {
+		return err
+	}
+	if err := fitter.ValidateNonJSONPolicy(o.NonJSON); err != nil {
+		return err
+	}
+	if o.Dates != nil {
+		if err := fitter.ValidateDateOptions(*o.Dates); err != nil {
+			return err
+		}
+	}
+	if o.Units != nil {
+		if err := fitter.ValidateUnitOptions(*o.Units); err != nil {
+			return err
+		}
+	}
+	if err := ValidateMatchPatterns(o.Match); err != nil {
+		return err
+	}
+	if err := fitter.ValidateTarget(o.Target); err != nil {
+		return err
+	}
+	if err := fitter.ValidateKeyPatterns(o.FlattenOpts.IncludeKeys, o.FlattenOpts.ExcludeKeys); err != nil {
+		return err
+	}
+	if err := fitter.ValidateEmptyModes(o.FlattenOpts); err != nil {
+		return err
+	}
+	if err := fitter.ValidateEscape(o.FlattenOpts.Escape, o.FlattenOpts.EscapeStyle, o.FlattenOpts.Separator); err != nil {
+		return err
+	}
+	if err := fitter.ValidateEscape(o.UnflattenOpts.Escape, o.UnflattenOpts.EscapeStyle, o.UnflattenOpts.Separator); err != nil {
+		return err
+	}
+	if o.Bulk != nil {
+		if err := utils.ValidateBulkOptions(*o.Bulk); err != nil {
+			return err
+		}
+	}
+	if o.MaxMemory < 0 {
+		return fmt.Errorf("max memory must not be negative")
+	}
+	return nil
+}
+
 // ProcessFile processes a single JSON file
 func ProcessFile(inputPath, outputPath string, unflatten bool) error {
 	return ProcessFileWithOptions(inputPath, outputPath, unflatten, DefaultOptions())
@@ -35,25 +120,188 @@ func ProcessFile(inputPath, outputPath string, unflatten bool) error {
 
 // ProcessFileWithOptions processes a single JSON file with custom options
 func ProcessFileWithOptions(inputPath, outputPath string, unflatten bool, options Options) error {
-	// Read and parse the input JSON file
-	jsonData, err := utils.ReadJSONFile(inputPath)
+	if err := options.Validate(); err != nil {
+		return err
+	}
+	summary, err := processFile(inputPath, outputPath, unflatten, options)
+	if err == nil && summary.skip != "" {
+		options.logger().Info(fmt.Sprintf("Skipped: %s (%s)", filepath.Base(inputPath), describeSkipReason(summary.skip)),
+			"file", filepath.Base(inputPath), "reason", summary.skip)
+	}
+	for _, warning := range summary.Warnings {
+		options.logger().Warn(fmt.Sprintf("Schema warning in '%s': %s", filepath.Base(inputPath), warning),
+			"file", filepath.Base(inputPath), "warning", warning)
+	}
+	return err
+}
+
+// processFile processes a single file, counting the keys read and written.
+// Errors are *FileError values.
+func processFile(inputPath, outputPath string, unflatten bool, options Options) (FileSummary, error) {
+	start := time.Now()
+	summary := FileSummary{Timing: &FileTiming{}}
+	defer func() { summary.Timing.Total = time.Since(start).Seconds() }()
+
+	// Read and parse the input file, keeping comments of JSONC and YAML files
+	separator := options.FlattenOpts.Separator
+	if unflatten {
+		separator = options.UnflattenOpts.Separator
+	}
+
+	doc, err := readDocument(inputPath, resolveFormat(inputPath, options.Format), separator, options.YAMLAnchors, options)
+	summary.Timing.Decode = time.Since(start).Seconds()
 	if err != nil {
-		return fmt.Errorf("failed to read input file %s: %v", inputPath, err)
+		return summary, &FileError{Code: ErrorRead, Err: fmt.Errorf("failed to read input file %s: %v", inputPath, err)}
 	}
+	summary.KeysIn = countKeys(doc.data)
 
+	// Documents in the target form are written without being transformed
+	stage := time.Now()
+	if summary.skip = targetFormSkip(doc, inputPath, unflatten, options); summary.skip == "" {
+		var issues []fitter.SchemaIssue
+		doc, issues, err = transformDocument(doc, unflatten, options)
+		summary.Timing.Transform = time.Since(stage).Seconds()
+		if err != nil {
+			return summary, &FileError{Code: ErrorTransform, Err: err}
+		}
+		for _, issue := range issues {
+			summary.Warnings = append(summary.Warnings, issue.Path+": "+issue.Problem)
+		}
+		if options.Strict && len(issues) > 0 {
+			return summary, &FileError{Code: ErrorSchema, Err: fmt.Errorf("%d schema warnings in strict mode", len(issues))}
+		}
+	}
+
+	// Write the processed data to the output file
+	stage = time.Now()
+	if doc.comments != nil {
+		doc.comments = utils.AnchorComments(doc.comments, doc.data, separator, doc.order)
+	}
+	if options.Bulk != nil {
+		err = utils.WriteBulkFile(utils.BulkPath(outputPath), doc.data, *options.Bulk, separator)
+	} else {
+		err = writeDocument(outputPath, resolveFormat(outputPath, options.Format), doc, separator, options)
+	}
+	summary.Timing.Encode = time.Since(stage).Seconds()
+	if err != nil {
+		return summary, &FileError{Code: ErrorWrite, Err: fmt.Errorf("failed to write output file %s: %v", outputPath, err)}
+	}
+
+	summary.KeysOut = countKeys(doc.data)
+	return summary, nil
+}
+
+// targetFormSkip returns the reason to skip transforming a document already in
+// the form the run writes, with SkipConverted: flat when flattening, nested
+// when unflattening. The document is still written, as read. Runs writing
+// update or bulk documents, converting the format or with options changing the
+// values or keys of documents still transform it.
+func targetFormSkip(doc document, inputPath string, unflatten bool, options Options) string {
+	if !options.SkipConverted || options.Target != "" || options.Bulk != nil || transformsValues(unflatten, options) {
+		return ""
+	}
+	if format := options.Format; format != "" && format != FormatAuto && format != DetectFormat(inputPath) {
+		return ""
+	}
+	if unflatten && !fitter.NeedsUnflatten(doc.data, options.UnflattenOpts) {
+		return SkipNested
+	}
+	if !unflatten && !fitter.NeedsFlatten(doc.data) {
+		return SkipFlat
+	}
+	return ""
+}
+
+// transformsValues reports whether the transformation of a run changes more than
+// the shape of documents: flattening filters keys, drops or replaces empty
+// values and parses units, unflattening coerces types and applies a schema, and
+// both format numbers. Timestamps, non-JSON values and the key order are
+// handled as documents are read and written.
+func transformsValues(unflatten bool, options Options) bool {
+	if !options.NumberFormat.IsDefault() {
+		return true
+	}
+	if unflatten {
+		return options.UnflattenOpts.CoerceTypes || options.Schema != nil
+	}
+	flattenOpts := options.FlattenOpts
+	for _, mode := range []string{flattenOpts.Nulls, flattenOpts.EmptyObjects, flattenOpts.EmptyArrays} {
+		if mode != "" && mode != fitter.EmptyKeep {
+			return true
+		}
+	}
+	return len(flattenOpts.IncludeKeys) > 0 || len(flattenOpts.ExcludeKeys) > 0 || options.Units != nil
+}
+
+// transformDocument flattens or unflattens a document with the processing options,
+// returning the schema issues found in unflattened output. Mappings keyed by
+// integers, as recorded in the key types, are not turned into arrays. When the
+// source order is preserved, the key order of the result is recorded as well.
+func transformDocument(doc document, unflatten bool, options Options) (document, []fitter.SchemaIssue, error) {
 	var processedData map[string]any
+	var issues []fitter.SchemaIssue
+	var order utils.KeyOrder
+	if !unflatten {
+		if err := parseUnits(doc.data, options); err != nil {
+			return doc, nil, err
+		}
+	}
 	if unflatten {
-		processedData = fitter.UnflattenMapWithOptions(jsonData, options.UnflattenOpts)
+		processedData, order = unflattenDocument(doc, options)
+		processedData = utils.RestoreKeyMaps(processedData, doc.keyTypes, options.UnflattenOpts.Separator)
+		issues = fitter.ApplySchema(processedData, options.Schema, options.UnflattenOpts.Separator)
+	} else if options.Target != "" {
+		if issues := fitter.ValidateFieldNames(doc.data, options.Target); len(issues) > 0 {
+			problems := make([]string, len(issues))
+			for i, issue := range issues {
+				problems[i] = issue.Path + ": " + issue.Problem
+			}
+			return doc, nil, fmt.Errorf("%d fields incompatible with %s: %s", len(issues), options.Target, strings.Join(problems, "; "))
+		}
+		flattenOpts := fitter.TargetFlattenOptions(options.FlattenOpts, options.Target)
+		processedData, order = flattenDocument(doc, flattenOpts, options)
+		processedData = fitter.UpdateDocument(processedData, options.Target)
 	} else {
-		processedData = fitter.FlattenMapWithOptions(jsonData, "", options.FlattenOpts)
+		processedData, order = flattenDocument(doc, options.FlattenOpts, options)
 	}
 
-	// Write the processed data to the output file
-	if err := utils.WriteJSONFile(outputPath, processedData); err != nil {
-		return fmt.Errorf("failed to write output file %s: %v", outputPath, err)
+	doc.data = utils.FormatNumbers(processedData, options.NumberFormat)
+	doc.order = order
+	return doc, issues, nil
+}
+
+// parseUnits parses the unit-suffixed values of a document being flattened, in
+// place, when the options ask for it
+func parseUnits(data map[string]any, options Options) error {
+	if options.Units == nil {
+		return nil
 	}
+	units := *options.Units
+	units.Separator, units.ArrayFormatting = options.FlattenOpts.Separator, options.FlattenOpts.ArrayFormatting
+	_, err := fitter.ParseUnits(data, units)
+	return err
+}
 
-	return nil
+// flattenDocument flattens the data of a document, in source order when the order
+// is preserved
+func flattenDocument(doc document, flattenOpts fitter.FlattenOptions, options Options) (map[string]any, utils.KeyOrder) {
+	if options.Order != utils.OrderPreserve {
+		return fitter.FlattenMapWithOptions(doc.data, "", flattenOpts), nil
+	}
+	source := utils.OrderMap(doc.data, doc.order, flattenOpts.Separator)
+	flat := fitter.FlattenOrdered(source, "", flattenOpts)
+	return flat.ToMap(), flat.KeyOrder(flattenOpts.Separator)
+}
+
+// unflattenDocument unflattens the data of a document, in source order when the
+// order is preserved
+func unflattenDocument(doc document, options Options) (map[string]any, utils.KeyOrder) {
+	if options.Order != utils.OrderPreserve {
+		return fitter.UnflattenMapWithOptions(doc.data, options.UnflattenOpts), nil
+	}
+	source := utils.OrderMap(doc.data, doc.order, options.UnflattenOpts.Separator)
+	nested := fitter.UnflattenOrdered(source, options.UnflattenOpts)
+	return nested.ToMap(), nested.KeyOrder(options.UnflattenOpts.Separator)
 }
 
 // ProcessDirectory processes all JSON files in a directory
@@ -63,37 +311,153 @@ func ProcessDirectory(inputDir, outputDir string, unflatten bool) error {
 
 // ProcessDirectoryWithOptions processes all JSON files in a directory with custom options
 func ProcessDirectoryWithOptions(inputDir, outputDir string, unflatten bool, options Options) error {
+	logger := options.logger()
+	summary, err := processDirectory(inputDir, outputDir, unflatten, options, func(file FileSummary) {
+		for _, warning := range file.Warnings {
+			logger.Warn(fmt.Sprintf("Schema warning in '%s': %s", file.File, warning), "file", file.File, "warning", warning)
+		}
+		if file.Success {
+			level := slog.LevelInfo
+			if options.Progress != nil {
+				level = slog.LevelDebug
+			}
+			logger.Log(context.Background(), level, "Processed: "+file.File, "file", file.File, "keys_in", file.KeysIn, "keys_out", file.KeysOut)
+		} else {
+			logger.Warn(fmt.Sprintf("Error processing file '%s': %s", file.File, file.Error), "file", file.File, "error", file.Error)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	for _, file := range summary.Skips {
+		// Documents in the target form were asked for, unlike other entries
+		level := slog.LevelDebug
+		if file.Reason == SkipFlat || file.Reason == SkipNested {
+			level = slog.LevelInfo
+		}
+		logger.Log(context.Background(), level, fmt.Sprintf("Skipped: %s (%s)", file.File, describeSkipReason(file.Reason)), "file", file.File, "reason", file.Reason)
+	}
+
+	if len(summary.Files) == 0 {
+		if summary.Unchanged == 0 {
+			logger.Warn(fmt.Sprintf("Warning: No JSON files found in '%s'", inputDir), "dir", inputDir)
+		}
+		if summary.Skipped > 0 {
+			logger.Info(fmt.Sprintf("Skipped %d files: %s", summary.Skipped, summary.SkipCounts()), "skipped", summary.Skipped)
+		}
+		if summary.Unchanged > 0 {
+			logger.Info(fmt.Sprintf("Wrote %d documents already in the target form unchanged", summary.Unchanged), "unchanged", summary.Unchanged)
+		}
+		return nil
+	}
+
+	logger.Info(fmt.Sprintf("Processing completed. Processed %d files (%d successful, %d failed)",
+		len(summary.Files), summary.Processed, summary.Failed),
+		"files", len(summary.Files), "processed", summary.Processed, "failed", summary.Failed)
+	logger.Info(fmt.Sprintf("Took %s with %d workers, %.0f%% utilized",
+		formatSeconds(summary.Duration), len(summary.Workers), summary.Utilization()*100),
+		"seconds", summary.Duration, "workers", len(summary.Workers), "utilization", summary.Utilization())
+	if summary.Resumed > 0 {
+		logger.Info(fmt.Sprintf("Resumed an interrupted run: skipped %d files completed before", summary.Resumed), "resumed", summary.Resumed)
+	}
+	if summary.Skipped > 0 {
+		logger.Info(fmt.Sprintf("Skipped %d files: %s", summary.Skipped, summary.SkipCounts()), "skipped", summary.Skipped)
+	}
+	if summary.Unchanged > 0 {
+		logger.Info(fmt.Sprintf("Wrote %d documents already in the target form unchanged", summary.Unchanged), "unchanged", summary.Unchanged)
+	}
+	if summary.Throttled > 0 {
+		logger.Info(fmt.Sprintf("Held back %d file starts to stay within the memory budget", summary.Throttled), "throttled", summary.Throttled)
+	}
+
+	if summary.Failed > 0 {
+		return &FilesFailedError{Failed: summary.Failed, Total: len(summary.Files)}
+	}
+
+	return nil
+}
+
+// ProcessDirectoryWithSummary processes all files in a directory as
+// ProcessDirectoryWithOptions, without printing anything. Files that fail are
+// reported in the summary; the error is only set when the run could not start.
+func ProcessDirectoryWithSummary(inputDir, outputDir string, unflatten bool, options Options) (Summary, error) {
+	return processDirectory(inputDir, outputDir, unflatten, options, nil)
+}
+
+// processDirectory processes all files in a directory, passing the summary of
+// each file to report (when set) as it completes
+func processDirectory(inputDir, outputDir string, unflatten bool, options Options, report func(FileSummary)) (Summary, error) {
+	summary := Summary{Files: []FileSummary{}}
+
+	if err := options.Validate(); err != nil {
+		return summary, err
+	}
+
 	// Validate input directory
 	inputInfo, err := os.Stat(inputDir)
 	if err != nil {
-		return fmt.Errorf("input directory error: %v", err)
+		return summary, fmt.Errorf("input directory error: %v", err)
 	}
 	if !inputInfo.IsDir() {
-		return fmt.Errorf("'%s' is not a directory", inputDir)
+		return summary, fmt.Errorf("'%s' is not a directory", inputDir)
 	}
 
 	// Ensure output directory exists
 	if err := utils.EnsureDirectoryExists(outputDir); err != nil {
-		return fmt.Errorf("failed to create output directory: %v", err)
+		return summary, fmt.Errorf("failed to create output directory: %v", err)
 	}
 
-	// Read directory contents
-	files, err := os.ReadDir(inputDir)
+	jsonFiles, skipped, err := scanInputFiles(inputDir, options.Match)
 	if err != nil {
-		return fmt.Errorf("failed to read directory: %v", err)
+		return summary, err
 	}
+	summary.Skips = skipped
+	summary.Skipped = len(skipped)
 
-	// Filter for JSON files
-	var jsonFiles []string
-	for _, file := range files {
-		if !file.IsDir() && strings.HasSuffix(file.Name(), ".json") {
-			jsonFiles = append(jsonFiles, file.Name())
+	if len(jsonFiles) == 0 {
+		if options.Strict {
+			return summary, fmt.Errorf("no input files found in '%s'", inputDir)
 		}
+		summary.Success = true
+		return summary, nil
 	}
 
-	if len(jsonFiles) == 0 {
-		fmt.Printf("Warning: No JSON files found in '%s'\n", inputDir)
-		return nil
+	// Journal completed files, and skip those an interrupted run completed
+	header := newJournalHeader(unflatten, options)
+	journalPath := filepath.Join(outputDir, JournalFile)
+	var recorded map[string]journalEntry
+	if options.Resume {
+		if recorded, err = readJournal(journalPath, header); err != nil {
+			return summary, err
+		}
+	}
+
+	inputs := make(map[string]journalEntry, len(jsonFiles))
+	var kept []journalEntry
+	var pending []string
+	for _, file := range jsonFiles {
+		state := inputState(inputDir, file)
+		inputs[file] = state
+		entry, ok := recorded[file]
+		if !ok || !resumable(entry, state, OutputPath(filepath.Join(outputDir, file), options.Format), options) {
+			pending = append(pending, file)
+			continue
+		}
+		kept = append(kept, entry)
+		summary.Files = append(summary.Files, FileSummary{File: file, Success: true, KeysIn: entry.KeysIn, KeysOut: entry.KeysOut, Resumed: true})
+		summary.Processed++
+		summary.Resumed++
+		summary.KeysIn += entry.KeysIn
+		summary.KeysOut += entry.KeysOut
+	}
+
+	runJournal, err := openJournal(journalPath, header, kept)
+	if err != nil {
+		return summary, err
+	}
+
+	if options.Progress != nil {
+		options.Progress.Start(len(pending))
 	}
 
 	// Set up concurrency
@@ -102,42 +466,54 @@ func ProcessDirectoryWithOptions(inputDir, outputDir string, unflatten bool, opt
 		numWorkers = 1
 	}
 
+	// Split the files into batches, sized by file size in adaptive mode
+	start := time.Now()
+	batches, numWorkers := planBatches(inputDir, pending, numWorkers, options.Adaptive)
+	if options.Adaptive {
+		summary.Batches = len(batches)
+	}
+
 	// Create channels
-	filesChan := make(chan string, len(jsonFiles))
-	resultsChan := make(chan ProcessResult, len(jsonFiles))
+	batchesChan := make(chan []string, len(batches))
+	resultsChan := make(chan FileSummary, len(pending))
 
-	// Counters for progress tracking
-	var processed, failed int64
+	// Hold back file starts while the decode memory estimate is over budget
+	budget := newMemoryBudget(options.MaxMemory)
 
 	// Start worker goroutines
 	var wg sync.WaitGroup
 	for i := 0; i < numWorkers; i++ {
 		wg.Add(1)
-		go func() {
+		go func(worker int) {
 			defer wg.Done()
-			for file := range filesChan {
-				inputPath := filepath.Join(inputDir, file)
-				outputPath := filepath.Join(outputDir, file)
+			for batch := range batchesChan {
+				for _, file := range batch {
+					inputPath := filepath.Join(inputDir, file)
+					outputPath := OutputPath(filepath.Join(outputDir, file), options.Format)
 
-				err := ProcessFileWithOptions(inputPath, outputPath, unflatten, options)
-
-				result := ProcessResult{Filename: file, Error: err}
-				resultsChan <- result
-
-				if err != nil {
-					atomic.AddInt64(&failed, 1)
-				} else {
-					atomic.AddInt64(&processed, 1)
+					cost := fileCost(inputPath)
+					throttled := budget.acquire(cost)
+					result, err := processFile(inputPath, outputPath, unflatten, options)
+					budget.release(cost)
+					result.File = file
+					result.Worker = worker
+					result.Throttled = throttled
+					result.Success = err == nil
+					if err != nil {
+						result.Error = err.Error()
+						result.Code = err.(*FileError).Code
+					}
+					resultsChan <- result
 				}
 			}
-		}()
+		}(i + 1)
 	}
 
-	// Send files to workers
-	for _, file := range jsonFiles {
-		filesChan <- file
+	// Send batches to workers
+	for _, batch := range batches {
+		batchesChan <- batch
 	}
-	close(filesChan)
+	close(batchesChan)
 
 	// Close results channel when all workers are done
 	go func() {
@@ -146,27 +522,185 @@ func ProcessDirectoryWithOptions(inputDir, outputDir string, unflatten bool, opt
 	}()
 
 	// Process results
+	summary.Workers = make([]WorkerSummary, numWorkers)
+	for i := range summary.Workers {
+		summary.Workers[i].Worker = i + 1
+	}
 	for result := range resultsChan {
-		if result.Error != nil {
-			fmt.Printf("Error processing file '%s': %v\n", result.Filename, result.Error)
+		if options.Progress != nil {
+			options.Progress.Advance(result)
+		}
+		worker := &summary.Workers[result.Worker-1]
+		worker.Files++
+		worker.Busy += result.Timing.Total
+		if result.skip != "" && result.Success {
+			summary.Skips = append(summary.Skips, SkippedFile{File: result.File, Reason: result.skip})
+			summary.Skipped++
+			summary.Unchanged++
+			entry := inputs[result.File]
+			entry.KeysIn, entry.KeysOut = result.KeysIn, result.KeysOut
+			runJournal.record(entry)
+			continue
+		}
+
+		if report != nil {
+			report(result)
+		}
+		if result.Success {
+			summary.Processed++
+			entry := inputs[result.File]
+			entry.KeysIn, entry.KeysOut = result.KeysIn, result.KeysOut
+			runJournal.record(entry)
 		} else {
-			fmt.Printf("Processed: %s\n", result.Filename)
+			summary.Failed++
+		}
+		summary.KeysIn += result.KeysIn
+		summary.KeysOut += result.KeysOut
+		if result.Throttled {
+			summary.Throttled++
+		}
+		summary.Files = append(summary.Files, result)
+	}
+	if options.Progress != nil {
+		options.Progress.Finish()
+	}
+	sort.Slice(summary.Files, func(i, j int) bool { return summary.Files[i].File < summary.Files[j].File })
+	sort.SliceStable(summary.Skips, func(i, j int) bool { return summary.Skips[i].File < summary.Skips[j].File })
+	summary.Success = summary.Failed == 0
+
+	summary.Duration = time.Since(start).Seconds()
+	for i := range summary.Workers {
+		if summary.Duration > 0 {
+			summary.Workers[i].Utilization = summary.Workers[i].Busy / summary.Duration
 		}
 	}
 
-	successCount := atomic.LoadInt64(&processed)
-	errorCount := atomic.LoadInt64(&failed)
+	if err := runJournal.close(summary.Failed == 0); err != nil {
+		return summary, err
+	}
+
+	if options.MetricsFile != "" {
+		if err := writeMetricsFile(options.MetricsFile, summary, unflatten); err != nil {
+			return summary, err
+		}
+	}
+	return summary, nil
+}
+
+// listInputFiles returns the documents of a directory, or those of its tree
+// matching patterns
+func listInputFiles(dir string, match []string) ([]string, error) {
+	inputFiles, _, err := scanInputFiles(dir, match)
+	return inputFiles, err
+}
+
+// scanInputFiles returns the documents of a directory, and the other entries with
+// the reason they are skipped. With match patterns the directory tree is walked
+// instead, hidden directories aside, and the documents are those matching one of
+// the patterns, by their slash-separated path relative to dir.
+func scanInputFiles(dir string, match []string) ([]string, []SkippedFile, error) {
+	if len(match) > 0 {
+		return scanMatchingFiles(dir, match)
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read directory: %v", err)
+	}
+
+	var inputFiles []string
+	var skipped []SkippedFile
+	for _, file := range files {
+		if reason := skipReason(file.Name(), file.IsDir()); reason != "" {
+			skipped = append(skipped, SkippedFile{File: file.Name(), Reason: reason})
+			continue
+		}
+		inputFiles = append(inputFiles, file.Name())
+	}
+	return inputFiles, skipped, nil
+}
+
+// scanMatchingFiles returns the documents of a directory tree matching one of the
+// patterns, and the other files with the reason they are skipped
+func scanMatchingFiles(dir string, match []string) ([]string, []SkippedFile, error) {
+	var inputFiles []string
+	var skipped []SkippedFile
+	err := filepath.WalkDir(dir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if filePath != dir && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
 
-	fmt.Printf("Processing completed. Processed %d files (%d successful, %d failed)\n",
-		len(jsonFiles), successCount, errorCount)
+		if reason := skipReason(entry.Name(), false); reason != "" {
+			skipped = append(skipped, SkippedFile{File: rel, Reason: reason})
+		} else if !matchesInputPath(match, rel) {
+			skipped = append(skipped, SkippedFile{File: rel, Reason: SkipUnmatched})
+		} else {
+			inputFiles = append(inputFiles, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read directory: %v", err)
+	}
+	return inputFiles, skipped, nil
+}
 
-	if errorCount > 0 {
-		return fmt.Errorf("%d files failed to process", errorCount)
+// matchesInputPath reports whether a relative input path matches one of the patterns
+func matchesInputPath(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if fitter.MatchPath(strings.TrimPrefix(pattern, "./"), rel, "/") {
+			return true
+		}
 	}
+	return false
+}
 
+// ValidateMatchPatterns checks that input path patterns compile
+func ValidateMatchPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		for _, segment := range strings.Split(pattern, "/") {
+			if _, err := path.Match(segment, ""); err != nil {
+				return fmt.Errorf("invalid match pattern '%s': %v", pattern, err)
+			}
+		}
+	}
 	return nil
 }
 
+// isInputFile reports whether a file name is a document of a registered format,
+// skipping recorded anchor, key type and provenance sidecars
+func isInputFile(name string) bool {
+	return skipReason(name, false) == ""
+}
+
+// skipReason returns why a directory entry is not processed, or "" for a document
+func skipReason(name string, dir bool) string {
+	switch {
+	case dir:
+		return SkipDirectory
+	case name == JournalFile || strings.HasPrefix(name, JournalFile+"."):
+		return SkipJournal
+	case strings.HasSuffix(name, utils.AnchorsSuffix) || strings.HasSuffix(name, utils.KeyTypesSuffix) ||
+		strings.HasSuffix(name, ProvenanceSuffix):
+		return SkipSidecar
+	}
+	if _, ok := detectFormat(name); ok {
+		return ""
+	}
+	return SkipExtension
+}
+
 // ProcessResult represents the result of processing a single file
 type ProcessResult struct {
 	Filename string
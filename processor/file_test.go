@@ -0,0 +1,148 @@
+package processor
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/haiyon/fitobj/utils"
+)
+
+// writeInputs writes JSON documents into a new directory
+func writeInputs(t *testing.T, docs map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range docs {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// readOutput reads a JSON document written by a run
+func readOutput(t *testing.T, path string) map[string]any {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected %s to be written: %v", path, err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestSkipConvertedWritesUnchangedDocuments(t *testing.T) {
+	plain := `{"name":"x","count":2}`
+	for _, unflatten := range []bool{false, true} {
+		inputDir := writeInputs(t, map[string]string{"plain.json": plain})
+		outputDir := t.TempDir()
+
+		options := DefaultOptions()
+		options.SkipConverted = true
+		summary, err := ProcessDirectoryWithSummary(inputDir, outputDir, unflatten, options)
+		if err != nil {
+			t.Fatalf("unflatten %v: unexpected error: %v", unflatten, err)
+		}
+
+		reason := SkipFlat
+		if unflatten {
+			reason = SkipNested
+		}
+		if summary.Unchanged != 1 || !reflect.DeepEqual(summary.Skips, []SkippedFile{{File: "plain.json", Reason: reason}}) {
+			t.Errorf("unflatten %v: expected plain.json skipped as %s, got %d unchanged, skips %v", unflatten, reason, summary.Unchanged, summary.Skips)
+		}
+		expected := map[string]any{"name": "x", "count": float64(2)}
+		if got := readOutput(t, filepath.Join(outputDir, "plain.json")); !reflect.DeepEqual(got, expected) {
+			t.Errorf("unflatten %v: expected the document written as read, got %v", unflatten, got)
+		}
+	}
+}
+
+func TestSkipConvertedAppliesValueOptions(t *testing.T) {
+	inputDir := writeInputs(t, map[string]string{"flat.json": `{"a.b":1,"secret":"x","none":null}`})
+
+	tests := map[string]struct {
+		change   func(*Options)
+		expected map[string]any
+	}{
+		"exclude": {
+			func(o *Options) { o.FlattenOpts.ExcludeKeys = []string{"secret"} },
+			map[string]any{"a.b": float64(1), "none": nil},
+		},
+		"nulls": {
+			func(o *Options) { o.FlattenOpts.Nulls = "drop" },
+			map[string]any{"a.b": float64(1), "secret": "x"},
+		},
+	}
+	for name, test := range tests {
+		outputDir := t.TempDir()
+		options := DefaultOptions()
+		options.SkipConverted = true
+		test.change(&options)
+
+		summary, err := ProcessDirectoryWithSummary(inputDir, outputDir, false, options)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if summary.Unchanged != 0 || summary.Processed != 1 {
+			t.Errorf("%s: expected the document to be flattened, got %d unchanged, %d processed", name, summary.Unchanged, summary.Processed)
+		}
+		if got := readOutput(t, filepath.Join(outputDir, "flat.json")); !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s: expected %v, got %v", name, test.expected, got)
+		}
+	}
+}
+
+func TestYAMLAnchorsRecordRoundTrip(t *testing.T) {
+	source := `defaults: &defaults
+  adapter: postgres
+  pool: 5
+dev:
+  <<: *defaults
+  database: dev
+hosts: &hosts
+  - a
+  - b
+backup:
+  hosts: *hosts
+`
+	inputDir := writeInputs(t, map[string]string{"config.yaml": source})
+	flatDir, outputDir := t.TempDir(), t.TempDir()
+	options := DefaultOptions()
+	options.YAMLAnchors = utils.AnchorsRecord
+
+	if _, err := ProcessDirectoryWithSummary(inputDir, flatDir, false, options); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(flatDir, "config.yaml"+utils.AnchorsSuffix)); err != nil {
+		t.Fatalf("Expected the anchors kept in a sidecar file: %v", err)
+	}
+	if _, err := ProcessDirectoryWithSummary(flatDir, outputDir, true, options); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(outputDir, "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `backup:
+  hosts: &hosts
+    - a
+    - b
+defaults: &defaults
+  adapter: postgres
+  pool: 5
+dev:
+  <<: *defaults
+  database: dev
+hosts: *hosts
+`
+	if string(got) != expected {
+		t.Errorf("Expected the anchors, merge keys and aliases restored:\n%s\ngot:\n%s", expected, got)
+	}
+}
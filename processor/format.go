@@ -0,0 +1,220 @@
+package processor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/haiyon/fitobj/fitter"
+	"github.com/haiyon/fitobj/utils"
+)
+
+// File formats for reading and writing documents
+const (
+	FormatAuto       = "auto"
+	FormatJSON       = "json"
+	FormatJSONC      = "jsonc"
+	FormatYAML       = "yaml"
+	FormatEnv        = "env"
+	FormatProperties = "properties"
+)
+
+// ValidateFormat checks that a file format is registered. An empty format means auto.
+func ValidateFormat(format string) error {
+	if format == "" || format == FormatAuto {
+		return nil
+	}
+	if _, ok := LookupFormat(format); ok {
+		return nil
+	}
+	names := append([]string{FormatAuto}, Formats()...)
+	expected := strings.Join(names[:len(names)-1], ", ") + " or " + names[len(names)-1]
+	return fmt.Errorf("unknown format '%s' (expected %s)", format, expected)
+}
+
+// DetectFormat returns the format of a file from its extension, defaulting to JSON
+func DetectFormat(path string) string {
+	if format, ok := detectFormat(path); ok {
+		return format
+	}
+	return FormatJSON
+}
+
+// resolveFormat returns the forced format, or the format detected from the path
+// when the format is auto
+func resolveFormat(path, format string) string {
+	if format == "" || format == FormatAuto {
+		return DetectFormat(path)
+	}
+	return format
+}
+
+// OutputPath returns the output path for a file written in a forced format: the
+// extension is replaced when it does not match the format (config.json becomes
+// config.yaml under yaml). Paths are unchanged when the format is auto. Hidden
+// dotenv files are made visible: .env becomes env.json and .env.prod env.prod.json.
+func OutputPath(path, format string) string {
+	if format == "" || format == FormatAuto || DetectFormat(path) == format {
+		return path
+	}
+	if base := filepath.Base(path); utils.IsEnvFile(path) && strings.HasPrefix(base, ".env") {
+		name := strings.TrimSuffix(strings.TrimPrefix(base, "."), ".env")
+		return filepath.Join(filepath.Dir(path), name+"."+format)
+	}
+	return strings.TrimSuffix(path, filepath.Ext(path)) + "." + format
+}
+
+// isFlatFormat reports whether a format holds flattened keys with string values
+func isFlatFormat(format string) bool {
+	flat, ok := formatHandler(format).(FlatFormat)
+	return ok && flat.Flat()
+}
+
+// document is a parsed input file with the metadata carried to its output
+type document struct {
+	data     map[string]any
+	comments utils.Comments
+	anchors  *utils.Anchors
+	keyTypes utils.KeyTypes
+	order    utils.KeyOrder // key positions of the source, recorded to preserve the order
+
+	trailingCommas bool // the JSONC source ended objects and arrays with commas
+}
+
+// ordering returns the key order of a written document, nil for alphabetical
+func (doc document) ordering(options Options) *utils.Ordering {
+	if options.Order == "" || options.Order == utils.OrderAlpha {
+		return nil
+	}
+	return &utils.Ordering{Mode: options.Order, Source: doc.order}
+}
+
+// formatOptions returns the options of the format handlers for a document
+func (doc document) formatOptions(separator string, options Options) FormatOptions {
+	return FormatOptions{Separator: separator, Order: doc.ordering(options), Env: options.Env, Properties: options.Properties}
+}
+
+// readDocument reads a document in the given format. Comments are kept for JSONC
+// and YAML; anchors are only returned for YAML in record mode, combined with those
+// recorded in its sidecar file by an earlier run, so they survive formats (such
+// as flattened YAML) that cannot hold them. Key types come from YAML keys that are
+// not strings and from a sidecar file written by an earlier run. Dotenv and
+// properties documents are returned flattened, with string values; the options
+// only configure these formats.
+func readDocument(path, format, separator, anchorMode string, options Options) (document, error) {
+	// Flat formats cannot be parsed as the other formats nor the reverse, so a
+	// forced format only changes the parser between JSON, JSONC and YAML
+	if detected := DetectFormat(path); isFlatFormat(detected) || isFlatFormat(format) {
+		format = detected
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return document{}, fmt.Errorf("failed to read file: %v", err)
+	}
+	doc, err := parseDocument(data, format, separator, options)
+	if err != nil {
+		return doc, err
+	}
+
+	if anchorMode != utils.AnchorsRecord || format != FormatYAML {
+		doc.anchors = nil
+	} else {
+		recorded, err := utils.ReadAnchorsFile(path + utils.AnchorsSuffix)
+		if err != nil {
+			return doc, err
+		}
+		doc.anchors = utils.MergeAnchors(doc.anchors, recorded)
+	}
+
+	recorded, err := utils.ReadKeyTypesFile(path + utils.KeyTypesSuffix)
+	if err != nil {
+		return doc, err
+	}
+	doc.keyTypes = utils.MergeKeyTypes(doc.keyTypes, recorded)
+	return doc, nil
+}
+
+// parseDocument parses a document held in memory with the handler of its
+// format, without sidecar files. The key positions are recorded when the source
+// order is preserved and the format has one, values JSON cannot encode are
+// handled by the NonJSON policy and timestamps are normalized with Dates.
+func parseDocument(data []byte, format, separator string, options Options) (document, error) {
+	handler := formatHandler(format)
+	parsed, err := handler.Read(data, document{}.formatOptions(separator, options))
+	if err != nil {
+		return document{}, err
+	}
+	doc := document{data: parsed.Data, comments: parsed.Comments, anchors: parsed.Anchors, keyTypes: parsed.KeyTypes, trailingCommas: parsed.TrailingCommas}
+	if doc.data == nil {
+		doc.data = make(map[string]any)
+	}
+	if err := fitter.SanitizeValues(doc.data, options.NonJSON, separator); err != nil {
+		return doc, err
+	}
+	if err := normalizeDocumentDates(doc.data, separator, options); err != nil {
+		return doc, err
+	}
+
+	if reader, ok := handler.(KeyOrderReader); ok && options.Order == utils.OrderPreserve {
+		if doc.order, err = reader.KeyOrder(data, separator); err != nil {
+			return doc, err
+		}
+	}
+	return doc, nil
+}
+
+// normalizeDocumentDates normalizes the timestamps of a document with Dates,
+// when set
+func normalizeDocumentDates(data map[string]any, separator string, options Options) error {
+	if options.Dates == nil {
+		return nil
+	}
+	dates := *options.Dates
+	dates.Separator, dates.ArrayFormatting = separator, options.FlattenOpts.ArrayFormatting
+	_, err := fitter.NormalizeDates(data, dates)
+	return err
+}
+
+// writeDocument writes a document in the given format. Comments are written for
+// JSONC and YAML, and recorded anchors are restored in YAML and kept in a sidecar
+// file so a later run can restore them. Key types are kept in a sidecar file for
+// every format, since flattened keys and JSON cannot hold them. Dotenv and
+// properties documents are flattened first; the options only configure these
+// formats.
+func writeDocument(path, format string, doc document, separator string, options Options) error {
+	data, err := marshalDocument(doc, format, separator, options)
+	if err != nil {
+		return err
+	}
+	if err := utils.EnsureDirectoryExists(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("failed to create parent directory: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %v", err)
+	}
+
+	if format == FormatYAML && !doc.anchors.IsEmpty() {
+		if err := utils.WriteAnchorsFile(path+utils.AnchorsSuffix, doc.anchors); err != nil {
+			return err
+		}
+	}
+	if len(doc.keyTypes) == 0 {
+		return nil
+	}
+	return utils.WriteKeyTypesFile(path+utils.KeyTypesSuffix, doc.keyTypes)
+}
+
+// marshalDocument encodes a document with the handler of its format, without
+// sidecar files. Documents of flat formats are flattened first.
+func marshalDocument(doc document, format, separator string, options Options) ([]byte, error) {
+	handler := formatHandler(format)
+	data := doc.data
+	if isFlatFormat(format) {
+		flattenOpts := fitter.DefaultFlattenOptions()
+		flattenOpts.Separator = separator
+		data = fitter.FlattenMapWithOptions(data, "", flattenOpts)
+	}
+	return handler.Write(Document{Data: data, Comments: doc.comments, Anchors: doc.anchors, KeyTypes: doc.keyTypes, TrailingCommas: doc.trailingCommas}, doc.formatOptions(separator, options))
+}
@@ -0,0 +1,121 @@
+package processor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/haiyon/fitobj/fitter"
+	"github.com/haiyon/fitobj/utils"
+)
+
+// GrepOptions configures a search of flattened documents. A key matches when its
+// value matches ValueRegex and the key matches KeyRegex, ignoring the patterns
+// that are unset; at least one of them must be set.
+type GrepOptions struct {
+	Options
+	ValueRegex string   // regular expression matched against values: strings as-is, other values as JSON
+	KeyRegex   string   // regular expression matched against flattened keys
+	KeyGlobs   []string // only search keys matching these patterns, like FlattenOptions.IncludeKeys (optional)
+}
+
+// GrepMatch is a flattened key that matched a search
+type GrepMatch struct {
+	File  string `json:"file"` // path of the document, below the searched path
+	Key   string `json:"key"`
+	Value any    `json:"value"`
+}
+
+// GrepPath searches the flattened keys of a document, or of every document below a
+// directory, returning the matches sorted by file and key
+func GrepPath(path string, options GrepOptions) ([]GrepMatch, error) {
+	if options.ValueRegex == "" && options.KeyRegex == "" {
+		return nil, fmt.Errorf("a value or key regular expression is required")
+	}
+	valueRegex, err := regexp.Compile(options.ValueRegex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value regular expression: %v", err)
+	}
+	keyRegex, err := regexp.Compile(options.KeyRegex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key regular expression: %v", err)
+	}
+	if err := fitter.ValidateKeyPatterns(options.KeyGlobs, nil); err != nil {
+		return nil, err
+	}
+	if err := ValidateFormat(options.Format); err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("input error: %v", err)
+	}
+
+	files, dir := []string{path}, ""
+	if info.IsDir() {
+		tree, err := listInputTree(path)
+		if err != nil {
+			return nil, err
+		}
+		files = files[:0]
+		for file := range tree {
+			files = append(files, file)
+		}
+		sort.Strings(files)
+		dir = path
+	}
+
+	flattenOpts := options.FlattenOpts
+	flattenOpts.IncludeKeys = options.KeyGlobs
+
+	// Search documents in parallel, combining matches in file order
+	type fileMatches struct {
+		matches []GrepMatch
+		err     error
+	}
+	var matches []GrepMatch
+	err = collectOrdered(len(files), options.Workers, func(i int) fileMatches {
+		filePath := filepath.Join(dir, filepath.FromSlash(files[i]))
+		doc, err := readDocument(filePath, resolveFormat(filePath, options.Format), flattenOpts.Separator, utils.AnchorsExpand, options.Options)
+		if err != nil {
+			return fileMatches{err: fmt.Errorf("failed to read input file %s: %v", filePath, err)}
+		}
+
+		flat := fitter.FlattenMapWithOptions(doc.data, "", flattenOpts)
+		keys := make([]string, 0, len(flat))
+		for key := range flat {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		var found []GrepMatch
+		for _, key := range keys {
+			if !keyRegex.MatchString(key) || !valueRegex.MatchString(searchText(flat[key])) {
+				continue
+			}
+			found = append(found, GrepMatch{File: filePath, Key: key, Value: flat[key]})
+		}
+		return fileMatches{matches: found}
+	}, func(_ int, result fileMatches) error {
+		matches = append(matches, result.matches...)
+		return result.err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+// searchText returns the text searched for a value: strings as-is and other
+// values as JSON
+func searchText(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
@@ -0,0 +1,221 @@
+package processor
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/haiyon/fitobj/utils"
+)
+
+// Document is a document as read and written by a format handler. Comments,
+// YAML anchors and key types are kept by the formats that can hold them.
+type Document struct {
+	Data     map[string]any
+	Comments utils.Comments // comments by key path
+	Anchors  *utils.Anchors // YAML anchors, aliases and merges
+	KeyTypes utils.KeyTypes // types of keys that are not strings
+
+	TrailingCommas bool // JSONC: objects and arrays end with a comma after their last entry
+}
+
+// FormatOptions configures reading and writing a document
+type FormatOptions struct {
+	Separator  string          // separator of key paths
+	Order      *utils.Ordering // key order of written objects, nil for alphabetical
+	Env        utils.EnvOptions
+	Properties utils.PropertiesOptions
+}
+
+// FormatHandler reads and writes the documents of a file format. Handlers of
+// new formats are made available to every command with RegisterFormat.
+type FormatHandler interface {
+	// Detect reports whether a file is in the format, from its name
+	Detect(path string) bool
+	// Read parses a document
+	Read(data []byte, options FormatOptions) (Document, error)
+	// Write encodes a document
+	Write(doc Document, options FormatOptions) ([]byte, error)
+}
+
+// FlatFormat is implemented by handlers of formats holding flattened keys with
+// string values: documents are flattened before they are written, and are only
+// read by their own handler whatever the forced format
+type FlatFormat interface {
+	Flat() bool
+}
+
+// KeyOrderReader is implemented by handlers that record the key positions of a
+// document, to write it in its source order
+type KeyOrderReader interface {
+	KeyOrder(data []byte, separator string) (utils.KeyOrder, error)
+}
+
+var (
+	formatsMu      sync.RWMutex
+	formatHandlers = make(map[string]FormatHandler)
+	formatNames    []string // in registration order
+)
+
+func init() {
+	RegisterFormat(FormatJSON, jsonHandler{})
+	RegisterFormat(FormatJSONC, jsoncHandler{})
+	RegisterFormat(FormatYAML, yamlHandler{})
+	RegisterFormat(FormatEnv, envHandler{})
+	RegisterFormat(FormatProperties, propertiesHandler{})
+}
+
+// RegisterFormat makes a format handler available by name, as a --format value
+// and for the files it detects. Registering a name again replaces its handler.
+func RegisterFormat(name string, handler FormatHandler) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	if _, ok := formatHandlers[name]; !ok {
+		formatNames = append(formatNames, name)
+	}
+	formatHandlers[name] = handler
+}
+
+// LookupFormat returns the handler registered for a format
+func LookupFormat(name string) (FormatHandler, bool) {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	handler, ok := formatHandlers[name]
+	return handler, ok
+}
+
+// Formats returns the names of registered formats in registration order
+func Formats() []string {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	return append([]string(nil), formatNames...)
+}
+
+// detectFormat returns the format of a file name. JSON is checked last, as the
+// other formats may be more specific (.env.json is a dotenv file).
+func detectFormat(path string) (string, bool) {
+	names := Formats()
+	for _, name := range names {
+		if handler, ok := LookupFormat(name); ok && name != FormatJSON && handler.Detect(path) {
+			return name, true
+		}
+	}
+	if handler, ok := LookupFormat(FormatJSON); ok && handler.Detect(path) {
+		return FormatJSON, true
+	}
+	return "", false
+}
+
+// formatHandler returns the handler of a format, the JSON handler for unknown
+// formats
+func formatHandler(format string) FormatHandler {
+	if handler, ok := LookupFormat(format); ok {
+		return handler
+	}
+	return jsonHandler{}
+}
+
+// jsonHandler reads and writes JSON documents; empty files are empty objects
+type jsonHandler struct{}
+
+func (jsonHandler) Detect(path string) bool { return utils.IsJSONFile(path) }
+
+func (jsonHandler) Read(data []byte, options FormatOptions) (Document, error) {
+	doc := Document{Data: make(map[string]any)}
+	if len(data) == 0 {
+		return doc, nil
+	}
+	if err := json.Unmarshal(data, &doc.Data); err != nil {
+		return doc, fmt.Errorf("failed to parse JSON: %v", err)
+	}
+	return doc, nil
+}
+
+func (jsonHandler) Write(doc Document, options FormatOptions) ([]byte, error) {
+	data, err := utils.MarshalJSONOrdered(doc.Data, options.Separator, options.Order)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize JSON: %v", err)
+	}
+	return data, nil
+}
+
+func (jsonHandler) KeyOrder(data []byte, separator string) (utils.KeyOrder, error) {
+	return utils.JSONKeyOrder(data, separator)
+}
+
+// jsoncHandler reads and writes JSON documents with comments
+type jsoncHandler struct{}
+
+func (jsoncHandler) Detect(path string) bool { return utils.IsJSONCFile(path) }
+
+func (jsoncHandler) Read(data []byte, options FormatOptions) (Document, error) {
+	var doc Document
+	var err error
+	doc.Data, doc.Comments, err = utils.ParseJSONC(data, options.Separator)
+	doc.TrailingCommas = utils.HasTrailingCommas(data)
+	return doc, err
+}
+
+func (jsoncHandler) Write(doc Document, options FormatOptions) ([]byte, error) {
+	if doc.TrailingCommas {
+		return utils.MarshalJSONCTrailingCommas(doc.Data, doc.Comments, options.Separator, options.Order)
+	}
+	return utils.MarshalJSONC(doc.Data, doc.Comments, options.Separator, options.Order)
+}
+
+func (jsoncHandler) KeyOrder(data []byte, separator string) (utils.KeyOrder, error) {
+	return utils.JSONKeyOrder(data, separator)
+}
+
+// yamlHandler reads and writes YAML documents with comments, anchors and keys
+// that are not strings
+type yamlHandler struct{}
+
+func (yamlHandler) Detect(path string) bool { return utils.IsYAMLFile(path) }
+
+func (yamlHandler) Read(data []byte, options FormatOptions) (Document, error) {
+	var doc Document
+	var err error
+	doc.Data, doc.Comments, doc.Anchors, doc.KeyTypes, err = utils.ParseYAML(data, options.Separator)
+	return doc, err
+}
+
+func (yamlHandler) Write(doc Document, options FormatOptions) ([]byte, error) {
+	return utils.MarshalYAML(doc.Data, doc.Comments, doc.Anchors, doc.KeyTypes, options.Separator, options.Order)
+}
+
+func (yamlHandler) KeyOrder(data []byte, separator string) (utils.KeyOrder, error) {
+	return utils.YAMLKeyOrder(data, separator)
+}
+
+// envHandler reads and writes dotenv files
+type envHandler struct{}
+
+func (envHandler) Detect(path string) bool { return utils.IsEnvFile(path) }
+
+func (envHandler) Read(data []byte, options FormatOptions) (Document, error) {
+	flat, err := utils.ParseEnv(data, options.Separator, options.Env)
+	return Document{Data: flat}, err
+}
+
+func (envHandler) Write(doc Document, options FormatOptions) ([]byte, error) {
+	return utils.MarshalEnv(doc.Data, options.Separator, options.Env)
+}
+
+func (envHandler) Flat() bool { return true }
+
+// propertiesHandler reads and writes Java properties files
+type propertiesHandler struct{}
+
+func (propertiesHandler) Detect(path string) bool { return utils.IsPropertiesFile(path) }
+
+func (propertiesHandler) Read(data []byte, options FormatOptions) (Document, error) {
+	flat, err := utils.ParseProperties(data)
+	return Document{Data: flat}, err
+}
+
+func (propertiesHandler) Write(doc Document, options FormatOptions) ([]byte, error) {
+	return utils.MarshalProperties(doc.Data, options.Properties)
+}
+
+func (propertiesHandler) Flat() bool { return true }
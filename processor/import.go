@@ -0,0 +1,69 @@
+package processor
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/haiyon/fitobj/fitter"
+	"github.com/haiyon/fitobj/utils"
+)
+
+// ImportTable reads key/value rows from a CSV or TSV file, as written by a csv or
+// tsv export, and unflattens them into documents in outputDir: one per value of
+// the file column, or a single <name>.json named after the input without one.
+// Values are strings unless options.UnflattenOpts.CoerceTypes is set. Bundles
+// are split by SplitBundle.
+func ImportTable(inputPath, outputDir, format string, options Options) error {
+	if format == ExportBundle {
+		return SplitBundle(inputPath, outputDir, options)
+	}
+	if format != ExportCSV && format != ExportTSV {
+		return fmt.Errorf("unknown import format '%s' (expected csv, tsv or bundle)", format)
+	}
+	if err := options.Validate(); err != nil {
+		return err
+	}
+
+	rows, err := utils.ReadKeyValueFile(inputPath, delimiter(format))
+	if err != nil {
+		return fmt.Errorf("failed to read input file %s: %v", inputPath, err)
+	}
+
+	defaultFile := strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath)) + ".json"
+	docs := make(map[string]map[string]any)
+	for _, row := range rows {
+		file := row.File
+		if file == "" {
+			file = defaultFile
+		}
+		if filepath.IsAbs(file) || strings.HasPrefix(filepath.Clean(file), "..") {
+			return fmt.Errorf("file '%s' is outside the output directory", row.File)
+		}
+		if docs[file] == nil {
+			docs[file] = make(map[string]any)
+		}
+		docs[file][row.Key] = row.Value
+	}
+
+	files := make([]string, 0, len(docs))
+	for file := range docs {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	separator := options.UnflattenOpts.Separator
+	for _, file := range files {
+		data := fitter.UnflattenMapWithOptions(docs[file], options.UnflattenOpts)
+		doc := document{data: utils.FormatNumbers(data, options.NumberFormat)}
+
+		outputPath := OutputPath(filepath.Join(outputDir, file), options.Format)
+		if err := writeDocument(outputPath, resolveFormat(outputPath, options.Format), doc, separator, options); err != nil {
+			return fmt.Errorf("failed to write output file %s: %v", outputPath, err)
+		}
+		options.logger().Info(fmt.Sprintf("Imported %d keys into %s", len(docs[file]), outputPath), "keys", len(docs[file]), "output", outputPath)
+	}
+
+	return nil
+}
@@ -0,0 +1,247 @@
+package processor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/haiyon/fitobj/utils"
+)
+
+// ManifestSuffix is appended to the name of an inbox file for its manifest
+const ManifestSuffix = ".manifest.json"
+
+// inboxClaimDir holds the inbox files being processed, so that a file is picked
+// up once and is recovered after a crash
+const inboxClaimDir = ".fitobj-processing"
+
+// InboxOptions configures a directory queue served by ServeInbox
+type InboxOptions struct {
+	Inbox     string        // directory polled for dropped documents
+	Outbox    string        // results and their manifests are moved here
+	ErrorDir  string        // failed documents and their manifests are moved here
+	Unflatten bool          // unflatten documents instead of flattening them
+	Interval  time.Duration // delay between inbox scans (default 2s)
+	Settle    time.Duration // files modified more recently are left for the next scan, as still being written
+	Options   Options
+}
+
+// InboxManifest records the processing of an inbox file. It is written last, next
+// to the result in the outbox or to the original file in the error directory, so
+// its presence tells readers the other files are complete.
+type InboxManifest struct {
+	FileSummary
+	SHA256      string    `json:"sha256"`            // checksum of the input document
+	Size        int64     `json:"size"`              // size of the input document in bytes
+	Outputs     []string  `json:"outputs,omitempty"` // files moved to the outbox
+	ProcessedAt time.Time `json:"processedAt"`
+}
+
+// ServeInbox scans the inbox every interval until the context is done, processing
+// the documents dropped into it with ProcessInbox. Files left claimed by an
+// interrupted run are put back into the inbox first.
+func ServeInbox(ctx context.Context, options InboxOptions, report func(InboxManifest)) error {
+	if err := validateInbox(options); err != nil {
+		return err
+	}
+	if err := recoverInbox(options.Inbox); err != nil {
+		return err
+	}
+
+	interval := options.Interval
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := ProcessInbox(options, report); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessInbox processes the documents of the inbox once, returning how many were
+// picked up. Each document is flattened or unflattened into the outbox and removed
+// from the inbox; documents that fail are moved to the error directory instead.
+// Either way a manifest is written next to them and passed to report (when set).
+// Files other than documents are left in the inbox.
+func ProcessInbox(options InboxOptions, report func(InboxManifest)) (int, error) {
+	if err := validateInbox(options); err != nil {
+		return 0, err
+	}
+
+	files, err := listInputFiles(options.Inbox, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for _, file := range files {
+		info, err := os.Stat(filepath.Join(options.Inbox, file))
+		if err != nil || time.Since(info.ModTime()) < options.Settle {
+			continue
+		}
+
+		manifest, err := processInboxFile(file, options)
+		if err != nil {
+			return processed, err
+		}
+		processed++
+		if report != nil {
+			report(manifest)
+		}
+	}
+	return processed, nil
+}
+
+// validateInbox checks the processing options and prepares the queue directories
+func validateInbox(options InboxOptions) error {
+	if err := options.Options.Validate(); err != nil {
+		return err
+	}
+
+	dirs := map[string]string{}
+	for _, dir := range []string{options.Inbox, options.Outbox, options.ErrorDir} {
+		if dir == "" {
+			return fmt.Errorf("inbox, outbox and error directories are required")
+		}
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return fmt.Errorf("invalid directory '%s': %v", dir, err)
+		}
+		if other, ok := dirs[abs]; ok {
+			return fmt.Errorf("'%s' and '%s' are the same directory", other, dir)
+		}
+		dirs[abs] = dir
+		if err := utils.EnsureDirectoryExists(dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recoverInbox moves the files claimed by an interrupted run back into the inbox
+func recoverInbox(inbox string) error {
+	claimDir := filepath.Join(inbox, inboxClaimDir)
+	files, err := os.ReadDir(claimDir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read directory: %v", err)
+	}
+	for _, file := range files {
+		if err := os.Rename(filepath.Join(claimDir, file.Name()), filepath.Join(inbox, file.Name())); err != nil {
+			return fmt.Errorf("failed to recover '%s': %v", file.Name(), err)
+		}
+	}
+	return nil
+}
+
+// processInboxFile claims an inbox file and processes it into a staging directory
+// of the outbox, so results appear in the outbox complete. Errors are returned only
+// when the queue itself fails; processing errors are recorded in the manifest.
+func processInboxFile(file string, options InboxOptions) (InboxManifest, error) {
+	manifest := InboxManifest{FileSummary: FileSummary{File: file}}
+
+	claimDir := filepath.Join(options.Inbox, inboxClaimDir)
+	if err := utils.EnsureDirectoryExists(claimDir); err != nil {
+		return manifest, err
+	}
+	claimed := filepath.Join(claimDir, file)
+	if err := os.Rename(filepath.Join(options.Inbox, file), claimed); err != nil {
+		return manifest, fmt.Errorf("failed to claim '%s': %v", file, err)
+	}
+
+	data, err := os.ReadFile(claimed)
+	if err != nil {
+		return manifest, fmt.Errorf("failed to read '%s': %v", file, err)
+	}
+	sum := sha256.Sum256(data)
+	manifest.SHA256 = hex.EncodeToString(sum[:])
+	manifest.Size = int64(len(data))
+
+	staging, err := os.MkdirTemp(options.Outbox, ".fitobj-staging-")
+	if err != nil {
+		return manifest, fmt.Errorf("failed to create staging directory: %v", err)
+	}
+	defer os.RemoveAll(staging)
+
+	summary, err := processFile(claimed, OutputPath(filepath.Join(staging, file), options.Options.Format), options.Unflatten, options.Options)
+	manifest.KeysIn = summary.KeysIn
+	manifest.KeysOut = summary.KeysOut
+	manifest.Warnings = summary.Warnings
+	if err == nil {
+		manifest.Outputs, err = moveStaged(staging, options.Outbox)
+	}
+	manifest.ProcessedAt = time.Now().UTC()
+
+	if err != nil {
+		manifest.Error = err.Error()
+		manifest.Code = ErrorWrite
+		if fileErr, ok := err.(*FileError); ok {
+			manifest.Code = fileErr.Code
+		}
+		if err := os.Rename(claimed, filepath.Join(options.ErrorDir, file)); err != nil {
+			return manifest, fmt.Errorf("failed to move '%s' to the error directory: %v", file, err)
+		}
+		return manifest, writeJSONAtomically(filepath.Join(options.ErrorDir, file+ManifestSuffix), manifest)
+	}
+
+	manifest.Success = true
+	if err := writeJSONAtomically(filepath.Join(options.Outbox, file+ManifestSuffix), manifest); err != nil {
+		return manifest, err
+	}
+	if err := os.Remove(claimed); err != nil {
+		return manifest, fmt.Errorf("failed to remove '%s' from the inbox: %v", file, err)
+	}
+	return manifest, nil
+}
+
+// moveStaged moves the files of a staging directory into the outbox, returning their names
+func moveStaged(staging, outbox string) ([]string, error) {
+	files, err := os.ReadDir(staging)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read staging directory: %v", err)
+	}
+
+	var names []string
+	for _, file := range files {
+		if err := os.Rename(filepath.Join(staging, file.Name()), filepath.Join(outbox, file.Name())); err != nil {
+			return names, fmt.Errorf("failed to move '%s' to the outbox: %v", file.Name(), err)
+		}
+		names = append(names, file.Name())
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// writeJSONAtomically writes indented JSON through a temporary file, so readers
+// never see it partially written
+func writeJSONAtomically(path string, value any) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", filepath.Base(path), err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write %s: %v", filepath.Base(path), err)
+	}
+	return nil
+}
@@ -0,0 +1,246 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/haiyon/fitobj/utils"
+)
+
+// JobStatusFile is the name of the status file a Scheduler keeps in its log directory
+const JobStatusFile = "jobs.json"
+
+// Job is a task run on a cron schedule by a Scheduler. Run writes its output to
+// the log of the run; an error marks the run as failed.
+type Job struct {
+	Name     string
+	Schedule string // cron expression, see utils.ParseSchedule
+	Run      func(ctx context.Context, log io.Writer) error
+}
+
+// JobStatus reports the runs of a scheduled job. Log names the log file of the
+// last run, relative to the log directory.
+type JobStatus struct {
+	Name        string     `json:"name"`
+	Schedule    string     `json:"schedule"`
+	Running     bool       `json:"running"`
+	Runs        int        `json:"runs"`
+	Failures    int        `json:"failures"`
+	LastStart   *time.Time `json:"lastStart,omitempty"`
+	LastEnd     *time.Time `json:"lastEnd,omitempty"`
+	LastSuccess bool       `json:"lastSuccess"`
+	LastError   string     `json:"lastError,omitempty"`
+	Log         string     `json:"log,omitempty"`
+	NextRun     *time.Time `json:"nextRun,omitempty"`
+}
+
+// Scheduler runs jobs on their cron schedules, writing a log file per run and the
+// status of every job to JobStatusFile in the log directory
+type Scheduler struct {
+	logDir    string
+	jobs      []Job
+	schedules []*utils.Schedule
+
+	mu     sync.Mutex
+	status []JobStatus
+}
+
+// NewScheduler checks the schedules of the jobs and prepares the log directory
+func NewScheduler(jobs []Job, logDir string) (*Scheduler, error) {
+	s := &Scheduler{logDir: logDir, jobs: jobs}
+
+	names := make(map[string]bool)
+	for _, job := range jobs {
+		if job.Name == "" {
+			return nil, fmt.Errorf("scheduled jobs need a name")
+		}
+		if names[job.Name] {
+			return nil, fmt.Errorf("duplicate job name '%s'", job.Name)
+		}
+		names[job.Name] = true
+
+		schedule, err := utils.ParseSchedule(job.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("job '%s': %v", job.Name, err)
+		}
+		s.schedules = append(s.schedules, schedule)
+		s.status = append(s.status, JobStatus{Name: job.Name, Schedule: job.Schedule})
+	}
+
+	if err := utils.EnsureDirectoryExists(logDir); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Run runs every job at its scheduled times until the context is done, then waits
+// for running jobs, whose context is cancelled as well. A run still going at the
+// next scheduled time makes the job skip it. report (when set) receives the status
+// of a job after each run.
+func (s *Scheduler) Run(ctx context.Context, report func(JobStatus)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(s.jobs))
+	for i := range s.jobs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := s.loop(ctx, i, report); err != nil {
+				errs <- err
+				cancel()
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	return <-errs
+}
+
+// loop waits for the scheduled times of a job and runs it
+func (s *Scheduler) loop(ctx context.Context, i int, report func(JobStatus)) error {
+	for {
+		next := s.schedules[i].Next(time.Now())
+		if next.IsZero() {
+			return fmt.Errorf("job '%s': schedule '%s' never runs", s.jobs[i].Name, s.jobs[i].Schedule)
+		}
+		if err := s.update(i, func(status *JobStatus) { status.NextRun = &next }); err != nil {
+			return err
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+
+		status, err := s.runJob(ctx, i)
+		if err != nil {
+			return err
+		}
+		if report != nil {
+			report(status)
+		}
+	}
+}
+
+// runJob runs a job once, logging its output to a new file of the log directory
+func (s *Scheduler) runJob(ctx context.Context, i int) (JobStatus, error) {
+	job := s.jobs[i]
+	start := time.Now()
+	logName := fmt.Sprintf("%s-%s.log", job.Name, start.Format("20060102T150405"))
+
+	log, err := os.Create(filepath.Join(s.logDir, logName))
+	if err != nil {
+		return JobStatus{}, fmt.Errorf("failed to create log file: %v", err)
+	}
+	defer log.Close()
+
+	if err := s.update(i, func(status *JobStatus) {
+		status.Running = true
+		status.LastStart = &start
+		status.Log = logName
+	}); err != nil {
+		return JobStatus{}, err
+	}
+
+	fmt.Fprintf(log, "# %s (%s) started at %s\n", job.Name, job.Schedule, start.Format(time.RFC3339))
+	runErr := job.Run(ctx, log)
+	end := time.Now()
+	if runErr != nil {
+		fmt.Fprintf(log, "# failed after %s: %v\n", end.Sub(start).Round(time.Millisecond), runErr)
+	} else {
+		fmt.Fprintf(log, "# succeeded after %s\n", end.Sub(start).Round(time.Millisecond))
+	}
+
+	var result JobStatus
+	err = s.update(i, func(status *JobStatus) {
+		status.Running = false
+		status.Runs++
+		status.LastEnd = &end
+		status.LastSuccess = runErr == nil
+		status.LastError = ""
+		if runErr != nil {
+			status.Failures++
+			status.LastError = runErr.Error()
+		}
+		result = *status
+	})
+	return result, err
+}
+
+// Status returns the status of every job, in the order they were given
+func (s *Scheduler) Status() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]JobStatus(nil), s.status...)
+}
+
+// update changes the status of a job and saves the status file
+func (s *Scheduler) update(i int, change func(status *JobStatus)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	change(&s.status[i])
+	return writeJSONAtomically(filepath.Join(s.logDir, JobStatusFile), s.status)
+}
+
+// ReadJobStatus reads the status file written by a Scheduler
+func ReadJobStatus(path string) ([]JobStatus, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job status: %v", err)
+	}
+	var status []JobStatus
+	if err := json.Unmarshal(data, &status); err != nil {
+		return nil, fmt.Errorf("failed to parse job status: %v", err)
+	}
+	return status, nil
+}
+
+// InboxJob returns a job processing the inbox once per run, for scheduling the
+// queue instead of polling it. Files left claimed by an interrupted run are put
+// back first. The manifests are logged one per line, and the run fails when a
+// document failed.
+func InboxJob(name, schedule string, options InboxOptions, report func(InboxManifest)) Job {
+	return Job{
+		Name:     name,
+		Schedule: schedule,
+		Run: func(ctx context.Context, log io.Writer) error {
+			if err := validateInbox(options); err != nil {
+				return err
+			}
+			if err := recoverInbox(options.Inbox); err != nil {
+				return err
+			}
+
+			encoder := json.NewEncoder(log)
+			failed := 0
+			processed, err := ProcessInbox(options, func(manifest InboxManifest) {
+				encoder.Encode(manifest)
+				if !manifest.Success {
+					failed++
+				}
+				if report != nil {
+					report(manifest)
+				}
+			})
+			if err != nil {
+				return err
+			}
+			if failed > 0 {
+				return &FilesFailedError{Failed: failed, Total: processed}
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,183 @@
+package processor
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/haiyon/fitobj/utils"
+)
+
+// JournalFile is the journal a directory run keeps in its output directory,
+// listing the files completed so far. It is removed when every file succeeded, so
+// it only remains after an interrupted or partly failed run, which Options.Resume
+// continues.
+const JournalFile = ".fitobj-journal"
+
+// journalHeader is the first line of a journal: a run only resumes the journal of
+// a run with the same mode and output options
+type journalHeader struct {
+	Mode    string `json:"mode"`
+	Options string `json:"options"`
+}
+
+// journalEntry records a completed file with the size and modification time of
+// its input when the run started, and its key counts
+type journalEntry struct {
+	File    string    `json:"file"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	KeysIn  int       `json:"keysIn"`
+	KeysOut int       `json:"keysOut"`
+}
+
+// journal appends the files completed by a directory run
+type journal struct {
+	path string
+	file *os.File
+	err  error
+}
+
+// newJournalHeader fingerprints the options shaping the output of a run
+func newJournalHeader(unflatten bool, options Options) journalHeader {
+	mode := "flatten"
+	if unflatten {
+		mode = "unflatten"
+	}
+
+	// Concurrency and reporting options do not change outputs
+	options.Workers, options.Adaptive, options.MaxMemory = 0, false, 0
+	options.MetricsFile, options.Resume, options.Logger, options.Progress = "", false, nil, nil
+	options.FlattenOpts.BufferSize, options.UnflattenOpts.BufferSize = 0, 0
+
+	data, _ := json.Marshal(options)
+	sum := sha256.Sum256(data)
+	return journalHeader{Mode: mode, Options: hex.EncodeToString(sum[:])}
+}
+
+// inputState returns the journal entry of an input file before it is processed
+func inputState(dir, file string) journalEntry {
+	entry := journalEntry{File: file}
+	if info, err := os.Stat(filepath.Join(dir, file)); err == nil {
+		entry.Size, entry.ModTime = info.Size(), info.ModTime()
+	}
+	return entry
+}
+
+// readJournal reads the completed files of a journal written with the same
+// header. A missing journal, or one of another mode or options, has no entries.
+// A truncated last line, left by a crash, is ignored.
+func readJournal(path string, header journalHeader) (map[string]journalEntry, error) {
+	entries := make(map[string]journalEntry)
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	if !scanner.Scan() {
+		return entries, nil
+	}
+	var recorded journalHeader
+	if err := json.Unmarshal(scanner.Bytes(), &recorded); err != nil || recorded != header {
+		return entries, nil
+	}
+
+	for scanner.Scan() {
+		var entry journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries[entry.File] = entry
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal: %v", err)
+	}
+	return entries, nil
+}
+
+// openJournal starts the journal of a run with the entries carried over from
+// the run it resumes
+func openJournal(path string, header journalHeader, kept []journalEntry) (*journal, error) {
+	lines := []any{header}
+	for _, entry := range kept {
+		lines = append(lines, entry)
+	}
+
+	var data []byte
+	for _, line := range lines {
+		encoded, err := json.Marshal(line)
+		if err != nil {
+			return nil, err
+		}
+		data = append(append(data, encoded...), '\n')
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write journal: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return nil, fmt.Errorf("failed to write journal: %v", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write journal: %v", err)
+	}
+	return &journal{path: path, file: file}, nil
+}
+
+// record appends a completed file, written in a single line so that an
+// interruption loses at most that line. The first error is kept for close.
+func (j *journal) record(entry journalEntry) {
+	if j.err != nil {
+		return
+	}
+	data, err := json.Marshal(entry)
+	if err == nil {
+		_, err = j.file.Write(append(data, '\n'))
+	}
+	j.err = err
+}
+
+// close closes the journal, removing it when the run is complete
+func (j *journal) close(complete bool) error {
+	err := j.file.Close()
+	if j.err != nil {
+		return fmt.Errorf("failed to write journal: %v", j.err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write journal: %v", err)
+	}
+	if complete {
+		if err := os.Remove(j.path); err != nil {
+			return fmt.Errorf("failed to remove journal: %v", err)
+		}
+	}
+	return nil
+}
+
+// resumable reports whether a journal entry still describes the input file and
+// the output it recorded was written
+func resumable(entry journalEntry, state journalEntry, outputPath string, options Options) bool {
+	if entry.Size != state.Size || !entry.ModTime.Equal(state.ModTime) {
+		return false
+	}
+	if options.Bulk != nil {
+		outputPath = utils.BulkPath(outputPath)
+	}
+	_, err := os.Stat(outputPath)
+	return err == nil
+}
@@ -0,0 +1,41 @@
+package processor
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// Mode is the transformation Process applies to a document
+type Mode string
+
+// Transformation modes
+const (
+	ModeFlatten   Mode = "flatten"
+	ModeUnflatten Mode = "unflatten"
+)
+
+// logger returns the logger of the options, the slog default by default
+func (o Options) logger() *slog.Logger {
+	if o.Logger != nil {
+		return o.Logger
+	}
+	return slog.Default()
+}
+
+// Process reads a document from r, flattens or unflattens it and writes it to w,
+// in the forced format of the options (JSON when it is auto). Nothing is read
+// from or written to disk, nor printed: schema warnings go to Options.Logger,
+// text lines on standard error when it is unset.
+//
+//	var out bytes.Buffer
+//	err := processor.Process(strings.NewReader(`{"a":{"b":1}}`), &out, processor.ModeFlatten, processor.DefaultOptions())
+func Process(r io.Reader, w io.Writer, mode Mode, options Options) error {
+	switch mode {
+	case ModeFlatten:
+		return ProcessPipe(r, w, false, options)
+	case ModeUnflatten:
+		return ProcessPipe(r, w, true, options)
+	}
+	return fmt.Errorf("unknown mode '%s' (expected flatten or unflatten)", mode)
+}
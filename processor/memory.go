@@ -0,0 +1,69 @@
+package processor
+
+import (
+	"os"
+	"sync"
+)
+
+// decodeMemoryFactor estimates the memory a document takes while processed, from
+// its file size: decoded maps, the transformed copy and the encoded output
+const decodeMemoryFactor = 8
+
+// memoryBudget limits the estimated memory of the files processed at once. A nil
+// budget admits everything.
+type memoryBudget struct {
+	mu    sync.Mutex
+	cond  *sync.Cond
+	limit int64
+	used  int64
+}
+
+func newMemoryBudget(limit int64) *memoryBudget {
+	if limit <= 0 {
+		return nil
+	}
+	b := &memoryBudget{limit: limit}
+	b.cond = sync.NewCond(&b.mu)
+	return b
+}
+
+// fileCost estimates the memory processing a file takes
+func fileCost(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size() * decodeMemoryFactor
+}
+
+// acquire waits until cost fits in the budget and reserves it, reporting whether
+// it had to wait. A file estimated above the whole budget runs alone.
+func (b *memoryBudget) acquire(cost int64) bool {
+	if b == nil {
+		return false
+	}
+	cost = min(cost, b.limit)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	waited := false
+	for b.used+cost > b.limit {
+		waited = true
+		b.cond.Wait()
+	}
+	b.used += cost
+	return waited
+}
+
+// release returns a reservation made by acquire
+func (b *memoryBudget) release(cost int64) {
+	if b == nil {
+		return
+	}
+	cost = min(cost, b.limit)
+
+	b.mu.Lock()
+	b.used -= cost
+	b.mu.Unlock()
+	b.cond.Broadcast()
+}
@@ -0,0 +1,58 @@
+package processor
+
+import (
+	"fmt"
+
+	"github.com/haiyon/fitobj/fitter"
+	"github.com/haiyon/fitobj/utils"
+)
+
+// FileConflict is a merge conflict found when merging an input file into the
+// files before it
+type FileConflict struct {
+	File string
+	fitter.MergeConflict
+}
+
+// MergeFiles deep-merges documents in order and writes the result to outputPath in
+// the format of its extension, returning the conflicts found in each file. Nothing
+// is written when the merge fails.
+func MergeFiles(outputPath string, inputs []string, mergeOpts fitter.MergeOptions, options Options) ([]FileConflict, error) {
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("at least one input file is required")
+	}
+	if err := fitter.ValidateMergeOptions(mergeOpts); err != nil {
+		return nil, err
+	}
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	var conflicts []FileConflict
+	merged := make(map[string]any)
+	for _, input := range inputs {
+		format := resolveFormat(input, options.Format)
+		doc, err := readDocument(input, format, mergeOpts.Separator, utils.AnchorsExpand, options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read input file %s: %v", input, err)
+		}
+		if isFlatFormat(DetectFormat(input)) {
+			doc.data = fitter.UnflattenMapWithOptions(doc.data, options.UnflattenOpts)
+		}
+
+		result, fileConflicts, err := fitter.MergeMaps(merged, doc.data, mergeOpts)
+		for _, conflict := range fileConflicts {
+			conflicts = append(conflicts, FileConflict{File: input, MergeConflict: conflict})
+		}
+		if err != nil {
+			return conflicts, fmt.Errorf("merging %s: %v", input, err)
+		}
+		merged = result
+	}
+
+	doc := document{data: utils.FormatNumbers(merged, options.NumberFormat)}
+	if err := writeDocument(outputPath, resolveFormat(outputPath, options.Format), doc, mergeOpts.Separator, options); err != nil {
+		return conflicts, fmt.Errorf("failed to write output file %s: %v", outputPath, err)
+	}
+	return conflicts, nil
+}
@@ -0,0 +1,210 @@
+package processor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/haiyon/fitobj/fitter"
+	"github.com/haiyon/fitobj/utils"
+)
+
+// ThreeWayMerge is the result of a three-way merge of documents
+type ThreeWayMerge struct {
+	Flat      map[string]any          // merged document, flattened with arrays as values
+	Conflicts []fitter.Merge3Conflict // keys changed differently on both sides, set to our value in Flat
+	comments  utils.Comments          // comments of our document
+	separator string
+}
+
+// Merge3Files merges the changes made to a base document in two versions of it,
+// key by key, as fitter.Merge3Flat. Arrays are compared and merged as whole values.
+// The documents may be in different formats.
+func Merge3Files(basePath, oursPath, theirsPath string, options Options) (*ThreeWayMerge, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	flattenOpts := options.FlattenOpts
+	flattenOpts.IncludeArrayIndices = false
+
+	merge := ThreeWayMerge{separator: flattenOpts.Separator}
+	flats := make([]map[string]any, 3)
+	for i, path := range []string{basePath, oursPath, theirsPath} {
+		doc, err := readDocument(path, resolveFormat(path, options.Format), flattenOpts.Separator, utils.AnchorsExpand, options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read input file %s: %v", path, err)
+		}
+		flats[i] = fitter.FlattenMapWithOptions(doc.data, "", flattenOpts)
+		if i == 1 {
+			merge.comments = doc.comments
+		}
+	}
+
+	merge.Flat, merge.Conflicts = fitter.Merge3Flat(flats[0], flats[1], flats[2], flattenOpts.Separator)
+	return &merge, nil
+}
+
+// Resolve sets a conflicting key to its value on a side, removing it when the side
+// does not set it. Setting a key removes the values above and below it.
+func (m *ThreeWayMerge) Resolve(conflict fitter.Merge3Conflict, side string) {
+	value := conflict.Side(side)
+	if value.Missing {
+		delete(m.Flat, conflict.Key)
+		return
+	}
+
+	for key := range m.Flat {
+		if strings.HasPrefix(key, conflict.Key+m.separator) || strings.HasPrefix(conflict.Key, key+m.separator) {
+			delete(m.Flat, key)
+		}
+	}
+	m.Flat[conflict.Key] = value.Value
+}
+
+// WriteMerge3 writes a merged document, in the format of the output path unless a
+// format is forced, keeping the comments of our document
+func WriteMerge3(path string, merge *ThreeWayMerge, options Options) error {
+	separator := options.UnflattenOpts.Separator
+	data := fitter.UnflattenMapWithOptions(merge.Flat, options.UnflattenOpts)
+
+	doc := document{data: utils.FormatNumbers(data, options.NumberFormat)}
+	if merge.comments != nil {
+		doc.comments = utils.AnchorComments(merge.comments, doc.data, separator, doc.order)
+	}
+	return writeDocument(path, resolveFormat(path, options.Format), doc, separator, options)
+}
+
+// conflictSlot marks the place of a conflicting key in a document rendered with
+// conflict markers
+type conflictSlot struct {
+	conflict fitter.Merge3Conflict
+}
+
+// WriteMerge3Markers writes a merged document as JSON in which every conflicting
+// key appears between git-style conflict markers, holding our entry then theirs.
+// A side that does not set the key leaves its section empty. Conflicts below a
+// value set by the result are written with our value, without markers.
+func WriteMerge3Markers(path string, merge *ThreeWayMerge, labels [2]string, markerSize int, options Options) error {
+	flat := make(map[string]any, len(merge.Flat)+len(merge.Conflicts))
+	for key, value := range merge.Flat {
+		flat[key] = value
+	}
+	for _, conflict := range merge.Conflicts {
+		if !hasFlatAncestor(flat, conflict.Key, merge.separator) {
+			flat[conflict.Key] = conflictSlot{conflict}
+		}
+	}
+
+	data := fitter.UnflattenMapWithOptions(flat, options.UnflattenOpts)
+	r := markerRenderer{labels: labels, markerSize: markerSize}
+	if err := r.object(utils.FormatNumbers(data, options.NumberFormat), ""); err != nil {
+		return err
+	}
+
+	if err := utils.EnsureDirectoryExists(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("failed to create parent directory: %v", err)
+	}
+	if err := os.WriteFile(path, r.buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write file: %v", err)
+	}
+	return nil
+}
+
+// hasFlatAncestor reports whether a flattened map sets a value at a path above a key
+func hasFlatAncestor(flat map[string]any, key, separator string) bool {
+	for _, ancestor := range keyAncestors(key, separator) {
+		if _, ok := flat[ancestor]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// markerRenderer renders JSON indented like utils.WriteJSONFile, writing conflict
+// slots between conflict markers
+type markerRenderer struct {
+	buf        bytes.Buffer
+	labels     [2]string
+	markerSize int
+}
+
+func (r *markerRenderer) object(m map[string]any, indent string) error {
+	if len(m) == 0 {
+		r.buf.WriteString("{}")
+		return nil
+	}
+
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	r.buf.WriteString("{\n")
+	inner := indent + "  "
+	for i, key := range keys {
+		comma := ","
+		if i == len(keys)-1 {
+			comma = ""
+		}
+
+		if slot, ok := m[key].(conflictSlot); ok {
+			if err := r.conflict(key, slot.conflict, inner, comma); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := r.entry(key, m[key], inner); err != nil {
+			return err
+		}
+		r.buf.WriteString(comma + "\n")
+	}
+	r.buf.WriteString(indent + "}")
+	return nil
+}
+
+// entry writes "key": value at an indentation, without the trailing comma
+func (r *markerRenderer) entry(key string, value any, indent string) error {
+	name, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+	r.buf.WriteString(indent)
+	r.buf.Write(name)
+	r.buf.WriteString(": ")
+
+	if m, ok := value.(map[string]any); ok {
+		return r.object(m, indent)
+	}
+	data, err := json.MarshalIndent(value, indent, "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize %s: %v", key, err)
+	}
+	r.buf.Write(data)
+	return nil
+}
+
+// conflict writes our and their entries of a key between conflict markers
+func (r *markerRenderer) conflict(key string, conflict fitter.Merge3Conflict, indent, comma string) error {
+	fmt.Fprintf(&r.buf, "%s %s\n", strings.Repeat("<", r.markerSize), r.labels[0])
+	for i, side := range []fitter.Merge3Value{conflict.Ours, conflict.Theirs} {
+		if i == 1 {
+			r.buf.WriteString(strings.Repeat("=", r.markerSize) + "\n")
+		}
+		if side.Missing {
+			continue
+		}
+		if err := r.entry(key, side.Value, indent); err != nil {
+			return err
+		}
+		r.buf.WriteString(comma + "\n")
+	}
+	fmt.Fprintf(&r.buf, "%s %s\n", strings.Repeat(">", r.markerSize), r.labels[1])
+	return nil
+}
@@ -0,0 +1,92 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/haiyon/fitobj/fitter"
+)
+
+func TestMerge3FilesClean(t *testing.T) {
+	dir := writeInputs(t, map[string]string{
+		"base.json":   `{"server":{"host":"a","port":80},"tags":["x"]}`,
+		"ours.json":   `{"server":{"host":"b","port":80},"tags":["x","y"]}`,
+		"theirs.json": `{"server":{"host":"a","port":8080},"tags":["x","y"]}`,
+	})
+
+	merge, err := Merge3Files(filepath.Join(dir, "base.json"), filepath.Join(dir, "ours.json"), filepath.Join(dir, "theirs.json"), DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(merge.Conflicts) != 0 {
+		t.Fatalf("Expected a clean merge, got conflicts %+v", merge.Conflicts)
+	}
+
+	output := filepath.Join(t.TempDir(), "merged.json")
+	if err := WriteMerge3(output, merge, DefaultOptions()); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]any{
+		"server": map[string]any{"host": "b", "port": float64(8080)},
+		"tags":   []any{"x", "y"},
+	}
+	if got := readOutput(t, output); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestMerge3FilesConflictMarkers(t *testing.T) {
+	dir := writeInputs(t, map[string]string{
+		"base.json":   `{"a":1,"b":{"c":"base"},"d":true}`,
+		"ours.json":   `{"b":{"c":"ours"},"d":true}`,
+		"theirs.json": `{"a":2,"b":{"c":"theirs"},"d":true}`,
+	})
+
+	merge, err := Merge3Files(filepath.Join(dir, "base.json"), filepath.Join(dir, "ours.json"), filepath.Join(dir, "theirs.json"), DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(merge.Conflicts) != 2 || merge.Conflicts[0].Key != "a" || merge.Conflicts[1].Key != "b.c" {
+		t.Fatalf("Expected conflicts on a and b.c, got %+v", merge.Conflicts)
+	}
+
+	output := filepath.Join(t.TempDir(), "merged.json")
+	if err := WriteMerge3Markers(output, merge, [2]string{"ours", "theirs"}, 7, DefaultOptions()); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The key deleted on our side leaves our section empty
+	expected := `{
+<<<<<<< ours
+=======
+  "a": 2,
+>>>>>>> theirs
+  "b": {
+<<<<<<< ours
+    "c": "ours"
+=======
+    "c": "theirs"
+>>>>>>> theirs
+  },
+  "d": true
+}`
+	if string(got) != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, got)
+	}
+
+	// Resolving every conflict leaves a document without markers
+	merge.Resolve(merge.Conflicts[0], fitter.SideTheirs)
+	merge.Resolve(merge.Conflicts[1], fitter.SideBase)
+	if err := WriteMerge3(output, merge, DefaultOptions()); err != nil {
+		t.Fatal(err)
+	}
+	resolved := map[string]any{"a": float64(2), "b": map[string]any{"c": "base"}, "d": true}
+	if got := readOutput(t, output); !reflect.DeepEqual(got, resolved) {
+		t.Errorf("Expected %v, got %v", resolved, got)
+	}
+}
@@ -0,0 +1,114 @@
+package processor
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// durationBuckets are the upper bounds, in seconds, of the file duration histogram
+var durationBuckets = []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10}
+
+// WriteMetrics writes the timing of a directory run in the Prometheus text format:
+// file counts, time spent per stage, a histogram of file durations and the load of
+// every worker. The metrics describe the last run, so they are all gauges except
+// the histogram.
+func WriteMetrics(w io.Writer, summary Summary, unflatten bool) error {
+	mode := "flatten"
+	if unflatten {
+		mode = "unflatten"
+	}
+
+	var decode, transform, encode float64
+	counts := make([]int, len(durationBuckets))
+	total := 0.0
+	for _, file := range summary.Files {
+		if file.Timing == nil {
+			continue
+		}
+		decode += file.Timing.Decode
+		transform += file.Timing.Transform
+		encode += file.Timing.Encode
+		total += file.Timing.Total
+		for i, bound := range durationBuckets {
+			if file.Timing.Total <= bound {
+				counts[i]++
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	gauge := func(name, help string) {
+		fmt.Fprintf(&buf, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+	}
+
+	gauge("fitobj_run_duration_seconds", "Duration of the last directory run.")
+	fmt.Fprintf(&buf, "fitobj_run_duration_seconds{mode=%q} %g\n", mode, summary.Duration)
+	gauge("fitobj_run_files", "Files of the last directory run by result.")
+	fmt.Fprintf(&buf, "fitobj_run_files{mode=%q,result=\"success\"} %d\n", mode, summary.Processed)
+	fmt.Fprintf(&buf, "fitobj_run_files{mode=%q,result=\"failure\"} %d\n", mode, summary.Failed)
+	gauge("fitobj_run_skipped_files", "Entries of the input directory of the last run that are not documents, or documents in the target form, by reason.")
+	for _, reason := range []string{SkipExtension, SkipSidecar, SkipDirectory, SkipJournal, SkipUnmatched, SkipFlat, SkipNested} {
+		count := 0
+		for _, file := range summary.Skips {
+			if file.Reason == reason {
+				count++
+			}
+		}
+		fmt.Fprintf(&buf, "fitobj_run_skipped_files{mode=%q,reason=%q} %d\n", mode, reason, count)
+	}
+	gauge("fitobj_run_throttled_files", "Files of the last run whose start waited for the memory budget.")
+	fmt.Fprintf(&buf, "fitobj_run_throttled_files{mode=%q} %d\n", mode, summary.Throttled)
+	gauge("fitobj_run_stage_seconds", "Time spent in each processing stage, summed over the files of the last run.")
+	fmt.Fprintf(&buf, "fitobj_run_stage_seconds{mode=%q,stage=\"decode\"} %g\n", mode, decode)
+	fmt.Fprintf(&buf, "fitobj_run_stage_seconds{mode=%q,stage=\"transform\"} %g\n", mode, transform)
+	fmt.Fprintf(&buf, "fitobj_run_stage_seconds{mode=%q,stage=\"encode\"} %g\n", mode, encode)
+
+	fmt.Fprintf(&buf, "# HELP fitobj_file_duration_seconds Processing time of the files of the last run.\n# TYPE fitobj_file_duration_seconds histogram\n")
+	for i, bound := range durationBuckets {
+		fmt.Fprintf(&buf, "fitobj_file_duration_seconds_bucket{mode=%q,le=\"%g\"} %d\n", mode, bound, counts[i])
+	}
+	fmt.Fprintf(&buf, "fitobj_file_duration_seconds_bucket{mode=%q,le=\"+Inf\"} %d\n", mode, len(summary.Files))
+	fmt.Fprintf(&buf, "fitobj_file_duration_seconds_sum{mode=%q} %g\n", mode, total)
+	fmt.Fprintf(&buf, "fitobj_file_duration_seconds_count{mode=%q} %d\n", mode, len(summary.Files))
+
+	gauge("fitobj_worker_files", "Files processed by each worker in the last run.")
+	for _, worker := range summary.Workers {
+		fmt.Fprintf(&buf, "fitobj_worker_files{mode=%q,worker=\"%d\"} %d\n", mode, worker.Worker, worker.Files)
+	}
+	gauge("fitobj_worker_busy_seconds", "Time each worker spent processing files in the last run.")
+	for _, worker := range summary.Workers {
+		fmt.Fprintf(&buf, "fitobj_worker_busy_seconds{mode=%q,worker=\"%d\"} %g\n", mode, worker.Worker, worker.Busy)
+	}
+	gauge("fitobj_worker_utilization_ratio", "Share of the last run each worker spent processing files.")
+	for _, worker := range summary.Workers {
+		fmt.Fprintf(&buf, "fitobj_worker_utilization_ratio{mode=%q,worker=\"%d\"} %g\n", mode, worker.Worker, worker.Utilization)
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writeMetricsFile writes the metrics of a run through a temporary file, as the
+// node_exporter textfile collector expects
+func writeMetricsFile(path string, summary Summary, unflatten bool) error {
+	var buf bytes.Buffer
+	if err := WriteMetrics(&buf, summary, unflatten); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write metrics file: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write metrics file: %v", err)
+	}
+	return nil
+}
+
+// formatSeconds renders a duration in seconds for progress lines
+func formatSeconds(seconds float64) string {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Millisecond).String()
+}
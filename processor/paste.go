@@ -0,0 +1,52 @@
+package processor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/haiyon/fitobj/fitter"
+	"github.com/haiyon/fitobj/utils"
+)
+
+// Conversion directions of ConvertText
+const (
+	ConvertAuto   = "auto"   // unflatten flat documents and flatten the others
+	ConvertFlat   = "flat"   // always flatten
+	ConvertNested = "nested" // always unflatten
+)
+
+// ConvertText parses a JSON or YAML object held in text and converts it in the
+// direction given: with ConvertAuto, documents detected as flat by fitter.IsFlat
+// are unflattened and the others flattened. It returns the converted document and
+// whether it was unflattened.
+func ConvertText(text []byte, direction string, options Options) (map[string]any, bool, error) {
+	switch direction {
+	case "", ConvertAuto, ConvertFlat, ConvertNested:
+	default:
+		return nil, false, fmt.Errorf("unknown conversion '%s' (expected auto, flat or nested)", direction)
+	}
+
+	text = bytes.TrimSpace(text)
+	if len(text) == 0 {
+		return nil, false, fmt.Errorf("no input")
+	}
+
+	var data map[string]any
+	var keyTypes utils.KeyTypes
+	if err := json.Unmarshal(text, &data); err != nil || data == nil {
+		var yamlErr error
+		data, _, _, keyTypes, yamlErr = utils.ParseYAML(text, options.FlattenOpts.Separator)
+		if yamlErr != nil || data == nil {
+			return nil, false, fmt.Errorf("input is not a JSON or YAML object")
+		}
+	}
+
+	unflatten := direction == ConvertNested
+	if direction == "" || direction == ConvertAuto {
+		unflatten = fitter.IsFlat(data, options.UnflattenOpts.Separator)
+	}
+
+	result, _, err := transformDocument(document{data: data, keyTypes: keyTypes}, unflatten, options)
+	return result.data, unflatten, err
+}
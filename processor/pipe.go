@@ -0,0 +1,73 @@
+package processor
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/haiyon/fitobj/utils"
+)
+
+// ProcessPipe reads a single document from in, flattens or unflattens it like
+// ProcessFileWithOptions and writes it to out, without touching the filesystem.
+// Empty or blank input is read as an empty document.
+// Both sides use the forced format, JSON when it is auto; YAML anchors are
+// expanded and sidecar files are neither read nor written. Schema warnings go to
+// the Logger and fail the document with Strict; without a Logger they are text
+// lines on standard error, as out may be standard output.
+func ProcessPipe(in io.Reader, out io.Writer, unflatten bool, options Options) error {
+	if err := options.Validate(); err != nil {
+		return err
+	}
+
+	separator := options.FlattenOpts.Separator
+	if unflatten {
+		separator = options.UnflattenOpts.Separator
+	}
+	format := resolveFormat("", options.Format)
+
+	input, err := io.ReadAll(in)
+	if err != nil {
+		return fmt.Errorf("failed to read input: %v", err)
+	}
+	// Empty input is an empty document, as for empty files
+	doc := document{data: make(map[string]any)}
+	if len(bytes.TrimSpace(input)) > 0 {
+		if doc, err = parseDocument(input, format, separator, options); err != nil {
+			return fmt.Errorf("failed to parse input: %v", err)
+		}
+	}
+	doc.anchors = nil
+
+	doc, issues, err := transformDocument(doc, unflatten, options)
+	if err != nil {
+		return err
+	}
+	logger := options.Logger
+	if logger == nil {
+		logger = utils.NewLogger(os.Stderr, utils.LogFormatText, slog.LevelInfo)
+	}
+	for _, issue := range issues {
+		logger.Warn(fmt.Sprintf("Schema warning: %s: %s", issue.Path, issue.Problem), "path", issue.Path, "problem", issue.Problem)
+	}
+	if options.Strict && len(issues) > 0 {
+		return fmt.Errorf("%d schema warnings in strict mode", len(issues))
+	}
+
+	if doc.comments != nil {
+		doc.comments = utils.AnchorComments(doc.comments, doc.data, separator, doc.order)
+	}
+	output, err := marshalDocument(doc, format, separator, options)
+	if err != nil {
+		return err
+	}
+	if !bytes.HasSuffix(output, []byte("\n")) {
+		output = append(output, '\n')
+	}
+	if _, err := out.Write(output); err != nil {
+		return fmt.Errorf("failed to write output: %v", err)
+	}
+	return nil
+}
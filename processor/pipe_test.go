@@ -0,0 +1,104 @@
+package processor
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/haiyon/fitobj/utils"
+)
+
+func TestProcessPipeEmptyInput(t *testing.T) {
+	for _, input := range []string{"", "  \n\t\n"} {
+		for _, unflatten := range []bool{false, true} {
+			var out bytes.Buffer
+			if err := ProcessPipe(strings.NewReader(input), &out, unflatten, DefaultOptions()); err != nil {
+				t.Fatalf("input %q, unflatten %v: unexpected error: %v", input, unflatten, err)
+			}
+			if got := strings.TrimSpace(out.String()); got != "{}" {
+				t.Errorf("input %q, unflatten %v: expected {}, got %q", input, unflatten, got)
+			}
+		}
+	}
+}
+
+func TestProcessPipe(t *testing.T) {
+	var out bytes.Buffer
+	if err := ProcessPipe(strings.NewReader(`{"a":{"b":1}}`), &out, false, DefaultOptions()); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(strings.Fields(out.String()), ""); got != `{"a.b":1}` {
+		t.Errorf("Expected the document flattened, got %s", out.String())
+	}
+}
+
+func TestProcessPipeJSONCRoundTrip(t *testing.T) {
+	source := `{
+  "server": {
+    // Listening port
+    "port": 8080, // default
+    "hosts": ["a", "b",],
+  },
+}
+`
+	options := DefaultOptions()
+	options.Format = FormatJSONC
+
+	var flat bytes.Buffer
+	if err := ProcessPipe(strings.NewReader(source), &flat, false, options); err != nil {
+		t.Fatal(err)
+	}
+	expectedFlat := `{
+  "server.hosts.0": "a",
+  "server.hosts.1": "b",
+  // Listening port
+  // default
+  "server.port": 8080,
+}
+`
+	if flat.String() != expectedFlat {
+		t.Errorf("Expected the flattened document:\n%s\ngot:\n%s", expectedFlat, flat.String())
+	}
+
+	var nested bytes.Buffer
+	if err := ProcessPipe(&flat, &nested, true, options); err != nil {
+		t.Fatal(err)
+	}
+	expectedNested := `{
+  "server": {
+    "hosts": [
+      "a",
+      "b",
+    ],
+    // Listening port
+    // default
+    "port": 8080,
+  },
+}
+`
+	if nested.String() != expectedNested {
+		t.Errorf("Expected the unflattened document:\n%s\ngot:\n%s", expectedNested, nested.String())
+	}
+}
+
+func TestProcessPipePreservesKeyOrder(t *testing.T) {
+	source := `{"zeta":{"b":1,"a":2},"alpha":[{"y":1,"x":2}]}`
+	options := DefaultOptions()
+	options.Order = utils.OrderPreserve
+
+	var flat bytes.Buffer
+	if err := ProcessPipe(strings.NewReader(source), &flat, false, options); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(strings.Fields(flat.String()), ""); got != `{"zeta.b":1,"zeta.a":2,"alpha.0.y":1,"alpha.0.x":2}` {
+		t.Errorf("Expected the flattened keys in source order, got %s", flat.String())
+	}
+
+	var nested bytes.Buffer
+	if err := ProcessPipe(&flat, &nested, true, options); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(strings.Fields(nested.String()), ""); got != source {
+		t.Errorf("Expected %s back, got %s", source, nested.String())
+	}
+}
@@ -0,0 +1,125 @@
+package processor
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProgressReporter follows a directory run as it goes: Start with the number of
+// files to process (files resumed from an interrupted run are not counted),
+// Advance as each one completes, from a single goroutine, and Finish once all
+// have. Set Options.Progress to display a progress bar or forward the progress
+// to API clients.
+type ProgressReporter interface {
+	Start(total int)
+	Advance(file FileSummary)
+	Finish()
+}
+
+// Progress is a snapshot of a directory run
+type Progress struct {
+	Done    int
+	Failed  int
+	Total   int
+	Elapsed time.Duration
+}
+
+// Rate returns the files completed per second
+func (p Progress) Rate() float64 {
+	if p.Elapsed <= 0 {
+		return 0
+	}
+	return float64(p.Done) / p.Elapsed.Seconds()
+}
+
+// ETA estimates the time left at the current rate, 0 when it is unknown
+func (p Progress) ETA() time.Duration {
+	rate := p.Rate()
+	if rate == 0 || p.Done >= p.Total {
+		return 0
+	}
+	return time.Duration(float64(p.Total-p.Done) / rate * float64(time.Second))
+}
+
+// ProgressBar is a ProgressReporter drawing a bar with the file count, rate and
+// ETA on a terminal line, redrawn at most every Interval
+type ProgressBar struct {
+	Width    int           // characters of the bar itself (default 30)
+	Interval time.Duration // minimum time between redraws (default 100ms)
+
+	w        io.Writer
+	mu       sync.Mutex
+	progress Progress
+	start    time.Time
+	drawn    time.Time
+}
+
+// NewProgressBar returns a progress bar writing to w, usually standard error
+func NewProgressBar(w io.Writer) *ProgressBar {
+	return &ProgressBar{Width: 30, Interval: 100 * time.Millisecond, w: w}
+}
+
+func (b *ProgressBar) Start(total int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.progress = Progress{Total: total}
+	b.start = time.Now()
+	b.draw()
+}
+
+func (b *ProgressBar) Advance(file FileSummary) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.progress.Done++
+	if !file.Success {
+		b.progress.Failed++
+	}
+	if time.Since(b.drawn) >= b.Interval || b.progress.Done == b.progress.Total {
+		b.draw()
+	}
+}
+
+func (b *ProgressBar) Finish() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.draw()
+	fmt.Fprintln(b.w)
+}
+
+// Progress returns a snapshot of the run
+func (b *ProgressBar) Progress() Progress {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	p := b.progress
+	p.Elapsed = time.Since(b.start)
+	return p
+}
+
+// draw redraws the bar line
+func (b *ProgressBar) draw() {
+	b.drawn = time.Now()
+	p := b.progress
+	p.Elapsed = b.drawn.Sub(b.start)
+
+	width := b.Width
+	if width <= 0 {
+		width = 30
+	}
+	filled := width
+	if p.Total > 0 {
+		filled = width * p.Done / p.Total
+	}
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", width-filled)
+
+	line := fmt.Sprintf("[%s] %d/%d files  %.1f files/s", bar, p.Done, p.Total, p.Rate())
+	if p.Failed > 0 {
+		line += fmt.Sprintf("  %d failed", p.Failed)
+	}
+	if eta := p.ETA(); eta > 0 {
+		line += "  ETA " + eta.Round(time.Second).String()
+	}
+	fmt.Fprintf(b.w, "\r%s\033[K", line)
+}
@@ -0,0 +1,170 @@
+package processor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/haiyon/fitobj/fitter"
+	"github.com/haiyon/fitobj/utils"
+)
+
+// RenderData is the dot of a rendered template: the document both nested and
+// flattened with the flatten options
+type RenderData struct {
+	Data map[string]any // nested document, as in {{ .Data.server.port }}
+	Flat map[string]any // flattened document, as in {{ index .Flat "server.port" }}
+}
+
+// RenderTemplate renders a Go text/template with a document as data. Besides the
+// built-in functions, templates can call:
+//
+//	get "a.b"      the value at a flattened path: a leaf, or the object or array below it (nil when missing)
+//	has "a.b"      whether a path is set
+//	keys "a"       the sorted flattened keys below a path ("" for all keys)
+//	default d v    v, or d when v is nil or empty
+//	json v         v encoded as JSON
+//
+// Referring to a missing map key with the dot syntax is an error.
+func RenderTemplate(dataPath, templatePath string, options Options) ([]byte, error) {
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	doc, err := readDocument(dataPath, resolveFormat(dataPath, options.Format), options.FlattenOpts.Separator, utils.AnchorsExpand, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data file %s: %v", dataPath, err)
+	}
+
+	data := RenderData{Data: doc.data, Flat: fitter.FlattenMapWithOptions(doc.data, "", options.FlattenOpts)}
+	if isFlatFormat(resolveFormat(dataPath, options.Format)) {
+		data.Data = fitter.UnflattenMapWithOptions(doc.data, options.UnflattenOpts)
+	}
+
+	text, err := os.ReadFile(templatePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %v", err)
+	}
+
+	funcs := renderFuncs(data, options.FlattenOpts)
+	tmpl, err := template.New(filepath.Base(templatePath)).Funcs(funcs).Option("missingkey=error").Parse(string(text))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render template: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// renderFuncs returns the path lookup and formatting functions of templates
+func renderFuncs(data RenderData, options fitter.FlattenOptions) template.FuncMap {
+	lookup := func(path string) (any, bool) {
+		if value, ok := data.Flat[path]; ok {
+			return value, true
+		}
+		return lookupPath(data.Data, path, options)
+	}
+
+	return template.FuncMap{
+		"get": func(path string) any {
+			value, _ := lookup(path)
+			return value
+		},
+		"has": func(path string) bool {
+			_, ok := lookup(path)
+			return ok
+		},
+		"keys": func(path string) []string {
+			keys := []string{}
+			for key := range data.Flat {
+				if path == "" || key == path || strings.HasPrefix(key, path+options.Separator) ||
+					strings.HasPrefix(key, path+"[") {
+					keys = append(keys, key)
+				}
+			}
+			sort.Strings(keys)
+			return keys
+		},
+		"default": func(fallback, value any) any {
+			switch v := value.(type) {
+			case nil:
+				return fallback
+			case string:
+				if v == "" {
+					return fallback
+				}
+			case map[string]any:
+				if len(v) == 0 {
+					return fallback
+				}
+			case []any:
+				if len(v) == 0 {
+					return fallback
+				}
+			}
+			return value
+		},
+		"json": func(value any) (string, error) {
+			out, err := json.Marshal(value)
+			return string(out), err
+		},
+	}
+}
+
+// lookupPath walks a nested document along the segments of a flattened path, with
+// array indices as segments or in brackets
+func lookupPath(data map[string]any, path string, options fitter.FlattenOptions) (any, bool) {
+	if path == "" {
+		return data, true
+	}
+
+	var current any = data
+	for _, segment := range strings.Split(path, options.Separator) {
+		name, indices := segment, []string(nil)
+		if options.ArrayFormatting == "bracket" {
+			if i := strings.IndexByte(segment, '['); i >= 0 && strings.HasSuffix(segment, "]") {
+				name = segment[:i]
+				indices = strings.Split(segment[i+1:len(segment)-1], "][")
+			}
+		}
+
+		if name != "" || indices == nil {
+			var ok bool
+			if current, ok = step(current, name); !ok {
+				return nil, false
+			}
+		}
+		for _, index := range indices {
+			var ok bool
+			if current, ok = step(current, index); !ok {
+				return nil, false
+			}
+		}
+	}
+	return current, true
+}
+
+// step returns the value of a map key or array index
+func step(value any, segment string) (any, bool) {
+	switch v := value.(type) {
+	case map[string]any:
+		next, ok := v[segment]
+		return next, ok
+	case []any:
+		i, err := strconv.Atoi(segment)
+		if err != nil || i < 0 || i >= len(v) {
+			return nil, false
+		}
+		return v[i], true
+	}
+	return nil, false
+}
@@ -0,0 +1,111 @@
+package processor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/haiyon/fitobj/fitter"
+	"github.com/haiyon/fitobj/utils"
+)
+
+// ReplaceOptions configures a find-and-replace across documents
+type ReplaceOptions struct {
+	Options
+	Replace fitter.ReplaceOptions // text, pattern and keys to replace; the separator and array format come from FlattenOpts
+	DryRun  bool                  // report the changes without writing anything
+}
+
+// FileReplacement lists the values replaced in a document
+type FileReplacement struct {
+	File    string              `json:"file"` // path of the document, relative to the directory searched
+	Changes []fitter.DiffChange `json:"changes"`
+}
+
+// ReplaceInPath replaces text in the string values of a document, or of every
+// document below a directory, as fitter.ReplaceValues, returning the changed files
+// sorted by path. Changed documents are rewritten in place, keeping their format
+// and comments, unless an output path is given: the documents are then written
+// there (mirroring the directory tree), changed or not, and the inputs are left
+// untouched.
+func ReplaceInPath(path, outputPath string, options ReplaceOptions) ([]FileReplacement, error) {
+	replaceOpts := options.Replace
+	replaceOpts.Separator = options.FlattenOpts.Separator
+	replaceOpts.ArrayFormatting = options.FlattenOpts.ArrayFormatting
+	if err := fitter.ValidateReplaceOptions(replaceOpts); err != nil {
+		return nil, err
+	}
+	if err := ValidateFormat(options.Format); err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("input error: %v", err)
+	}
+
+	files, dir := []string{path}, ""
+	if info.IsDir() {
+		tree, err := listInputTree(path)
+		if err != nil {
+			return nil, err
+		}
+		files = files[:0]
+		for file := range tree {
+			files = append(files, file)
+		}
+		sort.Strings(files)
+		dir = path
+	}
+
+	var replacements []FileReplacement
+	for _, file := range files {
+		inputFile := filepath.Join(dir, filepath.FromSlash(file))
+		doc, err := readDocument(inputFile, resolveFormat(inputFile, options.Format), replaceOpts.Separator, options.YAMLAnchors, options.Options)
+		if err != nil {
+			return replacements, fmt.Errorf("failed to read input file %s: %v", inputFile, err)
+		}
+
+		changes, err := fitter.ReplaceValues(doc.data, replaceOpts)
+		if err != nil {
+			return replacements, err
+		}
+		if len(changes) > 0 {
+			replacements = append(replacements, FileReplacement{File: file, Changes: changes})
+		}
+		if options.DryRun || (outputPath == "" && len(changes) == 0) {
+			continue
+		}
+
+		outputFile := inputFile
+		switch {
+		case outputPath == "":
+		case info.IsDir():
+			outputFile = filepath.Join(outputPath, filepath.FromSlash(file))
+		default:
+			outputFile = outputPath
+		}
+		if len(changes) == 0 {
+			err = copyFile(inputFile, outputFile)
+		} else {
+			err = writeDocument(outputFile, resolveFormat(outputFile, options.Format), doc, replaceOpts.Separator, options.Options)
+		}
+		if err != nil {
+			return replacements, fmt.Errorf("failed to write output file %s: %v", outputFile, err)
+		}
+	}
+	return replacements, nil
+}
+
+// copyFile copies a file unchanged, creating the parent directory of the copy
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	if err := utils.EnsureDirectoryExists(filepath.Dir(dst)); err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0644)
+}
@@ -0,0 +1,198 @@
+package processor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/haiyon/fitobj/fitter"
+	"github.com/haiyon/fitobj/utils"
+)
+
+// ResolveOptions configures layered configuration resolution
+type ResolveOptions struct {
+	Options
+	Interpolate bool                        // replace ${NAME} and ${NAME:-default} in string values
+	Lookup      func(string) (string, bool) // variable lookup (default: os.LookupEnv)
+}
+
+// ProvenanceSuffix is appended to an output path for the sidecar file recording
+// the layer that provided each key
+const ProvenanceSuffix = ".provenance.json"
+
+// Resolution is the result of resolving configuration layers
+type Resolution struct {
+	Layers    []string               // layer paths, in merge order
+	Data      map[string]any         // resolved document
+	Flat      map[string]any         // resolved document, flattened
+	Sources   map[string]int         // flattened key -> index of the layer that provided it
+	Conflicts []fitter.MergeConflict // keys set to different values by several layers
+}
+
+// ResolveLayers deep-merges configuration layers in order, later layers overriding
+// earlier ones key by key. A value replaces the object an earlier layer set at the
+// same path and the reverse, and arrays are replaced whole. String values of the
+// result are then interpolated; undefined variables without a default fail.
+func ResolveLayers(paths []string, options ResolveOptions) (*Resolution, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("at least one layer is required")
+	}
+	if err := ValidateFormat(options.Format); err != nil {
+		return nil, err
+	}
+
+	flattenOpts := options.FlattenOpts
+	flattenOpts.IncludeArrayIndices = false
+	separator := flattenOpts.Separator
+
+	layers := make([]map[string]any, len(paths))
+	for i, path := range paths {
+		doc, err := readDocument(path, resolveFormat(path, options.Format), separator, utils.AnchorsExpand, options.Options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read layer %s: %v", path, err)
+		}
+		layers[i] = fitter.FlattenMapWithOptions(doc.data, "", flattenOpts)
+	}
+
+	for i := 1; i < len(layers); i++ {
+		for j := 0; j < i; j++ {
+			dropShadowedKeys(layers[j], layers[i], separator)
+		}
+	}
+
+	merged, conflicts, err := fitter.MergeFlat(fitter.MergeLast, layers...)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make(map[string]int, len(merged))
+	for i, layer := range layers {
+		for key := range layer {
+			sources[key] = i
+		}
+	}
+
+	if options.Interpolate {
+		lookup := options.Lookup
+		if lookup == nil {
+			lookup = os.LookupEnv
+		}
+		if missing := utils.InterpolateValues(merged, lookup); len(missing) > 0 {
+			return nil, fmt.Errorf("undefined variables: %s", strings.Join(missing, ", "))
+		}
+	}
+
+	data := fitter.UnflattenMapWithOptions(merged, options.UnflattenOpts)
+	return &Resolution{
+		Layers:    paths,
+		Data:      utils.FormatNumbers(data, options.NumberFormat),
+		Flat:      merged,
+		Sources:   sources,
+		Conflicts: conflicts,
+	}, nil
+}
+
+// dropShadowedKeys removes the keys of an earlier layer that a later layer replaces
+// structurally: keys below a key the later layer sets to a value, and keys whose
+// path the later layer uses for an object
+func dropShadowedKeys(earlier, later map[string]any, separator string) {
+	prefixes := make(map[string]bool)
+	for key := range later {
+		for _, ancestor := range keyAncestors(key, separator) {
+			prefixes[ancestor] = true
+		}
+	}
+
+	for key := range earlier {
+		if prefixes[key] {
+			delete(earlier, key)
+			continue
+		}
+		for _, ancestor := range keyAncestors(key, separator) {
+			if _, ok := later[ancestor]; ok {
+				delete(earlier, key)
+				break
+			}
+		}
+	}
+}
+
+// keyAncestors returns the paths of the objects containing a flattened key
+func keyAncestors(key, separator string) []string {
+	var ancestors []string
+	for i := 0; ; {
+		j := strings.Index(key[i:], separator)
+		if j < 0 {
+			return ancestors
+		}
+		i += j
+		ancestors = append(ancestors, key[:i])
+		i += len(separator)
+	}
+}
+
+// WriteResolution writes a resolved document, in the format of the output path
+// unless a format is forced
+func WriteResolution(path string, resolution *Resolution, options Options) error {
+	doc := document{data: resolution.Data}
+	return writeDocument(path, resolveFormat(path, options.Format), doc, options.UnflattenOpts.Separator, options)
+}
+
+// Provenance describes the layer that provided each flattened key of the result,
+// followed by the layers it overrode with a different value, as in
+// "env/prod.json (overrides base.json)"
+func (r *Resolution) Provenance() map[string]string {
+	overridden := make(map[string][]int, len(r.Conflicts))
+	for _, conflict := range r.Conflicts {
+		overridden[conflict.Key] = conflict.Sources[:len(conflict.Sources)-1]
+	}
+
+	provenance := make(map[string]string, len(r.Sources))
+	for key, source := range r.Sources {
+		text := r.Layers[source]
+		if sources := overridden[key]; len(sources) > 0 {
+			names := make([]string, len(sources))
+			for i, s := range sources {
+				names[i] = r.Layers[s]
+			}
+			text += " (overrides " + strings.Join(names, ", ") + ")"
+		}
+		provenance[key] = text
+	}
+	return provenance
+}
+
+// WriteResolutionWithProvenance writes a resolved document like WriteResolution and
+// records the layer that provided each key: as a comment above the key in YAML and
+// JSONC output, and in a ProvenanceSuffix sidecar file otherwise
+func WriteResolutionWithProvenance(path string, resolution *Resolution, options Options) error {
+	provenance := resolution.Provenance()
+	format := resolveFormat(path, options.Format)
+	separator := options.UnflattenOpts.Separator
+
+	if format == FormatYAML || format == FormatJSONC {
+		marker := "#"
+		if format == FormatJSONC {
+			marker = "//"
+		}
+		comments := make(utils.Comments, len(provenance))
+		for key, text := range provenance {
+			comments[key] = []string{marker + " from " + text}
+		}
+		doc := document{data: resolution.Data, comments: comments}
+		return writeDocument(path, format, doc, separator, options)
+	}
+
+	if err := WriteResolution(path, resolution, options); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(provenance, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize provenance: %v", err)
+	}
+	if err := os.WriteFile(path+ProvenanceSuffix, data, 0644); err != nil {
+		return fmt.Errorf("failed to write provenance: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package processor
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/haiyon/fitobj/utils"
+)
+
+// StreamOptions configures message stream transformation
+type StreamOptions struct {
+	Unflatten bool
+	BatchSize int       // messages written before the output is flushed (default 1)
+	Errors    io.Writer // invalid messages are reported here (optional)
+	Options   Options
+}
+
+// StreamStats counts the messages of a stream
+type StreamStats struct {
+	Messages int64
+	Failed   int64
+}
+
+// TransformStream reads newline-delimited JSON messages, flattens or unflattens each
+// one and writes it as a line to the output. The messages available at once, up
+// to a batch, are transformed in parallel by Options.Workers and written in input
+// order. The output is flushed after every batch, and whenever no further input is
+// immediately available so that a slow stream is not held back. Messages that are
+// not JSON objects are reported and skipped. Timestamps are normalized with
+// Options.Dates before the transformation.
+func TransformStream(in io.Reader, out io.Writer, options StreamOptions) (StreamStats, error) {
+	var stats StreamStats
+	if err := options.Options.Validate(); err != nil {
+		return stats, err
+	}
+
+	batchSize := options.BatchSize
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+
+	reader := bufio.NewReader(in)
+	writer := bufio.NewWriter(out)
+	pending := 0
+
+	flush := func() error {
+		if pending == 0 {
+			return nil
+		}
+		pending = 0
+		if err := writer.Flush(); err != nil {
+			return fmt.Errorf("failed to write messages: %v", err)
+		}
+		return nil
+	}
+
+	for {
+		if reader.Buffered() == 0 {
+			if err := flush(); err != nil {
+				return stats, err
+			}
+		}
+
+		// Read the messages available without waiting, up to a batch
+		var messages [][]byte
+		var err error
+		for len(messages) < batchSize && (len(messages) == 0 || reader.Buffered() > 0) {
+			var line []byte
+			line, err = reader.ReadBytes('\n')
+			if err != nil && err != io.EOF {
+				return stats, fmt.Errorf("failed to read messages: %v", err)
+			}
+			if message := bytes.TrimSpace(line); len(message) > 0 {
+				messages = append(messages, message)
+			}
+			if err == io.EOF {
+				break
+			}
+		}
+
+		writeErr := collectOrdered(len(messages), options.Options.Workers, func(i int) transformedMessage {
+			return transformMessage(messages[i], options)
+		}, func(_ int, result transformedMessage) error {
+			stats.Messages++
+			if options.Errors != nil {
+				for _, warning := range result.warnings {
+					fmt.Fprintln(options.Errors, warning)
+				}
+			}
+			if result.err != nil {
+				stats.Failed++
+				if options.Errors != nil {
+					fmt.Fprintf(options.Errors, "Skipping message %d: %v\n", stats.Messages, result.err)
+				}
+				return nil
+			}
+			if _, err := writer.Write(result.line); err != nil {
+				return fmt.Errorf("failed to write messages: %v", err)
+			}
+			pending++
+			if pending >= batchSize {
+				return flush()
+			}
+			return nil
+		})
+		if writeErr != nil {
+			return stats, writeErr
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+
+	return stats, flush()
+}
+
+// transformedMessage is a transformed message encoded as a line, with its schema
+// warnings
+type transformedMessage struct {
+	line     []byte
+	warnings []string
+	err      error
+}
+
+// transformMessage transforms a single JSON message and encodes it as a line
+func transformMessage(message []byte, options StreamOptions) transformedMessage {
+	separator := options.Options.FlattenOpts.Separator
+	if options.Unflatten {
+		separator = options.Options.UnflattenOpts.Separator
+	}
+
+	var doc document
+	if options.Options.Order == utils.OrderPreserve {
+		source, err := utils.ParseOrderedJSON(message)
+		if err != nil {
+			return transformedMessage{err: err}
+		}
+		doc = document{data: source.ToMap(), order: source.KeyOrder(separator)}
+	} else if err := json.Unmarshal(message, &doc.data); err != nil {
+		return transformedMessage{err: fmt.Errorf("failed to parse JSON: %v", err)}
+	}
+
+	if err := normalizeDocumentDates(doc.data, separator, options.Options); err != nil {
+		return transformedMessage{err: err}
+	}
+
+	processed, issues, err := transformDocument(doc, options.Unflatten, options.Options)
+	if err != nil {
+		return transformedMessage{err: err}
+	}
+	var result transformedMessage
+	for _, issue := range issues {
+		result.warnings = append(result.warnings, fmt.Sprintf("Schema warning: %s: %s", issue.Path, issue.Problem))
+	}
+
+	var output any = processed.data
+	if options.Options.Order == utils.OrderPreserve {
+		output = utils.OrderMap(processed.data, processed.order, separator)
+	}
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(output); err != nil {
+		return transformedMessage{err: fmt.Errorf("failed to serialize JSON: %v", err)}
+	}
+	result.line = buf.Bytes()
+	return result
+}
@@ -0,0 +1,36 @@
+package processor
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/haiyon/fitobj/fitter"
+)
+
+func TestTransformStreamDates(t *testing.T) {
+	options := StreamOptions{BatchSize: 2, Options: DefaultOptions()}
+	options.Options.Dates = &fitter.DateOptions{To: fitter.DatesEpoch}
+
+	var out bytes.Buffer
+	input := `{"event":{"at":"2024-05-01T12:30:00Z"}}` + "\n" + `{"at":"not a date"}` + "\n"
+	stats, err := TransformStream(strings.NewReader(input), &out, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Messages != 2 || stats.Failed != 0 {
+		t.Fatalf("Expected 2 messages transformed, got %+v", stats)
+	}
+	expected := `{"event.at":1714566600}` + "\n" + `{"at":"not a date"}` + "\n"
+	if out.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, out.String())
+	}
+}
+
+func TestTransformStreamInvalidOptions(t *testing.T) {
+	options := StreamOptions{Options: DefaultOptions()}
+	options.Options.Dates = &fitter.DateOptions{To: "iso"}
+	if _, err := TransformStream(strings.NewReader(`{"a":1}`+"\n"), &bytes.Buffer{}, options); err == nil {
+		t.Error("Expected invalid date options to fail the stream")
+	}
+}
@@ -0,0 +1,191 @@
+package processor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Codes of file processing errors, reported in summaries
+const (
+	ErrorRead      = "read_error"      // the input file could not be read or parsed
+	ErrorTransform = "transform_error" // the document could not be transformed, e.g. field names invalid for --target
+	ErrorWrite     = "write_error"     // the output file could not be written
+	ErrorSchema    = "schema_error"    // with Strict, the unflattened output does not match the schema
+	ErrorRun       = "run_error"       // the run could not start: invalid options or directories
+)
+
+// FileError is an error processing a file, with the code of the stage that failed
+type FileError struct {
+	Code string
+	Err  error
+}
+
+func (e *FileError) Error() string {
+	return e.Err.Error()
+}
+
+// FilesFailedError reports the files of a directory run that could not be processed
+type FilesFailedError struct {
+	Failed int
+	Total  int
+}
+
+func (e *FilesFailedError) Error() string {
+	return fmt.Sprintf("%d files failed to process", e.Failed)
+}
+
+// FileSummary reports the processing of one file. Keys count the leaf values of
+// the input and output documents.
+type FileSummary struct {
+	File      string      `json:"file"`
+	Success   bool        `json:"success"`
+	KeysIn    int         `json:"keysIn"`
+	KeysOut   int         `json:"keysOut"`
+	Warnings  []string    `json:"warnings,omitempty"` // schema warnings of unflattened output
+	Error     string      `json:"error,omitempty"`
+	Code      string      `json:"code,omitempty"`
+	Worker    int         `json:"worker,omitempty"`    // worker of a directory run that processed the file, from 1
+	Throttled bool        `json:"throttled,omitempty"` // the start of the file waited for the memory budget
+	Resumed   bool        `json:"resumed,omitempty"`   // completed by the interrupted run a resumed run continues, and skipped
+	Timing    *FileTiming `json:"timing,omitempty"`
+
+	skip string // reason a document read by a directory run was skipped, reported in Summary.Skips
+}
+
+// FileTiming splits the processing time of a file into its stages, in seconds.
+// Stages after a failure are zero.
+type FileTiming struct {
+	Total     float64 `json:"total"`
+	Decode    float64 `json:"decode"`    // reading and parsing the input
+	Transform float64 `json:"transform"` // flattening or unflattening, with schema checks
+	Encode    float64 `json:"encode"`    // encoding and writing the output
+}
+
+// WorkerSummary reports the load of a worker of a directory run. Utilization is
+// the share of the run the worker spent processing files.
+type WorkerSummary struct {
+	Worker      int     `json:"worker"`
+	Files       int     `json:"files"`
+	Busy        float64 `json:"busy"` // seconds
+	Utilization float64 `json:"utilization"`
+}
+
+// Summary reports a directory run, with files in name order. Error and Code are
+// set by callers when the run could not start.
+type Summary struct {
+	Files     []FileSummary   `json:"files"`
+	Success   bool            `json:"success"`
+	Processed int             `json:"processed"` // files processed successfully
+	Failed    int             `json:"failed"`
+	KeysIn    int             `json:"keysIn"`
+	KeysOut   int             `json:"keysOut"`
+	Duration  float64         `json:"duration"` // seconds from the first file to the last
+	Workers   []WorkerSummary `json:"workers,omitempty"`
+	Batches   int             `json:"batches,omitempty"`   // batches of files handed to workers in adaptive mode
+	Throttled int             `json:"throttled,omitempty"` // files whose start waited for the memory budget
+	Resumed   int             `json:"resumed,omitempty"`   // files skipped as completed by an interrupted run
+	Skipped   int             `json:"skipped,omitempty"`   // directory entries that are not documents, or documents in the target form
+	Unchanged int             `json:"unchanged,omitempty"` // documents in the target form, written without being transformed
+	Skips     []SkippedFile   `json:"skippedFiles,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	Code      string          `json:"code,omitempty"`
+}
+
+// Reasons directory entries are skipped, reported in summaries
+const (
+	SkipExtension = "extension" // not a file of a registered format: JSON, JSONC, YAML, dotenv, properties, ...
+	SkipSidecar   = "sidecar"   // anchors, key types or provenance recorded for another document
+	SkipDirectory = "directory" // directory runs do not descend into subdirectories
+	SkipJournal   = "journal"   // the JournalFile of a run writing to the input directory
+	SkipUnmatched = "unmatched" // a document not selected by the Match patterns of the run
+	SkipFlat      = "flat"      // a document already flat, written untransformed by a flatten run with SkipConverted
+	SkipNested    = "nested"    // a document already nested, written untransformed by an unflatten run with SkipConverted
+)
+
+// SkippedFile is a directory entry a run did not process, with the reason
+type SkippedFile struct {
+	File   string `json:"file"`
+	Reason string `json:"reason"`
+}
+
+// describeSkipReason explains a skip reason for progress lines
+func describeSkipReason(reason string) string {
+	switch reason {
+	case SkipExtension:
+		return "extension: not a JSON, JSONC, YAML, dotenv or properties file"
+	case SkipSidecar:
+		return "sidecar: metadata recorded for another document"
+	case SkipDirectory:
+		return "directory: subdirectories are not processed"
+	case SkipJournal:
+		return "journal: progress of an interrupted run"
+	case SkipUnmatched:
+		return "unmatched: not selected by the match patterns"
+	case SkipFlat:
+		return "flat: already flat, no nested objects or arrays; written unchanged"
+	case SkipNested:
+		return "nested: already nested, no key holds the separator; written unchanged"
+	}
+	return reason
+}
+
+// SkipCounts lists the skipped entries by reason, as "3 extension, 1 sidecar"
+func (s Summary) SkipCounts() string {
+	counts := make(map[string]int)
+	var reasons []string
+	for _, file := range s.Skips {
+		if counts[file.Reason] == 0 {
+			reasons = append(reasons, file.Reason)
+		}
+		counts[file.Reason]++
+	}
+	sort.Strings(reasons)
+
+	parts := make([]string, len(reasons))
+	for i, reason := range reasons {
+		parts[i] = fmt.Sprintf("%d %s", counts[reason], reason)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// countKeys counts the leaf values of a document; empty objects and arrays count
+// as one value
+func countKeys(data map[string]any) int {
+	count := 0
+	for _, value := range data {
+		count += countLeaves(value)
+	}
+	return count
+}
+
+func countLeaves(value any) int {
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) > 0 {
+			return countKeys(v)
+		}
+	case []any:
+		if len(v) > 0 {
+			count := 0
+			for _, item := range v {
+				count += countLeaves(item)
+			}
+			return count
+		}
+	}
+	return 1
+}
+
+// Utilization returns the share of the run the workers spent processing files,
+// averaged over the workers
+func (s Summary) Utilization() float64 {
+	if len(s.Workers) == 0 || s.Duration <= 0 {
+		return 0
+	}
+	busy := 0.0
+	for _, worker := range s.Workers {
+		busy += worker.Busy
+	}
+	return busy / (s.Duration * float64(len(s.Workers)))
+}
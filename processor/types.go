@@ -0,0 +1,150 @@
+package processor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/haiyon/fitobj/fitter"
+	"github.com/haiyon/fitobj/utils"
+)
+
+// TypeCheckOptions configures a check of value types across documents
+type TypeCheckOptions struct {
+	Options
+	StrictNumbers bool // tell integers from numbers with a fraction, as typed schemas such as BigQuery's do
+}
+
+// TypeDrift is a key whose values have different types across documents
+type TypeDrift struct {
+	Key   string    `json:"key"`
+	Types []TypeUse `json:"types"` // sorted by type name
+}
+
+// TypeUse lists the documents holding a key with a type
+type TypeUse struct {
+	Type  string   `json:"type"`
+	Files []string `json:"files"`
+}
+
+// CheckTypes walks every document below a directory and returns the keys whose
+// values have different types across documents, or across the elements of an
+// array, sorted by key, with the number of documents checked. Objects and arrays
+// are types too, so a key holding a string in one document and nested keys in
+// another drifts. Array elements share the key of their array followed by [], as
+// in items[].id. Null values are compatible with every type and are ignored.
+func CheckTypes(dir string, options TypeCheckOptions) ([]TypeDrift, int, error) {
+	if err := ValidateFormat(options.Format); err != nil {
+		return nil, 0, err
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, 0, fmt.Errorf("input directory error: %v", err)
+	}
+	if !info.IsDir() {
+		return nil, 0, fmt.Errorf("'%s' is not a directory", dir)
+	}
+	tree, err := listInputTree(dir)
+	if err != nil {
+		return nil, 0, err
+	}
+	files := make([]string, 0, len(tree))
+	for file := range tree {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	separator := options.FlattenOpts.Separator
+
+	// Read documents in parallel, recording the files of each key type in file order
+	type fileTypes struct {
+		types map[string]map[string]bool // key -> types
+		err   error
+	}
+	uses := make(map[string]map[string][]string) // key -> type -> files
+	err = collectOrdered(len(files), options.Workers, func(i int) fileTypes {
+		filePath := filepath.Join(dir, filepath.FromSlash(files[i]))
+		format := resolveFormat(filePath, options.Format)
+		doc, err := readDocument(filePath, format, separator, utils.AnchorsExpand, options.Options)
+		if err != nil {
+			return fileTypes{err: fmt.Errorf("failed to read input file %s: %v", filePath, err)}
+		}
+		if isFlatFormat(format) {
+			doc.data = fitter.UnflattenMapWithOptions(doc.data, options.UnflattenOpts)
+		}
+
+		types := make(map[string]map[string]bool)
+		collectTypes(doc.data, "", separator, options.StrictNumbers, types)
+		return fileTypes{types: types}
+	}, func(i int, result fileTypes) error {
+		if result.err != nil {
+			return result.err
+		}
+		filePath := filepath.Join(dir, filepath.FromSlash(files[i]))
+		for key, keyTypes := range result.types {
+			if uses[key] == nil {
+				uses[key] = make(map[string][]string)
+			}
+			for keyType := range keyTypes {
+				uses[key][keyType] = append(uses[key][keyType], filePath)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var drifts []TypeDrift
+	for key, keyUses := range uses {
+		if len(keyUses) < 2 {
+			continue
+		}
+		drift := TypeDrift{Key: key, Types: make([]TypeUse, 0, len(keyUses))}
+		for keyType, typeFiles := range keyUses {
+			drift.Types = append(drift.Types, TypeUse{Type: keyType, Files: typeFiles})
+		}
+		sort.Slice(drift.Types, func(i, j int) bool {
+			return drift.Types[i].Type < drift.Types[j].Type
+		})
+		drifts = append(drifts, drift)
+	}
+	sort.Slice(drifts, func(i, j int) bool {
+		return drifts[i].Key < drifts[j].Key
+	})
+	return drifts, len(files), nil
+}
+
+// collectTypes records the type of a value and of the values nested in it by key
+func collectTypes(value any, key, separator string, strictNumbers bool, types map[string]map[string]bool) {
+	if value == nil {
+		return
+	}
+
+	valueType := fitter.ValueType(value)
+	if strictNumbers && valueType == "number" {
+		valueType = utils.ValueColumnType(value)
+	}
+	if key != "" {
+		if types[key] == nil {
+			types[key] = make(map[string]bool)
+		}
+		types[key][valueType] = true
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		for child, childValue := range v {
+			if key != "" {
+				child = key + separator + child
+			}
+			collectTypes(childValue, child, separator, strictNumbers, types)
+		}
+	case []any:
+		for _, element := range v {
+			collectTypes(element, key+"[]", separator, strictNumbers, types)
+		}
+	}
+}
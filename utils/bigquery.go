@@ -0,0 +1,155 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// bigQueryMaxName is the maximum length of a BigQuery column name
+const bigQueryMaxName = 300
+
+// bigQueryInvalid matches characters not allowed in BigQuery column names
+var bigQueryInvalid = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// bigQueryReserved lists column name prefixes reserved by BigQuery
+var bigQueryReserved = []string{"_TABLE_", "_FILE_", "_PARTITION", "_ROW_TIMESTAMP", "__ROOT__", "_COLIDENTIFIER"}
+
+// BigQueryField is a column of a BigQuery table schema
+type BigQueryField struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Mode        string `json:"mode"`
+	Description string `json:"description,omitempty"`
+}
+
+// SanitizeBigQueryName maps a flattened key to a valid BigQuery column name:
+// invalid characters become underscores, names starting with a digit or a reserved
+// prefix get a leading underscore, and long names are truncated
+func SanitizeBigQueryName(key string) string {
+	name := bigQueryInvalid.ReplaceAllString(key, "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	for _, prefix := range bigQueryReserved {
+		if strings.HasPrefix(strings.ToUpper(name), prefix) {
+			name = "f" + name
+			break
+		}
+	}
+	if len(name) > bigQueryMaxName {
+		name = name[:bigQueryMaxName]
+	}
+	return name
+}
+
+// BigQueryNames returns sanitized column names for a table. Column names are case
+// insensitive in BigQuery, so names that collide get a numeric suffix.
+func BigQueryNames(columns []Column) []string {
+	names := make([]string, len(columns))
+	used := make(map[string]bool)
+	for i, column := range columns {
+		base := SanitizeBigQueryName(column.Name)
+		name := base
+		for n := 2; used[strings.ToLower(name)]; n++ {
+			suffix := "_" + strconv.Itoa(n)
+			if len(base)+len(suffix) > bigQueryMaxName {
+				base = base[:bigQueryMaxName-len(suffix)]
+			}
+			name = base + suffix
+		}
+		used[strings.ToLower(name)] = true
+		names[i] = name
+	}
+	return names
+}
+
+// BigQuerySchema returns the BigQuery schema of a table. Every column is nullable and
+// keeps the original flattened key as its description.
+func BigQuerySchema(table Table) []BigQueryField {
+	names := BigQueryNames(table.Columns)
+	fields := make([]BigQueryField, len(table.Columns))
+	for i, column := range table.Columns {
+		fields[i] = BigQueryField{
+			Name:        names[i],
+			Type:        bigQueryType(column.Type),
+			Mode:        "NULLABLE",
+			Description: column.Name,
+		}
+	}
+	return fields
+}
+
+// bigQueryType maps an inferred column type to a BigQuery column type
+func bigQueryType(columnType string) string {
+	switch columnType {
+	case ColumnBoolean:
+		return "BOOLEAN"
+	case ColumnInteger:
+		return "INTEGER"
+	case ColumnNumber:
+		return "FLOAT"
+	default:
+		return "STRING"
+	}
+}
+
+// WriteBigQueryFiles writes a table as newline-delimited JSON rows keyed by the
+// sanitized column names, and its schema as a JSON array as accepted by
+// `bq load --schema`. Null cells are omitted from the rows.
+func WriteBigQueryFiles(dataPath, schemaPath string, table Table) error {
+	dir := filepath.Dir(dataPath)
+	if err := EnsureDirectoryExists(dir); err != nil {
+		return fmt.Errorf("failed to create parent directory: %v", err)
+	}
+
+	fields := BigQuerySchema(table)
+
+	file, err := os.Create(dataPath)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %v", err)
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, row := range table.Rows {
+		record := make(map[string]any, len(fields))
+		for j, value := range row {
+			if value == nil {
+				continue
+			}
+			switch table.Columns[j].Type {
+			case ColumnBoolean:
+				record[fields[j].Name] = value
+			case ColumnInteger:
+				record[fields[j].Name] = CellInt(value)
+			case ColumnNumber:
+				record[fields[j].Name] = CellFloat(value)
+			default:
+				record[fields[j].Name] = CellString(value)
+			}
+		}
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("failed to serialize JSON: %v", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write file: %v", err)
+	}
+
+	schema, err := json.MarshalIndent(fields, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize schema: %v", err)
+	}
+	if err := os.WriteFile(schemaPath, append(schema, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write schema: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BulkOptions configures Elasticsearch bulk API output
+type BulkOptions struct {
+	Index  string // target index
+	IDPath string // key path of the document id (optional; ids are generated when empty)
+	Action string // bulk action: "index" (default) or "create"
+}
+
+// ValidateBulkOptions checks that bulk options are usable
+func ValidateBulkOptions(options BulkOptions) error {
+	if options.Index == "" {
+		return fmt.Errorf("bulk output requires an index")
+	}
+	switch options.Action {
+	case "", "index", "create":
+		return nil
+	}
+	return fmt.Errorf("unknown bulk action '%s' (expected index or create)", options.Action)
+}
+
+// BulkPath returns the output path of a bulk file for a JSON output path
+func BulkPath(outputPath string) string {
+	return strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + ".ndjson"
+}
+
+// WriteBulkFile writes a document as an Elasticsearch bulk action line followed by
+// the document line. The id is looked up in the document, first as a literal
+// (flattened) key and then as a nested path.
+func WriteBulkFile(filePath string, doc map[string]any, options BulkOptions, separator string) error {
+	dir := filepath.Dir(filePath)
+	if err := EnsureDirectoryExists(dir); err != nil {
+		return fmt.Errorf("failed to create parent directory: %v", err)
+	}
+
+	action := options.Action
+	if action == "" {
+		action = "index"
+	}
+
+	meta := map[string]any{"_index": options.Index}
+	if options.IDPath != "" {
+		id, ok := LookupPath(doc, options.IDPath, separator)
+		if !ok || id == nil {
+			return fmt.Errorf("document has no id at '%s'", options.IDPath)
+		}
+		if s, isString := id.(string); isString {
+			meta["_id"] = s
+		} else {
+			meta["_id"] = fmt.Sprint(id)
+		}
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	if err := encoder.Encode(map[string]any{action: meta}); err != nil {
+		return fmt.Errorf("failed to serialize bulk action: %v", err)
+	}
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to serialize JSON: %v", err)
+	}
+
+	if err := os.WriteFile(filePath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write file: %v", err)
+	}
+
+	return nil
+}
+
+// LookupPath returns the value at a key path, trying the path as a literal key
+// before walking nested maps segment by segment
+func LookupPath(data map[string]any, path, separator string) (any, bool) {
+	if value, ok := data[path]; ok {
+		return value, true
+	}
+
+	var current any = data
+	for _, segment := range strings.Split(path, separator) {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[segment]; !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+)
+
+// clipboardCommands lists the commands printing the clipboard on each platform, in
+// order of preference
+var clipboardCommands = map[string][][]string{
+	"darwin":  {{"pbpaste"}},
+	"windows": {{"powershell.exe", "-NoProfile", "-Command", "Get-Clipboard -Raw"}},
+	"linux":   {{"wl-paste", "--no-newline"}, {"xclip", "-selection", "clipboard", "-o"}, {"xsel", "--clipboard", "--output"}},
+}
+
+// ReadClipboard returns the text of the system clipboard, read with pbpaste on
+// macOS, PowerShell on Windows, and wl-paste, xclip or xsel elsewhere
+func ReadClipboard() ([]byte, error) {
+	commands, ok := clipboardCommands[runtime.GOOS]
+	if !ok {
+		commands = clipboardCommands["linux"]
+	}
+
+	for _, command := range commands {
+		if _, err := exec.LookPath(command[0]); err != nil {
+			continue
+		}
+		out, err := exec.Command(command[0], command[1:]...).Output()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read clipboard with %s: %v", command[0], err)
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("no clipboard tool found (install wl-paste, xclip or xsel, or pipe the text to stdin)")
+}
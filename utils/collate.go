@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// KeyCollator sorts keys in natural order with the text between digit runs
+// collated by the rules of a locale, so accented and non-Latin keys sort as
+// speakers of that language expect ("é" next to "e" rather than after "z"). A
+// nil KeyCollator compares like NaturalLess.
+type KeyCollator struct {
+	locale string
+
+	mu       sync.Mutex // a collator holds buffers and is not safe for concurrent use
+	collator *collate.Collator
+}
+
+// NewKeyCollator returns a collator for a BCP 47 locale such as "de", "sv" or
+// "zh-Hans"
+func NewKeyCollator(locale string) (*KeyCollator, error) {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return nil, fmt.Errorf("invalid collation locale '%s': %v", locale, err)
+	}
+	return &KeyCollator{locale: locale, collator: collate.New(tag, collate.Numeric)}, nil
+}
+
+// Locale returns the locale the keys are collated by
+func (c *KeyCollator) Locale() string {
+	if c == nil {
+		return ""
+	}
+	return c.locale
+}
+
+// Less reports whether key a sorts before b. Keys the locale considers equal
+// are ordered by NaturalLess, so the order is total.
+func (c *KeyCollator) Less(a, b string) bool {
+	if c == nil {
+		return NaturalLess(a, b)
+	}
+	c.mu.Lock()
+	cmp := c.collator.CompareString(a, b)
+	c.mu.Unlock()
+	if cmp != 0 {
+		return cmp < 0
+	}
+	return NaturalLess(a, b)
+}
+
+// Sort sorts keys in collation order
+func (c *KeyCollator) Sort(keys []string) {
+	sort.Slice(keys, func(i, j int) bool { return c.Less(keys[i], keys[j]) })
+}
@@ -0,0 +1,165 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed cron expression with minute, hour, day of month, month and
+// day of week fields, evaluated in local time
+type Schedule struct {
+	minute, hour, day, month, weekday uint64 // bit sets of the matching values
+
+	anyDay, anyWeekday bool // the day field starts with '*', so only the other one restricts days
+}
+
+// cronMacros are the shorthand schedules accepted in place of five fields
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	monthNames   = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+	weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+)
+
+// ParseSchedule parses a cron expression: five fields ("0 3 * * *" is 03:00 every
+// day) of values, ranges (1-5), steps (*/15) and lists (1,15), with month and day
+// names (jan, mon), or a macro such as @daily or @hourly. As in cron, a day
+// matches when either day field matches if both are restricted.
+func ParseSchedule(spec string) (*Schedule, error) {
+	expr := strings.TrimSpace(spec)
+	if macro, ok := cronMacros[strings.ToLower(expr)]; ok {
+		expr = macro
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule '%s': expected 5 fields (minute hour day month weekday)", spec)
+	}
+
+	var s Schedule
+	var err error
+	if s.minute, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("invalid schedule '%s': minute: %v", spec, err)
+	}
+	if s.hour, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("invalid schedule '%s': hour: %v", spec, err)
+	}
+	if s.day, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("invalid schedule '%s': day of month: %v", spec, err)
+	}
+	if s.month, err = parseCronField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, fmt.Errorf("invalid schedule '%s': month: %v", spec, err)
+	}
+	if s.weekday, err = parseCronField(fields[4], 0, 7, weekdayNames); err != nil {
+		return nil, fmt.Errorf("invalid schedule '%s': day of week: %v", spec, err)
+	}
+	if s.weekday&(1<<7) != 0 {
+		s.weekday |= 1 // 7 is Sunday as well
+	}
+	s.anyDay = strings.HasPrefix(fields[2], "*")
+	s.anyWeekday = strings.HasPrefix(fields[4], "*")
+	return &s, nil
+}
+
+// parseCronField parses a comma-separated list of values, ranges and steps into a
+// bit set. Names, when given, stand for the values from min.
+func parseCronField(field string, min, max int, names []string) (uint64, error) {
+	value := func(text string) (int, error) {
+		for i, name := range names {
+			if strings.EqualFold(text, name) {
+				return min + i, nil
+			}
+		}
+		n, err := strconv.Atoi(text)
+		if err != nil || n < min || n > max {
+			return 0, fmt.Errorf("'%s' is not a value from %d to %d", text, min, max)
+		}
+		return n, nil
+	}
+
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step in '%s'", part)
+			}
+			rangePart, step = part[:i], n
+		}
+
+		low, high := min, max
+		if rangePart != "*" {
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if low, err = value(bounds[0]); err != nil {
+				return 0, err
+			}
+			high = low
+			if len(bounds) == 2 {
+				if high, err = value(bounds[1]); err != nil {
+					return 0, err
+				}
+			} else if step > 1 {
+				high = max // 5/15 means from 5 to the end, every 15
+			}
+			if high < low {
+				return 0, fmt.Errorf("invalid range '%s'", rangePart)
+			}
+		}
+
+		for n := low; n <= high; n += step {
+			bits |= 1 << n
+		}
+	}
+	return bits, nil
+}
+
+// Next returns the first time after t matching the schedule, or the zero time
+// when none exists within five years (such as February 30th)
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// matchesDay reports whether the day of t matches the day of month and day of
+// week fields
+func (s *Schedule) matchesDay(t time.Time) bool {
+	day := s.day&(1<<uint(t.Day())) != 0
+	weekday := s.weekday&(1<<uint(t.Weekday())) != 0
+	switch {
+	case s.anyDay && s.anyWeekday:
+		return true
+	case s.anyDay:
+		return weekday
+	case s.anyWeekday:
+		return day
+	}
+	return day || weekday
+}
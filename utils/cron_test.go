@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2024, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		spec     string
+		from     time.Time
+		expected time.Time
+	}{
+		{"0 3 * * *", at(5, 1, 0, 0), at(5, 1, 3, 0)},
+		{"0 3 * * *", at(5, 1, 3, 0), at(5, 2, 3, 0)},
+		{"*/15 * * * *", at(5, 1, 0, 0), at(5, 1, 0, 15)},
+		{"1,30 * * * *", at(5, 1, 0, 10), at(5, 1, 0, 30)},
+		{"10-12 * * * *", at(5, 1, 0, 12), at(5, 1, 1, 10)},
+		{"0 8-18/4 * * *", at(5, 1, 13, 0), at(5, 1, 16, 0)},
+
+		// A start value with a step runs from that value to the end of the range
+		{"5/15 * * * *", at(5, 1, 0, 0), at(5, 1, 0, 5)},
+		{"5/15 * * * *", at(5, 1, 0, 5), at(5, 1, 0, 20)},
+		{"5/15 * * * *", at(5, 1, 0, 50), at(5, 1, 1, 5)},
+
+		// Names of months and days, in any case; 2024-05-04 is a Saturday
+		{"0 9 * * mon-fri", at(5, 4, 0, 0), at(5, 6, 9, 0)},
+		{"0 0 1 JAN *", at(5, 1, 0, 0), time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * feb,mar sun", at(1, 1, 0, 0), at(2, 4, 0, 0)},
+
+		// 7 is Sunday, as 0 is; 2024-05-05 is a Sunday
+		{"0 0 * * 7", at(5, 1, 0, 0), at(5, 5, 0, 0)},
+		{"0 0 * * 0", at(5, 1, 0, 0), at(5, 5, 0, 0)},
+		{"0 0 * * 5-7", at(5, 1, 0, 0), at(5, 3, 0, 0)},
+
+		// With both day fields restricted, either one matching is enough
+		{"0 0 13 * fri", at(5, 1, 0, 0), at(5, 3, 0, 0)},
+		{"0 0 13 * fri", at(5, 11, 0, 0), at(5, 13, 0, 0)},
+		// With one of them '*', only the other restricts days
+		{"0 0 13 * *", at(5, 1, 0, 0), at(5, 13, 0, 0)},
+		{"0 0 * * fri", at(5, 4, 0, 0), at(5, 10, 0, 0)},
+
+		{"@hourly", at(5, 1, 0, 30), at(5, 1, 1, 0)},
+		{"@weekly", at(5, 1, 0, 0), at(5, 5, 0, 0)},
+		{"0 0 29 2 *", at(5, 1, 0, 0), time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+
+		// Impossible dates never match
+		{"0 0 30 2 *", at(5, 1, 0, 0), time.Time{}},
+		{"0 0 31 apr,jun,sep,nov *", at(5, 1, 0, 0), time.Time{}},
+	}
+	for _, test := range tests {
+		schedule, err := ParseSchedule(test.spec)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.spec, err)
+		}
+		if got := schedule.Next(test.from); !got.Equal(test.expected) {
+			t.Errorf("%s after %s: expected %s, got %s", test.spec, test.from.Format(time.RFC3339),
+				test.expected.Format(time.RFC3339), got.Format(time.RFC3339))
+		}
+	}
+}
+
+func TestParseScheduleInvalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"5-1 * * * *",
+		"* * * foo *",
+		"@often",
+	} {
+		if _, err := ParseSchedule(spec); err == nil {
+			t.Errorf("Expected an error for '%s'", spec)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Key/value table column names
+const (
+	ColumnFile  = "file"
+	ColumnKey   = "key"
+	ColumnValue = "value"
+)
+
+// KeyValueRow is a flattened key and its value, with the file it belongs to
+type KeyValueRow struct {
+	File  string
+	Key   string
+	Value any
+}
+
+// WriteKeyValueFile writes flattened key/value pairs as a CSV file (or TSV with a
+// tab delimiter) with a key,value header, preceded by a file column when withFile
+// is set. Strings are written as-is and other values as JSON.
+func WriteKeyValueFile(filePath string, rows []KeyValueRow, delimiter rune, withFile bool) error {
+	if err := EnsureDirectoryExists(filepath.Dir(filePath)); err != nil {
+		return fmt.Errorf("failed to create parent directory: %v", err)
+	}
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %v", err)
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	w.Comma = delimiter
+
+	header := []string{ColumnKey, ColumnValue}
+	if withFile {
+		header = append([]string{ColumnFile}, header...)
+	}
+	if err := w.Write(header); err != nil {
+		return fmt.Errorf("failed to write header: %v", err)
+	}
+
+	for _, row := range rows {
+		value, ok := row.Value.(string)
+		if !ok {
+			data, err := json.Marshal(row.Value)
+			if err != nil {
+				return fmt.Errorf("failed to serialize %s: %v", row.Key, err)
+			}
+			value = string(data)
+		}
+
+		record := []string{row.Key, value}
+		if withFile {
+			record = append([]string{row.File}, record...)
+		}
+		if err := w.Write(record); err != nil {
+			return fmt.Errorf("failed to write row: %v", err)
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("failed to write file: %v", err)
+	}
+	return nil
+}
+
+// ReadKeyValueFile reads key/value pairs from a CSV or TSV file. The header must
+// name a key and a value column and may name a file column; other columns, such as
+// translator notes, are ignored. Values are returned as strings.
+func ReadKeyValueFile(filePath string, delimiter rune) ([]KeyValueRow, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+	defer file.Close()
+
+	r := csv.NewReader(file)
+	r.Comma = delimiter
+	r.FieldsPerRecord = -1
+	if delimiter == '\t' {
+		r.LazyQuotes = true
+	}
+
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse file: %v", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("missing header row")
+	}
+
+	columns := map[string]int{ColumnFile: -1, ColumnKey: -1, ColumnValue: -1}
+	for i, name := range records[0] {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, ok := columns[name]; ok {
+			columns[name] = i
+		}
+	}
+	if columns[ColumnKey] < 0 || columns[ColumnValue] < 0 {
+		return nil, fmt.Errorf("header must name '%s' and '%s' columns", ColumnKey, ColumnValue)
+	}
+
+	cell := func(record []string, column string) string {
+		if i := columns[column]; i >= 0 && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	rows := make([]KeyValueRow, 0, len(records)-1)
+	for line, record := range records[1:] {
+		key := cell(record, ColumnKey)
+		if key == "" {
+			if strings.Join(record, "") == "" {
+				continue
+			}
+			return nil, fmt.Errorf("row %d: empty key", line+2)
+		}
+		rows = append(rows, KeyValueRow{File: cell(record, ColumnFile), Key: key, Value: cell(record, ColumnValue)})
+	}
+	return rows, nil
+}
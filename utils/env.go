@@ -0,0 +1,227 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EnvOptions configures the mangling of flattened keys into dotenv variable names
+type EnvOptions struct {
+	Separator string // joins key segments in variable names (default: "_")
+	KeepCase  bool   // keep the case of keys instead of upper-casing names (and lower-casing them when reading)
+	Prefix    string // prepended to every variable name and stripped when reading, as in "APP_"
+}
+
+// envInvalidChars matches the characters not allowed in variable names
+var envInvalidChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// envKeyPattern matches a variable assignment, with an optional export keyword
+var envKeyPattern = regexp.MustCompile(`^(?:export\s+)?([A-Za-z_][A-Za-z0-9_.-]*)\s*=\s*(.*)$`)
+
+// IsEnvFile checks if a file is a dotenv file: .env, name.env or .env.<environment>
+func IsEnvFile(filename string) bool {
+	base := filepath.Base(filename)
+	return filepath.Ext(base) == ".env" || strings.HasPrefix(base, ".env.")
+}
+
+// EnvName mangles a flattened key into a variable name: segments split by separator
+// are joined with the env separator, characters other than letters, digits and
+// underscores become underscores, and the name is upper-cased and prefixed
+func EnvName(key, separator string, options EnvOptions) string {
+	envSeparator := options.Separator
+	if envSeparator == "" {
+		envSeparator = "_"
+	}
+
+	segments := strings.Split(key, separator)
+	for i, segment := range segments {
+		segments[i] = envInvalidChars.ReplaceAllString(segment, "_")
+	}
+	name := strings.Join(segments, envSeparator)
+	if !options.KeepCase {
+		name = strings.ToUpper(name)
+	}
+	return options.Prefix + name
+}
+
+// EnvKey turns a variable name back into a flattened key, the reverse of EnvName.
+// It reports false for names without the prefix.
+func EnvKey(name, separator string, options EnvOptions) (string, bool) {
+	envSeparator := options.Separator
+	if envSeparator == "" {
+		envSeparator = "_"
+	}
+
+	name, ok := strings.CutPrefix(name, options.Prefix)
+	if !ok || name == "" {
+		return "", false
+	}
+	if !options.KeepCase {
+		name = strings.ToLower(name)
+	}
+	return strings.ReplaceAll(name, envSeparator, separator), true
+}
+
+// ReadEnvFile reads a dotenv file into a flattened map of string values
+func ReadEnvFile(filePath, separator string, options EnvOptions) (map[string]any, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+	return ParseEnv(data, separator, options)
+}
+
+// ParseEnv parses a dotenv document into a flattened map of string values keyed by
+// EnvKey. Blank lines, # comments and variables without the prefix are skipped.
+// Values may be unquoted (trailing " #" comments are removed), single-quoted
+// (literal) or double-quoted (with \n, \t, \" and \\ escapes, spanning lines).
+func ParseEnv(data []byte, separator string, options EnvOptions) (map[string]any, error) {
+	result := make(map[string]any)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNum := 0
+
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		match := envKeyPattern.FindStringSubmatch(line)
+		if match == nil {
+			return nil, fmt.Errorf("line %d: expected NAME=value", lineNum)
+		}
+		name, raw := match[1], match[2]
+
+		var value string
+		switch {
+		case strings.HasPrefix(raw, `"`):
+			// Double-quoted values may continue on the following lines
+			for closingQuote(raw[1:]) < 0 && scanner.Scan() {
+				lineNum++
+				raw += "\n" + scanner.Text()
+			}
+			end := closingQuote(raw[1:])
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated quoted value of %s", lineNum, name)
+			}
+			unquoted, err := strconv.Unquote(`"` + strings.ReplaceAll(raw[1:end+1], "\n", `\n`) + `"`)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid quoted value of %s: %v", lineNum, name, err)
+			}
+			value = unquoted
+		case strings.HasPrefix(raw, "'"):
+			end := strings.Index(raw[1:], "'")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated quoted value of %s", lineNum, name)
+			}
+			value = raw[1 : end+1]
+		default:
+			if i := strings.Index(raw, " #"); i >= 0 {
+				raw = raw[:i]
+			}
+			value = strings.TrimSpace(raw)
+		}
+
+		if key, ok := EnvKey(name, separator, options); ok {
+			result[key] = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read dotenv: %v", err)
+	}
+
+	return result, nil
+}
+
+// closingQuote returns the index of the first unescaped double quote, or -1
+func closingQuote(s string) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		}
+	}
+	return -1
+}
+
+// MarshalEnv serializes a flattened map as a dotenv document sorted by variable
+// name. Strings are double-quoted when they hold spaces, quotes, # or control
+// characters; nil becomes an empty value; arrays and objects left by flattening
+// are written as quoted JSON.
+func MarshalEnv(flat map[string]any, separator string, options EnvOptions) ([]byte, error) {
+	lines := make([]string, 0, len(flat))
+	names := make(map[string]string, len(flat))
+
+	for key, value := range flat {
+		name := EnvName(key, separator, options)
+		if other, ok := names[name]; ok {
+			return nil, fmt.Errorf("keys '%s' and '%s' both map to variable %s", other, key, name)
+		}
+		names[name] = key
+
+		text, err := envValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize %s: %v", key, err)
+		}
+		lines = append(lines, name+"="+text)
+	}
+
+	sort.Strings(lines)
+	var buf bytes.Buffer
+	for _, line := range lines {
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// envValue formats a value for the right-hand side of an assignment
+func envValue(value any) (string, error) {
+	var text string
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		text = v
+	case map[string]any, []any:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return strconv.Quote(string(data)), nil
+	default:
+		text = fmt.Sprint(v)
+	}
+
+	if strings.ContainsAny(text, " \t\"'#\\$\n\r") {
+		return strconv.Quote(text), nil
+	}
+	return text, nil
+}
+
+// WriteEnvFile writes a flattened map as a dotenv file
+func WriteEnvFile(filePath string, flat map[string]any, separator string, options EnvOptions) error {
+	if err := EnsureDirectoryExists(filepath.Dir(filePath)); err != nil {
+		return fmt.Errorf("failed to create parent directory: %v", err)
+	}
+
+	data, err := MarshalEnv(flat, separator, options)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseEnv(t *testing.T) {
+	source := `# comment
+PLAIN=value
+export EXPORTED=yes
+export   SPACED = trimmed   # trailing comment
+HASH=a#b
+SINGLE='literal \n $HOME # kept'
+DOUBLE="tab\there \"quoted\" \\ # kept" # comment
+MULTI="first
+second"
+EMPTY=
+EMPTY_QUOTED=""
+SERVER_HOST=localhost
+OTHER.NAME=dotted
+`
+	expected := map[string]any{
+		"plain":        "value",
+		"exported":     "yes",
+		"spaced":       "trimmed",
+		"hash":         "a#b",
+		"single":       `literal \n $HOME # kept`,
+		"double":       "tab\there \"quoted\" \\ # kept",
+		"multi":        "first\nsecond",
+		"empty":        "",
+		"empty.quoted": "",
+		"server.host":  "localhost",
+		"other.name":   "dotted",
+	}
+	got, err := ParseEnv([]byte(source), ".", EnvOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	// Variables without the prefix are skipped, and the case is kept on request
+	got, err = ParseEnv([]byte("APP_Db__Host=x\nPATH=/bin\nexport APP_Port=1\n"), ".", EnvOptions{Prefix: "APP_", Separator: "__", KeepCase: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[string]any{"Db.Host": "x", "Port": "1"}; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	for _, invalid := range []string{"not an assignment\n", "A=\"open\n", "A='open\n", "1A=x\n"} {
+		if _, err := ParseEnv([]byte(invalid), ".", EnvOptions{}); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestMarshalEnvRoundTrip(t *testing.T) {
+	flat := map[string]any{
+		"server.host": "localhost",
+		"server.port": "8080",
+		"greeting":    "hello \"world\" # not a comment",
+		"lines":       "a\nb",
+		"path":        `C:\dir`,
+		"literal":     "$HOME",
+		"empty":       "",
+	}
+	data, err := MarshalEnv(flat, ".", EnvOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `EMPTY=
+GREETING="hello \"world\" # not a comment"
+LINES="a\nb"
+LITERAL="$HOME"
+PATH="C:\\dir"
+SERVER_HOST=localhost
+SERVER_PORT=8080
+`
+	if string(data) != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, data)
+	}
+
+	got, err := ParseEnv(data, ".", EnvOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, flat) {
+		t.Errorf("Expected %v back, got %v", flat, got)
+	}
+}
+
+func TestMarshalEnvKeyCollisions(t *testing.T) {
+	tests := map[string]struct {
+		flat    map[string]any
+		options EnvOptions
+	}{
+		"case":       {map[string]any{"host": "a", "HOST": "b"}, EnvOptions{}},
+		"separator":  {map[string]any{"db.host": "a", "db_host": "b"}, EnvOptions{}},
+		"characters": {map[string]any{"db-host": "a", "db.host": "b"}, EnvOptions{}},
+	}
+	for name, test := range tests {
+		_, err := MarshalEnv(test.flat, ".", test.options)
+		if err == nil || !strings.Contains(err.Error(), "both map to variable") {
+			t.Errorf("%s: expected a collision error, got %v", name, err)
+		}
+	}
+
+	// Keys differing only in case stay apart when the case is kept
+	if _, err := MarshalEnv(map[string]any{"host": "a", "HOST": "b"}, ".", EnvOptions{KeepCase: true}); err != nil {
+		t.Errorf("Expected no collision with KeepCase, got %v", err)
+	}
+}
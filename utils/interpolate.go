@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"regexp"
+	"sort"
+)
+
+// envPattern matches ${NAME} and ${NAME:-default} references, and the $${ escape
+var envPattern = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// Interpolate replaces ${NAME} and ${NAME:-default} references in a string with
+// values from lookup; $${ is written as a literal ${. Names that are undefined and
+// have no default are left in place and returned.
+func Interpolate(s string, lookup func(string) (string, bool)) (string, []string) {
+	var missing []string
+	result := envPattern.ReplaceAllStringFunc(s, func(match string) string {
+		if match == "$${" {
+			return "${"
+		}
+
+		parts := envPattern.FindStringSubmatch(match)
+		if value, ok := lookup(parts[1]); ok {
+			return value
+		}
+		if len(match) > len(parts[1])+3 { // has a :- default, possibly empty
+			return parts[2]
+		}
+		missing = append(missing, parts[1])
+		return match
+	})
+	return result, missing
+}
+
+// InterpolateValues interpolates every string value of a flattened map in place and
+// returns the sorted names of undefined variables
+func InterpolateValues(flat map[string]any, lookup func(string) (string, bool)) []string {
+	seen := make(map[string]bool)
+	for key, value := range flat {
+		s, ok := value.(string)
+		if !ok {
+			continue
+		}
+		result, missing := Interpolate(s, lookup)
+		flat[key] = result
+		for _, name := range missing {
+			seen[name] = true
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
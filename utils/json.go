@@ -28,13 +28,19 @@ func ReadJSONFile(filePath string) (map[string]any, error) {
 
 // WriteJSONFile writes a map to a JSON file with indentation
 func WriteJSONFile(filePath string, data map[string]any) error {
+	return WriteOrderedJSONFile(filePath, data, "", nil)
+}
+
+// WriteOrderedJSONFile writes a map to a JSON file with indentation and object keys
+// in the given order, keyed by paths joined with separator
+func WriteOrderedJSONFile(filePath string, data map[string]any, separator string, order *Ordering) error {
 	// Ensure directory exists
 	dir := filepath.Dir(filePath)
 	if err := EnsureDirectoryExists(dir); err != nil {
 		return fmt.Errorf("failed to create parent directory: %v", err)
 	}
 
-	jsonData, err := json.MarshalIndent(data, "", "  ")
+	jsonData, err := MarshalJSONOrdered(data, separator, order)
 	if err != nil {
 		return fmt.Errorf("failed to serialize JSON: %v", err)
 	}
@@ -0,0 +1,414 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Comments maps key paths (segments joined with a separator) to the comment lines
+// written above them. The empty path holds document comments outside the root object.
+type Comments map[string][]string
+
+// IsJSONCFile checks if a file is a JSON-with-comments file based on its extension
+func IsJSONCFile(filename string) bool {
+	return filepath.Ext(filename) == ".jsonc"
+}
+
+// jsoncComment is a comment found while stripping a JSONC document
+type jsoncComment struct {
+	text     string
+	offset   int64 // offset in the stripped document
+	trailing bool  // on the same line as preceding content
+}
+
+// ReadJSONCFile reads a JSONC file (JSON with // and /* */ comments and trailing
+// commas) and returns its data with the comments keyed by path
+func ReadJSONCFile(filePath, separator string) (map[string]any, Comments, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read file: %v", err)
+	}
+	return ParseJSONC(data, separator)
+}
+
+// ParseJSONC parses a JSONC document. Comments are attached to the key that follows
+// them, or to the preceding key when they end a line.
+func ParseJSONC(data []byte, separator string) (map[string]any, Comments, error) {
+	clean, found, _ := stripJSONC(data)
+
+	result := make(map[string]any)
+	comments := make(Comments)
+	if len(bytes.TrimSpace(clean)) == 0 {
+		for _, c := range found {
+			comments[""] = append(comments[""], c.text)
+		}
+		return result, comments, nil
+	}
+
+	if err := json.Unmarshal(clean, &result); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse JSON: %v", err)
+	}
+
+	type frame struct {
+		path      []string
+		object    bool
+		expectKey bool
+		index     int
+	}
+
+	var stack []*frame
+	next := 0
+	lastKey, lastPath := "", ""
+
+	valueDone := func() {
+		if len(stack) == 0 {
+			return
+		}
+		top := stack[len(stack)-1]
+		if top.object {
+			top.expectKey = true
+		} else {
+			top.index++
+		}
+	}
+
+	childPath := func() []string {
+		if len(stack) == 0 {
+			return nil
+		}
+		top := stack[len(stack)-1]
+		if top.object {
+			return append(append([]string{}, top.path...), lastKey)
+		}
+		return append(append([]string{}, top.path...), strconv.Itoa(top.index))
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(clean))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse JSON: %v", err)
+		}
+		end := decoder.InputOffset()
+
+		switch t := token.(type) {
+		case json.Delim:
+			switch t {
+			case '{', '[':
+				if len(stack) == 0 {
+					for ; next < len(found) && found[next].offset < end; next++ {
+						comments[""] = append(comments[""], found[next].text)
+					}
+				}
+				path := childPath()
+				stack = append(stack, &frame{path: path, object: t == '{', expectKey: t == '{'})
+			default:
+				stack = stack[:len(stack)-1]
+				valueDone()
+			}
+		case string:
+			if top := stack[len(stack)-1]; top.object && top.expectKey {
+				path := append(append([]string{}, top.path...), t)
+				key := strings.Join(path, separator)
+				for next < len(found) && found[next].offset < end {
+					target := key
+					if found[next].trailing && lastPath != "" {
+						target = lastPath
+					}
+					comments[target] = append(comments[target], found[next].text)
+					next++
+				}
+				lastKey, lastPath = t, key
+				top.expectKey = false
+				continue
+			}
+			valueDone()
+		default:
+			valueDone()
+		}
+	}
+
+	for ; next < len(found); next++ {
+		target := ""
+		if found[next].trailing {
+			target = lastPath
+		}
+		comments[target] = append(comments[target], found[next].text)
+	}
+
+	return result, comments, nil
+}
+
+// HasTrailingCommas reports whether a JSONC document has a comma after the last
+// entry of an object or array, a style MarshalJSONCTrailingCommas writes again
+func HasTrailingCommas(data []byte) bool {
+	_, _, trailingCommas := stripJSONC(data)
+	return trailingCommas
+}
+
+// stripJSONC removes comments and trailing commas, returning the plain JSON, the
+// comments with their positions in it and whether a trailing comma was removed
+func stripJSONC(data []byte) ([]byte, []jsoncComment, bool) {
+	var out bytes.Buffer
+	var comments []jsoncComment
+	lineHasContent, trailingCommas := false, false
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c == '"':
+			start := i
+			for i++; i < len(data) && data[i] != '"'; i++ {
+				if data[i] == '\\' {
+					i++
+				}
+			}
+			out.Write(data[start:min(i+1, len(data))])
+			lineHasContent = true
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			start := i
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			comments = append(comments, jsoncComment{
+				text:     strings.TrimRight(string(data[start:i]), "\r"),
+				offset:   int64(out.Len()),
+				trailing: lineHasContent,
+			})
+			i--
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			start := i
+			end := bytes.Index(data[i+2:], []byte("*/"))
+			if end < 0 {
+				i = len(data)
+			} else {
+				i += end + 3
+			}
+			comments = append(comments, jsoncComment{
+				text:     string(data[start:min(i+1, len(data))]),
+				offset:   int64(out.Len()),
+				trailing: lineHasContent,
+			})
+		case c == '}' || c == ']':
+			// Drop a trailing comma before the closing delimiter
+			trimmed := bytes.TrimRight(out.Bytes(), " \t\r\n")
+			if len(trimmed) > 0 && trimmed[len(trimmed)-1] == ',' {
+				rest := append([]byte{}, out.Bytes()[len(trimmed):]...)
+				out.Truncate(len(trimmed) - 1)
+				out.Write(rest)
+				trailingCommas = true
+			}
+			out.WriteByte(c)
+			lineHasContent = true
+		case c == '\n':
+			out.WriteByte(c)
+			lineHasContent = false
+		default:
+			out.WriteByte(c)
+			if c != ' ' && c != '\t' && c != '\r' {
+				lineHasContent = true
+			}
+		}
+	}
+
+	return out.Bytes(), comments, trailingCommas
+}
+
+// AnchorComments re-keys comments so that each one targets a key path present in
+// data, moving comments of missing paths to the first key below them: the first
+// in order when a key order is given, else the first alphabetically. Comments
+// without any matching key are moved to the end of the document.
+func AnchorComments(comments Comments, data map[string]any, separator string, order KeyOrder) Comments {
+	if len(comments) == 0 {
+		return comments
+	}
+
+	var paths []string
+	collectKeyPaths(data, "", separator, &paths)
+	sort.Strings(paths)
+
+	existing := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		existing[path] = true
+	}
+
+	anchored := make(Comments, len(comments))
+	targets := make([]string, 0, len(comments))
+	for path := range comments {
+		targets = append(targets, path)
+	}
+	sort.Strings(targets)
+
+	for _, path := range targets {
+		target := path
+		if path != "" && !existing[path] {
+			target = ""
+			prefix := path + separator
+			i := sort.SearchStrings(paths, prefix)
+			for ; i < len(paths) && strings.HasPrefix(paths[i], prefix); i++ {
+				if target == "" || order.before(paths[i], target) {
+					target = paths[i]
+				}
+			}
+		}
+		anchored[target] = append(anchored[target], comments[path]...)
+	}
+
+	return anchored
+}
+
+func collectKeyPaths(value any, prefix, separator string, paths *[]string) {
+	join := func(segment string) string {
+		if prefix == "" {
+			return segment
+		}
+		return prefix + separator + segment
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			path := join(key)
+			*paths = append(*paths, path)
+			collectKeyPaths(item, path, separator, paths)
+		}
+	case []any:
+		for i, item := range v {
+			collectKeyPaths(item, join(strconv.Itoa(i)), separator, paths)
+		}
+	}
+}
+
+// WriteJSONCFile writes a map to a JSONC file with indentation, emitting comments
+// above the keys they are attached to. Keys are sorted alphabetically when order is nil.
+func WriteJSONCFile(filePath string, data map[string]any, comments Comments, separator string, order *Ordering) error {
+	dir := filepath.Dir(filePath)
+	if err := EnsureDirectoryExists(dir); err != nil {
+		return fmt.Errorf("failed to create parent directory: %v", err)
+	}
+
+	jsoncData, err := MarshalJSONC(data, comments, separator, order)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filePath, jsoncData, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %v", err)
+	}
+
+	return nil
+}
+
+// MarshalJSONC encodes a map as indented JSON with comments above their keys
+func MarshalJSONC(data map[string]any, comments Comments, separator string, order *Ordering) ([]byte, error) {
+	return marshalJSONC(data, comments, separator, order, false)
+}
+
+// MarshalJSONCTrailingCommas encodes a map as MarshalJSONC does, with a comma
+// after the last entry of every object and array
+func MarshalJSONCTrailingCommas(data map[string]any, comments Comments, separator string, order *Ordering) ([]byte, error) {
+	return marshalJSONC(data, comments, separator, order, true)
+}
+
+func marshalJSONC(data map[string]any, comments Comments, separator string, order *Ordering, trailingCommas bool) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, comment := range comments[""] {
+		buf.WriteString(comment + "\n")
+	}
+	w := jsoncWriter{buf: &buf, comments: comments, separator: separator, order: order, trailingCommas: trailingCommas}
+	if err := w.value(data, "", ""); err != nil {
+		return nil, fmt.Errorf("failed to serialize JSON: %v", err)
+	}
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+// jsoncWriter writes the values of a JSONC document
+type jsoncWriter struct {
+	buf            *bytes.Buffer
+	comments       Comments
+	separator      string
+	order          *Ordering
+	trailingCommas bool
+}
+
+func (w jsoncWriter) value(value any, path, indent string) error {
+	buf, comments, separator, order := w.buf, w.comments, w.separator, w.order
+	join := func(segment string) string {
+		if path == "" {
+			return segment
+		}
+		return path + separator + segment
+	}
+	inner := indent + "  "
+
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		order.SortKeys(keys, path, separator)
+
+		buf.WriteString("{")
+		for i, key := range keys {
+			keyPath := join(key)
+			buf.WriteString("\n")
+			for _, comment := range comments[keyPath] {
+				buf.WriteString(inner + comment + "\n")
+			}
+			name, err := json.Marshal(key)
+			if err != nil {
+				return err
+			}
+			buf.WriteString(inner)
+			buf.Write(name)
+			buf.WriteString(": ")
+			if err := w.value(v[key], keyPath, inner); err != nil {
+				return err
+			}
+			if i < len(keys)-1 || w.trailingCommas {
+				buf.WriteString(",")
+			}
+		}
+		buf.WriteString("\n" + indent + "}")
+	case []any:
+		if len(v) == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+		buf.WriteString("[")
+		for i, item := range v {
+			buf.WriteString("\n" + inner)
+			if err := w.value(item, join(strconv.Itoa(i)), inner); err != nil {
+				return err
+			}
+			if i < len(v)-1 || w.trailingCommas {
+				buf.WriteString(",")
+			}
+		}
+		buf.WriteString("\n" + indent + "]")
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(encoded)
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+const jsoncSource = `// Settings of the app
+{
+  // Display name
+  "name": "app", // shown in the title bar
+  "server": {
+    /* Listening port */
+    "port": 8080,
+    "hosts": [
+      "a",
+      "b",
+    ],
+  },
+}
+`
+
+func TestParseJSONC(t *testing.T) {
+	data, comments, err := ParseJSONC([]byte(jsoncSource), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedData := map[string]any{
+		"name":   "app",
+		"server": map[string]any{"port": 8080.0, "hosts": []any{"a", "b"}},
+	}
+	if !reflect.DeepEqual(data, expectedData) {
+		t.Errorf("Expected %v, got %v", expectedData, data)
+	}
+	expectedComments := Comments{
+		"":            {"// Settings of the app"},
+		"name":        {"// Display name", "// shown in the title bar"},
+		"server.port": {"/* Listening port */"},
+	}
+	if !reflect.DeepEqual(comments, expectedComments) {
+		t.Errorf("Expected comments %v, got %v", expectedComments, comments)
+	}
+
+	if !HasTrailingCommas([]byte(jsoncSource)) {
+		t.Error("Expected trailing commas to be found")
+	}
+	if HasTrailingCommas([]byte(`{"a": [1, 2], "b": "x,}"} // ,}`)) {
+		t.Error("Expected commas in strings and comments to be ignored")
+	}
+}
+
+func TestMarshalJSONCRoundTrip(t *testing.T) {
+	data, comments, err := ParseJSONC([]byte(jsoncSource), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `// Settings of the app
+{
+  // Display name
+  // shown in the title bar
+  "name": "app",
+  "server": {
+    "hosts": [
+      "a",
+      "b",
+    ],
+    /* Listening port */
+    "port": 8080,
+  },
+}
+`
+	out, err := MarshalJSONCTrailingCommas(data, comments, ".", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out)
+	}
+
+	// Without trailing commas the output is plain JSON apart from the comments
+	out, err = MarshalJSONC(data, comments, ".", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if HasTrailingCommas(out) {
+		t.Errorf("Expected no trailing commas, got:\n%s", out)
+	}
+
+	again, againComments, err := ParseJSONC(out, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(again, data) || !reflect.DeepEqual(againComments, comments) {
+		t.Errorf("Expected the written document to parse back, got %v and %v", again, againComments)
+	}
+}
@@ -0,0 +1,239 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// KeyTypesSuffix is appended to an output path for the sidecar file holding the
+// types of non-string mapping keys
+const KeyTypesSuffix = ".keys.json"
+
+// Types of non-string mapping keys
+const (
+	KeyInt   = "int"
+	KeyFloat = "float"
+	KeyBool  = "bool"
+	KeyNull  = "null"
+)
+
+// KeyTypes records the type of mapping keys that were not strings, by key path
+// (segments joined with a separator). Such keys are stringified in the data ("1",
+// "true") and restored from their hints where the output format can hold them.
+type KeyTypes map[string]string
+
+// yamlKeyType returns the key type of a resolved YAML tag, or "" for string keys
+func yamlKeyType(tag string) string {
+	switch tag {
+	case "!!int":
+		return KeyInt
+	case "!!float":
+		return KeyFloat
+	case "!!bool":
+		return KeyBool
+	case "!!null":
+		return KeyNull
+	}
+	return ""
+}
+
+// yamlKeyNode returns the YAML node of a mapping key, typed by its hint when the key
+// still reads as a value of that type
+func yamlKeyNode(key, keyType string) *yaml.Node {
+	n := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+	if keyType == "" {
+		return n
+	}
+
+	resolved := (&yaml.Node{Kind: yaml.ScalarNode, Value: key}).ShortTag()
+	switch {
+	case resolved == "!!"+keyType:
+		n.Tag = resolved
+	case keyType == KeyFloat && resolved == "!!int":
+		n.Tag = "!!float" // written with an explicit tag, e.g. !!float 1
+	}
+	return n
+}
+
+// StringifyKeys converts maps with keys of any type, such as map[int]string or the
+// map[any]any produced by some YAML decoders, into map[string]any and records the
+// types of the keys that were not strings. Slices are converted to []any.
+func StringifyKeys(value any, separator string) (any, KeyTypes) {
+	types := make(KeyTypes)
+	return stringifyValue(reflect.ValueOf(value), "", separator, types), types
+}
+
+func stringifyValue(v reflect.Value, path, separator string, types KeyTypes) any {
+	join := func(segment string) string {
+		if path == "" {
+			return segment
+		}
+		return path + separator + segment
+	}
+
+	switch v.Kind() {
+	case reflect.Invalid:
+		return nil
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return stringifyValue(v.Elem(), path, separator, types)
+
+	case reflect.Map:
+		result := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key, keyType := stringifyKey(iter.Key())
+			childPath := join(key)
+			if keyType != "" {
+				types[childPath] = keyType
+			}
+			result[key] = stringifyValue(iter.Value(), childPath, separator, types)
+		}
+		return result
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Interface() // []byte is a value, not a list
+		}
+		result := make([]any, v.Len())
+		for i := range result {
+			result[i] = stringifyValue(v.Index(i), join(strconv.Itoa(i)), separator, types)
+		}
+		return result
+
+	default:
+		return v.Interface()
+	}
+}
+
+// stringifyKey returns the string form of a map key and its type hint
+func stringifyKey(k reflect.Value) (string, string) {
+	for k.Kind() == reflect.Interface {
+		if k.IsNil() {
+			return "null", KeyNull
+		}
+		k = k.Elem()
+	}
+
+	switch k.Kind() {
+	case reflect.String:
+		return k.String(), ""
+	case reflect.Bool:
+		return strconv.FormatBool(k.Bool()), KeyBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), KeyInt
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), KeyInt
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(k.Float(), 'g', -1, 64), KeyFloat
+	default:
+		return fmt.Sprint(k.Interface()), ""
+	}
+}
+
+// RestoreKeyMaps turns arrays back into maps where they were mappings keyed by
+// integers, undoing the array detection of unflattening for such keys
+func RestoreKeyMaps(data map[string]any, types KeyTypes, separator string) map[string]any {
+	if len(types) == 0 {
+		return data
+	}
+	restoreKeyMaps(data, "", separator, types)
+	return data
+}
+
+func restoreKeyMaps(value any, path, separator string, types KeyTypes) any {
+	join := func(segment string) string {
+		if path == "" {
+			return segment
+		}
+		return path + separator + segment
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			v[key] = restoreKeyMaps(item, join(key), separator, types)
+		}
+		return v
+
+	case []any:
+		// Unflattening pads arrays up to the highest index, so the gaps between
+		// integer keys are dropped again
+		intKeyed := false
+		for i := range v {
+			if types[join(strconv.Itoa(i))] == KeyInt {
+				intKeyed = true
+				break
+			}
+		}
+		if intKeyed {
+			m := make(map[string]any)
+			for i, item := range v {
+				key := strconv.Itoa(i)
+				if item != nil || types[join(key)] == KeyInt {
+					m[key] = restoreKeyMaps(item, join(key), separator, types)
+				}
+			}
+			return m
+		}
+		for i, item := range v {
+			v[i] = restoreKeyMaps(item, join(strconv.Itoa(i)), separator, types)
+		}
+		return v
+	}
+
+	return value
+}
+
+// MergeKeyTypes fills key types missing from a with those recorded in b
+func MergeKeyTypes(a, b KeyTypes) KeyTypes {
+	if len(b) == 0 {
+		return a
+	}
+	if a == nil {
+		a = make(KeyTypes, len(b))
+	}
+	for path, keyType := range b {
+		if _, ok := a[path]; !ok {
+			a[path] = keyType
+		}
+	}
+	return a
+}
+
+// ReadKeyTypesFile reads key types from a sidecar file, returning nil when it does not exist
+func ReadKeyTypesFile(filePath string) (KeyTypes, error) {
+	data, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key types: %v", err)
+	}
+
+	var types KeyTypes
+	if err := json.Unmarshal(data, &types); err != nil {
+		return nil, fmt.Errorf("failed to parse key types: %v", err)
+	}
+	return types, nil
+}
+
+// WriteKeyTypesFile writes key types to a sidecar file
+func WriteKeyTypesFile(filePath string, types KeyTypes) error {
+	data, err := json.MarshalIndent(types, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize key types: %v", err)
+	}
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write key types: %v", err)
+	}
+	return nil
+}
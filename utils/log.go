@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// Log formats
+const (
+	LogFormatText = "text" // the message of each record on a line of its own
+	LogFormatJSON = "json" // a JSON object per record, with its level, time and attributes
+)
+
+// ValidateLogFormat checks that a log format is known
+func ValidateLogFormat(format string) error {
+	switch format {
+	case LogFormatText, LogFormatJSON:
+		return nil
+	}
+	return fmt.Errorf("unknown log format '%s' (expected text or json)", format)
+}
+
+// NewLogger returns a logger writing records of level and above to w in a format
+func NewLogger(w io.Writer, format string, level slog.Leveler) *slog.Logger {
+	if format == LogFormatJSON {
+		return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level}))
+	}
+	return slog.New(NewPlainHandler(w, level))
+}
+
+// PlainHandler is a slog.Handler writing the message of each record on a line of
+// its own, without the level, time and attributes, as progress lines are read by
+// people. Attributes are only written by structured handlers.
+type PlainHandler struct {
+	w     io.Writer
+	level slog.Leveler
+	mu    *sync.Mutex
+}
+
+// NewPlainHandler returns a plain handler writing records of level and above to w
+func NewPlainHandler(w io.Writer, level slog.Leveler) *PlainHandler {
+	if level == nil {
+		level = slog.LevelInfo
+	}
+	return &PlainHandler{w: w, level: level, mu: &sync.Mutex{}}
+}
+
+func (h *PlainHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *PlainHandler) Handle(_ context.Context, record slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, strings.TrimSuffix(record.Message, "\n")+"\n")
+	return err
+}
+
+func (h *PlainHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *PlainHandler) WithGroup(string) slog.Handler { return h }
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Number notations for formatted output
+const (
+	NotationAuto       = "auto"       // encoding/json default
+	NotationDecimal    = "decimal"    // always plain decimal, e.g. 1500000.25
+	NotationScientific = "scientific" // always exponent form, e.g. 1.50000025e+06
+)
+
+// NumberFormat controls how floating point numbers are rendered on output
+type NumberFormat struct {
+	Precision     int    // digits after the decimal point; negative keeps the shortest representation
+	IntegralAsInt bool   // render integral values without fraction or exponent
+	Notation      string // auto, decimal or scientific
+}
+
+// DefaultNumberFormat returns a format that leaves numbers unchanged
+func DefaultNumberFormat() NumberFormat {
+	return NumberFormat{
+		Precision: -1,
+		Notation:  NotationAuto,
+	}
+}
+
+// ValidateNumberFormat checks that a number format is usable
+func ValidateNumberFormat(format NumberFormat) error {
+	switch format.Notation {
+	case "", NotationAuto, NotationDecimal, NotationScientific:
+		return nil
+	}
+	return fmt.Errorf("unknown number notation '%s' (expected auto, decimal or scientific)", format.Notation)
+}
+
+// IsDefault reports whether the format leaves numbers unchanged
+func (f NumberFormat) IsDefault() bool {
+	return f.Precision < 0 && !f.IntegralAsInt && (f.Notation == "" || f.Notation == NotationAuto)
+}
+
+// FormatNumbers returns a copy of data with every float rendered according to the
+// format. Formatted numbers are stored as json.Number so they are written verbatim.
+func FormatNumbers(data map[string]any, format NumberFormat) map[string]any {
+	if format.IsDefault() {
+		return data
+	}
+	return formatValue(data, format).(map[string]any)
+}
+
+func formatValue(value any, format NumberFormat) any {
+	switch v := value.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, item := range v {
+			result[key] = formatValue(item, format)
+		}
+		return result
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			result[i] = formatValue(item, format)
+		}
+		return result
+	case float64:
+		return FormatFloat(v, format)
+	default:
+		return value
+	}
+}
+
+// FormatFloat renders a single float according to the format. NaN and infinities
+// are returned unchanged since JSON cannot represent them.
+func FormatFloat(value float64, format NumberFormat) any {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return value
+	}
+
+	if format.IntegralAsInt && value == math.Trunc(value) {
+		return json.Number(strconv.FormatFloat(value, 'f', 0, 64))
+	}
+
+	switch format.Notation {
+	case NotationDecimal:
+		return json.Number(strconv.FormatFloat(value, 'f', format.Precision, 64))
+	case NotationScientific:
+		return json.Number(strconv.FormatFloat(value, 'e', format.Precision, 64))
+	default:
+		if format.Precision < 0 {
+			return value
+		}
+		scale := math.Pow(10, float64(format.Precision))
+		rounded := math.Round(value*scale) / scale
+		if math.IsInf(rounded, 0) || math.IsNaN(rounded) {
+			return value
+		}
+		return rounded
+	}
+}
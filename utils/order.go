@@ -0,0 +1,284 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Key orders of written objects
+const (
+	OrderAlpha    = "alpha"    // sorted by code point (default)
+	OrderNatural  = "natural"  // sorted with digit runs compared as numbers: item2 before item10
+	OrderPreserve = "preserve" // in the order of the source document
+)
+
+// KeyOrder maps key paths (segments joined with a separator, like Comments) to
+// their position in a source document
+type KeyOrder map[string]int
+
+// Ordering configures the order of object keys in written documents. A nil
+// Ordering sorts keys alphabetically.
+type Ordering struct {
+	Mode     string       // "alpha" (default), "natural" or "preserve"
+	Source   KeyOrder     // preserve: positions of the source document keys
+	Collator *KeyCollator // natural: collate the text between digit runs by a locale (optional)
+
+	prefixes KeyOrder // first position below each path of the source, built on demand
+}
+
+// ValidateOrder checks a key order mode
+func ValidateOrder(mode string) error {
+	switch mode {
+	case "", OrderAlpha, OrderNatural, OrderPreserve:
+		return nil
+	}
+	return fmt.Errorf("invalid key order '%s' (expected alpha, natural or preserve)", mode)
+}
+
+// SortKeys sorts the keys of the object at path. In preserve mode, keys follow
+// their position in the source; a key missing from it takes the position of the
+// first source key below it, so unflattened objects follow their flattened keys.
+// Keys not found at all (renamed, or indexed with brackets) come last, in
+// natural order. Natural order follows the Collator when it is set.
+func (o *Ordering) SortKeys(keys []string, path, separator string) {
+	if o == nil || o.Mode == "" || o.Mode == OrderAlpha {
+		sort.Strings(keys)
+		return
+	}
+	if o.Mode != OrderPreserve {
+		o.Collator.Sort(keys)
+		return
+	}
+
+	ranks := make(map[string]int, len(keys))
+	for _, key := range keys {
+		ranks[key] = o.rank(joinKeyPath(path, key, separator), separator)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ri, rj := ranks[keys[i]], ranks[keys[j]]
+		if ri != rj {
+			return ri < rj
+		}
+		return o.Collator.Less(keys[i], keys[j])
+	})
+}
+
+// rank returns the source position of a path, or of the first source key below
+// it. Unknown paths rank last.
+func (o *Ordering) rank(path, separator string) int {
+	if rank, ok := o.Source[path]; ok {
+		return rank
+	}
+
+	if o.prefixes == nil {
+		o.prefixes = make(KeyOrder)
+		for sourcePath, position := range o.Source {
+			segments := strings.Split(sourcePath, separator)
+			for i := 1; i < len(segments); i++ {
+				prefix := strings.Join(segments[:i], separator)
+				if rank, ok := o.prefixes[prefix]; !ok || position < rank {
+					o.prefixes[prefix] = position
+				}
+			}
+		}
+	}
+	if rank, ok := o.prefixes[path]; ok {
+		return rank
+	}
+	return len(o.Source)
+}
+
+// add records a path at the next position, unless it was already seen
+func (o KeyOrder) add(path string) {
+	if _, ok := o[path]; !ok {
+		o[path] = len(o)
+	}
+}
+
+// before reports whether path a has an earlier position than b, or b has none
+func (o KeyOrder) before(a, b string) bool {
+	ra, ok := o[a]
+	if !ok {
+		return false
+	}
+	rb, ok := o[b]
+	return !ok || ra < rb
+}
+
+// NaturalLess compares strings with runs of digits compared by their numeric
+// value, so "item2" sorts before "item10". Equal values are ordered by length,
+// then bytewise ("2" before "02").
+func NaturalLess(a, b string) bool {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if isDigit(a[i]) && isDigit(b[j]) {
+			si, sj := i, j
+			for i < len(a) && isDigit(a[i]) {
+				i++
+			}
+			for j < len(b) && isDigit(b[j]) {
+				j++
+			}
+			x := strings.TrimLeft(a[si:i], "0")
+			y := strings.TrimLeft(b[sj:j], "0")
+			if len(x) != len(y) {
+				return len(x) < len(y)
+			}
+			if x != y {
+				return x < y
+			}
+			continue
+		}
+		if a[i] != b[j] {
+			return a[i] < b[j]
+		}
+		i++
+		j++
+	}
+	if len(a)-i != len(b)-j {
+		return len(a)-i < len(b)-j
+	}
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// JSONKeyOrder records the position of every key path of a JSON or JSONC
+// document, in document order; array elements are keyed by their index
+func JSONKeyOrder(data []byte, separator string) (KeyOrder, error) {
+	clean, _, _ := stripJSONC(data)
+	m, err := ParseOrderedJSON(clean)
+	if err != nil {
+		return nil, err
+	}
+	return m.KeyOrder(separator), nil
+}
+
+// YAMLKeyOrder records the position of every key path of a YAML document, in
+// document order. Keys brought in by merge keys and aliases are not recorded.
+func YAMLKeyOrder(data []byte, separator string) (KeyOrder, error) {
+	order := make(KeyOrder)
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %v", err)
+	}
+
+	var walk func(node *yaml.Node, path string)
+	walk = func(node *yaml.Node, path string) {
+		switch node.Kind {
+		case yaml.DocumentNode:
+			for _, child := range node.Content {
+				walk(child, path)
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key := node.Content[i].Value
+				if key == "<<" {
+					continue
+				}
+				keyPath := joinKeyPath(path, key, separator)
+				order.add(keyPath)
+				walk(node.Content[i+1], keyPath)
+			}
+		case yaml.SequenceNode:
+			for i, child := range node.Content {
+				itemPath := joinKeyPath(path, strconv.Itoa(i), separator)
+				order.add(itemPath)
+				walk(child, itemPath)
+			}
+		}
+	}
+	walk(&root, "")
+	return order, nil
+}
+
+func joinKeyPath(path, segment, separator string) string {
+	if path == "" {
+		return segment
+	}
+	return path + separator + segment
+}
+
+// MarshalJSONOrdered encodes a map as indented JSON like json.MarshalIndent,
+// with object keys in the given order
+func MarshalJSONOrdered(data map[string]any, separator string, order *Ordering) ([]byte, error) {
+	if order == nil || order.Mode == "" || order.Mode == OrderAlpha {
+		return json.MarshalIndent(data, "", "  ")
+	}
+
+	var buf bytes.Buffer
+	if err := writeOrderedJSON(&buf, data, "", "", separator, order); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeOrderedJSON(buf *bytes.Buffer, value any, path, indent, separator string, order *Ordering) error {
+	inner := indent + "  "
+
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		order.SortKeys(keys, path, separator)
+
+		buf.WriteString("{")
+		for i, key := range keys {
+			name, err := json.Marshal(key)
+			if err != nil {
+				return err
+			}
+			buf.WriteString("\n" + inner)
+			buf.Write(name)
+			buf.WriteString(": ")
+			if err := writeOrderedJSON(buf, v[key], joinKeyPath(path, key, separator), inner, separator, order); err != nil {
+				return err
+			}
+			if i < len(keys)-1 {
+				buf.WriteString(",")
+			}
+		}
+		buf.WriteString("\n" + indent + "}")
+	case []any:
+		if len(v) == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+		buf.WriteString("[")
+		for i, item := range v {
+			buf.WriteString("\n" + inner)
+			if err := writeOrderedJSON(buf, item, joinKeyPath(path, strconv.Itoa(i), separator), inner, separator, order); err != nil {
+				return err
+			}
+			if i < len(v)-1 {
+				buf.WriteString(",")
+			}
+		}
+		buf.WriteString("\n" + indent + "]")
+	default:
+		encoded, err := json.MarshalIndent(v, indent, "  ")
+		if err != nil {
+			return err
+		}
+		buf.Write(encoded)
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// OrderedMap is a JSON object that keeps its keys in insertion order. Decoded
+// objects hold nested objects as *OrderedMap and arrays as []any.
+type OrderedMap struct {
+	keys   []string
+	values map[string]any
+}
+
+// NewOrderedMap returns an empty ordered map
+func NewOrderedMap() *OrderedMap {
+	return &OrderedMap{values: make(map[string]any)}
+}
+
+// Set sets the value of a key, adding new keys at the end
+func (m *OrderedMap) Set(key string, value any) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// Get returns the value of a key
+func (m *OrderedMap) Get(key string) (any, bool) {
+	value, ok := m.values[key]
+	return value, ok
+}
+
+// Delete removes a key
+func (m *OrderedMap) Delete(key string) {
+	if _, ok := m.values[key]; !ok {
+		return
+	}
+	delete(m.values, key)
+	for i, k := range m.keys {
+		if k == key {
+			m.keys = append(m.keys[:i], m.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// Keys returns the keys in order
+func (m *OrderedMap) Keys() []string {
+	return m.keys
+}
+
+// Len returns the number of keys
+func (m *OrderedMap) Len() int {
+	return len(m.keys)
+}
+
+// ParseOrderedJSON parses a JSON object, keeping the order of its keys
+func ParseOrderedJSON(data []byte) (*OrderedMap, error) {
+	m := NewOrderedMap()
+	if len(bytes.TrimSpace(data)) == 0 {
+		return m, nil
+	}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %v", err)
+	}
+	return m, nil
+}
+
+// UnmarshalJSON decodes a JSON object, keeping the order of its keys at every level
+func (m *OrderedMap) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != json.Delim('{') {
+		return fmt.Errorf("expected a JSON object")
+	}
+	if m.values == nil {
+		m.values = make(map[string]any)
+	}
+	return decodeOrderedObject(decoder, m)
+}
+
+// decodeOrderedObject decodes the members of an object whose opening brace was read
+func decodeOrderedObject(decoder *json.Decoder, m *OrderedMap) error {
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		value, err := decodeOrderedValue(decoder)
+		if err != nil {
+			return err
+		}
+		m.Set(key.(string), value)
+	}
+	_, err := decoder.Token()
+	return err
+}
+
+// decodeOrderedValue decodes a JSON value with objects as *OrderedMap
+func decodeOrderedValue(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		m := NewOrderedMap()
+		return m, decodeOrderedObject(decoder, m)
+	case json.Delim('['):
+		items := []any{}
+		for decoder.More() {
+			item, err := decodeOrderedValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		_, err := decoder.Token()
+		return items, err
+	}
+	return token, nil
+}
+
+// MarshalJSON encodes the map as a JSON object with its keys in order
+func (m *OrderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteString(":")
+		buf.Write(value)
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
+
+// ToMap converts the map, and the ordered maps nested in it, to plain maps
+func (m *OrderedMap) ToMap() map[string]any {
+	result := make(map[string]any, len(m.keys))
+	for key, value := range m.values {
+		result[key] = plainValue(value)
+	}
+	return result
+}
+
+func plainValue(value any) any {
+	switch v := value.(type) {
+	case *OrderedMap:
+		return v.ToMap()
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = plainValue(item)
+		}
+		return items
+	}
+	return value
+}
+
+// KeyOrder records the position of every key path of the map, in order; array
+// elements are keyed by their index
+func (m *OrderedMap) KeyOrder(separator string) KeyOrder {
+	order := make(KeyOrder)
+	var walk func(value any, path string)
+	walk = func(value any, path string) {
+		switch v := value.(type) {
+		case *OrderedMap:
+			for _, key := range v.keys {
+				keyPath := joinKeyPath(path, key, separator)
+				order.add(keyPath)
+				walk(v.values[key], keyPath)
+			}
+		case []any:
+			for i, item := range v {
+				itemPath := joinKeyPath(path, strconv.Itoa(i), separator)
+				order.add(itemPath)
+				walk(item, itemPath)
+			}
+		}
+	}
+	walk(m, "")
+	return order
+}
+
+// OrderMap converts a plain map to an ordered map, with the keys of every object
+// sorted by the position of their path in order. Keys without a position come
+// last, in natural order.
+func OrderMap(data map[string]any, order KeyOrder, separator string) *OrderedMap {
+	ordering := &Ordering{Mode: OrderPreserve, Source: order}
+	return orderValue(data, "", ordering, separator).(*OrderedMap)
+}
+
+func orderValue(value any, path string, ordering *Ordering, separator string) any {
+	switch v := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		ordering.SortKeys(keys, path, separator)
+
+		m := NewOrderedMap()
+		for _, key := range keys {
+			m.Set(key, orderValue(v[key], joinKeyPath(path, key, separator), ordering, separator))
+		}
+		return m
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = orderValue(item, joinKeyPath(path, strconv.Itoa(i), separator), ordering, separator)
+		}
+		return items
+	}
+	return value
+}
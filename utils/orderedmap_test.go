@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+const orderedSource = `{"z":1,"a":{"y":true,"b":null},"m":[{"k":"v","c":[2,1]},3],"item10":"x","item2":"y"}`
+
+func TestOrderedJSONKeepsKeyOrder(t *testing.T) {
+	m, err := ParseOrderedJSON([]byte(orderedSource))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"z", "a", "m", "item10", "item2"}; !reflect.DeepEqual(m.Keys(), expected) {
+		t.Errorf("Expected keys %v, got %v", expected, m.Keys())
+	}
+	data, err := m.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != orderedSource {
+		t.Errorf("Expected %s, got %s", orderedSource, data)
+	}
+
+	// Setting an existing key keeps its place; new keys come last
+	m.Set("a", "replaced")
+	m.Set("new", 1)
+	m.Delete("z")
+	m.Delete("missing")
+	if expected := []string{"a", "m", "item10", "item2", "new"}; !reflect.DeepEqual(m.Keys(), expected) {
+		t.Errorf("Expected keys %v, got %v", expected, m.Keys())
+	}
+}
+
+func TestKeyOrderAndOrderMap(t *testing.T) {
+	m, err := ParseOrderedJSON([]byte(orderedSource))
+	if err != nil {
+		t.Fatal(err)
+	}
+	order := m.KeyOrder(".")
+	expected := KeyOrder{
+		"z": 0, "a": 1, "a.y": 2, "a.b": 3,
+		"m": 4, "m.0": 5, "m.0.k": 6, "m.0.c": 7, "m.0.c.0": 8, "m.0.c.1": 9, "m.1": 10,
+		"item10": 11, "item2": 12,
+	}
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("Expected %v, got %v", expected, order)
+	}
+
+	// A plain map is ordered back as the source, with unknown keys last in
+	// natural order
+	plain := m.ToMap()
+	plain["extra10"] = 1
+	plain["extra2"] = 2
+	data, err := OrderMap(plain, order, ".").MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := orderedSource[:len(orderedSource)-1] + `,"extra2":2,"extra10":1}`
+	if string(data) != want {
+		t.Errorf("Expected %s, got %s", want, data)
+	}
+}
@@ -0,0 +1,306 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// Parquet physical types, repetition types and encodings (parquet.thrift)
+const (
+	parquetBoolean   = 0
+	parquetInt64     = 2
+	parquetDouble    = 5
+	parquetByteArray = 6
+
+	parquetOptional = 1
+	parquetUTF8     = 0
+
+	parquetPlain = 0
+	parquetRLE   = 3
+)
+
+// Thrift compact protocol field types
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// WriteParquetFile writes a table as an uncompressed Parquet file with a single row
+// group. Every column is optional, so missing keys are stored as nulls.
+func WriteParquetFile(filePath string, table Table) error {
+	if len(table.Columns) == 0 {
+		return fmt.Errorf("no columns to write")
+	}
+
+	dir := filepath.Dir(filePath)
+	if err := EnsureDirectoryExists(dir); err != nil {
+		return fmt.Errorf("failed to create parent directory: %v", err)
+	}
+
+	var file bytes.Buffer
+	file.WriteString("PAR1")
+
+	chunks := make([]parquetChunk, len(table.Columns))
+	for j := range table.Columns {
+		page := encodeParquetPage(table, j)
+
+		header := newThriftWriter()
+		header.i32(1, 0) // DATA_PAGE
+		header.i32(2, int32(len(page)))
+		header.i32(3, int32(len(page)))
+		header.structBegin(5)
+		header.i32(1, int32(len(table.Rows)))
+		header.i32(2, parquetPlain)
+		header.i32(3, parquetRLE)
+		header.i32(4, parquetRLE)
+		header.structEnd()
+		header.stop()
+
+		chunks[j] = parquetChunk{
+			offset: int64(file.Len()),
+			size:   int64(header.buf.Len() + len(page)),
+		}
+		file.Write(header.buf.Bytes())
+		file.Write(page)
+	}
+
+	meta := encodeParquetMetadata(table, chunks)
+	file.Write(meta)
+	binary.Write(&file, binary.LittleEndian, uint32(len(meta)))
+	file.WriteString("PAR1")
+
+	if err := os.WriteFile(filePath, file.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write file: %v", err)
+	}
+
+	return nil
+}
+
+// parquetChunk locates a column chunk in the file
+type parquetChunk struct {
+	offset int64
+	size   int64
+}
+
+// parquetType maps an inferred column type to a Parquet physical type
+func parquetType(columnType string) int32 {
+	switch columnType {
+	case ColumnBoolean:
+		return parquetBoolean
+	case ColumnInteger:
+		return parquetInt64
+	case ColumnNumber:
+		return parquetDouble
+	default:
+		return parquetByteArray
+	}
+}
+
+// encodeParquetPage encodes a column as a v1 data page: definition levels
+// (length-prefixed, bit-packed with width 1) followed by PLAIN encoded values
+func encodeParquetPage(table Table, column int) []byte {
+	columnType := table.Columns[column].Type
+
+	var levels, values bytes.Buffer
+	var bits []bool
+	var booleans []bool
+
+	for _, row := range table.Rows {
+		value := row[column]
+		bits = append(bits, value != nil)
+		if value == nil {
+			continue
+		}
+
+		switch columnType {
+		case ColumnBoolean:
+			booleans = append(booleans, value.(bool))
+		case ColumnInteger:
+			binary.Write(&values, binary.LittleEndian, CellInt(value))
+		case ColumnNumber:
+			binary.Write(&values, binary.LittleEndian, math.Float64bits(CellFloat(value)))
+		default:
+			text := CellString(value)
+			binary.Write(&values, binary.LittleEndian, uint32(len(text)))
+			values.WriteString(text)
+		}
+	}
+	if columnType == ColumnBoolean {
+		values.Write(packBits(booleans))
+	}
+
+	groups := (len(bits) + 7) / 8
+	writeUvarint(&levels, uint64(groups<<1|1))
+	levels.Write(packBits(bits))
+
+	var page bytes.Buffer
+	binary.Write(&page, binary.LittleEndian, uint32(levels.Len()))
+	page.Write(levels.Bytes())
+	page.Write(values.Bytes())
+	return page.Bytes()
+}
+
+// packBits packs booleans LSB first, padding the last byte with zeros
+func packBits(bits []bool) []byte {
+	packed := make([]byte, (len(bits)+7)/8)
+	for i, bit := range bits {
+		if bit {
+			packed[i/8] |= 1 << (i % 8)
+		}
+	}
+	return packed
+}
+
+// encodeParquetMetadata encodes the FileMetaData footer
+func encodeParquetMetadata(table Table, chunks []parquetChunk) []byte {
+	w := newThriftWriter()
+	w.i32(1, 1) // version
+
+	w.listBegin(2, thriftStruct, len(table.Columns)+1)
+	w.elementBegin()
+	w.str(4, "schema")
+	w.i32(5, int32(len(table.Columns)))
+	w.elementEnd()
+	for _, column := range table.Columns {
+		w.elementBegin()
+		w.i32(1, parquetType(column.Type))
+		w.i32(3, parquetOptional)
+		w.str(4, column.Name)
+		if column.Type == ColumnString {
+			w.i32(6, parquetUTF8)
+		}
+		w.elementEnd()
+	}
+
+	w.i64(3, int64(len(table.Rows)))
+
+	var totalSize int64
+	for _, chunk := range chunks {
+		totalSize += chunk.size
+	}
+
+	w.listBegin(4, thriftStruct, 1)
+	w.elementBegin()
+	w.listBegin(1, thriftStruct, len(chunks))
+	for j, chunk := range chunks {
+		w.elementBegin()
+		w.i64(2, chunk.offset)
+		w.structBegin(3)
+		w.i32(1, parquetType(table.Columns[j].Type))
+		w.listBegin(2, thriftI32, 2)
+		w.zigzag32(parquetPlain)
+		w.zigzag32(parquetRLE)
+		w.listBegin(3, thriftBinary, 1)
+		w.binary(table.Columns[j].Name)
+		w.i32(4, 0) // UNCOMPRESSED
+		w.i64(5, int64(len(table.Rows)))
+		w.i64(6, chunk.size)
+		w.i64(7, chunk.size)
+		w.i64(9, chunk.offset)
+		w.structEnd()
+		w.elementEnd()
+	}
+	w.i64(2, totalSize)
+	w.i64(3, int64(len(table.Rows)))
+	w.elementEnd()
+
+	w.str(6, "fitobj")
+	w.stop()
+
+	return w.buf.Bytes()
+}
+
+// thriftWriter encodes structs with the Thrift compact protocol
+type thriftWriter struct {
+	buf  bytes.Buffer
+	last []int16 // last field id per open struct
+}
+
+func newThriftWriter() *thriftWriter {
+	return &thriftWriter{last: []int16{0}}
+}
+
+func (w *thriftWriter) field(id int16, fieldType byte) {
+	top := len(w.last) - 1
+	if delta := id - w.last[top]; delta > 0 && delta <= 15 {
+		w.buf.WriteByte(byte(delta)<<4 | fieldType)
+	} else {
+		w.buf.WriteByte(fieldType)
+		w.zigzag32(int32(id))
+	}
+	w.last[top] = id
+}
+
+func (w *thriftWriter) zigzag32(v int32) {
+	writeUvarint(&w.buf, uint64(uint32(v<<1)^uint32(v>>31)))
+}
+
+func (w *thriftWriter) zigzag64(v int64) {
+	writeUvarint(&w.buf, uint64(v<<1)^uint64(v>>63))
+}
+
+func (w *thriftWriter) binary(s string) {
+	writeUvarint(&w.buf, uint64(len(s)))
+	w.buf.WriteString(s)
+}
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.field(id, thriftI32)
+	w.zigzag32(v)
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.field(id, thriftI64)
+	w.zigzag64(v)
+}
+
+func (w *thriftWriter) str(id int16, s string) {
+	w.field(id, thriftBinary)
+	w.binary(s)
+}
+
+func (w *thriftWriter) listBegin(id int16, elementType byte, size int) {
+	w.field(id, thriftList)
+	if size < 15 {
+		w.buf.WriteByte(byte(size)<<4 | elementType)
+	} else {
+		w.buf.WriteByte(0xF0 | elementType)
+		writeUvarint(&w.buf, uint64(size))
+	}
+}
+
+func (w *thriftWriter) structBegin(id int16) {
+	w.field(id, thriftStruct)
+	w.elementBegin()
+}
+
+func (w *thriftWriter) structEnd() {
+	w.elementEnd()
+}
+
+// elementBegin starts a struct that is a list element (no field header)
+func (w *thriftWriter) elementBegin() {
+	w.last = append(w.last, 0)
+}
+
+func (w *thriftWriter) elementEnd() {
+	w.stop()
+	w.last = w.last[:len(w.last)-1]
+}
+
+func (w *thriftWriter) stop() {
+	w.buf.WriteByte(0)
+}
+
+func writeUvarint(buf *bytes.Buffer, v uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	buf.Write(tmp[:n])
+}
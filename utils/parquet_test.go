@@ -0,0 +1,231 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// thriftReader decodes Thrift compact protocol structs into maps of field id to
+// value, enough to check the footers and page headers WriteParquetFile writes
+type thriftReader struct {
+	t   *testing.T
+	buf *bytes.Reader
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, err := binary.ReadUvarint(r.buf)
+	if err != nil {
+		r.t.Fatalf("Truncated varint: %v", err)
+	}
+	return v
+}
+
+func (r *thriftReader) zigzag() int64 {
+	v := r.uvarint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *thriftReader) byte() byte {
+	b, err := r.buf.ReadByte()
+	if err != nil {
+		r.t.Fatalf("Truncated struct: %v", err)
+	}
+	return b
+}
+
+func (r *thriftReader) value(fieldType byte) any {
+	switch fieldType {
+	case thriftI32, thriftI64:
+		return r.zigzag()
+	case thriftBinary:
+		data := make([]byte, r.uvarint())
+		if _, err := r.buf.Read(data); err != nil && len(data) > 0 {
+			r.t.Fatalf("Truncated binary: %v", err)
+		}
+		return string(data)
+	case thriftList:
+		header := r.byte()
+		size, elementType := int(header>>4), header&0x0F
+		if size == 15 {
+			size = int(r.uvarint())
+		}
+		items := make([]any, size)
+		for i := range items {
+			items[i] = r.value(elementType)
+		}
+		return items
+	case thriftStruct:
+		return r.structure()
+	}
+	r.t.Fatalf("Unexpected field type %d", fieldType)
+	return nil
+}
+
+func (r *thriftReader) structure() map[int16]any {
+	fields := make(map[int16]any)
+	var last int16
+	for {
+		header := r.byte()
+		if header == 0 {
+			return fields
+		}
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			id = int16(r.zigzag())
+		}
+		last = id
+		fields[id] = r.value(header & 0x0F)
+	}
+}
+
+func TestWriteParquetFile(t *testing.T) {
+	table := BuildTable([]map[string]any{
+		{"active": true, "count": int64(3), "name": "a", "score": 1.5},
+		{"active": false, "name": "b"},
+	})
+	path := filepath.Join(t.TempDir(), "out.parquet")
+	if err := WriteParquetFile(path, table); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The file starts and ends with the magic, the footer length before the last one
+	if string(data[:4]) != "PAR1" || string(data[len(data)-4:]) != "PAR1" {
+		t.Fatalf("Expected PAR1 magic at both ends, got %q and %q", data[:4], data[len(data)-4:])
+	}
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footerStart := len(data) - 8 - footerLen
+	if footerStart < 4 {
+		t.Fatalf("Footer length %d exceeds the file size %d", footerLen, len(data))
+	}
+	footer := bytes.NewReader(data[footerStart : len(data)-8])
+	meta := (&thriftReader{t: t, buf: footer}).structure()
+	if footer.Len() != 0 {
+		t.Fatalf("Expected the footer to end with its struct, %d bytes left", footer.Len())
+	}
+
+	// FileMetaData: 1 version, 2 schema, 3 num_rows, 4 row_groups, 6 created_by
+	if meta[1] != int64(1) || meta[3] != int64(2) || meta[6] != "fitobj" {
+		t.Errorf("Unexpected version, row count or creator: %v", meta)
+	}
+	schema := meta[2].([]any)
+	if len(schema) != len(table.Columns)+1 {
+		t.Fatalf("Expected a root and %d schema elements, got %d", len(table.Columns), len(schema))
+	}
+	// SchemaElement: 1 type, 3 repetition_type, 4 name, 5 num_children, 6 converted_type
+	root := schema[0].(map[int16]any)
+	if root[4] != "schema" || root[5] != int64(len(table.Columns)) {
+		t.Errorf("Unexpected root schema element: %v", root)
+	}
+	expectedTypes := map[string]int64{"active": parquetBoolean, "count": parquetInt64, "name": parquetByteArray, "score": parquetDouble}
+	for i, column := range table.Columns {
+		element := schema[i+1].(map[int16]any)
+		if element[4] != column.Name || element[1] != expectedTypes[column.Name] || element[3] != int64(parquetOptional) {
+			t.Errorf("Unexpected schema element for %s: %v", column.Name, element)
+		}
+		if _, utf8 := element[6]; utf8 != (column.Type == ColumnString) {
+			t.Errorf("Expected the UTF8 converted type on string columns only, %s: %v", column.Name, element)
+		}
+	}
+
+	// RowGroup: 1 columns, 2 total_byte_size, 3 num_rows; ColumnChunk: 2 file_offset,
+	// 3 meta_data; ColumnMetaData: 1 type, 3 path_in_schema, 5 num_values, 9 data_page_offset
+	rowGroups := meta[4].([]any)
+	if len(rowGroups) != 1 {
+		t.Fatalf("Expected a single row group, got %d", len(rowGroups))
+	}
+	group := rowGroups[0].(map[int16]any)
+	if group[3] != int64(2) {
+		t.Errorf("Expected 2 rows in the row group, got %v", group[3])
+	}
+	chunks := group[1].([]any)
+	values := make(map[string][]any)
+	for i, column := range table.Columns {
+		chunk := chunks[i].(map[int16]any)
+		columnMeta := chunk[3].(map[int16]any)
+		if !reflect.DeepEqual(columnMeta[3], []any{column.Name}) || columnMeta[5] != int64(2) || columnMeta[9] != chunk[2] {
+			t.Errorf("Unexpected column chunk for %s: %v", column.Name, chunk)
+		}
+		values[column.Name] = readParquetPage(t, data, chunk[2].(int64), column.Type)
+	}
+
+	// Reading the pages back returns the table, with nulls for missing keys
+	expected := map[string][]any{
+		"active": {true, false},
+		"count":  {int64(3), nil},
+		"name":   {"a", "b"},
+		"score":  {1.5, nil},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("Expected %v read back, got %v", expected, values)
+	}
+}
+
+// readParquetPage decodes the data page of a column chunk written by
+// WriteParquetFile: a page header, bit-packed definition levels and PLAIN values
+func readParquetPage(t *testing.T, data []byte, offset int64, columnType string) []any {
+	t.Helper()
+	page := bytes.NewReader(data[offset:])
+	// PageHeader: 1 type, 2 uncompressed_page_size, 5 data_page_header (1 num_values)
+	header := (&thriftReader{t: t, buf: page}).structure()
+	numValues := int(header[5].(map[int16]any)[1].(int64))
+
+	var levelsLen uint32
+	binary.Read(page, binary.LittleEndian, &levelsLen)
+	levels := make([]byte, levelsLen)
+	page.Read(levels)
+	levelReader := bytes.NewReader(levels)
+	if _, err := binary.ReadUvarint(levelReader); err != nil {
+		t.Fatal(err)
+	}
+	bits := make([]byte, levelReader.Len())
+	levelReader.Read(bits)
+
+	present := 0
+	for i := 0; i < numValues; i++ {
+		if bits[i/8]&(1<<(i%8)) != 0 {
+			present++
+		}
+	}
+	var booleans []byte
+	if columnType == ColumnBoolean {
+		booleans = make([]byte, (present+7)/8)
+		page.Read(booleans)
+	}
+
+	result := make([]any, numValues)
+	read := 0
+	for i := range result {
+		if bits[i/8]&(1<<(i%8)) == 0 {
+			continue
+		}
+		switch columnType {
+		case ColumnBoolean:
+			result[i] = booleans[read/8]&(1<<(read%8)) != 0
+		case ColumnInteger:
+			var v int64
+			binary.Read(page, binary.LittleEndian, &v)
+			result[i] = v
+		case ColumnNumber:
+			var v uint64
+			binary.Read(page, binary.LittleEndian, &v)
+			result[i] = math.Float64frombits(v)
+		default:
+			var size uint32
+			binary.Read(page, binary.LittleEndian, &size)
+			text := make([]byte, size)
+			page.Read(text)
+			result[i] = string(text)
+		}
+		read++
+	}
+	return result
+}
@@ -0,0 +1,241 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// PropertiesOptions configures writing Java .properties files
+type PropertiesOptions struct {
+	ASCII bool // escape non-ASCII characters as \uXXXX, as ISO-8859-1 readers (Java 8 and earlier) expect
+}
+
+// IsPropertiesFile checks if a file is a Java properties file based on its extension
+func IsPropertiesFile(filename string) bool {
+	return filepath.Ext(filename) == ".properties"
+}
+
+// ReadPropertiesFile reads a properties file into a flattened map of string values
+func ReadPropertiesFile(filePath string) (map[string]any, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+	return ParseProperties(data)
+}
+
+// ParseProperties parses a properties document as java.util.Properties does: #
+// and ! comment lines, key/value separated by '=', ':' or whitespace, lines
+// continued by a trailing backslash, and \t, \n, \r, \f and \uXXXX escapes. Keys
+// are returned as written, with string values.
+func ParseProperties(data []byte) (map[string]any, error) {
+	result := make(map[string]any)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNum := 0
+
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimLeft(scanner.Text(), " \t\f")
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+
+		// Join continuation lines, dropping the leading whitespace of each
+		for continues(line) && scanner.Scan() {
+			lineNum++
+			line = line[:len(line)-1] + strings.TrimLeft(scanner.Text(), " \t\f")
+		}
+
+		keyEnd := len(line)
+		for i := 0; i < len(line); i++ {
+			if line[i] == '\\' {
+				i++
+				continue
+			}
+			if strings.IndexByte("=: \t\f", line[i]) >= 0 {
+				keyEnd = i
+				break
+			}
+		}
+
+		rest := strings.TrimLeft(line[keyEnd:], " \t\f")
+		if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+			rest = strings.TrimLeft(rest[1:], " \t\f")
+		}
+
+		key, err := unescapeProperty(line[:keyEnd])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNum, err)
+		}
+		value, err := unescapeProperty(rest)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNum, err)
+		}
+		result[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read properties: %v", err)
+	}
+
+	return result, nil
+}
+
+// continues reports whether a line ends with an odd number of backslashes
+func continues(line string) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// unescapeProperty resolves the escapes of a properties key or value, combining
+// \uXXXX surrogate pairs
+func unescapeProperty(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+
+	var units []uint16
+	var b strings.Builder
+	flush := func() {
+		if len(units) > 0 {
+			b.WriteString(string(utf16.Decode(units)))
+			units = units[:0]
+		}
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			flush()
+			b.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		if s[i] == 'u' {
+			if i+5 > len(s) {
+				return "", fmt.Errorf("malformed \\u escape")
+			}
+			unit, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("malformed \\u escape: %s", s[i+1:i+5])
+			}
+			units = append(units, uint16(unit))
+			i += 4
+			continue
+		}
+
+		flush()
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	flush()
+	return b.String(), nil
+}
+
+// MarshalProperties serializes a flattened map as a properties document sorted
+// by key, escaping keys and values as java.util.Properties.store does. Strings
+// are written as-is, nil as an empty value, and arrays and objects as JSON.
+func MarshalProperties(flat map[string]any, options PropertiesOptions) ([]byte, error) {
+	keys := make([]string, 0, len(flat))
+	for key := range flat {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	for _, key := range keys {
+		var text string
+		switch v := flat[key].(type) {
+		case nil:
+		case string:
+			text = v
+		case map[string]any, []any:
+			data, err := json.Marshal(v)
+			if err != nil {
+				return nil, fmt.Errorf("failed to serialize %s: %v", key, err)
+			}
+			text = string(data)
+		default:
+			text = fmt.Sprint(v)
+		}
+
+		buf.WriteString(escapeProperty(key, true, options.ASCII))
+		buf.WriteByte('=')
+		buf.WriteString(escapeProperty(text, false, options.ASCII))
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// escapeProperty escapes a key or value. Spaces are escaped throughout keys but
+// only at the start of values.
+func escapeProperty(s string, isKey, ascii bool) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '=', ':', '#', '!':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case ' ':
+			if isKey || i == 0 {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(' ')
+		default:
+			if r < 0x20 || (ascii && r > 0x7e) {
+				for _, unit := range utf16.Encode([]rune{r}) {
+					fmt.Fprintf(&b, `\u%04X`, unit)
+				}
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// WritePropertiesFile writes a flattened map as a properties file
+func WritePropertiesFile(filePath string, flat map[string]any, options PropertiesOptions) error {
+	if err := EnsureDirectoryExists(filepath.Dir(filePath)); err != nil {
+		return fmt.Errorf("failed to create parent directory: %v", err)
+	}
+
+	data, err := MarshalProperties(flat, options)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %v", err)
+	}
+	return nil
+}
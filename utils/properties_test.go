@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseProperties(t *testing.T) {
+	source := "# comment\n" +
+		"! also a comment \\\n" +
+		"plain=value\n" +
+		"colon: value\n" +
+		"space value\n" +
+		"  indented = trimmed  \n" +
+		"empty=\n" +
+		"key\\ with\\ spaces=v\n" +
+		"key\\=eq\\:colon=v\n" +
+		"escapes=a\\tb\\nc\\rd\\fe\\\\f\\#g\n" +
+		"unicode=caf\\u00e9 \\u4e2d\n" +
+		"surrogates=\\uD83D\\uDE00\n" +
+		"continued=one, \\\n" +
+		"    two, \\\n" +
+		"\tthree\n" +
+		"escaped\\\\=not continued\\\\\n" +
+		"next=line\n"
+
+	expected := map[string]any{
+		"plain":           "value",
+		"colon":           "value",
+		"space":           "value",
+		"indented":        "trimmed  ",
+		"empty":           "",
+		"key with spaces": "v",
+		"key=eq:colon":    "v",
+		"escapes":         "a\tb\nc\rd\fe\\f#g",
+		"unicode":         "café 中",
+		"surrogates":      "😀",
+		"continued":       "one, two, three",
+		"escaped\\":       "not continued\\",
+		"next":            "line",
+	}
+	got, err := ParseProperties([]byte(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	for _, invalid := range []string{"a=\\u12\n", "a=\\uzzzz\n"} {
+		if _, err := ParseProperties([]byte(invalid)); err == nil {
+			t.Errorf("Expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestMarshalPropertiesRoundTrip(t *testing.T) {
+	flat := map[string]any{
+		"key with spaces": "v",
+		"key=eq:colon":    "a#b!c",
+		"leading":         "  two spaces",
+		"controls":        "a\tb\nc\rd\fe\\f",
+		"unicode":         "café 中 😀",
+		"bell":            "\a",
+		"":                "empty key",
+	}
+
+	tests := map[string]struct {
+		options  PropertiesOptions
+		expected string
+	}{
+		"utf-8": {
+			expected: "=empty key\n" +
+				"bell=\\u0007\n" +
+				"controls=a\\tb\\nc\\rd\\fe\\\\f\n" +
+				"key\\ with\\ spaces=v\n" +
+				"key\\=eq\\:colon=a\\#b\\!c\n" +
+				"leading=\\  two spaces\n" +
+				"unicode=café 中 😀\n",
+		},
+		"ascii": {
+			options: PropertiesOptions{ASCII: true},
+			expected: "=empty key\n" +
+				"bell=\\u0007\n" +
+				"controls=a\\tb\\nc\\rd\\fe\\\\f\n" +
+				"key\\ with\\ spaces=v\n" +
+				"key\\=eq\\:colon=a\\#b\\!c\n" +
+				"leading=\\  two spaces\n" +
+				"unicode=caf\\u00E9 \\u4E2D \\uD83D\\uDE00\n",
+		},
+	}
+	for name, test := range tests {
+		data, err := MarshalProperties(flat, test.options)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if string(data) != test.expected {
+			t.Errorf("%s: expected:\n%s\ngot:\n%s", name, test.expected, data)
+		}
+
+		got, err := ParseProperties(data)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !reflect.DeepEqual(got, flat) {
+			t.Errorf("%s: expected %v back, got %v", name, flat, got)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// byteUnits are the multipliers of size suffixes; K, M and G are binary as in
+// container memory limits
+var byteUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"k":   1 << 10,
+	"kb":  1000,
+	"kib": 1 << 10,
+	"m":   1 << 20,
+	"mb":  1000 * 1000,
+	"mib": 1 << 20,
+	"g":   1 << 30,
+	"gb":  1000 * 1000 * 1000,
+	"gib": 1 << 30,
+}
+
+// ParseByteSize parses a size such as 512MiB, 1.5G or 200000 (bytes)
+func ParseByteSize(text string) (int64, error) {
+	s := strings.TrimSpace(text)
+	i := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if i < 0 {
+		i = len(s)
+	}
+
+	number, unit := s[:i], strings.ToLower(strings.TrimSpace(s[i:]))
+	multiplier, ok := byteUnits[unit]
+	value, err := strconv.ParseFloat(number, 64)
+	if !ok || err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid size '%s' (expected a number of bytes with an optional unit such as 512MiB or 2G)", text)
+	}
+	return int64(value * float64(multiplier)), nil
+}
@@ -0,0 +1,164 @@
+package utils
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// Column types inferred for tabular output
+const (
+	ColumnBoolean = "boolean"
+	ColumnInteger = "integer"
+	ColumnNumber  = "number"
+	ColumnString  = "string"
+)
+
+// Column describes a column of a tabular view
+type Column struct {
+	Name string
+	Type string
+}
+
+// Table is the union-of-keys tabular view of a collection of flattened documents.
+// Missing keys are nil cells.
+type Table struct {
+	Columns []Column
+	Rows    [][]any
+}
+
+// BuildTable builds a table from flattened documents with one column per distinct
+// key, sorted by name, and column types inferred from the non-null values
+func BuildTable(docs []map[string]any) Table {
+	seen := make(map[string]bool)
+	var names []string
+	for _, doc := range docs {
+		for key := range doc {
+			if !seen[key] {
+				seen[key] = true
+				names = append(names, key)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	table := Table{Columns: make([]Column, len(names)), Rows: make([][]any, len(docs))}
+	for i, doc := range docs {
+		row := make([]any, len(names))
+		for j, name := range names {
+			row[j] = doc[name]
+		}
+		table.Rows[i] = row
+	}
+
+	for j, name := range names {
+		table.Columns[j] = Column{Name: name, Type: inferColumnType(table.Rows, j)}
+	}
+
+	return table
+}
+
+// inferColumnType returns the narrowest type holding every non-null value of a column
+func inferColumnType(rows [][]any, column int) string {
+	columnType := ""
+	for _, row := range rows {
+		valueType := ValueColumnType(row[column])
+		switch {
+		case valueType == "":
+			continue
+		case valueType == ColumnString:
+			return ColumnString
+		case columnType == "" || columnType == valueType:
+			columnType = valueType
+		case (columnType == ColumnInteger && valueType == ColumnNumber) || (columnType == ColumnNumber && valueType == ColumnInteger):
+			columnType = ColumnNumber
+		default:
+			return ColumnString
+		}
+	}
+
+	if columnType == "" {
+		return ColumnString
+	}
+	return columnType
+}
+
+// ValueColumnType returns the column type of a value: integer for numbers without
+// a fraction, string for strings and nested values, and "" for nil
+func ValueColumnType(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case bool:
+		return ColumnBoolean
+	case int, int64:
+		return ColumnInteger
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return ColumnInteger
+		}
+		return ColumnNumber
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return ColumnInteger
+		}
+		return ColumnNumber
+	}
+	return ColumnString
+}
+
+// CellString renders a cell as text for string columns. Nested values such as
+// empty maps and arrays are JSON encoded.
+func CellString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case json.Number:
+		return v.String()
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(encoded)
+	}
+}
+
+// CellFloat returns a numeric cell as a float64
+func CellFloat(value any) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	}
+	return 0
+}
+
+// CellInt returns an integral cell as an int64
+func CellInt(value any) int64 {
+	switch v := value.(type) {
+	case float64:
+		return int64(v)
+	case int:
+		return int64(v)
+	case int64:
+		return v
+	case json.Number:
+		n, _ := v.Int64()
+		return n
+	}
+	return 0
+}
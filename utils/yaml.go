@@ -0,0 +1,523 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAML anchor handling modes
+const (
+	AnchorsExpand = "expand" // resolve aliases into independent copies
+	AnchorsRecord = "record" // resolve aliases but record them so they can be restored on output
+)
+
+// AnchorsSuffix is appended to an output path for the sidecar file holding recorded anchors
+const AnchorsSuffix = ".anchors.json"
+
+// Anchors records YAML anchors, aliases and merge keys by key path
+type Anchors struct {
+	Defined map[string]string   `json:"defined,omitempty"` // path -> anchor name
+	Aliases map[string]string   `json:"aliases,omitempty"` // path -> anchor name
+	Merges  map[string][]string `json:"merges,omitempty"`  // mapping path -> merged anchor names
+}
+
+// IsEmpty reports whether no anchors were recorded
+func (a *Anchors) IsEmpty() bool {
+	return a == nil || (len(a.Defined) == 0 && len(a.Aliases) == 0 && len(a.Merges) == 0)
+}
+
+// IsYAMLFile checks if a file is a YAML file based on its extension
+func IsYAMLFile(filename string) bool {
+	ext := filepath.Ext(filename)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// ValidateAnchorMode checks that an anchor handling mode is known
+func ValidateAnchorMode(mode string) error {
+	switch mode {
+	case "", AnchorsExpand, AnchorsRecord:
+		return nil
+	}
+	return fmt.Errorf("unknown YAML anchor mode '%s' (expected expand or record)", mode)
+}
+
+// ReadYAMLFile reads a YAML file, expanding aliases and merge keys, and returns its
+// data together with its comments, the anchors it used and the types of its
+// non-string keys, keyed by path
+func ReadYAMLFile(filePath, separator string) (map[string]any, Comments, *Anchors, KeyTypes, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("failed to read file: %v", err)
+	}
+	return ParseYAML(data, separator)
+}
+
+// ParseYAML parses a YAML document whose root is a mapping. Keys that are not
+// strings (1, true) are stringified and their types returned as hints.
+func ParseYAML(data []byte, separator string) (map[string]any, Comments, *Anchors, KeyTypes, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("failed to parse YAML: %v", err)
+	}
+
+	parser := &yamlParser{
+		separator: separator,
+		comments:  make(Comments),
+		anchors: &Anchors{
+			Defined: make(map[string]string),
+			Aliases: make(map[string]string),
+			Merges:  make(map[string][]string),
+		},
+		keyTypes: make(KeyTypes),
+	}
+
+	if root.Kind == 0 {
+		return make(map[string]any), parser.comments, parser.anchors, parser.keyTypes, nil
+	}
+	parser.addComments("", root.HeadComment, root.FootComment)
+
+	value, err := parser.value(&root, "")
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	result, ok := value.(map[string]any)
+	if !ok {
+		if value != nil {
+			return nil, nil, nil, nil, fmt.Errorf("failed to parse YAML: document root must be a mapping")
+		}
+		result = make(map[string]any)
+	}
+
+	return result, parser.comments, parser.anchors, parser.keyTypes, nil
+}
+
+// yamlParser converts YAML nodes to plain values while recording metadata. Nodes
+// reached through an alias are expanded without recording comments or anchors.
+type yamlParser struct {
+	separator string
+	comments  Comments
+	anchors   *Anchors
+	keyTypes  KeyTypes
+	expanding int
+}
+
+func (p *yamlParser) join(path, segment string) string {
+	if path == "" {
+		return segment
+	}
+	return path + p.separator + segment
+}
+
+func (p *yamlParser) addComments(path string, texts ...string) {
+	if p.expanding > 0 {
+		return
+	}
+	for _, text := range texts {
+		for _, line := range strings.Split(text, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				p.comments[path] = append(p.comments[path], line)
+			}
+		}
+	}
+}
+
+func (p *yamlParser) value(node *yaml.Node, path string) (any, error) {
+	if node.Anchor != "" && p.expanding == 0 {
+		p.anchors.Defined[path] = node.Anchor
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil, nil
+		}
+		return p.value(node.Content[0], path)
+
+	case yaml.AliasNode:
+		if p.expanding == 0 {
+			p.anchors.Aliases[path] = node.Value
+		}
+		p.expanding++
+		defer func() { p.expanding-- }()
+		return p.value(node.Alias, path)
+
+	case yaml.MappingNode:
+		result := make(map[string]any, len(node.Content)/2)
+		var merged []map[string]any
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+
+			if key.Tag == "!!merge" {
+				sources := []*yaml.Node{value}
+				if value.Kind == yaml.SequenceNode {
+					sources = value.Content
+				}
+				for _, source := range sources {
+					if source.Kind == yaml.AliasNode && p.expanding == 0 {
+						p.anchors.Merges[path] = append(p.anchors.Merges[path], source.Value)
+					}
+					p.expanding++
+					v, err := p.value(source, path)
+					p.expanding--
+					if err != nil {
+						return nil, err
+					}
+					m, ok := v.(map[string]any)
+					if !ok {
+						return nil, fmt.Errorf("failed to parse YAML: merge value at line %d is not a mapping", source.Line)
+					}
+					merged = append(merged, m)
+				}
+				continue
+			}
+
+			childPath := p.join(path, key.Value)
+			if keyType := yamlKeyType(key.ShortTag()); keyType != "" {
+				p.keyTypes[childPath] = keyType
+			}
+			p.addComments(childPath, key.HeadComment, key.LineComment, value.LineComment, key.FootComment)
+
+			v, err := p.value(value, childPath)
+			if err != nil {
+				return nil, err
+			}
+			result[key.Value] = v
+		}
+
+		// Explicit keys override merged ones; earlier merges override later ones
+		for _, m := range merged {
+			for key, value := range m {
+				if _, exists := result[key]; !exists {
+					result[key] = value
+				}
+			}
+		}
+		return result, nil
+
+	case yaml.SequenceNode:
+		result := make([]any, len(node.Content))
+		for i, item := range node.Content {
+			v, err := p.value(item, p.join(path, strconv.Itoa(i)))
+			if err != nil {
+				return nil, err
+			}
+			result[i] = v
+		}
+		return result, nil
+
+	default:
+		if node.Tag == "!!timestamp" {
+			return node.Value, nil
+		}
+		var v any
+		if err := node.Decode(&v); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML value at line %d: %v", node.Line, err)
+		}
+		return v, nil
+	}
+}
+
+// WriteYAMLFile writes a map to a YAML file, emitting comments above their keys and,
+// when anchors are given, restoring anchors, aliases and merge keys whose values
+// are still shared. Values that diverged from their anchor are written in full.
+// Keys with a type hint are written untagged as numbers, booleans or null. Keys
+// are sorted alphabetically when order is nil.
+func WriteYAMLFile(filePath string, data map[string]any, comments Comments, anchors *Anchors, keyTypes KeyTypes, separator string, order *Ordering) error {
+	dir := filepath.Dir(filePath)
+	if err := EnsureDirectoryExists(dir); err != nil {
+		return fmt.Errorf("failed to create parent directory: %v", err)
+	}
+
+	yamlData, err := MarshalYAML(data, comments, anchors, keyTypes, separator, order)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filePath, yamlData, 0644); err != nil {
+		return fmt.Errorf("failed to write file: %v", err)
+	}
+
+	return nil
+}
+
+// MarshalYAML encodes a map as YAML with ordered keys, comments, anchors and typed keys
+func MarshalYAML(data map[string]any, comments Comments, anchors *Anchors, keyTypes KeyTypes, separator string, order *Ordering) ([]byte, error) {
+	builder := &yamlBuilder{
+		separator: separator,
+		order:     order,
+		comments:  comments,
+		anchors:   anchors,
+		keyTypes:  keyTypes,
+		groups:    make(map[string]string),
+		values:    make(map[string]any),
+		emitted:   make(map[string]*yaml.Node),
+	}
+	if !anchors.IsEmpty() {
+		for path, name := range anchors.Defined {
+			builder.groups[path] = name
+		}
+		for path, name := range anchors.Aliases {
+			builder.groups[path] = name
+		}
+		builder.collectAnchorValues(data, "")
+	}
+
+	root, err := builder.node(data, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize YAML: %v", err)
+	}
+	doc := &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}
+	doc.HeadComment = strings.Join(comments[""], "\n")
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, fmt.Errorf("failed to serialize YAML: %v", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to serialize YAML: %v", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// yamlBuilder converts plain values to YAML nodes, re-creating recorded anchors.
+// The first node of an anchor group in document order carries the anchor and
+// later equal nodes become aliases of it.
+type yamlBuilder struct {
+	separator string
+	comments  Comments
+	anchors   *Anchors
+	keyTypes  KeyTypes
+	order     *Ordering
+	groups    map[string]string // path -> anchor name
+	values    map[string]any    // anchor name -> anchored value
+	emitted   map[string]*yaml.Node
+}
+
+func (b *yamlBuilder) join(path, segment string) string {
+	if path == "" {
+		return segment
+	}
+	return path + b.separator + segment
+}
+
+func (b *yamlBuilder) collectAnchorValues(value any, path string) {
+	if name, ok := b.anchors.Defined[path]; ok && path != "" {
+		b.values[name] = value
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			b.collectAnchorValues(item, b.join(path, key))
+		}
+	case []any:
+		for i, item := range v {
+			b.collectAnchorValues(item, b.join(path, strconv.Itoa(i)))
+		}
+	}
+}
+
+func (b *yamlBuilder) node(value any, path string) (*yaml.Node, error) {
+	name, grouped := b.groups[path]
+	if !grouped || path == "" {
+		return b.build(value, path)
+	}
+
+	anchored, ok := b.values[name]
+	if !ok || !reflect.DeepEqual(anchored, value) {
+		return b.build(value, path)
+	}
+
+	if target := b.emitted[name]; target != nil {
+		return &yaml.Node{Kind: yaml.AliasNode, Value: name, Alias: target}, nil
+	}
+
+	n, err := b.build(value, path)
+	if err != nil {
+		return nil, err
+	}
+	n.Anchor = name
+	b.emitted[name] = n
+	return n, nil
+}
+
+func (b *yamlBuilder) build(value any, path string) (*yaml.Node, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		n := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+
+		covered := b.mergedKeys(v, path, n)
+
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			if !covered[key] {
+				keys = append(keys, key)
+			}
+		}
+		b.order.SortKeys(keys, path, b.separator)
+
+		for _, key := range keys {
+			childPath := b.join(path, key)
+			keyNode := yamlKeyNode(key, b.keyTypes[childPath])
+			keyNode.HeadComment = strings.Join(b.comments[childPath], "\n")
+
+			valueNode, err := b.node(v[key], childPath)
+			if err != nil {
+				return nil, err
+			}
+			n.Content = append(n.Content, keyNode, valueNode)
+		}
+		return n, nil
+
+	case []any:
+		n := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for i, item := range v {
+			itemNode, err := b.node(item, b.join(path, strconv.Itoa(i)))
+			if err != nil {
+				return nil, err
+			}
+			n.Content = append(n.Content, itemNode)
+		}
+		return n, nil
+
+	case json.Number:
+		tag := "!!float"
+		if _, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			tag = "!!int"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: string(v)}, nil
+
+	default:
+		n := &yaml.Node{}
+		if err := n.Encode(v); err != nil {
+			return nil, err
+		}
+		return n, nil
+	}
+}
+
+// mergedKeys adds the recorded merge keys of a mapping that still apply and returns
+// the keys they cover. A merge applies when its anchor was already emitted and
+// every anchored key is still present; keys that differ stay explicit overrides.
+func (b *yamlBuilder) mergedKeys(m map[string]any, path string, n *yaml.Node) map[string]bool {
+	if b.anchors.IsEmpty() || len(b.anchors.Merges[path]) == 0 {
+		return nil
+	}
+
+	covered := make(map[string]bool)
+	var sources []*yaml.Node
+
+	for _, name := range b.anchors.Merges[path] {
+		target := b.emitted[name]
+		anchored, ok := b.values[name].(map[string]any)
+		if target == nil || !ok {
+			continue
+		}
+
+		applies := true
+		for key := range anchored {
+			if _, exists := m[key]; !exists {
+				applies = false
+				break
+			}
+		}
+		if !applies {
+			continue
+		}
+
+		sources = append(sources, &yaml.Node{Kind: yaml.AliasNode, Value: name, Alias: target})
+		for key, value := range anchored {
+			if _, seen := covered[key]; !seen {
+				covered[key] = reflect.DeepEqual(m[key], value)
+			}
+		}
+	}
+
+	if len(sources) == 0 {
+		return nil
+	}
+
+	keyNode := &yaml.Node{Kind: yaml.ScalarNode, Value: "<<"}
+	if len(sources) == 1 {
+		n.Content = append(n.Content, keyNode, sources[0])
+	} else {
+		n.Content = append(n.Content, keyNode, &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle, Content: sources})
+	}
+	return covered
+}
+
+// ReadAnchorsFile reads recorded anchors from a sidecar file, returning nil when it does not exist
+func ReadAnchorsFile(filePath string) (*Anchors, error) {
+	data, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read anchors: %v", err)
+	}
+
+	var anchors Anchors
+	if err := json.Unmarshal(data, &anchors); err != nil {
+		return nil, fmt.Errorf("failed to parse anchors: %v", err)
+	}
+	return &anchors, nil
+}
+
+// WriteAnchorsFile writes recorded anchors to a sidecar file
+func WriteAnchorsFile(filePath string, anchors *Anchors) error {
+	data, err := json.MarshalIndent(anchors, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize anchors: %v", err)
+	}
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write anchors: %v", err)
+	}
+	return nil
+}
+
+// MergeAnchors fills anchors missing from a with those recorded in b
+func MergeAnchors(a, b *Anchors) *Anchors {
+	if b.IsEmpty() {
+		return a
+	}
+	if a == nil {
+		return b
+	}
+
+	fill := func(dst, src map[string]string) map[string]string {
+		if dst == nil {
+			dst = make(map[string]string)
+		}
+		for k, v := range src {
+			if _, ok := dst[k]; !ok {
+				dst[k] = v
+			}
+		}
+		return dst
+	}
+
+	a.Defined = fill(a.Defined, b.Defined)
+	a.Aliases = fill(a.Aliases, b.Aliases)
+	if a.Merges == nil {
+		a.Merges = make(map[string][]string)
+	}
+	for k, v := range b.Merges {
+		if _, ok := a.Merges[k]; !ok {
+			a.Merges[k] = v
+		}
+	}
+	return a
+}
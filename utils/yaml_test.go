@@ -0,0 +1,141 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const yamlAnchorsSource = `defaults: &defaults
+  adapter: postgres
+  pool: 5
+dev:
+  <<: *defaults
+  database: dev
+prod:
+  <<: *defaults
+  pool: 20
+hosts: &hosts
+  - a
+  - b
+backup:
+  hosts: *hosts
+`
+
+func TestParseYAMLAnchors(t *testing.T) {
+	data, _, anchors, _, err := ParseYAML([]byte(yamlAnchorsSource), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Aliases and merge keys are expanded, keys of the mapping overriding merged ones
+	expectedData := map[string]any{
+		"defaults": map[string]any{"adapter": "postgres", "pool": 5},
+		"dev":      map[string]any{"adapter": "postgres", "pool": 5, "database": "dev"},
+		"prod":     map[string]any{"adapter": "postgres", "pool": 20},
+		"hosts":    []any{"a", "b"},
+		"backup":   map[string]any{"hosts": []any{"a", "b"}},
+	}
+	if !reflect.DeepEqual(data, expectedData) {
+		t.Errorf("Expected %v, got %v", expectedData, data)
+	}
+
+	expectedAnchors := &Anchors{
+		Defined: map[string]string{"defaults": "defaults", "hosts": "hosts"},
+		Aliases: map[string]string{"backup.hosts": "hosts"},
+		Merges:  map[string][]string{"dev": {"defaults"}, "prod": {"defaults"}},
+	}
+	if !reflect.DeepEqual(anchors, expectedAnchors) {
+		t.Errorf("Expected anchors %+v, got %+v", expectedAnchors, anchors)
+	}
+}
+
+func TestMarshalYAMLRestoresAnchors(t *testing.T) {
+	data, comments, anchors, keyTypes, err := ParseYAML([]byte(yamlAnchorsSource), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := MarshalYAML(data, comments, anchors, keyTypes, ".", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Keys are written alphabetically: the first node of a group carries the anchor
+	expected := `backup:
+  hosts: &hosts
+    - a
+    - b
+defaults: &defaults
+  adapter: postgres
+  pool: 5
+dev:
+  <<: *defaults
+  database: dev
+hosts: *hosts
+prod:
+  <<: *defaults
+  pool: 20
+`
+	if string(out) != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out)
+	}
+
+	again, _, _, _, err := ParseYAML(out, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(again, data) {
+		t.Errorf("Expected the written document to parse back to %v, got %v", data, again)
+	}
+}
+
+func TestMarshalYAMLDivergedAnchors(t *testing.T) {
+	data, comments, anchors, keyTypes, err := ParseYAML([]byte(yamlAnchorsSource), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A changed alias is written in full, leaving the anchor on the unchanged
+	// node, and a merged value that no longer matches is written in the mapping
+	data["backup"].(map[string]any)["hosts"] = []any{"c"}
+	data["dev"].(map[string]any)["adapter"] = "mysql"
+
+	out, err := MarshalYAML(data, comments, anchors, keyTypes, ".", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(out), "*hosts") || !strings.Contains(string(out), "hosts: &hosts") {
+		t.Errorf("Expected the diverged alias written in full and the anchor kept on hosts, got:\n%s", out)
+	}
+	if !strings.Contains(string(out), "adapter: mysql") {
+		t.Errorf("Expected the diverged merged value written in the mapping, got:\n%s", out)
+	}
+	again, _, _, _, err := ParseYAML(out, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(again, data) {
+		t.Errorf("Expected the written document to parse back to %v, got %v\n%s", data, again, out)
+	}
+}
+
+func TestAnchorsFileRoundTrip(t *testing.T) {
+	_, _, anchors, _, err := ParseYAML([]byte(yamlAnchorsSource), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := t.TempDir() + "/doc.yaml" + AnchorsSuffix
+	if err := WriteAnchorsFile(path, anchors); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadAnchorsFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, anchors) {
+		t.Errorf("Expected %+v read back, got %+v", anchors, read)
+	}
+	if missing, err := ReadAnchorsFile(path + ".missing"); missing != nil || err != nil {
+		t.Errorf("Expected nil for a missing file, got %v, %v", missing, err)
+	}
+}